@@ -4,7 +4,9 @@ package client
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/kelda/kelda/api"
@@ -56,14 +58,72 @@ type Client interface {
 	// QueryImages retrieves the image information tracked by the Kelda daemon.
 	QueryImages() ([]db.Image, error)
 
+	// SelectMachines retrieves the machines that match `query`.
+	SelectMachines(query api.Query) ([]db.Machine, error)
+
+	// SelectContainers retrieves the containers that match `query`.
+	SelectContainers(query api.Query) ([]db.Container, error)
+
+	// SelectConnections retrieves the connections that match `query`.
+	SelectConnections(query api.Query) ([]db.Connection, error)
+
+	// SelectLoadBalancers retrieves the load balancers that match `query`.
+	SelectLoadBalancers(query api.Query) ([]db.LoadBalancer, error)
+
+	// SelectImages retrieves the images that match `query`.
+	SelectImages(query api.Query) ([]db.Image, error)
+
+	// SelectStats retrieves the resource usage stats that match `query`.
+	SelectStats(query api.Query) ([]db.Stats, error)
+
 	// SetSecret sets the value of a named secret in the cluster. The value is
 	// encrypted and stored in Vault.
 	SetSecret(name, value string) error
 
+	// ListSecrets retrieves the names and versions of the secrets in the
+	// cluster, along with the containers that reference them. Secret values
+	// are never returned.
+	ListSecrets() ([]pb.SecretInfo, error)
+
+	// DeleteSecret removes a secret and all of its prior versions from the
+	// cluster.
+	DeleteSecret(name string) error
+
+	// RollbackSecret restores a prior version of a secret. If version is zero,
+	// the most recent prior version is restored.
+	RollbackSecret(name string, version int64) error
+
+	// Exec runs a command in a container, and returns the command's exit code.
+	// Exec blocks until the command exits.
+	Exec(opts api.ExecOptions) (int, error)
+
+	// Logs writes the logs of a container to opts.Stdout and opts.Stderr. If
+	// opts.Follow is set, Logs blocks until the logs end or opts.Context is
+	// cancelled.
+	Logs(opts api.LogsOptions) error
+
+	// PortForward tunnels a connection to a port in a container. PortForward
+	// blocks until the container closes the connection.
+	PortForward(opts api.PortForwardOptions) error
+
 	// Deploy makes a request to the Kelda daemon to deploy the given deployment.
 	// Only defined on the daemon.
 	Deploy(deployment string) error
 
+	// DeployFromSource is like Deploy, but also records a hash of the file
+	// that the deployment was compiled from in the deployment history.
+	// Only defined on the daemon.
+	DeployFromSource(deployment, sourceHash string) error
+
+	// DeploymentHistory retrieves the blueprints deployed by the Kelda daemon,
+	// ordered from oldest to newest. Only defined on the daemon.
+	DeploymentHistory() ([]pb.Deployment, error)
+
+	// Rollback redeploys the blueprint with the given version from the
+	// deployment history. If version is zero, the deployment before the
+	// current one is redeployed. Only defined on the daemon.
+	Rollback(version int64) error
+
 	// Version retrieves the Kelda version of the remote daemon.
 	Version() (string, error)
 }
@@ -104,8 +164,14 @@ func New(lAddr string, creds connection.Credentials) (Client, error) {
 // Writes the result into `v` a pointer to a slice of database structs.  For example
 // *[]db.Machine.
 func query(pbClient pb.APIClient, table db.TableType, v interface{}) error {
+	return selectRows(pbClient, table, api.Query{}, v)
+}
+
+// selectRows is like query, but only retrieves the rows matching `q`.
+func selectRows(pbClient pb.APIClient, table db.TableType, q api.Query,
+	v interface{}) error {
 	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
-	reply, err := pbClient.Query(ctx, &pb.DBQuery{Table: string(table)})
+	reply, err := pbClient.Query(ctx, q.ToPB(table))
 	if err != nil {
 		return err
 	}
@@ -162,6 +228,42 @@ func (c clientImpl) QueryImages() ([]db.Image, error) {
 	return rows, query(c.pbClient, db.ImageTable, &rows)
 }
 
+// SelectMachines retrieves the machines that match `q`.
+func (c clientImpl) SelectMachines(q api.Query) ([]db.Machine, error) {
+	var rows []db.Machine
+	return rows, selectRows(c.pbClient, db.MachineTable, q, &rows)
+}
+
+// SelectContainers retrieves the containers that match `q`.
+func (c clientImpl) SelectContainers(q api.Query) ([]db.Container, error) {
+	var rows []db.Container
+	return rows, selectRows(c.pbClient, db.ContainerTable, q, &rows)
+}
+
+// SelectConnections retrieves the connections that match `q`.
+func (c clientImpl) SelectConnections(q api.Query) ([]db.Connection, error) {
+	var rows []db.Connection
+	return rows, selectRows(c.pbClient, db.ConnectionTable, q, &rows)
+}
+
+// SelectLoadBalancers retrieves the load balancers that match `q`.
+func (c clientImpl) SelectLoadBalancers(q api.Query) ([]db.LoadBalancer, error) {
+	var rows []db.LoadBalancer
+	return rows, selectRows(c.pbClient, db.LoadBalancerTable, q, &rows)
+}
+
+// SelectImages retrieves the images that match `q`.
+func (c clientImpl) SelectImages(q api.Query) ([]db.Image, error) {
+	var rows []db.Image
+	return rows, selectRows(c.pbClient, db.ImageTable, q, &rows)
+}
+
+// SelectStats retrieves the resource usage stats that match `q`.
+func (c clientImpl) SelectStats(q api.Query) ([]db.Stats, error) {
+	var rows []db.Stats
+	return rows, selectRows(c.pbClient, db.StatsTable, q, &rows)
+}
+
 // QueryCounters retrieves the debugging counters tracked with the Kelda daemon.
 func (c clientImpl) QueryCounters() ([]pb.Counter, error) {
 	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
@@ -198,10 +300,274 @@ func (c clientImpl) SetSecret(name, value string) error {
 	return err
 }
 
+// ListSecrets retrieves metadata about the secrets in the cluster.
+func (c clientImpl) ListSecrets() ([]pb.SecretInfo, error) {
+	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	reply, err := c.pbClient.ListSecrets(ctx, &pb.ListSecretsRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var secrets []pb.SecretInfo
+	for _, secret := range reply.Secrets {
+		secrets = append(secrets, *secret)
+	}
+	return secrets, nil
+}
+
+// DeleteSecret removes a secret from the cluster.
+func (c clientImpl) DeleteSecret(name string) error {
+	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	_, err := c.pbClient.DeleteSecret(ctx, &pb.DeleteSecretRequest{Name: name})
+	return err
+}
+
+// RollbackSecret restores a prior version of a secret.
+func (c clientImpl) RollbackSecret(name string, version int64) error {
+	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	_, err := c.pbClient.RollbackSecret(ctx,
+		&pb.RollbackSecretRequest{Name: name, Version: version})
+	return err
+}
+
+// Exec runs a command in a container, and returns its exit code.
+func (c clientImpl) Exec(opts api.ExecOptions) (int, error) {
+	// There's no timeout because the command may be interactive. The context
+	// is cancelled once the command exits to stop forwarding input.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.pbClient.Exec(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = stream.Send(&pb.ExecInput{Start: &pb.ExecStart{
+		Container: opts.Container,
+		Command:   opts.Command,
+		Stdin:     opts.Stdin != nil,
+		Tty:       opts.TTY,
+		PodName:   opts.PodName,
+	}})
+	if err != nil {
+		return 0, err
+	}
+
+	go sendExecInput(ctx, stream, opts.Stdin, opts.Resize)
+
+	for {
+		output, err := stream.Recv()
+		if err == io.EOF {
+			return 0, errors.New("stream closed before the command exited")
+		} else if err != nil {
+			return 0, err
+		}
+
+		if len(output.Stdout) != 0 && opts.Stdout != nil {
+			opts.Stdout.Write(output.Stdout)
+		}
+		if len(output.Stderr) != 0 && opts.Stderr != nil {
+			opts.Stderr.Write(output.Stderr)
+		}
+
+		if output.Exited {
+			return int(output.ExitCode), nil
+		}
+	}
+}
+
+// sendExecInput forwards `stdin` and the terminal sizes read from `resize` to
+// the Exec stream until `ctx` is cancelled. All messages are sent from this
+// goroutine because gRPC streams don't support concurrent sends.
+func sendExecInput(ctx context.Context, stream pb.API_ExecClient, stdin io.Reader,
+	resize <-chan pb.TerminalSize) {
+	var stdinChunks chan []byte
+	if stdin != nil {
+		stdinChunks = make(chan []byte)
+		go readChunks(ctx, stdin, stdinChunks)
+	}
+
+	for {
+		var input pb.ExecInput
+		select {
+		case chunk, ok := <-stdinChunks:
+			if !ok {
+				input.StdinClosed = true
+				stdinChunks = nil
+			}
+			input.Stdin = chunk
+		case size, ok := <-resize:
+			if !ok {
+				resize = nil
+				continue
+			}
+			input.Resize = &size
+		case <-ctx.Done():
+			return
+		}
+
+		if err := stream.Send(&input); err != nil {
+			return
+		}
+	}
+}
+
+// readChunks sends the data read from `r` to `chunks`, and closes `chunks` once
+// `r` has been fully read.
+func readChunks(ctx context.Context, r io.Reader, chunks chan<- []byte) {
+	defer close(chunks)
+	for {
+		buf := make([]byte, 32*1024)
+		n, err := r.Read(buf)
+		if n > 0 {
+			select {
+			case chunks <- buf[:n]:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Logs writes the logs of a container.
+func (c clientImpl) Logs(opts api.LogsOptions) error {
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// There's no timeout because the logs may be followed indefinitely.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	req := &pb.LogsRequest{
+		Container:  opts.Container,
+		Follow:     opts.Follow,
+		Tail:       int32(opts.Tail),
+		Timestamps: opts.Timestamps,
+		Previous:   opts.Previous,
+		PodName:    opts.PodName,
+	}
+	if !opts.Since.IsZero() {
+		req.Since = opts.Since.Unix()
+	}
+
+	stream, err := c.pbClient.Logs(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		output, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if len(output.Stdout) != 0 && opts.Stdout != nil {
+			opts.Stdout.Write(output.Stdout)
+		}
+		if len(output.Stderr) != 0 && opts.Stderr != nil {
+			opts.Stderr.Write(output.Stderr)
+		}
+	}
+}
+
+// PortForward tunnels a connection to a port in a container.
+func (c clientImpl) PortForward(opts api.PortForwardOptions) error {
+	// There's no timeout because the connection may be long lived. The
+	// context is cancelled once the container closes the connection to stop
+	// forwarding data.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := c.pbClient.PortForward(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = stream.Send(&pb.PortForwardInput{Start: &pb.PortForwardStart{
+		Target: opts.Target,
+		Port:   int32(opts.Port),
+		IP:     opts.IP,
+	}})
+	if err != nil {
+		return err
+	}
+
+	go sendPortForwardInput(ctx, stream, opts.Conn)
+
+	for {
+		output, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if _, err := opts.Conn.Write(output.Data); err != nil {
+			return err
+		}
+	}
+}
+
+// sendPortForwardInput forwards the data read from `conn` to the PortForward
+// stream, and closes the sending side of the stream once `conn` has been fully
+// read.
+func sendPortForwardInput(ctx context.Context, stream pb.API_PortForwardClient,
+	conn io.Reader) {
+	chunks := make(chan []byte)
+	go readChunks(ctx, conn, chunks)
+
+	for chunk := range chunks {
+		if err := stream.Send(&pb.PortForwardInput{Data: chunk}); err != nil {
+			return
+		}
+	}
+	stream.CloseSend()
+}
+
 // Deploy makes a request to the Kelda daemon to deploy the given deployment.
 func (c clientImpl) Deploy(deployment string) error {
+	return c.DeployFromSource(deployment, "")
+}
+
+// DeployFromSource makes a request to the Kelda daemon to deploy the given
+// deployment, which was compiled from a file with the given hash.
+func (c clientImpl) DeployFromSource(deployment, sourceHash string) error {
+	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	_, err := c.pbClient.Deploy(ctx, &pb.DeployRequest{
+		Deployment: deployment,
+		SourceHash: sourceHash,
+	})
+	return err
+}
+
+// DeploymentHistory retrieves the blueprints deployed by the Kelda daemon.
+func (c clientImpl) DeploymentHistory() ([]pb.Deployment, error) {
+	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
+	reply, err := c.pbClient.DeploymentHistory(ctx,
+		&pb.DeploymentHistoryRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	var deployments []pb.Deployment
+	for _, deployment := range reply.Deployments {
+		deployments = append(deployments, *deployment)
+	}
+	return deployments, nil
+}
+
+// Rollback redeploys a blueprint from the deployment history.
+func (c clientImpl) Rollback(version int64) error {
 	ctx, _ := context.WithTimeout(context.Background(), requestTimeout)
-	_, err := c.pbClient.Deploy(ctx, &pb.DeployRequest{Deployment: deployment})
+	_, err := c.pbClient.Rollback(ctx, &pb.RollbackRequest{Version: version})
 	return err
 }
 
@@ -1,20 +1,30 @@
 package client
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/api/pb"
 	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/util"
 )
 
 type mockAPIClient struct {
-	mockResponse string
-	mockError    error
+	mockResponse  string
+	mockError     error
+	execStream    *mockExecClient
+	logsStream    *mockLogsClient
+	forwardStream *mockPortForwardClient
 }
 
 func (c mockAPIClient) Query(ctx context.Context, in *pb.DBQuery,
@@ -29,6 +39,19 @@ func (c mockAPIClient) Deploy(ctx context.Context, in *pb.DeployRequest,
 	return &pb.DeployReply{}, nil
 }
 
+func (c mockAPIClient) DeploymentHistory(ctx context.Context,
+	in *pb.DeploymentHistoryRequest, opts ...grpc.CallOption) (
+	*pb.DeploymentHistoryReply, error) {
+
+	return &pb.DeploymentHistoryReply{}, nil
+}
+
+func (c mockAPIClient) Rollback(ctx context.Context, in *pb.RollbackRequest,
+	opts ...grpc.CallOption) (*pb.DeployReply, error) {
+
+	return &pb.DeployReply{}, nil
+}
+
 func (c mockAPIClient) QueryCounters(ctx context.Context, in *pb.CountersRequest,
 	opts ...grpc.CallOption) (*pb.CountersReply, error) {
 
@@ -53,6 +76,131 @@ func (c mockAPIClient) SetSecret(ctx context.Context, in *pb.Secret,
 	return &pb.SecretReply{}, nil
 }
 
+func (c mockAPIClient) ListSecrets(ctx context.Context, in *pb.ListSecretsRequest,
+	opts ...grpc.CallOption) (*pb.ListSecretsReply, error) {
+
+	return &pb.ListSecretsReply{}, nil
+}
+
+func (c mockAPIClient) DeleteSecret(ctx context.Context, in *pb.DeleteSecretRequest,
+	opts ...grpc.CallOption) (*pb.SecretReply, error) {
+
+	return &pb.SecretReply{}, nil
+}
+
+func (c mockAPIClient) RollbackSecret(ctx context.Context,
+	in *pb.RollbackSecretRequest, opts ...grpc.CallOption) (*pb.SecretReply, error) {
+
+	return &pb.SecretReply{}, nil
+}
+
+func (c mockAPIClient) Exec(ctx context.Context, opts ...grpc.CallOption) (
+	pb.API_ExecClient, error) {
+
+	return c.execStream, c.mockError
+}
+
+// mockExecClient records the messages sent to an Exec stream, and replies with
+// `outputs`.
+type mockExecClient struct {
+	sync.Mutex
+	sent    []*pb.ExecInput
+	outputs []*pb.ExecOutput
+
+	grpc.ClientStream
+}
+
+func (c *mockExecClient) Send(input *pb.ExecInput) error {
+	c.Lock()
+	defer c.Unlock()
+	c.sent = append(c.sent, input)
+	return nil
+}
+
+func (c *mockExecClient) Recv() (*pb.ExecOutput, error) {
+	c.Lock()
+	defer c.Unlock()
+	if len(c.outputs) == 0 {
+		return nil, io.EOF
+	}
+
+	output := c.outputs[0]
+	c.outputs = c.outputs[1:]
+	return output, nil
+}
+
+func (c mockAPIClient) Logs(ctx context.Context, in *pb.LogsRequest,
+	opts ...grpc.CallOption) (pb.API_LogsClient, error) {
+
+	if c.logsStream != nil {
+		c.logsStream.request = in
+	}
+	return c.logsStream, c.mockError
+}
+
+// mockLogsClient records the request that started a Logs stream, and replies
+// with `outputs`.
+type mockLogsClient struct {
+	request *pb.LogsRequest
+	outputs []*pb.LogsOutput
+
+	grpc.ClientStream
+}
+
+func (c *mockLogsClient) Recv() (*pb.LogsOutput, error) {
+	if len(c.outputs) == 0 {
+		return nil, io.EOF
+	}
+
+	output := c.outputs[0]
+	c.outputs = c.outputs[1:]
+	return output, nil
+}
+
+func (c mockAPIClient) PortForward(ctx context.Context, opts ...grpc.CallOption) (
+	pb.API_PortForwardClient, error) {
+
+	return c.forwardStream, c.mockError
+}
+
+// mockPortForwardClient records the messages sent to a PortForward stream, and
+// replies with `outputs`. Like a server that responds once it has read the
+// entire request, the stream ends once the client closes its side.
+type mockPortForwardClient struct {
+	sync.Mutex
+	sent       []*pb.PortForwardInput
+	sendClosed chan struct{}
+	outputs    []*pb.PortForwardOutput
+
+	grpc.ClientStream
+}
+
+func (c *mockPortForwardClient) Send(input *pb.PortForwardInput) error {
+	c.Lock()
+	defer c.Unlock()
+	c.sent = append(c.sent, input)
+	return nil
+}
+
+func (c *mockPortForwardClient) CloseSend() error {
+	close(c.sendClosed)
+	return nil
+}
+
+func (c *mockPortForwardClient) Recv() (*pb.PortForwardOutput, error) {
+	c.Lock()
+	if len(c.outputs) == 0 {
+		c.Unlock()
+		<-c.sendClosed
+		return nil, io.EOF
+	}
+	defer c.Unlock()
+
+	output := c.outputs[0]
+	c.outputs = c.outputs[1:]
+	return output, nil
+}
+
 func TestUnmarshalMachine(t *testing.T) {
 	t.Parallel()
 
@@ -139,3 +287,139 @@ func TestGrpcError(t *testing.T) {
 	_, err := c.QueryMachines()
 	assert.EqualError(t, err, "timeout")
 }
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	stream := &mockExecClient{outputs: []*pb.ExecOutput{
+		{Stdout: []byte("out")},
+		{Stderr: []byte("err")},
+		{Exited: true, ExitCode: 2},
+	}}
+	c := clientImpl{pbClient: mockAPIClient{execStream: stream}}
+
+	var stdout, stderr bytes.Buffer
+	exitCode, err := c.Exec(api.ExecOptions{
+		Container: "container",
+		Command:   []string{"cat", "-"},
+		Stdin:     strings.NewReader("in"),
+		Stdout:    &stdout,
+		Stderr:    &stderr,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, exitCode)
+	assert.Equal(t, "out", stdout.String())
+	assert.Equal(t, "err", stderr.String())
+
+	stream.Lock()
+	assert.Equal(t, &pb.ExecInput{Start: &pb.ExecStart{
+		Container: "container",
+		Command:   []string{"cat", "-"},
+		Stdin:     true,
+	}}, stream.sent[0])
+	stream.Unlock()
+
+	stream = &mockExecClient{outputs: []*pb.ExecOutput{{Stdout: []byte("out")}}}
+	c = clientImpl{pbClient: mockAPIClient{execStream: stream}}
+	_, err = c.Exec(api.ExecOptions{Container: "container"})
+	assert.EqualError(t, err, "stream closed before the command exited")
+}
+
+func TestSendExecInput(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resize := make(chan pb.TerminalSize)
+	stream := &mockExecClient{}
+	done := make(chan struct{})
+	go func() {
+		sendExecInput(ctx, stream, strings.NewReader("in"), resize)
+		close(done)
+	}()
+
+	resize <- pb.TerminalSize{Height: 24, Width: 80}
+	close(resize)
+
+	// Wait until both the input and the resize have been sent.
+	err := util.BackoffWaitFor(func() bool {
+		stream.Lock()
+		defer stream.Unlock()
+		return len(stream.sent) == 3
+	}, 10*time.Millisecond, 5*time.Second)
+	assert.NoError(t, err)
+	cancel()
+	<-done
+
+	assert.Contains(t, stream.sent, &pb.ExecInput{Stdin: []byte("in")})
+	assert.Contains(t, stream.sent, &pb.ExecInput{StdinClosed: true})
+	assert.Contains(t, stream.sent,
+		&pb.ExecInput{Resize: &pb.TerminalSize{Height: 24, Width: 80}})
+}
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+
+	stream := &mockLogsClient{outputs: []*pb.LogsOutput{
+		{Stdout: []byte("out\n")},
+		{Stderr: []byte("err\n")},
+	}}
+	c := clientImpl{pbClient: mockAPIClient{logsStream: stream}}
+
+	var stdout, stderr bytes.Buffer
+	err := c.Logs(api.LogsOptions{
+		Container: "container",
+		Follow:    true,
+		Since:     time.Unix(100, 0),
+		Tail:      10,
+		Previous:  true,
+		Stdout:    &stdout,
+		Stderr:    &stderr,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "out\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+	assert.Equal(t, &pb.LogsRequest{
+		Container: "container",
+		Follow:    true,
+		Since:     100,
+		Tail:      10,
+		Previous:  true,
+	}, stream.request)
+
+	c = clientImpl{pbClient: mockAPIClient{mockError: errors.New("error")}}
+	assert.EqualError(t, c.Logs(api.LogsOptions{Container: "container"}), "error")
+}
+
+func TestPortForward(t *testing.T) {
+	t.Parallel()
+
+	stream := &mockPortForwardClient{
+		sendClosed: make(chan struct{}),
+		outputs: []*pb.PortForwardOutput{
+			{Data: []byte("res")},
+			{Data: []byte("ponse")},
+		},
+	}
+	c := clientImpl{pbClient: mockAPIClient{forwardStream: stream}}
+
+	var received bytes.Buffer
+	conn := struct {
+		io.Reader
+		io.Writer
+	}{strings.NewReader("request"), &received}
+
+	err := c.PortForward(api.PortForwardOptions{
+		Target: "container",
+		Port:   80,
+		Conn:   conn,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "response", received.String())
+
+	stream.Lock()
+	defer stream.Unlock()
+	assert.Equal(t, []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "container", Port: 80}},
+		{Data: []byte("request")},
+	}, stream.sent)
+}
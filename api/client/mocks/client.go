@@ -2,6 +2,7 @@
 
 package mocks
 
+import api "github.com/kelda/kelda/api"
 import db "github.com/kelda/kelda/db"
 import mock "github.com/stretchr/testify/mock"
 import pb "github.com/kelda/kelda/api/pb"
@@ -25,6 +26,20 @@ func (_m *Client) Close() error {
 	return r0
 }
 
+// DeleteSecret provides a mock function with given fields: name
+func (_m *Client) DeleteSecret(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Deploy provides a mock function with given fields: deployment
 func (_m *Client) Deploy(deployment string) error {
 	ret := _m.Called(deployment)
@@ -39,6 +54,115 @@ func (_m *Client) Deploy(deployment string) error {
 	return r0
 }
 
+// DeployFromSource provides a mock function with given fields: deployment, sourceHash
+func (_m *Client) DeployFromSource(deployment string, sourceHash string) error {
+	ret := _m.Called(deployment, sourceHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(deployment, sourceHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeploymentHistory provides a mock function with given fields:
+func (_m *Client) DeploymentHistory() ([]pb.Deployment, error) {
+	ret := _m.Called()
+
+	var r0 []pb.Deployment
+	if rf, ok := ret.Get(0).(func() []pb.Deployment); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]pb.Deployment)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Exec provides a mock function with given fields: opts
+func (_m *Client) Exec(opts api.ExecOptions) (int, error) {
+	ret := _m.Called(opts)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(api.ExecOptions) int); ok {
+		r0 = rf(opts)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.ExecOptions) error); ok {
+		r1 = rf(opts)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListSecrets provides a mock function with given fields:
+func (_m *Client) ListSecrets() ([]pb.SecretInfo, error) {
+	ret := _m.Called()
+
+	var r0 []pb.SecretInfo
+	if rf, ok := ret.Get(0).(func() []pb.SecretInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]pb.SecretInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Logs provides a mock function with given fields: opts
+func (_m *Client) Logs(opts api.LogsOptions) error {
+	ret := _m.Called(opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(api.LogsOptions) error); ok {
+		r0 = rf(opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PortForward provides a mock function with given fields: opts
+func (_m *Client) PortForward(opts api.PortForwardOptions) error {
+	ret := _m.Called(opts)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(api.PortForwardOptions) error); ok {
+		r0 = rf(opts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // QueryBlueprints provides a mock function with given fields:
 func (_m *Client) QueryBlueprints() ([]db.Blueprint, error) {
 	ret := _m.Called()
@@ -246,6 +370,172 @@ func (_m *Client) QueryMinionCounters(_a0 string) ([]pb.Counter, error) {
 	return r0, r1
 }
 
+// Rollback provides a mock function with given fields: version
+func (_m *Client) Rollback(version int64) error {
+	ret := _m.Called(version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RollbackSecret provides a mock function with given fields: name, version
+func (_m *Client) RollbackSecret(name string, version int64) error {
+	ret := _m.Called(name, version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
+		r0 = rf(name, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SelectConnections provides a mock function with given fields: query
+func (_m *Client) SelectConnections(query api.Query) ([]db.Connection, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.Connection
+	if rf, ok := ret.Get(0).(func(api.Query) []db.Connection); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Connection)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.Query) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelectContainers provides a mock function with given fields: query
+func (_m *Client) SelectContainers(query api.Query) ([]db.Container, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.Container
+	if rf, ok := ret.Get(0).(func(api.Query) []db.Container); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Container)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.Query) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelectImages provides a mock function with given fields: query
+func (_m *Client) SelectImages(query api.Query) ([]db.Image, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.Image
+	if rf, ok := ret.Get(0).(func(api.Query) []db.Image); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Image)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.Query) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelectLoadBalancers provides a mock function with given fields: query
+func (_m *Client) SelectLoadBalancers(query api.Query) ([]db.LoadBalancer, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.LoadBalancer
+	if rf, ok := ret.Get(0).(func(api.Query) []db.LoadBalancer); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.LoadBalancer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.Query) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelectMachines provides a mock function with given fields: query
+func (_m *Client) SelectMachines(query api.Query) ([]db.Machine, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.Machine
+	if rf, ok := ret.Get(0).(func(api.Query) []db.Machine); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Machine)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.Query) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelectStats provides a mock function with given fields: query
+func (_m *Client) SelectStats(query api.Query) ([]db.Stats, error) {
+	ret := _m.Called(query)
+
+	var r0 []db.Stats
+	if rf, ok := ret.Get(0).(func(api.Query) []db.Stats); ok {
+		r0 = rf(query)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]db.Stats)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(api.Query) error); ok {
+		r1 = rf(query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SetSecret provides a mock function with given fields: name, value
 func (_m *Client) SetSecret(name string, value string) error {
 	ret := _m.Called(name, value)
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"io"
+
+	"github.com/kelda/kelda/api/pb"
+)
+
+// ExecOptions describes a command to run in a container.
+type ExecOptions struct {
+	// The hostname of the container to run the command in.
+	Container string
+	Command   []string
+
+	// If Stdin is nil, the command's standard input isn't attached.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Whether to allocate a pseudo-terminal for the command. When a terminal
+	// is allocated, all output is written to Stdout.
+	TTY bool
+
+	// Changes to the size of the terminal are read from Resize until the
+	// command exits. May be nil.
+	Resize <-chan pb.TerminalSize
+
+	// The pod running the container. It's looked up by the daemon, and
+	// should only be set when connecting directly to a minion.
+	PodName string
+}
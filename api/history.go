@@ -0,0 +1,26 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/kelda/kelda/api/pb"
+)
+
+// RollbackTarget returns the deployment with the given version. Version zero
+// refers to the deployment before the current one.
+func RollbackTarget(history []pb.Deployment, version int64) (pb.Deployment, error) {
+	if version == 0 {
+		if len(history) < 2 {
+			return pb.Deployment{}, fmt.Errorf(
+				"no previous deployment to roll back to")
+		}
+		return history[len(history)-2], nil
+	}
+
+	for _, deployment := range history {
+		if deployment.Version == version {
+			return deployment, nil
+		}
+	}
+	return pb.Deployment{}, fmt.Errorf("unknown deployment version %d", version)
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/api/pb"
+)
+
+func TestRollbackTarget(t *testing.T) {
+	t.Parallel()
+
+	history := []pb.Deployment{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	target, err := RollbackTarget(history, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), target.Version)
+
+	target, err = RollbackTarget(history, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), target.Version)
+
+	_, err = RollbackTarget(history, 4)
+	assert.EqualError(t, err, "unknown deployment version 4")
+
+	_, err = RollbackTarget(history[:1], 0)
+	assert.EqualError(t, err, "no previous deployment to roll back to")
+}
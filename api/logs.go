@@ -0,0 +1,40 @@
+package api
+
+import (
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// LogsOptions describes which logs to fetch from a container.
+type LogsOptions struct {
+	// The hostname of the container to fetch the logs of.
+	Container string
+
+	// Whether to keep streaming new logs until Context is cancelled.
+	Follow bool
+
+	// If non-zero, only logs written after Since are shown.
+	Since time.Time
+
+	// If positive, only the last Tail lines of the logs are shown.
+	Tail int
+
+	// Whether to prefix each line with the time it was written.
+	Timestamps bool
+
+	// Whether to show the logs of the previous instance of the container,
+	// e.g. before it crashed and was restarted.
+	Previous bool
+
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Cancelling Context stops the logs from being streamed. May be nil.
+	Context context.Context
+
+	// The pod running the container. It's looked up by the daemon, and
+	// should only be set when connecting directly to a minion.
+	PodName string
+}
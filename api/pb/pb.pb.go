@@ -10,10 +10,29 @@ It is generated from these files:
 It has these top-level messages:
 	Secret
 	SecretReply
+	ListSecretsRequest
+	ListSecretsReply
+	SecretInfo
+	DeleteSecretRequest
+	RollbackSecretRequest
+	ExecInput
+	ExecStart
+	TerminalSize
+	ExecOutput
+	LogsRequest
+	LogsOutput
+	PortForwardInput
+	PortForwardStart
+	PortForwardOutput
 	DBQuery
+	QueryFilter
 	QueryReply
 	DeployRequest
 	DeployReply
+	Deployment
+	DeploymentHistoryRequest
+	DeploymentHistoryReply
+	RollbackRequest
 	VersionRequest
 	VersionReply
 	CountersRequest
@@ -75,18 +94,557 @@ func (m *SecretReply) String() string            { return proto.CompactTextStrin
 func (*SecretReply) ProtoMessage()               {}
 func (*SecretReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{1} }
 
+type ListSecretsRequest struct {
+}
+
+func (m *ListSecretsRequest) Reset()                    { *m = ListSecretsRequest{} }
+func (m *ListSecretsRequest) String() string            { return proto.CompactTextString(m) }
+func (*ListSecretsRequest) ProtoMessage()               {}
+func (*ListSecretsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+
+type ListSecretsReply struct {
+	Secrets []*SecretInfo `protobuf:"bytes,1,rep,name=Secrets" json:"Secrets,omitempty"`
+}
+
+func (m *ListSecretsReply) Reset()                    { *m = ListSecretsReply{} }
+func (m *ListSecretsReply) String() string            { return proto.CompactTextString(m) }
+func (*ListSecretsReply) ProtoMessage()               {}
+func (*ListSecretsReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+
+func (m *ListSecretsReply) GetSecrets() []*SecretInfo {
+	if m != nil {
+		return m.Secrets
+	}
+	return nil
+}
+
+// SecretInfo describes a secret without revealing its value.
+type SecretInfo struct {
+	Name string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	// Incremented each time the secret's value changes.
+	Version int64 `protobuf:"varint,2,opt,name=Version" json:"Version,omitempty"`
+	// The versions that can be restored by RollbackSecret, in increasing order.
+	PriorVersions []int64 `protobuf:"varint,3,rep,packed,name=PriorVersions" json:"PriorVersions,omitempty"`
+	// When the value last changed, in seconds since the Unix epoch.
+	Updated int64 `protobuf:"varint,4,opt,name=Updated" json:"Updated,omitempty"`
+	// The hostnames of the containers that reference the secret.
+	Containers []string `protobuf:"bytes,5,rep,name=Containers" json:"Containers,omitempty"`
+}
+
+func (m *SecretInfo) Reset()                    { *m = SecretInfo{} }
+func (m *SecretInfo) String() string            { return proto.CompactTextString(m) }
+func (*SecretInfo) ProtoMessage()               {}
+func (*SecretInfo) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+
+func (m *SecretInfo) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *SecretInfo) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *SecretInfo) GetPriorVersions() []int64 {
+	if m != nil {
+		return m.PriorVersions
+	}
+	return nil
+}
+
+func (m *SecretInfo) GetUpdated() int64 {
+	if m != nil {
+		return m.Updated
+	}
+	return 0
+}
+
+func (m *SecretInfo) GetContainers() []string {
+	if m != nil {
+		return m.Containers
+	}
+	return nil
+}
+
+type DeleteSecretRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+}
+
+func (m *DeleteSecretRequest) Reset()                    { *m = DeleteSecretRequest{} }
+func (m *DeleteSecretRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeleteSecretRequest) ProtoMessage()               {}
+func (*DeleteSecretRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+
+func (m *DeleteSecretRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type RollbackSecretRequest struct {
+	Name string `protobuf:"bytes,1,opt,name=Name" json:"Name,omitempty"`
+	// The version to restore. Zero restores the most recent prior version.
+	Version int64 `protobuf:"varint,2,opt,name=Version" json:"Version,omitempty"`
+}
+
+func (m *RollbackSecretRequest) Reset()                    { *m = RollbackSecretRequest{} }
+func (m *RollbackSecretRequest) String() string            { return proto.CompactTextString(m) }
+func (*RollbackSecretRequest) ProtoMessage()               {}
+func (*RollbackSecretRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+
+func (m *RollbackSecretRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *RollbackSecretRequest) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+// ExecInput is sent by the client of an Exec stream. The first message must
+// contain Start. Later messages carry the command's standard input and changes
+// to the client's terminal size.
+type ExecInput struct {
+	Start *ExecStart `protobuf:"bytes,1,opt,name=Start" json:"Start,omitempty"`
+	Stdin []byte     `protobuf:"bytes,2,opt,name=Stdin,proto3" json:"Stdin,omitempty"`
+	// Set once all of the standard input has been sent.
+	StdinClosed bool          `protobuf:"varint,3,opt,name=StdinClosed" json:"StdinClosed,omitempty"`
+	Resize      *TerminalSize `protobuf:"bytes,4,opt,name=Resize" json:"Resize,omitempty"`
+}
+
+func (m *ExecInput) Reset()                    { *m = ExecInput{} }
+func (m *ExecInput) String() string            { return proto.CompactTextString(m) }
+func (*ExecInput) ProtoMessage()               {}
+func (*ExecInput) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+
+func (m *ExecInput) GetStart() *ExecStart {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *ExecInput) GetStdin() []byte {
+	if m != nil {
+		return m.Stdin
+	}
+	return nil
+}
+
+func (m *ExecInput) GetStdinClosed() bool {
+	if m != nil {
+		return m.StdinClosed
+	}
+	return false
+}
+
+func (m *ExecInput) GetResize() *TerminalSize {
+	if m != nil {
+		return m.Resize
+	}
+	return nil
+}
+
+type ExecStart struct {
+	// The hostname of the container to run the command in.
+	Container string   `protobuf:"bytes,1,opt,name=Container" json:"Container,omitempty"`
+	Command   []string `protobuf:"bytes,2,rep,name=Command" json:"Command,omitempty"`
+	// Whether to attach the command's standard input, and whether to
+	// allocate a pseudo-terminal for the command.
+	Stdin bool `protobuf:"varint,3,opt,name=Stdin" json:"Stdin,omitempty"`
+	Tty   bool `protobuf:"varint,4,opt,name=Tty" json:"Tty,omitempty"`
+	// The pod running the container. Set by the daemon when it forwards the
+	// stream to a minion.
+	PodName string `protobuf:"bytes,5,opt,name=PodName" json:"PodName,omitempty"`
+}
+
+func (m *ExecStart) Reset()                    { *m = ExecStart{} }
+func (m *ExecStart) String() string            { return proto.CompactTextString(m) }
+func (*ExecStart) ProtoMessage()               {}
+func (*ExecStart) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+
+func (m *ExecStart) GetContainer() string {
+	if m != nil {
+		return m.Container
+	}
+	return ""
+}
+
+func (m *ExecStart) GetCommand() []string {
+	if m != nil {
+		return m.Command
+	}
+	return nil
+}
+
+func (m *ExecStart) GetStdin() bool {
+	if m != nil {
+		return m.Stdin
+	}
+	return false
+}
+
+func (m *ExecStart) GetTty() bool {
+	if m != nil {
+		return m.Tty
+	}
+	return false
+}
+
+func (m *ExecStart) GetPodName() string {
+	if m != nil {
+		return m.PodName
+	}
+	return ""
+}
+
+type TerminalSize struct {
+	Height uint32 `protobuf:"varint,1,opt,name=Height" json:"Height,omitempty"`
+	Width  uint32 `protobuf:"varint,2,opt,name=Width" json:"Width,omitempty"`
+}
+
+func (m *TerminalSize) Reset()                    { *m = TerminalSize{} }
+func (m *TerminalSize) String() string            { return proto.CompactTextString(m) }
+func (*TerminalSize) ProtoMessage()               {}
+func (*TerminalSize) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+
+func (m *TerminalSize) GetHeight() uint32 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+func (m *TerminalSize) GetWidth() uint32 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+// ExecOutput is sent by the server of an Exec stream. The last message has
+// Exited set.
+type ExecOutput struct {
+	Stdout   []byte `protobuf:"bytes,1,opt,name=Stdout,proto3" json:"Stdout,omitempty"`
+	Stderr   []byte `protobuf:"bytes,2,opt,name=Stderr,proto3" json:"Stderr,omitempty"`
+	Exited   bool   `protobuf:"varint,3,opt,name=Exited" json:"Exited,omitempty"`
+	ExitCode int32  `protobuf:"varint,4,opt,name=ExitCode" json:"ExitCode,omitempty"`
+}
+
+func (m *ExecOutput) Reset()                    { *m = ExecOutput{} }
+func (m *ExecOutput) String() string            { return proto.CompactTextString(m) }
+func (*ExecOutput) ProtoMessage()               {}
+func (*ExecOutput) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+
+func (m *ExecOutput) GetStdout() []byte {
+	if m != nil {
+		return m.Stdout
+	}
+	return nil
+}
+
+func (m *ExecOutput) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
+	}
+	return nil
+}
+
+func (m *ExecOutput) GetExited() bool {
+	if m != nil {
+		return m.Exited
+	}
+	return false
+}
+
+func (m *ExecOutput) GetExitCode() int32 {
+	if m != nil {
+		return m.ExitCode
+	}
+	return 0
+}
+
+type LogsRequest struct {
+	// The hostname of the container to fetch the logs of.
+	Container string `protobuf:"bytes,1,opt,name=Container" json:"Container,omitempty"`
+	// Whether to keep streaming new logs.
+	Follow bool `protobuf:"varint,2,opt,name=Follow" json:"Follow,omitempty"`
+	// Only show logs written after this time, in seconds since the Unix
+	// epoch. Zero shows all logs.
+	Since int64 `protobuf:"varint,3,opt,name=Since" json:"Since,omitempty"`
+	// If positive, only show this many lines from the end of the logs.
+	Tail       int32 `protobuf:"varint,4,opt,name=Tail" json:"Tail,omitempty"`
+	Timestamps bool  `protobuf:"varint,5,opt,name=Timestamps" json:"Timestamps,omitempty"`
+	// Show the logs of the previous instance of the container, e.g. before it
+	// crashed and was restarted.
+	Previous bool `protobuf:"varint,6,opt,name=Previous" json:"Previous,omitempty"`
+	// The pod running the container. Set by the daemon when it forwards the
+	// request to a minion.
+	PodName string `protobuf:"bytes,7,opt,name=PodName" json:"PodName,omitempty"`
+}
+
+func (m *LogsRequest) Reset()                    { *m = LogsRequest{} }
+func (m *LogsRequest) String() string            { return proto.CompactTextString(m) }
+func (*LogsRequest) ProtoMessage()               {}
+func (*LogsRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+
+func (m *LogsRequest) GetContainer() string {
+	if m != nil {
+		return m.Container
+	}
+	return ""
+}
+
+func (m *LogsRequest) GetFollow() bool {
+	if m != nil {
+		return m.Follow
+	}
+	return false
+}
+
+func (m *LogsRequest) GetSince() int64 {
+	if m != nil {
+		return m.Since
+	}
+	return 0
+}
+
+func (m *LogsRequest) GetTail() int32 {
+	if m != nil {
+		return m.Tail
+	}
+	return 0
+}
+
+func (m *LogsRequest) GetTimestamps() bool {
+	if m != nil {
+		return m.Timestamps
+	}
+	return false
+}
+
+func (m *LogsRequest) GetPrevious() bool {
+	if m != nil {
+		return m.Previous
+	}
+	return false
+}
+
+func (m *LogsRequest) GetPodName() string {
+	if m != nil {
+		return m.PodName
+	}
+	return ""
+}
+
+type LogsOutput struct {
+	Stdout []byte `protobuf:"bytes,1,opt,name=Stdout,proto3" json:"Stdout,omitempty"`
+	Stderr []byte `protobuf:"bytes,2,opt,name=Stderr,proto3" json:"Stderr,omitempty"`
+}
+
+func (m *LogsOutput) Reset()                    { *m = LogsOutput{} }
+func (m *LogsOutput) String() string            { return proto.CompactTextString(m) }
+func (*LogsOutput) ProtoMessage()               {}
+func (*LogsOutput) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{12} }
+
+func (m *LogsOutput) GetStdout() []byte {
+	if m != nil {
+		return m.Stdout
+	}
+	return nil
+}
+
+func (m *LogsOutput) GetStderr() []byte {
+	if m != nil {
+		return m.Stderr
+	}
+	return nil
+}
+
+// PortForwardInput is sent by the client of a PortForward stream. The first
+// message must contain Start, and later messages carry the data sent to the
+// container. The client closes its side of the stream when the connection's
+// sender closes its side of the connection.
+type PortForwardInput struct {
+	Start *PortForwardStart `protobuf:"bytes,1,opt,name=Start" json:"Start,omitempty"`
+	Data  []byte            `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
+}
+
+func (m *PortForwardInput) Reset()                    { *m = PortForwardInput{} }
+func (m *PortForwardInput) String() string            { return proto.CompactTextString(m) }
+func (*PortForwardInput) ProtoMessage()               {}
+func (*PortForwardInput) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{13} }
+
+func (m *PortForwardInput) GetStart() *PortForwardStart {
+	if m != nil {
+		return m.Start
+	}
+	return nil
+}
+
+func (m *PortForwardInput) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type PortForwardStart struct {
+	// The hostname of a container, or the name of a load balancer.
+	Target string `protobuf:"bytes,1,opt,name=Target" json:"Target,omitempty"`
+	Port   int32  `protobuf:"varint,2,opt,name=Port" json:"Port,omitempty"`
+	// The IP of the container to connect to. Set by the daemon when it
+	// forwards the stream to a minion.
+	IP string `protobuf:"bytes,3,opt,name=IP" json:"IP,omitempty"`
+}
+
+func (m *PortForwardStart) Reset()                    { *m = PortForwardStart{} }
+func (m *PortForwardStart) String() string            { return proto.CompactTextString(m) }
+func (*PortForwardStart) ProtoMessage()               {}
+func (*PortForwardStart) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{14} }
+
+func (m *PortForwardStart) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
+func (m *PortForwardStart) GetPort() int32 {
+	if m != nil {
+		return m.Port
+	}
+	return 0
+}
+
+func (m *PortForwardStart) GetIP() string {
+	if m != nil {
+		return m.IP
+	}
+	return ""
+}
+
+// PortForwardOutput carries the data sent by the container. The stream ends
+// when the container closes the connection.
+type PortForwardOutput struct {
+	Data []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+}
+
+func (m *PortForwardOutput) Reset()                    { *m = PortForwardOutput{} }
+func (m *PortForwardOutput) String() string            { return proto.CompactTextString(m) }
+func (*PortForwardOutput) ProtoMessage()               {}
+func (*PortForwardOutput) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{15} }
+
+func (m *PortForwardOutput) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
 type DBQuery struct {
 	Table string `protobuf:"bytes,1,opt,name=Table" json:"Table,omitempty"`
+	// Only rows that match all of the filter's non-empty fields are returned.
+	Filter *QueryFilter `protobuf:"bytes,2,opt,name=Filter" json:"Filter,omitempty"`
+	// The names of the fields to include in each row. If empty, all fields are
+	// included.
+	Fields []string `protobuf:"bytes,3,rep,name=Fields" json:"Fields,omitempty"`
+	// Select a page of the matching rows, ordered by their database IDs. A
+	// Limit of zero returns all rows after Offset.
+	Offset int32 `protobuf:"varint,4,opt,name=Offset" json:"Offset,omitempty"`
+	Limit  int32 `protobuf:"varint,5,opt,name=Limit" json:"Limit,omitempty"`
 }
 
 func (m *DBQuery) Reset()                    { *m = DBQuery{} }
 func (m *DBQuery) String() string            { return proto.CompactTextString(m) }
 func (*DBQuery) ProtoMessage()               {}
-func (*DBQuery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{2} }
+func (*DBQuery) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{16} }
+
+func (m *DBQuery) GetTable() string {
+	if m != nil {
+		return m.Table
+	}
+	return ""
+}
+
+func (m *DBQuery) GetFilter() *QueryFilter {
+	if m != nil {
+		return m.Filter
+	}
+	return nil
+}
+
+func (m *DBQuery) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func (m *DBQuery) GetOffset() int32 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *DBQuery) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type QueryFilter struct {
+	// Hostname and Status are glob patterns, as understood by path.Match.
+	Hostname string `protobuf:"bytes,1,opt,name=Hostname" json:"Hostname,omitempty"`
+	Status   string `protobuf:"bytes,2,opt,name=Status" json:"Status,omitempty"`
+	// The private IP of the minion that the row is on.
+	Minion string `protobuf:"bytes,3,opt,name=Minion" json:"Minion,omitempty"`
+	// The name of a load balancer, or a connection endpoint.
+	Label string `protobuf:"bytes,4,opt,name=Label" json:"Label,omitempty"`
+}
+
+func (m *QueryFilter) Reset()                    { *m = QueryFilter{} }
+func (m *QueryFilter) String() string            { return proto.CompactTextString(m) }
+func (*QueryFilter) ProtoMessage()               {}
+func (*QueryFilter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{17} }
+
+func (m *QueryFilter) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *QueryFilter) GetStatus() string {
+	if m != nil {
+		return m.Status
+	}
+	return ""
+}
+
+func (m *QueryFilter) GetMinion() string {
+	if m != nil {
+		return m.Minion
+	}
+	return ""
+}
 
-func (m *DBQuery) GetTable() string {
+func (m *QueryFilter) GetLabel() string {
 	if m != nil {
-		return m.Table
+		return m.Label
 	}
 	return ""
 }
@@ -98,7 +656,7 @@ type QueryReply struct {
 func (m *QueryReply) Reset()                    { *m = QueryReply{} }
 func (m *QueryReply) String() string            { return proto.CompactTextString(m) }
 func (*QueryReply) ProtoMessage()               {}
-func (*QueryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{3} }
+func (*QueryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{18} }
 
 func (m *QueryReply) GetTableContents() string {
 	if m != nil {
@@ -109,12 +667,14 @@ func (m *QueryReply) GetTableContents() string {
 
 type DeployRequest struct {
 	Deployment string `protobuf:"bytes,1,opt,name=Deployment" json:"Deployment,omitempty"`
+	// A hash of the file that the deployment was compiled from, if any.
+	SourceHash string `protobuf:"bytes,2,opt,name=SourceHash" json:"SourceHash,omitempty"`
 }
 
 func (m *DeployRequest) Reset()                    { *m = DeployRequest{} }
 func (m *DeployRequest) String() string            { return proto.CompactTextString(m) }
 func (*DeployRequest) ProtoMessage()               {}
-func (*DeployRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{4} }
+func (*DeployRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
 
 func (m *DeployRequest) GetDeployment() string {
 	if m != nil {
@@ -123,13 +683,121 @@ func (m *DeployRequest) GetDeployment() string {
 	return ""
 }
 
+func (m *DeployRequest) GetSourceHash() string {
+	if m != nil {
+		return m.SourceHash
+	}
+	return ""
+}
+
 type DeployReply struct {
 }
 
 func (m *DeployReply) Reset()                    { *m = DeployReply{} }
 func (m *DeployReply) String() string            { return proto.CompactTextString(m) }
 func (*DeployReply) ProtoMessage()               {}
-func (*DeployReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{5} }
+func (*DeployReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+// Deployment is a blueprint that was deployed by the daemon.
+type Deployment struct {
+	Version int64 `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
+	// When the blueprint was deployed, in seconds since the Unix epoch.
+	Deployed int64 `protobuf:"varint,2,opt,name=Deployed" json:"Deployed,omitempty"`
+	// The name of the user whose credentials were used to deploy.
+	DeployedBy string `protobuf:"bytes,3,opt,name=DeployedBy" json:"DeployedBy,omitempty"`
+	SourceHash string `protobuf:"bytes,4,opt,name=SourceHash" json:"SourceHash,omitempty"`
+	Blueprint  string `protobuf:"bytes,5,opt,name=Blueprint" json:"Blueprint,omitempty"`
+	// The version that was restored if this deployment was a rollback.
+	RollbackOf int64 `protobuf:"varint,6,opt,name=RollbackOf" json:"RollbackOf,omitempty"`
+}
+
+func (m *Deployment) Reset()                    { *m = Deployment{} }
+func (m *Deployment) String() string            { return proto.CompactTextString(m) }
+func (*Deployment) ProtoMessage()               {}
+func (*Deployment) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *Deployment) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Deployment) GetDeployed() int64 {
+	if m != nil {
+		return m.Deployed
+	}
+	return 0
+}
+
+func (m *Deployment) GetDeployedBy() string {
+	if m != nil {
+		return m.DeployedBy
+	}
+	return ""
+}
+
+func (m *Deployment) GetSourceHash() string {
+	if m != nil {
+		return m.SourceHash
+	}
+	return ""
+}
+
+func (m *Deployment) GetBlueprint() string {
+	if m != nil {
+		return m.Blueprint
+	}
+	return ""
+}
+
+func (m *Deployment) GetRollbackOf() int64 {
+	if m != nil {
+		return m.RollbackOf
+	}
+	return 0
+}
+
+type DeploymentHistoryRequest struct {
+}
+
+func (m *DeploymentHistoryRequest) Reset()                    { *m = DeploymentHistoryRequest{} }
+func (m *DeploymentHistoryRequest) String() string            { return proto.CompactTextString(m) }
+func (*DeploymentHistoryRequest) ProtoMessage()               {}
+func (*DeploymentHistoryRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{22} }
+
+type DeploymentHistoryReply struct {
+	Deployments []*Deployment `protobuf:"bytes,1,rep,name=Deployments" json:"Deployments,omitempty"`
+}
+
+func (m *DeploymentHistoryReply) Reset()                    { *m = DeploymentHistoryReply{} }
+func (m *DeploymentHistoryReply) String() string            { return proto.CompactTextString(m) }
+func (*DeploymentHistoryReply) ProtoMessage()               {}
+func (*DeploymentHistoryReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{23} }
+
+func (m *DeploymentHistoryReply) GetDeployments() []*Deployment {
+	if m != nil {
+		return m.Deployments
+	}
+	return nil
+}
+
+type RollbackRequest struct {
+	// The version to redeploy. Zero redeploys the version before the current one.
+	Version int64 `protobuf:"varint,1,opt,name=Version" json:"Version,omitempty"`
+}
+
+func (m *RollbackRequest) Reset()                    { *m = RollbackRequest{} }
+func (m *RollbackRequest) String() string            { return proto.CompactTextString(m) }
+func (*RollbackRequest) ProtoMessage()               {}
+func (*RollbackRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{24} }
+
+func (m *RollbackRequest) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
 
 type VersionRequest struct {
 }
@@ -137,7 +805,7 @@ type VersionRequest struct {
 func (m *VersionRequest) Reset()                    { *m = VersionRequest{} }
 func (m *VersionRequest) String() string            { return proto.CompactTextString(m) }
 func (*VersionRequest) ProtoMessage()               {}
-func (*VersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{6} }
+func (*VersionRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{25} }
 
 type VersionReply struct {
 	Version string `protobuf:"bytes,1,opt,name=Version" json:"Version,omitempty"`
@@ -146,7 +814,7 @@ type VersionReply struct {
 func (m *VersionReply) Reset()                    { *m = VersionReply{} }
 func (m *VersionReply) String() string            { return proto.CompactTextString(m) }
 func (*VersionReply) ProtoMessage()               {}
-func (*VersionReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{7} }
+func (*VersionReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{26} }
 
 func (m *VersionReply) GetVersion() string {
 	if m != nil {
@@ -161,7 +829,7 @@ type CountersRequest struct {
 func (m *CountersRequest) Reset()                    { *m = CountersRequest{} }
 func (m *CountersRequest) String() string            { return proto.CompactTextString(m) }
 func (*CountersRequest) ProtoMessage()               {}
-func (*CountersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{8} }
+func (*CountersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{27} }
 
 type MinionCountersRequest struct {
 	Host string `protobuf:"bytes,1,opt,name=Host" json:"Host,omitempty"`
@@ -170,7 +838,7 @@ type MinionCountersRequest struct {
 func (m *MinionCountersRequest) Reset()                    { *m = MinionCountersRequest{} }
 func (m *MinionCountersRequest) String() string            { return proto.CompactTextString(m) }
 func (*MinionCountersRequest) ProtoMessage()               {}
-func (*MinionCountersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{9} }
+func (*MinionCountersRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{28} }
 
 func (m *MinionCountersRequest) GetHost() string {
 	if m != nil {
@@ -186,7 +854,7 @@ type CountersReply struct {
 func (m *CountersReply) Reset()                    { *m = CountersReply{} }
 func (m *CountersReply) String() string            { return proto.CompactTextString(m) }
 func (*CountersReply) ProtoMessage()               {}
-func (*CountersReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{10} }
+func (*CountersReply) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{29} }
 
 func (m *CountersReply) GetCounters() []*Counter {
 	if m != nil {
@@ -205,7 +873,7 @@ type Counter struct {
 func (m *Counter) Reset()                    { *m = Counter{} }
 func (m *Counter) String() string            { return proto.CompactTextString(m) }
 func (*Counter) ProtoMessage()               {}
-func (*Counter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{11} }
+func (*Counter) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{30} }
 
 func (m *Counter) GetPkg() string {
 	if m != nil {
@@ -238,10 +906,29 @@ func (m *Counter) GetPrevValue() uint64 {
 func init() {
 	proto.RegisterType((*Secret)(nil), "Secret")
 	proto.RegisterType((*SecretReply)(nil), "SecretReply")
+	proto.RegisterType((*ListSecretsRequest)(nil), "ListSecretsRequest")
+	proto.RegisterType((*ListSecretsReply)(nil), "ListSecretsReply")
+	proto.RegisterType((*SecretInfo)(nil), "SecretInfo")
+	proto.RegisterType((*DeleteSecretRequest)(nil), "DeleteSecretRequest")
+	proto.RegisterType((*RollbackSecretRequest)(nil), "RollbackSecretRequest")
+	proto.RegisterType((*ExecInput)(nil), "ExecInput")
+	proto.RegisterType((*ExecStart)(nil), "ExecStart")
+	proto.RegisterType((*TerminalSize)(nil), "TerminalSize")
+	proto.RegisterType((*ExecOutput)(nil), "ExecOutput")
+	proto.RegisterType((*LogsRequest)(nil), "LogsRequest")
+	proto.RegisterType((*LogsOutput)(nil), "LogsOutput")
+	proto.RegisterType((*PortForwardInput)(nil), "PortForwardInput")
+	proto.RegisterType((*PortForwardStart)(nil), "PortForwardStart")
+	proto.RegisterType((*PortForwardOutput)(nil), "PortForwardOutput")
 	proto.RegisterType((*DBQuery)(nil), "DBQuery")
+	proto.RegisterType((*QueryFilter)(nil), "QueryFilter")
 	proto.RegisterType((*QueryReply)(nil), "QueryReply")
 	proto.RegisterType((*DeployRequest)(nil), "DeployRequest")
 	proto.RegisterType((*DeployReply)(nil), "DeployReply")
+	proto.RegisterType((*Deployment)(nil), "Deployment")
+	proto.RegisterType((*DeploymentHistoryRequest)(nil), "DeploymentHistoryRequest")
+	proto.RegisterType((*DeploymentHistoryReply)(nil), "DeploymentHistoryReply")
+	proto.RegisterType((*RollbackRequest)(nil), "RollbackRequest")
 	proto.RegisterType((*VersionRequest)(nil), "VersionRequest")
 	proto.RegisterType((*VersionReply)(nil), "VersionReply")
 	proto.RegisterType((*CountersRequest)(nil), "CountersRequest")
@@ -266,9 +953,24 @@ type APIClient interface {
 	Version(ctx context.Context, in *VersionRequest, opts ...grpc.CallOption) (*VersionReply, error)
 	QueryCounters(ctx context.Context, in *CountersRequest, opts ...grpc.CallOption) (*CountersReply, error)
 	SetSecret(ctx context.Context, in *Secret, opts ...grpc.CallOption) (*SecretReply, error)
+	ListSecrets(ctx context.Context, in *ListSecretsRequest, opts ...grpc.CallOption) (*ListSecretsReply, error)
+	DeleteSecret(ctx context.Context, in *DeleteSecretRequest, opts ...grpc.CallOption) (*SecretReply, error)
+	RollbackSecret(ctx context.Context, in *RollbackSecretRequest, opts ...grpc.CallOption) (*SecretReply, error)
+	// Run a command in a container. On the daemon, the stream is proxied to
+	// the minion running the container.
+	Exec(ctx context.Context, opts ...grpc.CallOption) (API_ExecClient, error)
+	// Stream the logs of a container. On the daemon, the stream is proxied to
+	// the minion running the container.
+	Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (API_LogsClient, error)
+	// Tunnel a TCP connection to a port on a container, or on a container in
+	// a load balancer. Each stream carries a single connection. On the daemon,
+	// the stream is proxied to the minion running the container.
+	PortForward(ctx context.Context, opts ...grpc.CallOption) (API_PortForwardClient, error)
 	// Only defined on the daemon.
 	Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.CallOption) (*DeployReply, error)
 	QueryMinionCounters(ctx context.Context, in *MinionCountersRequest, opts ...grpc.CallOption) (*CountersReply, error)
+	DeploymentHistory(ctx context.Context, in *DeploymentHistoryRequest, opts ...grpc.CallOption) (*DeploymentHistoryReply, error)
+	Rollback(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*DeployReply, error)
 }
 
 type aPIClient struct {
@@ -315,6 +1017,127 @@ func (c *aPIClient) SetSecret(ctx context.Context, in *Secret, opts ...grpc.Call
 	return out, nil
 }
 
+func (c *aPIClient) ListSecrets(ctx context.Context, in *ListSecretsRequest, opts ...grpc.CallOption) (*ListSecretsReply, error) {
+	out := new(ListSecretsReply)
+	err := grpc.Invoke(ctx, "/API/ListSecrets", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) DeleteSecret(ctx context.Context, in *DeleteSecretRequest, opts ...grpc.CallOption) (*SecretReply, error) {
+	out := new(SecretReply)
+	err := grpc.Invoke(ctx, "/API/DeleteSecret", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) RollbackSecret(ctx context.Context, in *RollbackSecretRequest, opts ...grpc.CallOption) (*SecretReply, error) {
+	out := new(SecretReply)
+	err := grpc.Invoke(ctx, "/API/RollbackSecret", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) Exec(ctx context.Context, opts ...grpc.CallOption) (API_ExecClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[0], c.cc, "/API/Exec", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIExecClient{stream}
+	return x, nil
+}
+
+type API_ExecClient interface {
+	Send(*ExecInput) error
+	Recv() (*ExecOutput, error)
+	grpc.ClientStream
+}
+
+type aPIExecClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIExecClient) Send(m *ExecInput) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aPIExecClient) Recv() (*ExecOutput, error) {
+	m := new(ExecOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) Logs(ctx context.Context, in *LogsRequest, opts ...grpc.CallOption) (API_LogsClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[1], c.cc, "/API/Logs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPILogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type API_LogsClient interface {
+	Recv() (*LogsOutput, error)
+	grpc.ClientStream
+}
+
+type aPILogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPILogsClient) Recv() (*LogsOutput, error) {
+	m := new(LogsOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *aPIClient) PortForward(ctx context.Context, opts ...grpc.CallOption) (API_PortForwardClient, error) {
+	stream, err := grpc.NewClientStream(ctx, &_API_serviceDesc.Streams[2], c.cc, "/API/PortForward", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &aPIPortForwardClient{stream}
+	return x, nil
+}
+
+type API_PortForwardClient interface {
+	Send(*PortForwardInput) error
+	Recv() (*PortForwardOutput, error)
+	grpc.ClientStream
+}
+
+type aPIPortForwardClient struct {
+	grpc.ClientStream
+}
+
+func (x *aPIPortForwardClient) Send(m *PortForwardInput) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *aPIPortForwardClient) Recv() (*PortForwardOutput, error) {
+	m := new(PortForwardOutput)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func (c *aPIClient) Deploy(ctx context.Context, in *DeployRequest, opts ...grpc.CallOption) (*DeployReply, error) {
 	out := new(DeployReply)
 	err := grpc.Invoke(ctx, "/API/Deploy", in, out, c.cc, opts...)
@@ -333,6 +1156,24 @@ func (c *aPIClient) QueryMinionCounters(ctx context.Context, in *MinionCountersR
 	return out, nil
 }
 
+func (c *aPIClient) DeploymentHistory(ctx context.Context, in *DeploymentHistoryRequest, opts ...grpc.CallOption) (*DeploymentHistoryReply, error) {
+	out := new(DeploymentHistoryReply)
+	err := grpc.Invoke(ctx, "/API/DeploymentHistory", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *aPIClient) Rollback(ctx context.Context, in *RollbackRequest, opts ...grpc.CallOption) (*DeployReply, error) {
+	out := new(DeployReply)
+	err := grpc.Invoke(ctx, "/API/Rollback", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for API service
 
 type APIServer interface {
@@ -341,9 +1182,24 @@ type APIServer interface {
 	Version(context.Context, *VersionRequest) (*VersionReply, error)
 	QueryCounters(context.Context, *CountersRequest) (*CountersReply, error)
 	SetSecret(context.Context, *Secret) (*SecretReply, error)
+	ListSecrets(context.Context, *ListSecretsRequest) (*ListSecretsReply, error)
+	DeleteSecret(context.Context, *DeleteSecretRequest) (*SecretReply, error)
+	RollbackSecret(context.Context, *RollbackSecretRequest) (*SecretReply, error)
+	// Run a command in a container. On the daemon, the stream is proxied to
+	// the minion running the container.
+	Exec(API_ExecServer) error
+	// Stream the logs of a container. On the daemon, the stream is proxied to
+	// the minion running the container.
+	Logs(*LogsRequest, API_LogsServer) error
+	// Tunnel a TCP connection to a port on a container, or on a container in
+	// a load balancer. Each stream carries a single connection. On the daemon,
+	// the stream is proxied to the minion running the container.
+	PortForward(API_PortForwardServer) error
 	// Only defined on the daemon.
 	Deploy(context.Context, *DeployRequest) (*DeployReply, error)
 	QueryMinionCounters(context.Context, *MinionCountersRequest) (*CountersReply, error)
+	DeploymentHistory(context.Context, *DeploymentHistoryRequest) (*DeploymentHistoryReply, error)
+	Rollback(context.Context, *RollbackRequest) (*DeployReply, error)
 }
 
 func RegisterAPIServer(s *grpc.Server, srv APIServer) {
@@ -422,6 +1278,133 @@ func _API_SetSecret_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_ListSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).ListSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/ListSecrets",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).ListSecrets(ctx, req.(*ListSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_DeleteSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeleteSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/DeleteSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeleteSecret(ctx, req.(*DeleteSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_RollbackSecret_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackSecretRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).RollbackSecret(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/RollbackSecret",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).RollbackSecret(ctx, req.(*RollbackSecretRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_Exec_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).Exec(&aPIExecServer{stream})
+}
+
+type API_ExecServer interface {
+	Send(*ExecOutput) error
+	Recv() (*ExecInput, error)
+	grpc.ServerStream
+}
+
+type aPIExecServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIExecServer) Send(m *ExecOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aPIExecServer) Recv() (*ExecInput, error) {
+	m := new(ExecInput)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _API_Logs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(APIServer).Logs(m, &aPILogsServer{stream})
+}
+
+type API_LogsServer interface {
+	Send(*LogsOutput) error
+	grpc.ServerStream
+}
+
+type aPILogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPILogsServer) Send(m *LogsOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _API_PortForward_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(APIServer).PortForward(&aPIPortForwardServer{stream})
+}
+
+type API_PortForwardServer interface {
+	Send(*PortForwardOutput) error
+	Recv() (*PortForwardInput, error)
+	grpc.ServerStream
+}
+
+type aPIPortForwardServer struct {
+	grpc.ServerStream
+}
+
+func (x *aPIPortForwardServer) Send(m *PortForwardOutput) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *aPIPortForwardServer) Recv() (*PortForwardInput, error) {
+	m := new(PortForwardInput)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _API_Deploy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeployRequest)
 	if err := dec(in); err != nil {
@@ -458,6 +1441,42 @@ func _API_QueryMinionCounters_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _API_DeploymentHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeploymentHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).DeploymentHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/DeploymentHistory",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).DeploymentHistory(ctx, req.(*DeploymentHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _API_Rollback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(APIServer).Rollback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/API/Rollback",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(APIServer).Rollback(ctx, req.(*RollbackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _API_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "API",
 	HandlerType: (*APIServer)(nil),
@@ -478,6 +1497,18 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SetSecret",
 			Handler:    _API_SetSecret_Handler,
 		},
+		{
+			MethodName: "ListSecrets",
+			Handler:    _API_ListSecrets_Handler,
+		},
+		{
+			MethodName: "DeleteSecret",
+			Handler:    _API_DeleteSecret_Handler,
+		},
+		{
+			MethodName: "RollbackSecret",
+			Handler:    _API_RollbackSecret_Handler,
+		},
 		{
 			MethodName: "Deploy",
 			Handler:    _API_Deploy_Handler,
@@ -486,39 +1517,117 @@ var _API_serviceDesc = grpc.ServiceDesc{
 			MethodName: "QueryMinionCounters",
 			Handler:    _API_QueryMinionCounters_Handler,
 		},
+		{
+			MethodName: "DeploymentHistory",
+			Handler:    _API_DeploymentHistory_Handler,
+		},
+		{
+			MethodName: "Rollback",
+			Handler:    _API_Rollback_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Exec",
+			Handler:       _API_Exec_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Logs",
+			Handler:       _API_Logs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "PortForward",
+			Handler:       _API_PortForward_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "pb/pb.proto",
 }
 
 func init() { proto.RegisterFile("pb/pb.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 402 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0x4d, 0xaf, 0x9a, 0x40,
-	0x14, 0x05, 0xf1, 0x89, 0x5e, 0xc4, 0x67, 0x6f, 0x3f, 0x42, 0x48, 0xd3, 0x92, 0xc9, 0x5b, 0x90,
-	0x98, 0x8c, 0x09, 0xa6, 0xeb, 0xa6, 0xd5, 0x45, 0xbb, 0x68, 0x63, 0xb1, 0x71, 0x2f, 0x66, 0xd2,
-	0x98, 0x22, 0x43, 0x61, 0x68, 0xe2, 0x5f, 0xe9, 0xaf, 0x6d, 0x98, 0x19, 0x10, 0x7c, 0xee, 0xee,
-	0x3d, 0xf7, 0x23, 0x67, 0xce, 0xb9, 0x03, 0x4e, 0x9e, 0x2c, 0xf3, 0x84, 0xe6, 0x05, 0x17, 0x9c,
-	0x44, 0x30, 0xda, 0xb1, 0x63, 0xc1, 0x04, 0x22, 0x0c, 0xbf, 0x1f, 0xce, 0xcc, 0x33, 0x03, 0x33,
-	0x9c, 0xc4, 0x32, 0xc6, 0x57, 0xf0, 0xb0, 0x3f, 0xa4, 0x15, 0xf3, 0x06, 0x12, 0x54, 0x09, 0x71,
-	0xc1, 0x51, 0x33, 0x31, 0xcb, 0xd3, 0x0b, 0x79, 0x0f, 0xf6, 0xe6, 0xf3, 0x8f, 0x8a, 0x15, 0x97,
-	0xba, 0xff, 0xe7, 0x21, 0x49, 0x9b, 0x25, 0x2a, 0x21, 0x11, 0x80, 0x2c, 0xcb, 0x76, 0x7c, 0x02,
-	0x57, 0xc2, 0x6b, 0x9e, 0x09, 0x96, 0x89, 0x52, 0xf7, 0xf6, 0x41, 0xb2, 0x04, 0x77, 0xc3, 0xf2,
-	0x94, 0x5f, 0x62, 0xf6, 0xa7, 0x62, 0xa5, 0xc0, 0x77, 0x00, 0x0a, 0x38, 0xb3, 0x4c, 0xe8, 0x99,
-	0x0e, 0x52, 0x93, 0x6a, 0x06, 0x6a, 0x52, 0x73, 0x98, 0xed, 0x59, 0x51, 0x9e, 0x78, 0xa6, 0x17,
-	0x90, 0x10, 0xa6, 0x2d, 0x52, 0xf3, 0xf0, 0xc0, 0xd6, 0xb9, 0xde, 0xd6, 0xa4, 0xe4, 0x05, 0x3c,
-	0xae, 0x79, 0x95, 0x09, 0x56, 0x94, 0xcd, 0xf0, 0x02, 0x5e, 0x7f, 0x3b, 0x65, 0x27, 0x9e, 0xdd,
-	0x14, 0x6a, 0xd5, 0xbe, 0xf0, 0xb2, 0x21, 0x24, 0x63, 0xf2, 0x01, 0xdc, 0x6b, 0x9b, 0x7a, 0xf2,
-	0xf8, 0xa8, 0x01, 0xcf, 0x0c, 0xac, 0xd0, 0x89, 0xc6, 0x54, 0x77, 0xc4, 0x6d, 0x85, 0x1c, 0xc1,
-	0xd6, 0x20, 0xce, 0xc1, 0xda, 0xfe, 0xfe, 0xa5, 0x97, 0xd6, 0x61, 0xeb, 0xce, 0xe0, 0x9e, 0x3b,
-	0x56, 0x60, 0x86, 0x43, 0xed, 0x0e, 0xbe, 0x85, 0xc9, 0xb6, 0x60, 0x7f, 0x55, 0x65, 0x28, 0x2b,
-	0x57, 0x20, 0xfa, 0x37, 0x00, 0xeb, 0xd3, 0xf6, 0x2b, 0x06, 0xf0, 0xa0, 0x2c, 0x1b, 0x53, 0x6d,
-	0x9e, 0xef, 0xd0, 0xab, 0x4b, 0xc4, 0xc0, 0x45, 0xab, 0x0f, 0x3e, 0xd2, 0xbe, 0x96, 0xbe, 0x4b,
-	0xbb, 0x52, 0x12, 0x03, 0x57, 0xe0, 0xca, 0xe1, 0xe6, 0xdd, 0x38, 0xa7, 0x37, 0x4a, 0xf9, 0x33,
-	0xda, 0x13, 0x85, 0x18, 0xf8, 0x04, 0x93, 0x1d, 0x13, 0xfa, 0xfc, 0x6c, 0xaa, 0x02, 0x7f, 0x4a,
-	0xbb, 0xc7, 0x65, 0x60, 0x08, 0x23, 0x65, 0x2c, 0xce, 0x68, 0xef, 0x24, 0xfc, 0x29, 0xed, 0x3a,
-	0x6e, 0xe0, 0x47, 0x78, 0x29, 0x49, 0xf4, 0x9d, 0xc2, 0x37, 0xf4, 0xae, 0x75, 0xcf, 0x09, 0x25,
-	0x23, 0xf9, 0x27, 0x56, 0xff, 0x03, 0x00, 0x00, 0xff, 0xff, 0x64, 0xa0, 0x22, 0xf6, 0x22, 0x03,
-	0x00, 0x00,
+	// 1235 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0x5b, 0x6f, 0x23, 0x35,
+	0x14, 0xce, 0x34, 0x97, 0x26, 0x67, 0x92, 0x6e, 0xea, 0xed, 0x86, 0x30, 0x42, 0xab, 0xc8, 0x6a,
+	0xb5, 0x41, 0x05, 0xb3, 0xca, 0x82, 0xb8, 0x08, 0x09, 0xd1, 0x1b, 0x8d, 0x54, 0xb6, 0xc1, 0x09,
+	0xcb, 0xf3, 0x24, 0xe3, 0xb6, 0xa3, 0x9d, 0x8c, 0xc3, 0x8c, 0xc3, 0x6e, 0xf6, 0x9d, 0x27, 0xf8,
+	0x05, 0xfc, 0x1a, 0x24, 0x24, 0x7e, 0x17, 0xf2, 0x65, 0x32, 0x9e, 0x34, 0xd5, 0x4a, 0xbc, 0xf9,
+	0xfb, 0xce, 0xd8, 0xe7, 0xf8, 0xb3, 0x7d, 0xce, 0x19, 0x70, 0x17, 0xd3, 0xcf, 0x16, 0x53, 0xb2,
+	0x48, 0xb8, 0xe0, 0x78, 0x00, 0xb5, 0x31, 0x9b, 0x25, 0x4c, 0x20, 0x04, 0x95, 0x97, 0xfe, 0x9c,
+	0x75, 0x9d, 0x9e, 0xd3, 0x6f, 0x50, 0x35, 0x46, 0x07, 0x50, 0x7d, 0xe5, 0x47, 0x4b, 0xd6, 0xdd,
+	0x51, 0xa4, 0x06, 0xb8, 0x05, 0xae, 0x9e, 0x43, 0xd9, 0x22, 0x5a, 0xe1, 0x03, 0x40, 0x57, 0x61,
+	0x2a, 0x34, 0x95, 0x52, 0xf6, 0xeb, 0x92, 0xa5, 0x02, 0x7f, 0x0d, 0xed, 0x02, 0xbb, 0x88, 0x56,
+	0xe8, 0x08, 0x76, 0x0d, 0xee, 0x3a, 0xbd, 0x72, 0xdf, 0x1d, 0xb8, 0x44, 0xe3, 0x61, 0x7c, 0xc3,
+	0x69, 0x66, 0xc3, 0x7f, 0x39, 0x00, 0x39, 0xbf, 0x35, 0xb0, 0x2e, 0xec, 0xbe, 0x62, 0x49, 0x1a,
+	0xf2, 0x58, 0x85, 0x56, 0xa6, 0x19, 0x44, 0x87, 0xd0, 0x1a, 0x25, 0x21, 0x4f, 0x0c, 0x4e, 0xbb,
+	0xe5, 0x5e, 0xb9, 0x5f, 0xa6, 0x45, 0x52, 0xce, 0xff, 0x79, 0x11, 0xf8, 0x82, 0x05, 0xdd, 0x8a,
+	0x9e, 0x6f, 0x20, 0x7a, 0x0a, 0x70, 0xca, 0x63, 0xe1, 0x87, 0x31, 0x4b, 0xd2, 0x6e, 0xb5, 0x57,
+	0xee, 0x37, 0xa8, 0xc5, 0xe0, 0x8f, 0xe1, 0xf1, 0x19, 0x8b, 0x98, 0x60, 0x99, 0x04, 0x6a, 0xbb,
+	0xdb, 0x82, 0xc4, 0xe7, 0xf0, 0x84, 0xf2, 0x28, 0x9a, 0xfa, 0xb3, 0xd7, 0xef, 0xfd, 0xf8, 0xe1,
+	0x1d, 0xe1, 0x3f, 0x1d, 0x68, 0x9c, 0xbf, 0x65, 0xb3, 0x61, 0xbc, 0x58, 0x0a, 0xd4, 0x83, 0xea,
+	0x58, 0xf8, 0x89, 0x50, 0x93, 0xdd, 0x01, 0x10, 0x69, 0x52, 0x0c, 0xd5, 0x06, 0x79, 0x68, 0x63,
+	0x11, 0x84, 0x7a, 0x9d, 0x26, 0xd5, 0x00, 0xf5, 0xc0, 0x55, 0x83, 0xd3, 0x88, 0xa7, 0x2c, 0xe8,
+	0x96, 0x7b, 0x4e, 0xbf, 0x4e, 0x6d, 0x0a, 0x1d, 0x41, 0x8d, 0xb2, 0x34, 0x7c, 0xc7, 0x94, 0x24,
+	0xee, 0xa0, 0x45, 0x26, 0x2c, 0x99, 0x87, 0xb1, 0x1f, 0x8d, 0xc3, 0x77, 0x8c, 0x1a, 0x23, 0xfe,
+	0xdd, 0x84, 0xa3, 0x9d, 0x7d, 0x04, 0x8d, 0xb5, 0x38, 0x66, 0x3f, 0x39, 0x21, 0x37, 0x75, 0xca,
+	0xe7, 0x73, 0x3f, 0x0e, 0xba, 0x3b, 0x4a, 0xc9, 0x0c, 0xe6, 0x41, 0xea, 0x40, 0x4c, 0x90, 0x6d,
+	0x28, 0x4f, 0xc4, 0x4a, 0xf9, 0xaf, 0x53, 0x39, 0x94, 0x2b, 0x8c, 0x78, 0xa0, 0xd4, 0xaa, 0xaa,
+	0xd5, 0x33, 0x88, 0xbf, 0x85, 0xa6, 0x1d, 0x1f, 0xea, 0x40, 0xed, 0x92, 0x85, 0xb7, 0x77, 0x5a,
+	0x99, 0x16, 0x35, 0x48, 0x7a, 0xfa, 0x25, 0x0c, 0xc4, 0x9d, 0x92, 0xa3, 0x45, 0x35, 0xc0, 0x0b,
+	0x00, 0xb9, 0x89, 0xeb, 0xa5, 0x90, 0xa2, 0x76, 0xa0, 0x36, 0x16, 0x01, 0x5f, 0xea, 0xb9, 0x4d,
+	0x6a, 0x90, 0xe1, 0x59, 0x92, 0x18, 0x2d, 0x0d, 0x92, 0xfc, 0xf9, 0xdb, 0x50, 0xac, 0x75, 0x34,
+	0x08, 0x79, 0x50, 0x97, 0xa3, 0x53, 0x1e, 0x68, 0x11, 0xab, 0x74, 0x8d, 0xf1, 0x3f, 0x0e, 0xb8,
+	0x57, 0xfc, 0x36, 0x7b, 0x20, 0xef, 0x51, 0xae, 0x03, 0xb5, 0x0b, 0x1e, 0x45, 0xfc, 0x8d, 0xf2,
+	0x5c, 0xa7, 0x06, 0x29, 0xdd, 0xc2, 0x78, 0xc6, 0x94, 0xe3, 0x32, 0xd5, 0x40, 0x5e, 0xa8, 0x89,
+	0x1f, 0x46, 0xc6, 0xa7, 0x1a, 0xcb, 0x8b, 0x3c, 0x09, 0xe7, 0x2c, 0x15, 0xfe, 0x7c, 0x91, 0x2a,
+	0xf1, 0xea, 0xd4, 0x62, 0x64, 0xac, 0xa3, 0x84, 0xfd, 0x16, 0xf2, 0x65, 0xda, 0xad, 0x29, 0xeb,
+	0x1a, 0xdb, 0xaa, 0xef, 0x6e, 0xaa, 0x0e, 0x72, 0x13, 0xff, 0x4f, 0x37, 0x7c, 0x0d, 0xed, 0x11,
+	0x4f, 0xc4, 0x05, 0x4f, 0xde, 0xf8, 0x49, 0xa0, 0x2f, 0xf4, 0xb3, 0xe2, 0x85, 0xde, 0x27, 0xd6,
+	0x17, 0x85, 0x7b, 0x8d, 0xa0, 0x72, 0xe6, 0x0b, 0xdf, 0x2c, 0xa9, 0xc6, 0xf8, 0x65, 0x61, 0x41,
+	0xfd, 0x5d, 0x07, 0x6a, 0x13, 0x3f, 0xb9, 0x65, 0xc2, 0xa8, 0x6a, 0x90, 0x9c, 0x2f, 0xbf, 0x55,
+	0xf3, 0xab, 0x54, 0x8d, 0xd1, 0x1e, 0xec, 0x0c, 0x47, 0x4a, 0xcb, 0x06, 0xdd, 0x19, 0x8e, 0xf0,
+	0x33, 0xd8, 0xb7, 0xd6, 0x33, 0xbb, 0xcc, 0x1c, 0x3b, 0x96, 0xe3, 0x3f, 0x1c, 0xd8, 0x3d, 0x3b,
+	0xf9, 0x69, 0xc9, 0x92, 0x95, 0x3c, 0x93, 0x89, 0x3f, 0x8d, 0xb2, 0xf7, 0xac, 0x01, 0x3a, 0x84,
+	0xda, 0x45, 0x18, 0x09, 0xa6, 0x35, 0x70, 0x07, 0x4d, 0xa2, 0xbe, 0xd6, 0x1c, 0x35, 0x36, 0x75,
+	0xce, 0x21, 0x8b, 0x02, 0x9d, 0xa7, 0x1a, 0xd4, 0x20, 0xc9, 0x5f, 0xdf, 0xdc, 0xa4, 0x4c, 0x98,
+	0x33, 0x35, 0x48, 0xfa, 0xba, 0x0a, 0xe7, 0xa1, 0x50, 0x07, 0x5a, 0xa5, 0x1a, 0x60, 0x0e, 0xae,
+	0xb5, 0xb8, 0x3c, 0xda, 0x4b, 0x9e, 0x8a, 0x38, 0xcf, 0x31, 0x6b, 0xac, 0x8f, 0xc6, 0x17, 0xcb,
+	0xd4, 0xe4, 0x74, 0x83, 0x24, 0xff, 0x63, 0x18, 0xcb, 0xf4, 0xa3, 0xd5, 0x30, 0x48, 0x39, 0xf4,
+	0xa7, 0x4c, 0xdf, 0xad, 0x06, 0xd5, 0x00, 0x0f, 0x00, 0x94, 0x43, 0x9d, 0xd7, 0x0f, 0xa1, 0xa5,
+	0xf6, 0x2c, 0xaf, 0x2f, 0x8b, 0x55, 0x76, 0x97, 0xdf, 0x16, 0x49, 0x7c, 0x0d, 0xad, 0x33, 0xb6,
+	0x88, 0xf8, 0x2a, 0x7b, 0x01, 0x4f, 0x01, 0x34, 0x31, 0x67, 0x71, 0x76, 0x58, 0x16, 0x23, 0xed,
+	0x63, 0xbe, 0x4c, 0x66, 0xec, 0xd2, 0x4f, 0xef, 0x4c, 0xb8, 0x16, 0x23, 0xeb, 0x50, 0xb6, 0xa0,
+	0xac, 0x43, 0x7f, 0x3b, 0xf6, 0x7a, 0x76, 0x42, 0x75, 0x8a, 0x25, 0xc2, 0x83, 0xba, 0xfe, 0x8e,
+	0x05, 0x26, 0xd7, 0xae, 0x71, 0x1e, 0x13, 0x0b, 0x4e, 0x56, 0x46, 0x0a, 0x8b, 0xd9, 0x88, 0xa9,
+	0xb2, 0x19, 0x93, 0x7c, 0xd5, 0x27, 0xd1, 0x92, 0x2d, 0x92, 0x30, 0x16, 0x26, 0x63, 0xe5, 0x84,
+	0x9c, 0x9d, 0x55, 0x84, 0xeb, 0x1b, 0xf5, 0xea, 0xca, 0xd4, 0x62, 0xb0, 0x07, 0xdd, 0x7c, 0x07,
+	0x97, 0x61, 0x2a, 0x78, 0x92, 0xa9, 0x85, 0x7f, 0x80, 0xce, 0x16, 0x9b, 0x94, 0xff, 0x53, 0x70,
+	0x73, 0x4b, 0x5e, 0x5a, 0x73, 0x8e, 0xda, 0x76, 0x7c, 0x0c, 0x8f, 0x32, 0x97, 0xd9, 0x49, 0x3c,
+	0xa8, 0x15, 0x6e, 0xc3, 0x9e, 0x19, 0x66, 0x71, 0xf4, 0xa1, 0xb9, 0x66, 0xa4, 0xf7, 0x8d, 0xb9,
+	0x8d, 0x7c, 0xee, 0x3e, 0x3c, 0x3a, 0xe5, 0xcb, 0x58, 0xb0, 0x64, 0xdd, 0x15, 0x1c, 0xc3, 0x13,
+	0x7d, 0xaf, 0x36, 0x0c, 0xf2, 0x8d, 0xc9, 0x2b, 0x9a, 0x95, 0x44, 0x39, 0xc6, 0x5f, 0x40, 0x2b,
+	0xff, 0x4c, 0xdf, 0xb3, 0xfa, 0xcc, 0x10, 0x66, 0x97, 0x75, 0x62, 0xbe, 0xa0, 0x6b, 0x0b, 0x9e,
+	0xc9, 0xa2, 0xa3, 0xc6, 0xb2, 0x9e, 0x8c, 0x5e, 0xdf, 0x9a, 0x45, 0xe5, 0x70, 0x5d, 0x7a, 0x77,
+	0xb6, 0x75, 0x39, 0xf2, 0xb8, 0x2b, 0xa6, 0xcb, 0x91, 0x27, 0x29, 0xf3, 0xa1, 0xb6, 0x54, 0x94,
+	0x25, 0x27, 0x06, 0xff, 0x56, 0xa1, 0xfc, 0xfd, 0x68, 0x28, 0xcb, 0xb1, 0x4e, 0x02, 0x75, 0x62,
+	0xd2, 0x81, 0xe7, 0x92, 0xfc, 0x69, 0xe0, 0x12, 0x3a, 0x5e, 0xeb, 0x83, 0x1e, 0x91, 0xa2, 0x96,
+	0x5e, 0x8b, 0xd8, 0x52, 0xe2, 0x12, 0x7a, 0x01, 0x2d, 0x35, 0x39, 0xdb, 0x37, 0x6a, 0x93, 0x0d,
+	0xa5, 0xbc, 0x3d, 0x52, 0x10, 0x05, 0x97, 0xd0, 0x21, 0x34, 0xc6, 0xcc, 0x74, 0x5a, 0x68, 0xd7,
+	0xb4, 0x54, 0x5e, 0x93, 0xd8, 0x4d, 0x5a, 0x09, 0x7d, 0x09, 0xae, 0xd5, 0x90, 0xa1, 0xc7, 0xe4,
+	0x7e, 0xd3, 0xe6, 0xed, 0x93, 0xcd, 0x9e, 0x0d, 0x97, 0xd0, 0xe7, 0xd0, 0xb4, 0x3b, 0x1e, 0x74,
+	0x40, 0xb6, 0x34, 0x40, 0xf7, 0xdc, 0x7d, 0x05, 0x7b, 0xc5, 0xe6, 0x07, 0x75, 0xc8, 0xd6, 0x6e,
+	0xe8, 0xde, 0xcc, 0x23, 0xa8, 0xc8, 0xd2, 0x8c, 0x74, 0x6b, 0xa3, 0x8a, 0x84, 0xe7, 0x92, 0xbc,
+	0x5a, 0xe3, 0x52, 0xdf, 0x79, 0xee, 0xc8, 0xcf, 0x64, 0x25, 0x42, 0x4d, 0x62, 0x55, 0x55, 0xcf,
+	0x25, 0x79, 0x79, 0xc2, 0xa5, 0xe7, 0x0e, 0xfa, 0x06, 0x5c, 0x2b, 0xa3, 0xa3, 0x42, 0x79, 0xd1,
+	0x6b, 0x23, 0x72, 0x2f, 0xe5, 0x1b, 0x17, 0x7d, 0xa8, 0xe9, 0x87, 0x83, 0xf6, 0x48, 0x21, 0x75,
+	0x79, 0x4d, 0x62, 0x67, 0x9e, 0x12, 0xfa, 0x0e, 0x1e, 0xab, 0x73, 0x2b, 0x5e, 0x6e, 0xd4, 0x21,
+	0x5b, 0x6f, 0xfb, 0x96, 0x33, 0x1c, 0xc2, 0xfe, 0xbd, 0xd7, 0x8d, 0x3e, 0x24, 0x0f, 0x65, 0x03,
+	0xef, 0x03, 0xb2, 0x3d, 0x19, 0xe0, 0x12, 0xfa, 0x04, 0xea, 0x99, 0xd0, 0xa8, 0x4d, 0x36, 0x9e,
+	0xfa, 0x66, 0xe4, 0xd3, 0x9a, 0xfa, 0x0f, 0x78, 0xf1, 0xdf, 0x00, 0x55, 0x65, 0x08, 0x2b, 0x16,
+	0x0c, 0x00, 0x00,
 }
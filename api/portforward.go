@@ -0,0 +1,21 @@
+package api
+
+import (
+	"io"
+)
+
+// PortForwardOptions describes a connection to tunnel to a port in a container.
+type PortForwardOptions struct {
+	// The hostname of a container, or the name of a load balancer. A
+	// connection to a load balancer is tunneled to one of its containers.
+	Target string
+	Port   int
+
+	// The data read from Conn is sent to the container, and the data sent by
+	// the container is written to Conn. Conn is closed by the caller.
+	Conn io.ReadWriter
+
+	// The IP of the container to connect to. It's looked up by the daemon,
+	// and should only be set when connecting directly to a minion.
+	IP string
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/db"
+)
+
+// Query restricts the rows returned when querying a table. The filtering and
+// pagination are performed by the server, so only the requested rows are sent
+// over the network.
+type Query struct {
+	// Only rows that match all of the non-empty filters are returned.
+	// Hostname and Status are glob patterns, as understood by path.Match.
+	// Which filters are supported depends on the table being queried.
+	Hostname string
+	Status   string
+	Minion   string
+	Label    string
+
+	// The names of the fields to include in each row. Fields that aren't
+	// included are left as their zero values. If empty, all fields are
+	// included.
+	Fields []string
+
+	// Offset and Limit select a page of the matching rows, ordered by their
+	// database IDs. A Limit of zero returns all rows after Offset.
+	Offset int
+	Limit  int
+}
+
+// ToPB converts the query into its protobuf representation.
+func (q Query) ToPB(table db.TableType) *pb.DBQuery {
+	return &pb.DBQuery{
+		Table: string(table),
+		Filter: &pb.QueryFilter{
+			Hostname: q.Hostname,
+			Status:   q.Status,
+			Minion:   q.Minion,
+			Label:    q.Label,
+		},
+		Fields: q.Fields,
+		Offset: int32(q.Offset),
+		Limit:  int32(q.Limit),
+	}
+}
+
+// QueryFromPB converts the protobuf representation of a query into a Query.
+func QueryFromPB(query *pb.DBQuery) Query {
+	q := Query{
+		Fields: query.Fields,
+		Offset: int(query.Offset),
+		Limit:  int(query.Limit),
+	}
+	if query.Filter != nil {
+		q.Hostname = query.Filter.Hostname
+		q.Status = query.Filter.Status
+		q.Minion = query.Filter.Minion
+		q.Label = query.Filter.Label
+	}
+	return q
+}
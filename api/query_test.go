@@ -0,0 +1,29 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/db"
+)
+
+func TestQueryPB(t *testing.T) {
+	t.Parallel()
+
+	q := Query{
+		Hostname: "web-*",
+		Status:   "running",
+		Minion:   "10.0.0.1",
+		Label:    "web",
+		Fields:   []string{"Hostname"},
+		Offset:   10,
+		Limit:    5,
+	}
+	pbQuery := q.ToPB(db.ContainerTable)
+	assert.Equal(t, string(db.ContainerTable), pbQuery.Table)
+	assert.Equal(t, q, QueryFromPB(pbQuery))
+
+	assert.Equal(t, Query{}, QueryFromPB(&pb.DBQuery{}))
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"strings"
+)
+
+// Role defines which RPCs an API client is allowed to call.
+type Role string
+
+const (
+	// ViewerRole allows clients to query the state of the deployment, but not
+	// to change it.
+	ViewerRole Role = "viewer"
+
+	// DeployerRole allows clients to deploy blueprints in addition to
+	// everything allowed by ViewerRole.
+	DeployerRole Role = "deployer"
+
+	// AdminRole allows clients to call any RPC, including those that manage
+	// secrets.
+	AdminRole Role = "admin"
+)
+
+// Roles contains all roles, ordered from least to most privileged.
+var Roles = []Role{ViewerRole, DeployerRole, AdminRole}
+
+// roleOUPrefix prefixes the Organizational Unit that records the role of a user
+// in their certificate.
+const roleOUPrefix = "kelda-role:"
+
+// userCNPrefix prefixes the Common Name of user certificates.
+const userCNPrefix = "kelda:user:"
+
+// ParseRole returns the Role represented by `str`.
+func ParseRole(str string) (Role, error) {
+	for _, r := range Roles {
+		if string(r) == str {
+			return r, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q", str)
+}
+
+// Allows returns whether a client with role `r` may call RPCs that require the
+// `required` role.
+func (r Role) Allows(required Role) bool {
+	return rank(r) >= rank(required)
+}
+
+func rank(r Role) int {
+	for i, role := range Roles {
+		if role == r {
+			return i
+		}
+	}
+	return -1
+}
+
+// UserSubject returns the certificate subject for a user with the given name and
+// role.
+func UserSubject(name string, role Role) pkix.Name {
+	return pkix.Name{
+		CommonName:         userCNPrefix + name,
+		OrganizationalUnit: []string{roleOUPrefix + string(role)},
+	}
+}
+
+// CertificateUser returns the name and role of the user that `cert` was issued
+// to. `ok` is false if the certificate wasn't issued to a user, which is the
+// case for the certificates used internally by the daemon and minions.
+func CertificateUser(cert *x509.Certificate) (name string, role Role, ok bool,
+	err error) {
+	if !strings.HasPrefix(cert.Subject.CommonName, userCNPrefix) {
+		return "", "", false, nil
+	}
+	name = strings.TrimPrefix(cert.Subject.CommonName, userCNPrefix)
+
+	for _, ou := range cert.Subject.OrganizationalUnit {
+		if strings.HasPrefix(ou, roleOUPrefix) {
+			role, err = ParseRole(strings.TrimPrefix(ou, roleOUPrefix))
+			return name, role, true, err
+		}
+	}
+	return name, "", true, fmt.Errorf("certificate for user %q has no role", name)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleAllows(t *testing.T) {
+	assert.True(t, ViewerRole.Allows(ViewerRole))
+	assert.False(t, ViewerRole.Allows(DeployerRole))
+	assert.False(t, ViewerRole.Allows(AdminRole))
+
+	assert.True(t, DeployerRole.Allows(ViewerRole))
+	assert.True(t, DeployerRole.Allows(DeployerRole))
+	assert.False(t, DeployerRole.Allows(AdminRole))
+
+	assert.True(t, AdminRole.Allows(ViewerRole))
+	assert.True(t, AdminRole.Allows(AdminRole))
+
+	assert.False(t, Role("unknown").Allows(ViewerRole))
+}
+
+func TestParseRole(t *testing.T) {
+	role, err := ParseRole("deployer")
+	assert.NoError(t, err)
+	assert.Equal(t, DeployerRole, role)
+
+	_, err = ParseRole("root")
+	assert.EqualError(t, err, `unknown role "root"`)
+}
+
+func TestCertificateUser(t *testing.T) {
+	cert := &x509.Certificate{Subject: UserSubject("alice", DeployerRole)}
+	name, role, ok, err := CertificateUser(cert)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", name)
+	assert.Equal(t, DeployerRole, role)
+
+	cert = &x509.Certificate{Subject: pkix.Name{CommonName: "kelda:daemon"}}
+	_, _, ok, err = CertificateUser(cert)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	cert = &x509.Certificate{Subject: pkix.Name{CommonName: "kelda:user:bob"}}
+	_, _, ok, err = CertificateUser(cert)
+	assert.True(t, ok)
+	assert.EqualError(t, err, `certificate for user "bob" has no role`)
+}
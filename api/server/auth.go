@@ -0,0 +1,153 @@
+package server
+
+import (
+	"crypto/x509"
+
+	"github.com/kelda/kelda/api"
+	cliPath "github.com/kelda/kelda/cli/path"
+	tlsIO "github.com/kelda/kelda/connection/tls/io"
+
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// methodRoles maps the full name of each RPC to the least privileged role that's
+// allowed to call it. RPCs that aren't listed may only be called by admins.
+var methodRoles = map[string]api.Role{
+	"/API/Query":               api.ViewerRole,
+	"/API/Version":             api.ViewerRole,
+	"/API/QueryCounters":       api.ViewerRole,
+	"/API/QueryMinionCounters": api.ViewerRole,
+	"/API/ListSecrets":         api.ViewerRole,
+	"/API/DeploymentHistory":   api.ViewerRole,
+	"/API/Logs":                api.ViewerRole,
+	"/API/Deploy":              api.DeployerRole,
+	"/API/Rollback":            api.DeployerRole,
+	"/API/Exec":                api.DeployerRole,
+	"/API/PortForward":         api.DeployerRole,
+	"/API/SetSecret":           api.AdminRole,
+	"/API/DeleteSecret":        api.AdminRole,
+	"/API/RollbackSecret":      api.AdminRole,
+}
+
+// authorizer enforces that API clients only call the RPCs allowed by the role
+// in their certificate.
+// Certificates that weren't issued to a user belong to the daemon or a minion,
+// and are allowed to call any RPC. User certificates are only accepted by the
+// daemon because the daemon is the only place that tracks revoked certificates.
+type authorizer struct {
+	runningOnDaemon bool
+}
+
+// InternalInterceptors returns the interceptors for servers that only the daemon
+// and minions should connect to, such as the minion's internal server. They
+// refuse all user certificates.
+func InternalInterceptors() (grpc.UnaryServerInterceptor,
+	grpc.StreamServerInterceptor) {
+	auth := authorizer{runningOnDaemon: false}
+	return auth.unaryInterceptor, auth.streamInterceptor
+}
+
+func (a authorizer) unaryInterceptor(ctx context.Context, req interface{},
+	info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := a.authorize(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a authorizer) streamInterceptor(srv interface{}, stream grpc.ServerStream,
+	info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := a.authorize(stream.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, stream)
+}
+
+func (a authorizer) authorize(ctx context.Context, method string) error {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return err
+	}
+
+	name, role, isUser, err := api.CertificateUser(cert)
+	if err != nil {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if !isUser {
+		return nil
+	}
+
+	if !a.runningOnDaemon {
+		return status.Error(codes.PermissionDenied, "user certificates may "+
+			"only be used to connect to the daemon")
+	}
+
+	users, err := readUsers()
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to read users: %s", err)
+	}
+
+	serial := cert.SerialNumber.String()
+	for _, user := range users {
+		if user.Serial == serial && user.Revoked {
+			return status.Errorf(codes.PermissionDenied,
+				"the certificate for user %q has been revoked", name)
+		}
+	}
+
+	required, ok := methodRoles[method]
+	if !ok {
+		required = api.AdminRole
+	}
+
+	if !role.Allows(required) {
+		return status.Errorf(codes.PermissionDenied, "user %q has role %q, "+
+			"but %s requires role %q", name, role, method, required)
+	}
+	return nil
+}
+
+// peerCertificate returns the verified certificate of the client that made the
+// request in `ctx`.
+func peerCertificate(ctx context.Context) (*x509.Certificate, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no peer information")
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.PeerCertificates) == 0 {
+		return nil, status.Error(codes.Unauthenticated,
+			"no client certificate")
+	}
+	return tlsInfo.State.PeerCertificates[0], nil
+}
+
+// clientName returns a description of the client that made the request in `ctx`
+// for auditing purposes. Clients using certificates that weren't issued to a
+// user are described as "admin".
+func clientName(ctx context.Context) string {
+	cert, err := peerCertificate(ctx)
+	if err != nil {
+		return "unknown"
+	}
+
+	name, _, isUser, err := api.CertificateUser(cert)
+	if err != nil {
+		return "unknown"
+	} else if !isUser {
+		return "admin"
+	}
+	return name
+}
+
+// Saved in a variable to facilitate injecting test users.
+var readUsers = func() ([]tlsIO.User, error) {
+	return tlsIO.ReadUsers(cliPath.DefaultTLSDir)
+}
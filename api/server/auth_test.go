@@ -0,0 +1,86 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/kelda/kelda/api"
+	tlsIO "github.com/kelda/kelda/connection/tls/io"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestAuthorize(t *testing.T) {
+	readUsers = func() ([]tlsIO.User, error) {
+		return []tlsIO.User{
+			{Name: "viewer", Serial: "1"},
+			{Name: "deployer", Serial: "2"},
+			{Name: "revoked", Serial: "3", Revoked: true},
+		}, nil
+	}
+
+	viewer := certContext(api.UserSubject("viewer", api.ViewerRole), 1)
+	deployer := certContext(api.UserSubject("deployer", api.DeployerRole), 2)
+	revoked := certContext(api.UserSubject("revoked", api.AdminRole), 3)
+	daemon := certContext(pkix.Name{CommonName: "kelda:daemon"}, 4)
+
+	daemonAuth := authorizer{runningOnDaemon: true}
+	assert.NoError(t, daemonAuth.authorize(viewer, "/API/Query"))
+	checkCode(t, codes.PermissionDenied, daemonAuth.authorize(viewer, "/API/Deploy"))
+	assert.NoError(t, daemonAuth.authorize(deployer, "/API/Deploy"))
+	checkCode(t, codes.PermissionDenied,
+		daemonAuth.authorize(deployer, "/API/SetSecret"))
+
+	// Unknown RPCs should require the admin role.
+	checkCode(t, codes.PermissionDenied,
+		daemonAuth.authorize(deployer, "/API/Unknown"))
+
+	checkCode(t, codes.PermissionDenied, daemonAuth.authorize(revoked, "/API/Query"))
+	assert.NoError(t, daemonAuth.authorize(daemon, "/API/SetSecret"))
+
+	// Minions should reject user certificates, but allow the daemon's.
+	minionAuth := authorizer{runningOnDaemon: false}
+	checkCode(t, codes.PermissionDenied, minionAuth.authorize(viewer, "/API/Query"))
+	assert.NoError(t, minionAuth.authorize(daemon, "/API/SetSecret"))
+
+	// Requests without TLS information should be rejected.
+	checkCode(t, codes.Unauthenticated,
+		daemonAuth.authorize(context.Background(), "/API/Query"))
+
+	readUsers = func() ([]tlsIO.User, error) {
+		return nil, errors.New("read error")
+	}
+	checkCode(t, codes.Internal, daemonAuth.authorize(viewer, "/API/Query"))
+}
+
+func TestClientName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "alice", clientName(certContext(
+		api.UserSubject("alice", api.ViewerRole), 1)))
+	assert.Equal(t, "admin", clientName(certContext(
+		pkix.Name{CommonName: "kelda:daemon"}, 1)))
+	assert.Equal(t, "unknown", clientName(context.Background()))
+}
+
+func certContext(subject pkix.Name, serial int64) context.Context {
+	cert := &x509.Certificate{Subject: subject, SerialNumber: big.NewInt(serial)}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+		}},
+	})
+}
+
+func checkCode(t *testing.T, exp codes.Code, err error) {
+	assert.Equal(t, exp, grpc.Code(err), "unexpected error: %v", err)
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/docker"
+)
+
+// The labels that the Kubelet applies to the Docker containers that it runs.
+const (
+	podNameLabel       = "io.kubernetes.pod.name"
+	containerNameLabel = "io.kubernetes.container.name"
+)
+
+// minionClient returns a client connected to the minion running the container
+// with the given hostname, and the container as tracked by the leader. Only
+// used on the daemon.
+func (s server) minionClient(hostname string) (client.Client, db.Container, error) {
+	containers, err := s.leaderContainers(api.Query{Hostname: hostname})
+	if err != nil {
+		return nil, db.Container{}, err
+	}
+
+	dbc, err := findContainer(containers, hostname)
+	if err != nil {
+		return nil, db.Container{}, err
+	}
+
+	minionClient, err := s.clientForContainer(dbc)
+	return minionClient, dbc, err
+}
+
+// leaderContainers returns the containers tracked by the leader that match
+// the given query. Only used on the daemon.
+func (s server) leaderContainers(query api.Query) ([]db.Container, error) {
+	leaderClient, err := newLeaderClient(s.conn.SelectFromMachine(nil),
+		s.clientCreds)
+	if err != nil {
+		return nil, err
+	}
+	defer leaderClient.Close()
+
+	return leaderClient.SelectContainers(query)
+}
+
+// clientForContainer returns a client connected to the minion running the
+// given container. Only used on the daemon.
+func (s server) clientForContainer(dbc db.Container) (client.Client, error) {
+	machines := s.conn.SelectFromMachine(func(dbm db.Machine) bool {
+		return dbm.PrivateIP == dbc.Minion
+	})
+	if len(machines) == 0 {
+		return nil, fmt.Errorf("unknown minion %q", dbc.Minion)
+	}
+
+	return newClient(api.RemoteAddress(machines[0].PublicIP), s.clientCreds)
+}
+
+// localContainers returns the Docker containers on this machine that have run
+// the container with the given hostname in the given pod, ordered from newest
+// to oldest. If `podName` is empty, it's looked up in the database.
+func (s server) localContainers(dk docker.Client, hostname, podName string) (
+	[]docker.Container, error) {
+	if podName == "" {
+		dbc, err := findContainer(s.conn.SelectFromContainer(nil), hostname)
+		if err != nil {
+			return nil, err
+		}
+		podName = dbc.PodName
+	}
+
+	containers, err := dk.List(map[string][]string{"label": {
+		podNameLabel + "=" + podName,
+		containerNameLabel + "=" + hostname,
+	}}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Created.After(containers[j].Created)
+	})
+	return containers, nil
+}
+
+// findContainer returns the scheduled container with the given hostname.
+func findContainer(containers []db.Container, hostname string) (db.Container, error) {
+	for _, dbc := range containers {
+		if dbc.Hostname != hostname {
+			continue
+		}
+
+		if dbc.Minion == "" || dbc.PodName == "" {
+			return db.Container{}, fmt.Errorf(
+				"container %q is not yet running", hostname)
+		}
+		return dbc, nil
+	}
+	return db.Container{}, fmt.Errorf("no container with hostname %q", hostname)
+}
+
+// streamWriter passes the data written to it to `send`, which sends it to the
+// client of a stream. Sends are serialized by `sendLock` because gRPC streams
+// don't support concurrent sends.
+type streamWriter struct {
+	send     func(data []byte) error
+	sendLock *sync.Mutex
+}
+
+func (w streamWriter) Write(p []byte) (int, error) {
+	// Copy the data because the caller may reuse `p` once Write returns.
+	data := append([]byte{}, p...)
+
+	w.sendLock.Lock()
+	defer w.sendLock.Unlock()
+	if err := w.send(data); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
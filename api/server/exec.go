@@ -0,0 +1,173 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/minion/docker"
+)
+
+// Exec runs a command in a container. When running on the daemon, the stream
+// is proxied to the minion running the container. On the minion, the command
+// is run with Docker.
+func (s server) Exec(stream pb.API_ExecServer) error {
+	input, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	start := input.Start
+	if start == nil {
+		return errors.New("the first message must start the command")
+	}
+
+	if start.Container == "" || len(start.Command) == 0 {
+		return errors.New("a container and command are required")
+	}
+
+	stdinReader, stdinWriter := io.Pipe()
+	defer stdinReader.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+
+	resize := make(chan pb.TerminalSize)
+	go recvExecInput(stream, stdinWriter, resize, done)
+
+	var sendLock sync.Mutex
+	opts := api.ExecOptions{
+		Container: start.Container,
+		Command:   start.Command,
+		Stdout: streamWriter{func(data []byte) error {
+			return stream.Send(&pb.ExecOutput{Stdout: data})
+		}, &sendLock},
+		Stderr: streamWriter{func(data []byte) error {
+			return stream.Send(&pb.ExecOutput{Stderr: data})
+		}, &sendLock},
+		TTY:     start.Tty,
+		Resize:  resize,
+		PodName: start.PodName,
+	}
+	if start.Stdin {
+		opts.Stdin = stdinReader
+	}
+
+	var exitCode int
+	if s.runningOnDaemon {
+		exitCode, err = s.execOnMinion(opts)
+	} else {
+		exitCode, err = s.execLocal(opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	sendLock.Lock()
+	defer sendLock.Unlock()
+	return stream.Send(&pb.ExecOutput{Exited: true, ExitCode: int32(exitCode)})
+}
+
+// execOnMinion forwards the command to the minion running the container.
+func (s server) execOnMinion(opts api.ExecOptions) (int, error) {
+	minionClient, dbc, err := s.minionClient(opts.Container)
+	if err != nil {
+		return 0, err
+	}
+	defer minionClient.Close()
+
+	opts.PodName = dbc.PodName
+	return minionClient.Exec(opts)
+}
+
+// execLocal runs the command in a container on this machine.
+func (s server) execLocal(opts api.ExecOptions) (int, error) {
+	dk := newDockerClient()
+	containers, err := s.localContainers(dk, opts.Container, opts.PodName)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(containers) == 0 || !containers[0].Running {
+		return 0, fmt.Errorf("container %q is not running on this machine",
+			opts.Container)
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	return dk.Exec(containers[0].ID, docker.ExecOptions{
+		Cmd:    opts.Command,
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Stderr: opts.Stderr,
+		TTY:    opts.TTY,
+		Resize: toDockerSizes(opts.Resize, done),
+	})
+}
+
+// toDockerSizes converts the terminal sizes received from the client into the
+// docker client's representation. It stops once `sizes` is closed, or `done`
+// is closed.
+func toDockerSizes(sizes <-chan pb.TerminalSize,
+	done <-chan struct{}) <-chan docker.TerminalSize {
+	dkSizes := make(chan docker.TerminalSize)
+	go func() {
+		defer close(dkSizes)
+		for {
+			select {
+			case size, ok := <-sizes:
+				if !ok {
+					return
+				}
+				dkSize := docker.TerminalSize{
+					Height: int(size.Height),
+					Width:  int(size.Width),
+				}
+				select {
+				case dkSizes <- dkSize:
+				case <-done:
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return dkSizes
+}
+
+// recvExecInput writes the standard input received from the client to `stdin`,
+// and forwards terminal size changes to `resize`. It returns once the client
+// closes the stream, or `done` is closed.
+func recvExecInput(stream pb.API_ExecServer, stdin *io.PipeWriter,
+	resize chan<- pb.TerminalSize, done <-chan struct{}) {
+	defer close(resize)
+	for {
+		input, err := stream.Recv()
+		if err != nil {
+			stdin.CloseWithError(err)
+			return
+		}
+
+		if len(input.Stdin) != 0 {
+			if _, err := stdin.Write(input.Stdin); err != nil {
+				return
+			}
+		}
+
+		if input.StdinClosed {
+			stdin.Close()
+		}
+
+		if input.Resize != nil {
+			select {
+			case resize <- *input.Resize:
+			case <-done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/connection"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/docker"
+)
+
+// mockExecServer replies to the server with `inputs`, and records the messages
+// sent by the server.
+type mockExecServer struct {
+	sync.Mutex
+	inputs []*pb.ExecInput
+	sent   []*pb.ExecOutput
+
+	grpc.ServerStream
+}
+
+func (s *mockExecServer) Send(output *pb.ExecOutput) error {
+	s.Lock()
+	defer s.Unlock()
+	s.sent = append(s.sent, output)
+	return nil
+}
+
+func (s *mockExecServer) Recv() (*pb.ExecInput, error) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.inputs) == 0 {
+		return nil, io.EOF
+	}
+
+	input := s.inputs[0]
+	s.inputs = s.inputs[1:]
+	return input, nil
+}
+
+func TestExecLocal(t *testing.T) {
+	md, dk := docker.NewMock()
+	newDockerClient = func() docker.Client {
+		return dk
+	}
+
+	_, err := dk.Run(docker.RunOptions{
+		Name: "k8s_foo",
+		Labels: map[string]string{
+			podNameLabel:       "pod",
+			containerNameLabel: "foo",
+		},
+	})
+	assert.NoError(t, err)
+	md.ExecExitCode = 1
+
+	stream := &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{
+			Container: "foo",
+			Command:   []string{"cat"},
+			Stdin:     true,
+			PodName:   "pod",
+		}},
+		{Stdin: []byte("input")},
+		{StdinClosed: true},
+	}}
+	assert.NoError(t, server{db.New(), false, nil}.Exec(stream))
+	assert.Equal(t, []*pb.ExecOutput{
+		{Stdout: []byte("input")},
+		{Exited: true, ExitCode: 1},
+	}, stream.sent)
+
+	// The pod name is looked up in the database if it's not supplied.
+	conn := db.New()
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.Hostname = "foo"
+		dbc.Minion = "10.0.0.2"
+		dbc.PodName = "pod"
+		view.Commit(dbc)
+		return nil
+	})
+
+	stream = &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{Container: "foo", Command: []string{"ls"}}},
+	}}
+	assert.NoError(t, server{conn, false, nil}.Exec(stream))
+	assert.Equal(t, []*pb.ExecOutput{{Exited: true, ExitCode: 1}}, stream.sent)
+
+	stream = &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{
+			Container: "bar",
+			Command:   []string{"ls"},
+			PodName:   "pod",
+		}},
+	}}
+	assert.EqualError(t, server{conn, false, nil}.Exec(stream),
+		`container "bar" is not running on this machine`)
+}
+
+func TestToDockerSizes(t *testing.T) {
+	t.Parallel()
+
+	sizes := make(chan pb.TerminalSize)
+	done := make(chan struct{})
+	dkSizes := toDockerSizes(sizes, done)
+
+	go func() { sizes <- pb.TerminalSize{Height: 24, Width: 80} }()
+	assert.Equal(t, docker.TerminalSize{Height: 24, Width: 80}, <-dkSizes)
+
+	// The converted sizes stop once the client's sizes are closed.
+	close(sizes)
+	_, ok := <-dkSizes
+	assert.False(t, ok)
+
+	// Or once the exec is done, even if the client keeps the sizes open.
+	dkSizes = toDockerSizes(make(chan pb.TerminalSize), done)
+	close(done)
+	_, ok = <-dkSizes
+	assert.False(t, ok)
+}
+
+func TestExecDaemon(t *testing.T) {
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		mc := new(mocks.Client)
+		mc.On("SelectContainers", api.Query{Hostname: "foo"}).Return(
+			[]db.Container{{Hostname: "foo", Minion: "10.0.0.2",
+				PodName: "pod"}}, nil)
+		mc.On("SelectContainers", api.Query{Hostname: "pending"}).Return(
+			[]db.Container{{Hostname: "pending"}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	var minionAddr string
+	newClient = func(addr string, _ connection.Credentials) (client.Client, error) {
+		minionAddr = addr
+		mc := new(mocks.Client)
+		mc.On("Exec", mock.MatchedBy(func(opts api.ExecOptions) bool {
+			return opts.Container == "foo" && opts.PodName == "pod" &&
+				opts.TTY && opts.Stdin == nil
+		})).Return(3, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		dbm := view.InsertMachine()
+		dbm.PrivateIP = "10.0.0.2"
+		dbm.PublicIP = "8.8.8.8"
+		view.Commit(dbm)
+		return nil
+	})
+	s := server{conn, true, nil}
+
+	stream := &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{Container: "foo", Command: []string{"sh"},
+			Tty: true}},
+	}}
+	assert.NoError(t, s.Exec(stream))
+	assert.Equal(t, api.RemoteAddress("8.8.8.8"), minionAddr)
+	assert.Equal(t, []*pb.ExecOutput{{Exited: true, ExitCode: 3}}, stream.sent)
+
+	stream = &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{Container: "pending", Command: []string{"sh"}}},
+	}}
+	assert.EqualError(t, s.Exec(stream),
+		`container "pending" is not yet running`)
+}
+
+func TestExecErrors(t *testing.T) {
+	t.Parallel()
+
+	stream := &mockExecServer{}
+	assert.Equal(t, io.EOF, server{}.Exec(stream))
+
+	stream = &mockExecServer{inputs: []*pb.ExecInput{{Stdin: []byte("in")}}}
+	assert.EqualError(t, server{}.Exec(stream),
+		"the first message must start the command")
+
+	stream = &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{Container: "foo"}}}}
+	assert.EqualError(t, server{}.Exec(stream),
+		"a container and command are required")
+
+	stream = &mockExecServer{inputs: []*pb.ExecInput{
+		{Start: &pb.ExecStart{Container: "foo", Command: []string{"ls"}}}}}
+	assert.EqualError(t, server{db.New(), false, nil}.Exec(stream),
+		`no container with hostname "foo"`)
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/kelda/kelda/api/pb"
+	cliPath "github.com/kelda/kelda/cli/path"
+	"github.com/kelda/kelda/util"
+)
+
+// The number of deployments kept in the history. Older deployments are
+// forgotten, and can't be rolled back to.
+const maxHistory = 100
+
+// historyLock serializes updates to the deployment history so that concurrent
+// deploys are assigned unique versions.
+var historyLock sync.Mutex
+
+// Saved in a variable to facilitate unit testing.
+var historyPath = cliPath.DefaultDeploymentHistoryPath
+
+// readHistory returns the deployments recorded by the daemon, ordered from
+// oldest to newest. It's not an error for nothing to have been deployed yet.
+func readHistory() ([]pb.Deployment, error) {
+	historyStr, err := util.ReadFile(historyPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read deployment history: %s", err)
+	}
+
+	var history []pb.Deployment
+	if err := json.Unmarshal([]byte(historyStr), &history); err != nil {
+		return nil, fmt.Errorf("parse deployment history: %s", err)
+	}
+	return history, nil
+}
+
+// recordDeployment appends `deployment` to the history, and returns it with its
+// version set.
+func recordDeployment(deployment pb.Deployment) (pb.Deployment, error) {
+	historyLock.Lock()
+	defer historyLock.Unlock()
+
+	history, err := readHistory()
+	if err != nil {
+		return pb.Deployment{}, err
+	}
+
+	deployment.Version = 1
+	if len(history) > 0 {
+		deployment.Version = history[len(history)-1].Version + 1
+	}
+
+	history = append(history, deployment)
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+
+	historyJSON, err := json.MarshalIndent(history, "", "    ")
+	if err != nil {
+		return pb.Deployment{}, err
+	}
+
+	if err := util.AppFs.MkdirAll(filepath.Dir(historyPath), 0700); err != nil {
+		return pb.Deployment{}, err
+	}
+	err = util.WriteFile(historyPath, historyJSON, 0600)
+	return deployment, err
+}
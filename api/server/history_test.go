@@ -0,0 +1,39 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/util"
+)
+
+func TestRecordDeployment(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	history, err := readHistory()
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+
+	for i := 0; i < maxHistory+5; i++ {
+		recorded, err := recordDeployment(pb.Deployment{DeployedBy: "user"})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(i+1), recorded.Version)
+	}
+
+	// Only the most recent deployments should be kept.
+	history, err = readHistory()
+	assert.NoError(t, err)
+	assert.Len(t, history, maxHistory)
+	assert.Equal(t, int64(6), history[0].Version)
+	assert.Equal(t, int64(maxHistory+5), history[maxHistory-1].Version)
+	assert.Equal(t, "user", history[0].DeployedBy)
+
+	util.WriteFile(historyPath, []byte("malformed"), 0600)
+	_, err = readHistory()
+	assert.Error(t, err)
+	_, err = recordDeployment(pb.Deployment{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/minion/docker"
+)
+
+// Logs streams the logs of a container. When running on the daemon, the
+// request is proxied to the minion running the container. On the minion, the
+// logs are read from Docker.
+func (s server) Logs(req *pb.LogsRequest, stream pb.API_LogsServer) error {
+	if req.Container == "" {
+		return errors.New("a container is required")
+	}
+
+	var sendLock sync.Mutex
+	opts := api.LogsOptions{
+		Container:  req.Container,
+		Follow:     req.Follow,
+		Tail:       int(req.Tail),
+		Timestamps: req.Timestamps,
+		Previous:   req.Previous,
+		Stdout: streamWriter{func(data []byte) error {
+			return stream.Send(&pb.LogsOutput{Stdout: data})
+		}, &sendLock},
+		Stderr: streamWriter{func(data []byte) error {
+			return stream.Send(&pb.LogsOutput{Stderr: data})
+		}, &sendLock},
+		Context: stream.Context(),
+		PodName: req.PodName,
+	}
+	if req.Since != 0 {
+		opts.Since = time.Unix(req.Since, 0)
+	}
+
+	if s.runningOnDaemon {
+		return s.logsOnMinion(opts)
+	}
+	return s.logsLocal(opts)
+}
+
+// logsOnMinion forwards the request to the minion running the container.
+func (s server) logsOnMinion(opts api.LogsOptions) error {
+	minionClient, dbc, err := s.minionClient(opts.Container)
+	if err != nil {
+		return err
+	}
+	defer minionClient.Close()
+
+	opts.PodName = dbc.PodName
+	return minionClient.Logs(opts)
+}
+
+// logsLocal writes the logs of a container on this machine.
+func (s server) logsLocal(opts api.LogsOptions) error {
+	dk := newDockerClient()
+	containers, err := s.localContainers(dk, opts.Container, opts.PodName)
+	if err != nil {
+		return err
+	}
+
+	// Kubernetes keeps the previous instance of a container when it
+	// restarts, so its logs can be inspected.
+	instance := 0
+	if opts.Previous {
+		instance = 1
+	}
+
+	if len(containers) <= instance {
+		if opts.Previous {
+			return fmt.Errorf("no previous instance of container %q",
+				opts.Container)
+		}
+		return fmt.Errorf("container %q is not running on this machine",
+			opts.Container)
+	}
+
+	return dk.Logs(containers[instance].ID, docker.LogsOptions{
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       opts.Tail,
+		Timestamps: opts.Timestamps,
+		Stdout:     opts.Stdout,
+		Stderr:     opts.Stderr,
+		Context:    opts.Context,
+	})
+}
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/connection"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/docker"
+)
+
+// mockLogsServer records the messages sent by the server.
+type mockLogsServer struct {
+	sync.Mutex
+	sent []*pb.LogsOutput
+
+	grpc.ServerStream
+}
+
+func (s *mockLogsServer) Send(output *pb.LogsOutput) error {
+	s.Lock()
+	defer s.Unlock()
+	s.sent = append(s.sent, output)
+	return nil
+}
+
+func (s *mockLogsServer) Context() context.Context {
+	return context.Background()
+}
+
+func TestLogsLocal(t *testing.T) {
+	md, dk := docker.NewMock()
+	newDockerClient = func() docker.Client {
+		return dk
+	}
+
+	labels := map[string]string{
+		podNameLabel:       "pod",
+		containerNameLabel: "foo",
+	}
+	previous, err := dk.Run(docker.RunOptions{Name: "previous", Labels: labels})
+	assert.NoError(t, err)
+	current, err := dk.Run(docker.RunOptions{Name: "current", Labels: labels})
+	assert.NoError(t, err)
+
+	md.Containers[previous].Created = time.Unix(100, 0)
+	md.Containers[current].Created = time.Unix(200, 0)
+	md.LogOutput[previous] = "crashed\n"
+	md.LogOutput[current] = "running\n"
+
+	s := server{db.New(), false, nil}
+	stream := &mockLogsServer{}
+	err = s.Logs(&pb.LogsRequest{Container: "foo", PodName: "pod"}, stream)
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.LogsOutput{{Stdout: []byte("running\n")}}, stream.sent)
+
+	stream = &mockLogsServer{}
+	err = s.Logs(&pb.LogsRequest{Container: "foo", PodName: "pod",
+		Previous: true}, stream)
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.LogsOutput{{Stdout: []byte("crashed\n")}}, stream.sent)
+
+	err = s.Logs(&pb.LogsRequest{Container: "bar", PodName: "pod",
+		Previous: true}, &mockLogsServer{})
+	assert.EqualError(t, err, `no previous instance of container "bar"`)
+
+	err = s.Logs(&pb.LogsRequest{Container: "bar", PodName: "pod"},
+		&mockLogsServer{})
+	assert.EqualError(t, err, `container "bar" is not running on this machine`)
+
+	err = s.Logs(&pb.LogsRequest{}, &mockLogsServer{})
+	assert.EqualError(t, err, "a container is required")
+}
+
+func TestLogsDaemon(t *testing.T) {
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		mc := new(mocks.Client)
+		mc.On("SelectContainers", api.Query{Hostname: "foo"}).Return(
+			[]db.Container{{Hostname: "foo", Minion: "10.0.0.2",
+				PodName: "pod"}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	var minionAddr string
+	newClient = func(addr string, _ connection.Credentials) (client.Client, error) {
+		minionAddr = addr
+		mc := new(mocks.Client)
+		mc.On("Logs", mock.MatchedBy(func(opts api.LogsOptions) bool {
+			return opts.Container == "foo" && opts.PodName == "pod" &&
+				opts.Follow && opts.Tail == 10 &&
+				opts.Since.Equal(time.Unix(100, 0))
+		})).Run(func(args mock.Arguments) {
+			args.Get(0).(api.LogsOptions).Stderr.Write([]byte("err\n"))
+		}).Return(nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		dbm := view.InsertMachine()
+		dbm.PrivateIP = "10.0.0.2"
+		dbm.PublicIP = "8.8.8.8"
+		view.Commit(dbm)
+		return nil
+	})
+
+	stream := &mockLogsServer{}
+	err := server{conn, true, nil}.Logs(&pb.LogsRequest{
+		Container: "foo",
+		Follow:    true,
+		Tail:      10,
+		Since:     100,
+	}, stream)
+	assert.NoError(t, err)
+	assert.Equal(t, api.RemoteAddress("8.8.8.8"), minionAddr)
+	assert.Equal(t, []*pb.LogsOutput{{Stderr: []byte("err\n")}}, stream.sent)
+}
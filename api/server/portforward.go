@@ -0,0 +1,173 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/db"
+)
+
+// The maximum amount of time to wait for a container to accept a connection.
+const portForwardDialTimeout = 10 * time.Second
+
+// PortForward tunnels a connection to a port in a container. When running on
+// the daemon, the stream is proxied to the minion running the container. On
+// the minion, the container is connected to over the overlay network.
+func (s server) PortForward(stream pb.API_PortForwardServer) error {
+	input, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	start := input.Start
+	if start == nil {
+		return errors.New("the first message must start the connection")
+	}
+
+	if start.Target == "" {
+		return errors.New("a target is required")
+	}
+
+	if start.Port <= 0 || start.Port > 65535 {
+		return fmt.Errorf("invalid port %d", start.Port)
+	}
+
+	opts := api.PortForwardOptions{
+		Target: start.Target,
+		Port:   int(start.Port),
+		Conn:   &portForwardConn{stream: stream},
+		IP:     start.IP,
+	}
+
+	if s.runningOnDaemon {
+		return s.portForwardOnMinion(opts)
+	}
+	return s.portForwardLocal(opts)
+}
+
+// portForwardOnMinion forwards the connection to the minion running the
+// target container. If the target is a load balancer, one of its containers
+// is chosen at random.
+func (s server) portForwardOnMinion(opts api.PortForwardOptions) error {
+	dbc, err := s.portForwardContainer(opts.Target)
+	if err != nil {
+		return err
+	}
+
+	minionClient, err := s.clientForContainer(dbc)
+	if err != nil {
+		return err
+	}
+	defer minionClient.Close()
+
+	opts.Target = dbc.Hostname
+	opts.IP = dbc.IP
+	return minionClient.PortForward(opts)
+}
+
+// portForwardContainer returns the container that connections to `target`
+// should be tunneled to.
+func (s server) portForwardContainer(target string) (db.Container, error) {
+	// Containers and load balancers share the same namespace of hostnames,
+	// so a target can't be both.
+	members, err := s.leaderContainers(api.Query{Label: target})
+	if err != nil {
+		return db.Container{}, err
+	}
+
+	if len(members) == 0 {
+		containers, err := s.leaderContainers(api.Query{Hostname: target})
+		if err != nil {
+			return db.Container{}, err
+		}
+		return findContainer(containers, target)
+	}
+
+	var running []db.Container
+	for _, dbc := range members {
+		if dbc.Minion != "" && dbc.IP != "" {
+			running = append(running, dbc)
+		}
+	}
+
+	if len(running) == 0 {
+		return db.Container{}, fmt.Errorf(
+			"no containers in load balancer %q are running", target)
+	}
+	return running[rand.Intn(len(running))], nil
+}
+
+// portForwardLocal connects to a container on this machine, and copies data
+// between the connection and the stream until the container closes the
+// connection.
+func (s server) portForwardLocal(opts api.PortForwardOptions) error {
+	ip := opts.IP
+	if ip == "" {
+		dbc, err := findContainer(s.conn.SelectFromContainer(nil), opts.Target)
+		if err != nil {
+			return err
+		}
+		ip = dbc.IP
+	}
+
+	if ip == "" {
+		return fmt.Errorf("container %q has no IP yet", opts.Target)
+	}
+
+	conn, err := dialContainer(net.JoinHostPort(ip, strconv.Itoa(opts.Port)))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	go func() {
+		io.Copy(conn, opts.Conn)
+
+		// Let the container know that the client is done sending, while
+		// still allowing it to respond.
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			tcpConn.CloseWrite()
+		}
+	}()
+
+	_, err = io.Copy(opts.Conn, conn)
+	return err
+}
+
+// portForwardConn reads the data sent by the client of a PortForward stream,
+// and sends the data written to it to the client. Reads return io.EOF once
+// the client closes its side of the stream.
+type portForwardConn struct {
+	stream pb.API_PortForwardServer
+	unread []byte
+}
+
+func (c *portForwardConn) Read(p []byte) (int, error) {
+	for len(c.unread) == 0 {
+		input, err := c.stream.Recv()
+		if err != nil {
+			return 0, err
+		}
+		c.unread = input.Data
+	}
+
+	n := copy(p, c.unread)
+	c.unread = c.unread[n:]
+	return n, nil
+}
+
+func (c *portForwardConn) Write(p []byte) (int, error) {
+	// Copy the data because the caller may reuse `p` once Write returns.
+	data := append([]byte{}, p...)
+	if err := c.stream.Send(&pb.PortForwardOutput{Data: data}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,220 @@
+package server
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/connection"
+	"github.com/kelda/kelda/db"
+)
+
+// mockPortForwardServer replies to the server with `inputs`, and records the
+// messages sent by the server.
+type mockPortForwardServer struct {
+	sync.Mutex
+	inputs []*pb.PortForwardInput
+	sent   []*pb.PortForwardOutput
+
+	grpc.ServerStream
+}
+
+func (s *mockPortForwardServer) Send(output *pb.PortForwardOutput) error {
+	s.Lock()
+	defer s.Unlock()
+	s.sent = append(s.sent, output)
+	return nil
+}
+
+func (s *mockPortForwardServer) Recv() (*pb.PortForwardInput, error) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.inputs) == 0 {
+		return nil, io.EOF
+	}
+
+	input := s.inputs[0]
+	s.inputs = s.inputs[1:]
+	return input, nil
+}
+
+// received returns all the data sent by the server.
+func (s *mockPortForwardServer) received() string {
+	s.Lock()
+	defer s.Unlock()
+
+	var data bytes.Buffer
+	for _, output := range s.sent {
+		data.Write(output.Data)
+	}
+	return data.String()
+}
+
+func TestPortForwardLocal(t *testing.T) {
+	// The container responds once it has read the entire request.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			request, _ := ioutil.ReadAll(conn)
+			conn.Write(append([]byte("response to "), request...))
+			conn.Close()
+		}
+	}()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	dialContainer = func(addr string) (net.Conn, error) {
+		return net.Dial("tcp", addr)
+	}
+
+	conn := db.New()
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.Hostname = "foo"
+		dbc.IP = "127.0.0.1"
+		dbc.Minion = "10.0.0.2"
+		dbc.PodName = "pod"
+		view.Commit(dbc)
+		return nil
+	})
+	s := server{conn, false, nil}
+
+	stream := &mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "foo", Port: int32(port)}},
+		{Data: []byte("req")},
+		{Data: []byte("uest")},
+	}}
+	assert.NoError(t, s.PortForward(stream))
+	assert.Equal(t, "response to request", stream.received())
+
+	// The IP set by the daemon takes precedence over the database.
+	stream = &mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "bar", Port: int32(port),
+			IP: "127.0.0.1"}},
+		{Data: []byte("request")},
+	}}
+	assert.NoError(t, s.PortForward(stream))
+	assert.Equal(t, "response to request", stream.received())
+
+	stream = &mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "bar", Port: int32(port)}},
+	}}
+	assert.EqualError(t, s.PortForward(stream), `no container with hostname "bar"`)
+}
+
+func TestPortForwardDaemon(t *testing.T) {
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		mc := new(mocks.Client)
+		mc.On("SelectContainers", api.Query{Label: "lb"}).Return(
+			[]db.Container{
+				{Hostname: "lb-member", IP: "10.1.0.3",
+					Minion: "10.0.0.2", PodName: "pod"},
+				{Hostname: "scheduling"},
+			}, nil)
+		mc.On("SelectContainers", api.Query{Label: "stopped-lb"}).Return(
+			[]db.Container{{Hostname: "scheduling"}}, nil)
+		mc.On("SelectContainers", api.Query{Label: "foo"}).Return(nil, nil)
+		mc.On("SelectContainers", api.Query{Hostname: "foo"}).Return(
+			[]db.Container{{Hostname: "foo", IP: "10.1.0.2",
+				Minion: "10.0.0.2", PodName: "pod"}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	var minionAddr string
+	var forwarded []api.PortForwardOptions
+	newClient = func(addr string, _ connection.Credentials) (client.Client, error) {
+		minionAddr = addr
+		mc := new(mocks.Client)
+		mc.On("PortForward", mock.Anything).Run(func(args mock.Arguments) {
+			opts := args.Get(0).(api.PortForwardOptions)
+			request, _ := ioutil.ReadAll(opts.Conn)
+			opts.Conn.Write(append([]byte("response to "), request...))
+
+			opts.Conn = nil
+			forwarded = append(forwarded, opts)
+		}).Return(nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	conn := db.New()
+	conn.Txn(db.MachineTable).Run(func(view db.Database) error {
+		dbm := view.InsertMachine()
+		dbm.PrivateIP = "10.0.0.2"
+		dbm.PublicIP = "8.8.8.8"
+		view.Commit(dbm)
+		return nil
+	})
+	s := server{conn, true, nil}
+
+	stream := &mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "foo", Port: 80}},
+		{Data: []byte("request")},
+	}}
+	assert.NoError(t, s.PortForward(stream))
+	assert.Equal(t, "response to request", stream.received())
+	assert.Equal(t, api.RemoteAddress("8.8.8.8"), minionAddr)
+
+	// Connections to load balancers are tunneled to a running member.
+	stream = &mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "lb", Port: 443}},
+	}}
+	assert.NoError(t, s.PortForward(stream))
+
+	assert.Equal(t, []api.PortForwardOptions{
+		{Target: "foo", Port: 80, IP: "10.1.0.2"},
+		{Target: "lb-member", Port: 443, IP: "10.1.0.3"},
+	}, forwarded)
+
+	stream = &mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Target: "stopped-lb", Port: 80}},
+	}}
+	assert.EqualError(t, s.PortForward(stream),
+		`no containers in load balancer "stopped-lb" are running`)
+}
+
+func TestPortForwardErrors(t *testing.T) {
+	s := server{db.New(), false, nil}
+
+	err := s.PortForward(&mockPortForwardServer{})
+	assert.Equal(t, io.EOF, err)
+
+	err = s.PortForward(&mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Data: []byte("data")},
+	}})
+	assert.EqualError(t, err, "the first message must start the connection")
+
+	err = s.PortForward(&mockPortForwardServer{inputs: []*pb.PortForwardInput{
+		{Start: &pb.PortForwardStart{Port: 80}},
+	}})
+	assert.EqualError(t, err, "a target is required")
+
+	for _, port := range []int32{0, -1, 65536} {
+		err = s.PortForward(&mockPortForwardServer{
+			inputs: []*pb.PortForwardInput{
+				{Start: &pb.PortForwardStart{Target: "foo", Port: port}},
+			},
+		})
+		assert.EqualError(t, err, "invalid port "+strconv.Itoa(int(port)))
+	}
+}
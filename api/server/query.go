@@ -0,0 +1,231 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/util/str"
+)
+
+// The filters that may be used when querying each table.
+var supportedFilters = map[db.TableType][]string{
+	db.MachineTable:      {"Status", "Minion"},
+	db.ContainerTable:    {"Hostname", "Status", "Minion", "Label"},
+	db.ConnectionTable:   {"Hostname", "Label"},
+	db.LoadBalancerTable: {"Hostname", "Label"},
+	db.ImageTable:        {"Status"},
+	db.StatsTable:        {"Hostname", "Minion"},
+	db.EtcdTable:         {},
+	db.BlueprintTable:    {},
+}
+
+// selectRows returns the rows of `table` that match the filters in `q`, sorted
+// by their database IDs and paginated according to `q`.
+func selectRows(conn db.Conn, table db.TableType, q api.Query) (interface{}, error) {
+	if err := checkFilters(table, q); err != nil {
+		return nil, err
+	}
+
+	var rows interface{}
+	switch table {
+	case db.MachineTable:
+		rows = conn.SelectFromMachine(func(dbm db.Machine) bool {
+			return globMatch(q.Status, dbm.Status) &&
+				exactMatch(q.Minion, dbm.PrivateIP)
+		})
+	case db.ContainerTable:
+		var labelHostnames []string
+		if q.Label != "" {
+			lbs := conn.SelectFromLoadBalancer(func(lb db.LoadBalancer) bool {
+				return lb.Name == q.Label
+			})
+			for _, lb := range lbs {
+				labelHostnames = append(labelHostnames, lb.Hostnames...)
+			}
+		}
+
+		rows = conn.SelectFromContainer(func(dbc db.Container) bool {
+			return globMatch(q.Hostname, dbc.Hostname) &&
+				globMatch(q.Status, dbc.Status) &&
+				exactMatch(q.Minion, dbc.Minion) &&
+				(q.Label == "" ||
+					str.SliceContains(labelHostnames, dbc.Hostname))
+		})
+	case db.ConnectionTable:
+		rows = conn.SelectFromConnection(func(dbc db.Connection) bool {
+			endpoints := append(append([]string{}, dbc.From...), dbc.To...)
+			return anyGlobMatch(q.Hostname, endpoints) &&
+				(q.Label == "" || str.SliceContains(endpoints, q.Label))
+		})
+	case db.LoadBalancerTable:
+		rows = conn.SelectFromLoadBalancer(func(lb db.LoadBalancer) bool {
+			return anyGlobMatch(q.Hostname, lb.Hostnames) &&
+				exactMatch(q.Label, lb.Name)
+		})
+	case db.ImageTable:
+		rows = conn.SelectFromImage(func(img db.Image) bool {
+			return globMatch(q.Status, img.Status)
+		})
+	case db.StatsTable:
+		rows = conn.SelectFromStats(func(stats db.Stats) bool {
+			return globMatch(q.Hostname, stats.Hostname) &&
+				exactMatch(q.Minion, stats.Minion)
+		})
+	case db.EtcdTable:
+		rows = conn.SelectFromEtcd(nil)
+	case db.BlueprintTable:
+		rows = conn.SelectFromBlueprint(nil)
+	default:
+		return nil, fmt.Errorf("unrecognized table: %s", table)
+	}
+
+	sortByID(rows)
+	return paginate(rows, q.Offset, q.Limit), nil
+}
+
+// checkFilters returns an error if `q` uses filters that aren't supported by
+// `table`, or contains malformed glob patterns.
+func checkFilters(table db.TableType, q api.Query) error {
+	supported, ok := supportedFilters[table]
+	if !ok {
+		return fmt.Errorf("unrecognized table: %s", table)
+	}
+
+	filters := map[string]string{
+		"Hostname": q.Hostname,
+		"Status":   q.Status,
+		"Minion":   q.Minion,
+		"Label":    q.Label,
+	}
+	for name, val := range filters {
+		if val != "" && !str.SliceContains(supported, name) {
+			return fmt.Errorf("%s does not support filtering by %s",
+				table, name)
+		}
+	}
+
+	for _, pattern := range []string{q.Hostname, q.Status} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("malformed pattern %q: %s", pattern, err)
+		}
+	}
+
+	if q.Offset < 0 || q.Limit < 0 {
+		return fmt.Errorf("offset and limit must not be negative")
+	}
+	return nil
+}
+
+// globMatch returns whether `val` matches `pattern`. An empty pattern matches
+// everything.
+func globMatch(pattern, val string) bool {
+	if pattern == "" {
+		return true
+	}
+	match, _ := path.Match(pattern, val)
+	return match
+}
+
+func anyGlobMatch(pattern string, vals []string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, val := range vals {
+		if globMatch(pattern, val) {
+			return true
+		}
+	}
+	return false
+}
+
+func exactMatch(filter, val string) bool {
+	return filter == "" || filter == val
+}
+
+// sortByID sorts a slice of database rows by their IDs so that pagination is
+// stable across queries.
+func sortByID(rows interface{}) {
+	slice := reflect.ValueOf(rows)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return slice.Index(i).FieldByName("ID").Int() <
+			slice.Index(j).FieldByName("ID").Int()
+	})
+}
+
+// paginate returns the rows in the page defined by `offset` and `limit`.
+func paginate(rows interface{}, offset, limit int) interface{} {
+	slice := reflect.ValueOf(rows)
+	if offset > slice.Len() {
+		offset = slice.Len()
+	}
+
+	end := slice.Len()
+	if limit != 0 && offset+limit < end {
+		end = offset + limit
+	}
+	return slice.Slice(offset, end).Interface()
+}
+
+// project strips all but the given JSON fields from each of the database rows.
+// If no fields are given, the rows are returned unchanged.
+func project(rows interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return rows, nil
+	}
+
+	valid := jsonFields(reflect.TypeOf(rows).Elem())
+	for _, field := range fields {
+		if !str.SliceContains(valid, field) {
+			return nil, fmt.Errorf("unknown field %q (valid fields: %s)",
+				field, strings.Join(valid, ", "))
+		}
+	}
+
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	var allFields []map[string]json.RawMessage
+	if err := json.Unmarshal(rowsJSON, &allFields); err != nil {
+		return nil, err
+	}
+
+	projected := []map[string]json.RawMessage{}
+	for _, row := range allFields {
+		projectedRow := map[string]json.RawMessage{}
+		for _, field := range fields {
+			if val, ok := row[field]; ok {
+				projectedRow[field] = val
+			}
+		}
+		projected = append(projected, projectedRow)
+	}
+	return projected, nil
+}
+
+// jsonFields returns the names of the fields in the JSON encoding of `t`.
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("json"), ",")[0]
+		switch {
+		case tag == "-" || field.PkgPath != "":
+			continue
+		case field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct:
+			fields = append(fields, jsonFields(field.Type)...)
+		case tag != "":
+			fields = append(fields, tag)
+		default:
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/connection"
+	"github.com/kelda/kelda/db"
+)
+
+func TestSelectContainers(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, c := range []db.Container{
+			{Hostname: "web-1", Status: "running", Minion: "10.0.0.1"},
+			{Hostname: "web-2", Status: "Waiting for secrets: [key]",
+				Minion: "10.0.0.2"},
+			{Hostname: "db", Status: "running", Minion: "10.0.0.2"},
+		} {
+			dbc := view.InsertContainer()
+			c.ID = dbc.ID
+			view.Commit(c)
+		}
+
+		lb := view.InsertLoadBalancer()
+		lb.Name = "web"
+		lb.Hostnames = []string{"web-1", "web-2"}
+		view.Commit(lb)
+		return nil
+	})
+
+	hostnames := func(q api.Query) []string {
+		rows, err := selectRows(conn, db.ContainerTable, q)
+		assert.NoError(t, err)
+
+		var hostnames []string
+		for _, dbc := range rows.([]db.Container) {
+			hostnames = append(hostnames, dbc.Hostname)
+		}
+		return hostnames
+	}
+
+	assert.Equal(t, []string{"web-1", "web-2", "db"}, hostnames(api.Query{}))
+	assert.Equal(t, []string{"web-1", "web-2"},
+		hostnames(api.Query{Hostname: "web-*"}))
+	assert.Equal(t, []string{"web-1", "db"},
+		hostnames(api.Query{Status: "running"}))
+	assert.Equal(t, []string{"web-2"}, hostnames(api.Query{Status: "Waiting*"}))
+	assert.Equal(t, []string{"web-2", "db"},
+		hostnames(api.Query{Minion: "10.0.0.2"}))
+	assert.Equal(t, []string{"web-1", "web-2"}, hostnames(api.Query{Label: "web"}))
+	assert.Equal(t, []string{"web-2"},
+		hostnames(api.Query{Label: "web", Minion: "10.0.0.2"}))
+	assert.Empty(t, hostnames(api.Query{Label: "missing"}))
+
+	// Pagination.
+	assert.Equal(t, []string{"web-1", "web-2"}, hostnames(api.Query{Limit: 2}))
+	assert.Equal(t, []string{"web-2", "db"}, hostnames(api.Query{Offset: 1}))
+	assert.Equal(t, []string{"db"}, hostnames(api.Query{Offset: 2, Limit: 2}))
+	assert.Empty(t, hostnames(api.Query{Offset: 5}))
+}
+
+func TestSelectRowsOtherTables(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		dbm := view.InsertMachine()
+		dbm.PrivateIP = "10.0.0.1"
+		dbm.Status = db.Connected
+		view.Commit(dbm)
+		view.Commit(view.InsertMachine())
+
+		dbc := view.InsertConnection()
+		dbc.From = []string{"web-1"}
+		dbc.To = []string{"db"}
+		view.Commit(dbc)
+
+		lb := view.InsertLoadBalancer()
+		lb.Name = "web"
+		lb.Hostnames = []string{"web-1"}
+		view.Commit(lb)
+
+		img := view.InsertImage()
+		img.Status = db.Built
+		view.Commit(img)
+
+		stats := view.InsertStats()
+		stats.Hostname = "web-1"
+		stats.Minion = "10.0.0.1"
+		view.Commit(stats)
+		stats = view.InsertStats()
+		stats.Minion = "10.0.0.1"
+		view.Commit(stats)
+		return nil
+	})
+
+	rows, err := selectRows(conn, db.MachineTable, api.Query{Minion: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	rows, err = selectRows(conn, db.MachineTable, api.Query{Status: "booting"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 0)
+
+	rows, err = selectRows(conn, db.ConnectionTable, api.Query{Hostname: "web-*"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	rows, err = selectRows(conn, db.ConnectionTable, api.Query{Label: "db"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	rows, err = selectRows(conn, db.ConnectionTable, api.Query{Label: "web"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 0)
+
+	rows, err = selectRows(conn, db.LoadBalancerTable,
+		api.Query{Hostname: "web-?", Label: "web"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+
+	rows, err = selectRows(conn, db.ImageTable, api.Query{Status: db.Building})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 0)
+
+	rows, err = selectRows(conn, db.StatsTable, api.Query{Minion: "10.0.0.1"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+
+	rows, err = selectRows(conn, db.StatsTable, api.Query{Hostname: "web-*"})
+	assert.NoError(t, err)
+	assert.Len(t, rows, 1)
+}
+
+func TestSelectRowsErrors(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	_, err := selectRows(conn, db.MachineTable, api.Query{Hostname: "foo"})
+	assert.EqualError(t, err, "db.Machine does not support filtering by Hostname")
+
+	_, err = selectRows(conn, db.ContainerTable, api.Query{Hostname: "["})
+	assert.EqualError(t, err, `malformed pattern "[": syntax error in pattern`)
+
+	_, err = selectRows(conn, db.ContainerTable, api.Query{Limit: -1})
+	assert.EqualError(t, err, "offset and limit must not be negative")
+
+	_, err = selectRows(conn, db.HostnameTable, api.Query{})
+	assert.EqualError(t, err, "unrecognized table: db.Hostname")
+}
+
+func TestProject(t *testing.T) {
+	t.Parallel()
+
+	rows, err := project([]db.Container{{
+		Hostname: "web",
+		Status:   "running",
+		Env: map[string]blueprint.ContainerValue{
+			"key": blueprint.NewString("val"),
+		},
+	}}, []string{"Hostname", "Status"})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"Hostname":"web","Status":"running"}]`, toJSON(t, rows))
+
+	// Fields that are omitted from the JSON should also be omitted from the
+	// projection.
+	rows, err = project([]db.Container{{Hostname: "web"}}, []string{"Status"})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{}]`, toJSON(t, rows))
+
+	// Fields of embedded structs are flattened.
+	rows, err = project([]db.Blueprint{{
+		ID: 1, Blueprint: blueprint.Blueprint{Namespace: "ns"},
+	}}, []string{"Namespace"})
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"Namespace":"ns"}]`, toJSON(t, rows))
+
+	rows, err = project([]db.Container(nil), []string{"Hostname"})
+	assert.NoError(t, err)
+	assert.Equal(t, `[]`, toJSON(t, rows))
+
+	_, err = project([]db.Image{}, []string{"Hostname"})
+	assert.EqualError(t, err, `unknown field "Hostname" (valid fields: ID, `+
+		`Name, Dockerfile, RepoDigest, Status)`)
+}
+
+func TestQueryDaemonProjection(t *testing.T) {
+	q := api.Query{Hostname: "web-*", Fields: []string{"Hostname"}, Limit: 1}
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		mc := new(mocks.Client)
+		mc.On("SelectContainers", q).Return([]db.Container{{
+			Hostname: "web-1",
+		}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	reply, err := server{db.New(), true, nil}.Query(context.Background(),
+		q.ToPB(db.ContainerTable))
+	assert.NoError(t, err)
+	assert.Equal(t, `[{"Hostname":"web-1"}]`, reply.TableContents)
+
+	_, err = server{db.New(), true, nil}.Query(context.Background(),
+		&pb.DBQuery{Table: string(db.MachineTable), Fields: []string{"foo"}})
+	assert.Error(t, err)
+}
+
+func toJSON(t *testing.T, v interface{}) string {
+	js, err := json.Marshal(v)
+	assert.NoError(t, err)
+	return string(js)
+}
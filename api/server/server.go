@@ -4,9 +4,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"os/signal"
+	"sort"
 	"syscall"
+	"time"
 
 	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/api/client"
@@ -16,12 +19,15 @@ import (
 	"github.com/kelda/kelda/connection"
 	"github.com/kelda/kelda/counter"
 	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/docker"
+	"github.com/kelda/kelda/minion/ipdef"
 	"github.com/kelda/kelda/minion/kubernetes"
+	"github.com/kelda/kelda/util/str"
 	"github.com/kelda/kelda/version"
 
-	"github.com/docker/distribution/reference"
 	log "github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 )
 
 var errDaemonOnlyRPC = errors.New("only defined on the daemon")
@@ -51,7 +57,11 @@ func Run(conn db.Conn, listenAddr string, runningOnDaemon bool,
 		return err
 	}
 
-	sock, s := connection.Server(proto, addr, creds.ServerOpts())
+	auth := authorizer{runningOnDaemon}
+	opts := append(creds.ServerOpts(),
+		grpc.UnaryInterceptor(auth.unaryInterceptor),
+		grpc.StreamInterceptor(auth.streamInterceptor))
+	sock, s := connection.Server(proto, addr, opts)
 
 	// Cleanup the socket if we're interrupted.
 	sigc := make(chan os.Signal, 1)
@@ -101,26 +111,123 @@ func (s server) SetSecret(ctx context.Context, msg *pb.Secret) (*pb.SecretReply,
 	return &pb.SecretReply{}, secretClient.Set(msg.Name, msg.Value)
 }
 
+func (s server) ListSecrets(ctx context.Context, _ *pb.ListSecretsRequest) (
+	*pb.ListSecretsReply, error) {
+	if s.runningOnDaemon {
+		leaderClient, err := newLeaderClient(s.conn.SelectFromMachine(nil),
+			s.clientCreds)
+		if err != nil {
+			return nil, err
+		}
+		defer leaderClient.Close()
+
+		secrets, err := leaderClient.ListSecrets()
+		if err != nil {
+			return nil, err
+		}
+
+		reply := &pb.ListSecretsReply{}
+		for i := range secrets {
+			reply.Secrets = append(reply.Secrets, &secrets[i])
+		}
+		return reply, nil
+	}
+
+	secretClient, err := newSecretClient()
+	if err != nil {
+		return nil, err
+	}
+
+	secrets, err := secretClient.List()
+	if err != nil {
+		return nil, err
+	}
+
+	referencedBy := map[string][]string{}
+	for _, dbc := range s.conn.SelectFromContainer(nil) {
+		for _, name := range dbc.GetReferencedSecrets() {
+			if !str.SliceContains(referencedBy[name], dbc.Hostname) {
+				referencedBy[name] = append(referencedBy[name],
+					dbc.Hostname)
+			}
+		}
+	}
+
+	reply := &pb.ListSecretsReply{}
+	for i := range secrets {
+		secrets[i].Containers = referencedBy[secrets[i].Name]
+		sort.Strings(secrets[i].Containers)
+		reply.Secrets = append(reply.Secrets, &secrets[i])
+	}
+	return reply, nil
+}
+
+func (s server) DeleteSecret(ctx context.Context, msg *pb.DeleteSecretRequest) (
+	*pb.SecretReply, error) {
+	if s.runningOnDaemon {
+		leaderClient, err := newLeaderClient(s.conn.SelectFromMachine(nil),
+			s.clientCreds)
+		if err != nil {
+			return &pb.SecretReply{}, err
+		}
+		defer leaderClient.Close()
+		return &pb.SecretReply{}, leaderClient.DeleteSecret(msg.Name)
+	}
+
+	secretClient, err := newSecretClient()
+	if err != nil {
+		return &pb.SecretReply{}, err
+	}
+	return &pb.SecretReply{}, secretClient.Delete(msg.Name)
+}
+
+func (s server) RollbackSecret(ctx context.Context, msg *pb.RollbackSecretRequest) (
+	*pb.SecretReply, error) {
+	if s.runningOnDaemon {
+		leaderClient, err := newLeaderClient(s.conn.SelectFromMachine(nil),
+			s.clientCreds)
+		if err != nil {
+			return &pb.SecretReply{}, err
+		}
+		defer leaderClient.Close()
+		return &pb.SecretReply{}, leaderClient.RollbackSecret(msg.Name,
+			msg.Version)
+	}
+
+	secretClient, err := newSecretClient()
+	if err != nil {
+		return &pb.SecretReply{}, err
+	}
+	return &pb.SecretReply{}, secretClient.Rollback(msg.Name, msg.Version)
+}
+
 // Query runs in two modes: daemon, or local. If in local mode, Query simply
 // returns the requested table from its local database. If in daemon mode,
 // Query proxies certain table requests (e.g. Container and Connection) to the
 // cluster. This is necessary because some tables are only used on the minions,
-// and aren't synced back to the daemon.
+// and aren't synced back to the daemon. In both modes, the filters and
+// pagination in the query are evaluated wherever the table is stored.
 func (s server) Query(cts context.Context, query *pb.DBQuery) (*pb.QueryReply, error) {
 	var rows interface{}
 	var err error
 
 	table := db.TableType(query.Table)
+	q := api.QueryFromPB(query)
 	if s.runningOnDaemon {
-		rows, err = s.queryFromDaemon(table)
+		rows, err = s.queryFromDaemon(table, q)
 	} else {
-		rows, err = s.queryLocal(table)
+		rows, err = s.queryLocal(table, q)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
+	rows, err = project(rows, q.Fields)
+	if err != nil {
+		return nil, err
+	}
+
 	json, err := json.Marshal(rows)
 	if err != nil {
 		return nil, err
@@ -129,33 +236,23 @@ func (s server) Query(cts context.Context, query *pb.DBQuery) (*pb.QueryReply, e
 	return &pb.QueryReply{TableContents: string(json)}, nil
 }
 
-func (s server) queryLocal(table db.TableType) (interface{}, error) {
+func (s server) queryLocal(table db.TableType, q api.Query) (interface{}, error) {
 	switch table {
-	case db.MachineTable:
-		return s.conn.SelectFromMachine(nil), nil
-	case db.ContainerTable:
-		return s.conn.SelectFromContainer(nil), nil
-	case db.EtcdTable:
-		return s.conn.SelectFromEtcd(nil), nil
-	case db.ConnectionTable:
-		return s.conn.SelectFromConnection(nil), nil
-	case db.LoadBalancerTable:
-		return s.conn.SelectFromLoadBalancer(nil), nil
-	case db.BlueprintTable:
-		return s.conn.SelectFromBlueprint(nil), nil
-	case db.ImageTable:
-		return s.conn.SelectFromImage(nil), nil
+	case db.MachineTable, db.ContainerTable, db.EtcdTable, db.ConnectionTable,
+		db.LoadBalancerTable, db.BlueprintTable, db.ImageTable,
+		db.StatsTable:
+		return selectRows(s.conn, table, q)
 	default:
 		return nil, fmt.Errorf("unrecognized table: %s", table)
 	}
 }
 
-func (s server) queryFromDaemon(table db.TableType) (
+func (s server) queryFromDaemon(table db.TableType, q api.Query) (
 	interface{}, error) {
 
 	switch table {
 	case db.MachineTable, db.BlueprintTable:
-		return s.queryLocal(table)
+		return s.queryLocal(table, q)
 	}
 
 	var leaderClient client.Client
@@ -165,15 +262,19 @@ func (s server) queryFromDaemon(table db.TableType) (
 	}
 	defer leaderClient.Close()
 
+	// The leader evaluates the query, and the caller re-applies the
+	// projection to the decoded rows.
 	switch table {
 	case db.ContainerTable:
-		return leaderClient.QueryContainers()
+		return leaderClient.SelectContainers(q)
 	case db.ConnectionTable:
-		return leaderClient.QueryConnections()
+		return leaderClient.SelectConnections(q)
 	case db.LoadBalancerTable:
-		return leaderClient.QueryLoadBalancers()
+		return leaderClient.SelectLoadBalancers(q)
 	case db.ImageTable:
-		return leaderClient.QueryImages()
+		return leaderClient.SelectImages(q)
+	case db.StatsTable:
+		return leaderClient.SelectStats(q)
 	default:
 		return nil, fmt.Errorf("unrecognized table: %s", table)
 	}
@@ -207,42 +308,74 @@ func (s server) QueryCounters(ctx context.Context, in *pb.CountersRequest) (
 	return &pb.CountersReply{Counters: counter.Dump()}, nil
 }
 
-func (s server) Deploy(cts context.Context, deployReq *pb.DeployRequest) (
+func (s server) Deploy(ctx context.Context, deployReq *pb.DeployRequest) (
+	*pb.DeployReply, error) {
+
+	if !s.runningOnDaemon {
+		return nil, errDaemonOnlyRPC
+	}
+
+	return s.deploy(ctx, deployReq.Deployment,
+		pb.Deployment{SourceHash: deployReq.SourceHash})
+}
+
+// Rollback redeploys a blueprint from the deployment history.
+func (s server) Rollback(ctx context.Context, req *pb.RollbackRequest) (
 	*pb.DeployReply, error) {
 
 	if !s.runningOnDaemon {
 		return nil, errDaemonOnlyRPC
 	}
 
-	newBlueprint, err := blueprint.FromJSON(deployReq.Deployment)
+	history, err := readHistory()
 	if err != nil {
 		return &pb.DeployReply{}, err
 	}
 
-	for _, c := range newBlueprint.Containers {
-		if _, err := reference.ParseAnyReference(c.Image.Name); err != nil {
-			return &pb.DeployReply{}, fmt.Errorf("could not parse "+
-				"container image %s: %s", c.Image.Name, err.Error())
-		}
+	target, err := api.RollbackTarget(history, req.Version)
+	if err != nil {
+		return &pb.DeployReply{}, err
 	}
 
-	// Ensure that the region is valid
-	if len(newBlueprint.Machines) > 0 {
-		// Since the Javascript code ensures that all machines have the same
-		// region and provider, we only need to check the region of the first
-		// machine is valid for its provider.
-		first := newBlueprint.Machines[0]
-		regionValid := false
-		for _, r := range cloud.ValidRegions(db.ProviderName(first.Provider)) {
-			if r == first.Region {
-				regionValid = true
-			}
-		}
-		if !regionValid {
-			return &pb.DeployReply{}, fmt.Errorf("region: %s is "+
-				"not supported for provider: %s", first.Region,
-				first.Provider)
-		}
+	return s.deploy(ctx, target.Blueprint, pb.Deployment{
+		SourceHash: target.SourceHash,
+		RollbackOf: target.Version,
+	})
+}
+
+// DeploymentHistory returns the blueprints deployed by the daemon, ordered
+// from oldest to newest.
+func (s server) DeploymentHistory(ctx context.Context,
+	_ *pb.DeploymentHistoryRequest) (*pb.DeploymentHistoryReply, error) {
+
+	if !s.runningOnDaemon {
+		return nil, errDaemonOnlyRPC
+	}
+
+	history, err := readHistory()
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &pb.DeploymentHistoryReply{}
+	for i := range history {
+		reply.Deployments = append(reply.Deployments, &history[i])
+	}
+	return reply, nil
+}
+
+// deploy validates and deploys the given blueprint, and records it in the
+// deployment history along with the metadata in `record`.
+func (s server) deploy(ctx context.Context, deployment string,
+	record pb.Deployment) (*pb.DeployReply, error) {
+
+	newBlueprint, err := blueprint.FromJSON(deployment)
+	if err != nil {
+		return &pb.DeployReply{}, err
+	}
+
+	if err := cloud.ValidateBlueprint(newBlueprint); err != nil {
+		return &pb.DeployReply{}, err
 	}
 
 	s.conn.Txn(db.BlueprintTable, db.MachineTable).Run(func(view db.Database) error {
@@ -266,6 +399,13 @@ func (s server) Deploy(cts context.Context, deployReq *pb.DeployRequest) (
 		return nil
 	})
 
+	record.Blueprint = newBlueprint.String()
+	record.Deployed = time.Now().Unix()
+	record.DeployedBy = clientName(ctx)
+	if _, err := recordDeployment(record); err != nil {
+		log.WithError(err).Warn("Failed to record deployment history")
+	}
+
 	// XXX: Remove this error when the Vagrant provider is done.
 	for _, machine := range newBlueprint.Machines {
 		if machine.Provider == string(db.Vagrant) {
@@ -289,3 +429,15 @@ func (s server) Version(_ context.Context, _ *pb.VersionRequest) (
 var newClient = client.New
 var newLeaderClient = client.Leader
 var newSecretClient = kubernetes.NewSecretClient
+var newDockerClient = func() docker.Client {
+	return docker.New("unix:///var/run/docker.sock")
+}
+var dialContainer = func(addr string) (net.Conn, error) {
+	// The OpenFlow rules only allow packets from the gateway to reach a
+	// container on ports without a public connection.
+	dialer := net.Dialer{
+		Timeout:   portForwardDialTimeout,
+		LocalAddr: &net.TCPAddr{IP: ipdef.GatewayIP},
+	}
+	return dialer.Dial("tcp", addr)
+}
@@ -7,6 +7,7 @@ import (
 
 	"golang.org/x/net/context"
 
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/api/client"
 	"github.com/kelda/kelda/api/client/mocks"
 	"github.com/kelda/kelda/api/pb"
@@ -15,9 +16,16 @@ import (
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion/kubernetes"
 	kubeMocks "github.com/kelda/kelda/minion/kubernetes/mocks"
+	"github.com/kelda/kelda/util"
+	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 )
 
+func init() {
+	// Deploying records the blueprint in the deployment history on disk.
+	util.AppFs = afero.NewMemMapFs()
+}
+
 func checkQuery(t *testing.T, s server, table db.TableType, exp string) {
 	reply, err := s.Query(context.Background(),
 		&pb.DBQuery{Table: string(table)})
@@ -85,7 +93,8 @@ func TestQueryContainersCluster(t *testing.T) {
 	})
 
 	exp := `[{"PodName":"podName","Command":["cmd","arg"],` +
-		`"Created":"0001-01-01T00:00:00Z","Image":"image"}]`
+		`"Created":"0001-01-01T00:00:00Z",` +
+		`"Completed":"0001-01-01T00:00:00Z","Image":"image"}]`
 
 	checkQuery(t, server{conn, false, nil}, db.ContainerTable, exp)
 }
@@ -94,7 +103,7 @@ func TestQueryContainersDaemon(t *testing.T) {
 	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
 		client.Client, error) {
 		mc := new(mocks.Client)
-		mc.On("QueryContainers").Return([]db.Container{{
+		mc.On("SelectContainers", api.Query{}).Return([]db.Container{{
 			BlueprintID: "id",
 			Image:       "image",
 		}, {
@@ -106,8 +115,9 @@ func TestQueryContainersDaemon(t *testing.T) {
 	}
 
 	exp := `[{"BlueprintID":"id","Created":"0001-01-01T00:00:00Z",` +
-		`"Image":"image"},{"BlueprintID":"id2",` +
-		`"Created":"0001-01-01T00:00:00Z","Image":"image2"}]`
+		`"Completed":"0001-01-01T00:00:00Z","Image":"image"},` +
+		`{"BlueprintID":"id2","Created":"0001-01-01T00:00:00Z",` +
+		`"Completed":"0001-01-01T00:00:00Z","Image":"image2"}]`
 	checkQuery(t, server{db.New(), true, nil}, db.ContainerTable, exp)
 }
 
@@ -127,23 +137,25 @@ func TestInvalidImage(t *testing.T) {
 	conn := db.New()
 	s := server{conn: conn, runningOnDaemon: true}
 	testInvalidImage(t, s, "has:morethan:two:colons",
-		"could not parse container image has:morethan:two:colons: "+
-			"invalid reference format")
+		`invalid blueprint: container "foo": could not parse image `+
+			"has:morethan:two:colons: invalid reference format")
 	testInvalidImage(t, s, "has-empty-tag:",
-		"could not parse container image has-empty-tag:: "+
-			"invalid reference format")
+		`invalid blueprint: container "foo": could not parse image `+
+			"has-empty-tag:: invalid reference format")
 	testInvalidImage(t, s, "has-empty-tag::digest",
-		"could not parse container image has-empty-tag::digest: "+
-			"invalid reference format")
+		`invalid blueprint: container "foo": could not parse image `+
+			"has-empty-tag::digest: invalid reference format")
 	testInvalidImage(t, s, "hasCapital",
-		"could not parse container image hasCapital: "+
-			"invalid reference format: repository name must be lowercase")
+		`invalid blueprint: container "foo": could not parse image `+
+			"hasCapital: invalid reference format: repository name "+
+			"must be lowercase")
 }
 
 func testInvalidImage(t *testing.T, s server, img, expErr string) {
 	deployment := fmt.Sprintf(`
 	{"Containers":[
 		{"ID": "1",
+                "Hostname": "foo",
                 "Image": {"Name": "%s"},
                 "Command":[
                         "sleep",
@@ -190,6 +202,57 @@ func TestDeploy(t *testing.T) {
 	assert.Equal(t, exp, bp.Blueprint)
 }
 
+func TestDeployHistory(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	conn := db.New()
+	s := server{conn: conn, runningOnDaemon: true}
+	alice := certContext(api.UserSubject("alice", api.DeployerRole), 1)
+
+	first := blueprint.Blueprint{Namespace: "first"}.String()
+	_, err := s.Deploy(alice, &pb.DeployRequest{
+		Deployment: first, SourceHash: "hash"})
+	assert.NoError(t, err)
+
+	second := blueprint.Blueprint{Namespace: "second"}.String()
+	_, err = s.Deploy(context.Background(), &pb.DeployRequest{Deployment: second})
+	assert.NoError(t, err)
+
+	reply, err := s.DeploymentHistory(nil, &pb.DeploymentHistoryRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, reply.Deployments, 2)
+	assert.Equal(t, int64(1), reply.Deployments[0].Version)
+	assert.Equal(t, "alice", reply.Deployments[0].DeployedBy)
+	assert.Equal(t, "hash", reply.Deployments[0].SourceHash)
+	assert.Equal(t, first, reply.Deployments[0].Blueprint)
+	assert.NotZero(t, reply.Deployments[0].Deployed)
+	assert.Equal(t, int64(2), reply.Deployments[1].Version)
+	assert.Equal(t, "unknown", reply.Deployments[1].DeployedBy)
+
+	// Rolling back should redeploy the first blueprint as a new version.
+	_, err = s.Rollback(alice, &pb.RollbackRequest{})
+	assert.NoError(t, err)
+
+	namespace, err := conn.GetBlueprintNamespace()
+	assert.NoError(t, err)
+	assert.Equal(t, "first", namespace)
+
+	reply, err = s.DeploymentHistory(nil, &pb.DeploymentHistoryRequest{})
+	assert.NoError(t, err)
+	assert.Len(t, reply.Deployments, 3)
+	assert.Equal(t, pb.Deployment{
+		Version:    3,
+		Deployed:   reply.Deployments[2].Deployed,
+		DeployedBy: "alice",
+		SourceHash: "hash",
+		Blueprint:  first,
+		RollbackOf: 1,
+	}, *reply.Deployments[2])
+
+	_, err = s.Rollback(alice, &pb.RollbackRequest{Version: 10})
+	assert.EqualError(t, err, "unknown deployment version 10")
+}
+
 func TestDeployUnsupportedRegion(t *testing.T) {
 	conn := db.New()
 	s := server{conn: conn, runningOnDaemon: true}
@@ -209,8 +272,8 @@ func TestDeployUnsupportedRegion(t *testing.T) {
 	_, err := s.Deploy(context.Background(),
 		&pb.DeployRequest{Deployment: createMachineDeployment})
 
-	assert.EqualError(t, err, "region: FakeRegion is not supported "+
-		"for provider: Amazon")
+	assert.EqualError(t, err, `invalid blueprint: machine 0: region `+
+		`"FakeRegion" is not supported for provider Amazon`)
 }
 
 func TestDeployChangeNamespace(t *testing.T) {
@@ -279,6 +342,12 @@ func TestDaemonOnlyEndpoints(t *testing.T) {
 
 	_, err = server{runningOnDaemon: false}.Deploy(nil, nil)
 	assert.EqualError(t, err, errDaemonOnlyRPC.Error())
+
+	_, err = server{runningOnDaemon: false}.Rollback(nil, nil)
+	assert.EqualError(t, err, errDaemonOnlyRPC.Error())
+
+	_, err = server{runningOnDaemon: false}.DeploymentHistory(nil, nil)
+	assert.EqualError(t, err, errDaemonOnlyRPC.Error())
 }
 
 func TestQueryImagesCluster(t *testing.T) {
@@ -301,7 +370,7 @@ func TestQueryImagesDaemon(t *testing.T) {
 	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
 		client.Client, error) {
 		mc := new(mocks.Client)
-		mc.On("QueryImages").Return([]db.Image{{
+		mc.On("SelectImages", api.Query{}).Return([]db.Image{{
 			Name: "bar",
 		}}, nil)
 		mc.On("Close").Return(nil)
@@ -312,6 +381,25 @@ func TestQueryImagesDaemon(t *testing.T) {
 	checkQuery(t, server{db.New(), true, nil}, db.ImageTable, exp)
 }
 
+func TestQueryStatsDaemon(t *testing.T) {
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		mc := new(mocks.Client)
+		mc.On("SelectStats", api.Query{}).Return([]db.Stats{{
+			Hostname:      "web",
+			CPUMillicores: 250,
+		}}, nil)
+		mc.On("Close").Return(nil)
+		return mc, nil
+	}
+
+	exp := `[{"ID":0,"Hostname":"web","Minion":"","CPUMillicores":250,` +
+		`"MemoryBytes":0,"NetworkRxBytes":0,"NetworkTxBytes":0,` +
+		`"NetworkRxBytesPerSec":0,"NetworkTxBytesPerSec":0,"DiskBytes":0,` +
+		`"Collected":"0001-01-01T00:00:00Z"}]`
+	checkQuery(t, server{db.New(), true, nil}, db.StatsTable, exp)
+}
+
 // The Daemon should get a connection to the leader of the cluster, and
 // forward the secret association.
 func TestSetSecretDaemon(t *testing.T) {
@@ -360,3 +448,109 @@ func TestSetSecretClusterError(t *testing.T) {
 	_, err := server{db.New(), false, nil}.SetSecret(nil, &pb.Secret{})
 	assert.NotNil(t, err)
 }
+
+func TestListSecretsCluster(t *testing.T) {
+	mockClient := &kubeMocks.SecretClient{}
+	newSecretClient = func() (kubernetes.SecretClient, error) {
+		return mockClient, nil
+	}
+	mockClient.On("List").Return([]pb.SecretInfo{
+		{Name: "used", Version: 2, PriorVersions: []int64{1}},
+		{Name: "unused", Version: 1},
+	}, nil)
+
+	conn := db.New()
+	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
+		for _, hostname := range []string{"b", "a"} {
+			dbc := view.InsertContainer()
+			dbc.Hostname = hostname
+			dbc.Env = map[string]blueprint.ContainerValue{
+				"env": blueprint.NewSecret("used"),
+			}
+			dbc.FilepathToContent = map[string]blueprint.ContainerValue{
+				"/file": blueprint.NewSecret("used"),
+			}
+			view.Commit(dbc)
+		}
+		return nil
+	})
+
+	reply, err := server{conn, false, nil}.ListSecrets(nil,
+		&pb.ListSecretsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.SecretInfo{
+		{Name: "used", Version: 2, PriorVersions: []int64{1},
+			Containers: []string{"a", "b"}},
+		{Name: "unused", Version: 1},
+	}, reply.Secrets)
+}
+
+func TestListSecretsDaemon(t *testing.T) {
+	secrets := []pb.SecretInfo{{Name: "foo", Version: 1}}
+
+	mc := new(mocks.Client)
+	mc.On("ListSecrets").Return(secrets, nil)
+	mc.On("Close").Return(nil)
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		return mc, nil
+	}
+
+	reply, err := server{db.New(), true, nil}.ListSecrets(nil,
+		&pb.ListSecretsRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []*pb.SecretInfo{&secrets[0]}, reply.Secrets)
+	mc.AssertExpectations(t)
+}
+
+func TestDeleteSecret(t *testing.T) {
+	mockClient := &kubeMocks.SecretClient{}
+	newSecretClient = func() (kubernetes.SecretClient, error) {
+		return mockClient, nil
+	}
+	mockClient.On("Delete", "foo").Return(nil).Once()
+
+	_, err := server{db.New(), false, nil}.DeleteSecret(nil,
+		&pb.DeleteSecretRequest{Name: "foo"})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	mc := new(mocks.Client)
+	mc.On("DeleteSecret", "foo").Return(nil)
+	mc.On("Close").Return(nil)
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		return mc, nil
+	}
+
+	_, err = server{db.New(), true, nil}.DeleteSecret(nil,
+		&pb.DeleteSecretRequest{Name: "foo"})
+	assert.NoError(t, err)
+	mc.AssertExpectations(t)
+}
+
+func TestRollbackSecret(t *testing.T) {
+	mockClient := &kubeMocks.SecretClient{}
+	newSecretClient = func() (kubernetes.SecretClient, error) {
+		return mockClient, nil
+	}
+	mockClient.On("Rollback", "foo", int64(2)).Return(nil).Once()
+
+	_, err := server{db.New(), false, nil}.RollbackSecret(nil,
+		&pb.RollbackSecretRequest{Name: "foo", Version: 2})
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+
+	mc := new(mocks.Client)
+	mc.On("RollbackSecret", "foo", int64(0)).Return(nil)
+	mc.On("Close").Return(nil)
+	newLeaderClient = func(_ []db.Machine, _ connection.Credentials) (
+		client.Client, error) {
+		return mc, nil
+	}
+
+	_, err = server{db.New(), true, nil}.RollbackSecret(nil,
+		&pb.RollbackSecretRequest{Name: "foo"})
+	assert.NoError(t, err)
+	mc.AssertExpectations(t)
+}
@@ -0,0 +1,217 @@
+// Package bindings builds blueprints in Go. It mirrors the JavaScript bindings,
+// so that tools written in Go can describe deployments without generating
+// JavaScript.
+//
+// Objects are deployed to an Infrastructure, which converts them into a
+// blueprint.Blueprint:
+//
+//	machine := bindings.Machine{Provider: "Amazon", Size: "m4.large"}
+//	infra := bindings.NewInfrastructure([]bindings.Machine{machine},
+//		machine.Replicate(2))
+//
+//	web := bindings.NewContainer("web", "nginx")
+//	web.Deploy(infra)
+//	infra.AllowTraffic([]bindings.Connectable{bindings.PublicInternet},
+//		[]bindings.Connectable{web}, bindings.Port(80))
+//
+//	bp, err := infra.Blueprint()
+package bindings
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/kelda/kelda/blueprint"
+)
+
+// The regions that machines are placed in if they don't specify one.
+var providerDefaultRegions = map[string]string{
+	"Amazon":       "us-west-1",
+	"Google":       "us-east1-b",
+	"DigitalOcean": "sfo2",
+	"Vagrant":      "",
+}
+
+// An Infrastructure is the collection of machines that a deployment runs on,
+// along with the containers, load balancers and connections deployed to it.
+type Infrastructure struct {
+	Masters []Machine
+	Workers []Machine
+
+	// The namespace that the deployment runs in. It defaults to "kelda".
+	Namespace string
+
+	// The IP ranges, in CIDR notation, that are allowed to access the
+	// machines, in addition to the machine running the daemon.
+	AdminACL []string
+
+	containers    []*Container
+	loadBalancers []*LoadBalancer
+	volumes       []*Volume
+	connections   []connection
+
+	hostnames   nameGenerator
+	volumeNames nameGenerator
+}
+
+// NewInfrastructure creates an Infrastructure that runs on the given masters
+// and workers.
+func NewInfrastructure(masters, workers []Machine) *Infrastructure {
+	return &Infrastructure{
+		Masters:     masters,
+		Workers:     workers,
+		Namespace:   "kelda",
+		hostnames:   nameGenerator{},
+		volumeNames: nameGenerator{},
+	}
+}
+
+// Blueprint converts the infrastructure and everything deployed to it into a
+// blueprint. The blueprint is checked with blueprint.Validate, and if there
+// are any problems a blueprint.ValidationError listing them is returned.
+func (infra *Infrastructure) Blueprint() (blueprint.Blueprint, error) {
+	var problems blueprint.ValidationError
+	if len(infra.Masters) == 0 {
+		problems = append(problems, "masters must include 1 or more machines")
+	}
+	if len(infra.Workers) == 0 {
+		problems = append(problems, "workers must include 1 or more machines")
+	}
+
+	bp := blueprint.Blueprint{
+		Namespace: infra.Namespace,
+		AdminACL:  infra.AdminACL,
+	}
+
+	var machines []Machine
+	machines = append(machines, infra.Masters...)
+	machines = append(machines, infra.Workers...)
+	for i, m := range machines {
+		role := "Master"
+		if i >= len(infra.Masters) {
+			role = "Worker"
+		}
+
+		machine, err := m.toBlueprint(role)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("machine %d: %s", i, err))
+			continue
+		}
+		bp.Machines = append(bp.Machines, machine)
+	}
+
+	for _, vol := range infra.volumes {
+		bp.Volumes = append(bp.Volumes, blueprint.Volume{
+			Name: vol.name,
+			Type: vol.Type,
+			Conf: vol.Conf,
+		})
+	}
+
+	for _, c := range infra.containers {
+		bp.Containers = append(bp.Containers, c.toBlueprint())
+		for _, plcm := range c.placements {
+			plcm.TargetContainer = c.hostname
+			bp.Placements = append(bp.Placements, plcm)
+		}
+	}
+
+	for _, lb := range infra.loadBalancers {
+		var hostnames []string
+		for _, c := range lb.Containers {
+			hostnames = append(hostnames, c.Hostname())
+		}
+		bp.LoadBalancers = append(bp.LoadBalancers, blueprint.LoadBalancer{
+			Name:      lb.hostname,
+			Hostnames: hostnames,
+		})
+	}
+
+	for _, conn := range infra.connections {
+		bp.Connections = append(bp.Connections, conn.toBlueprint())
+	}
+
+	if err := blueprint.Validate(bp); err != nil {
+		problems = append(problems, err.(blueprint.ValidationError)...)
+	}
+
+	if len(problems) != 0 {
+		return blueprint.Blueprint{}, problems
+	}
+	return bp, nil
+}
+
+// A Machine describes the virtual machines that the deployment runs on.
+type Machine struct {
+	// The cloud provider: Amazon, DigitalOcean, Google or Vagrant.
+	Provider string
+
+	// The region defaults to the provider's default region.
+	Region string
+
+	// The provider's name for the machine size, e.g. "m4.large". Unlike the
+	// JavaScript bindings, sizes aren't chosen from CPU and RAM requirements,
+	// so they're required for every provider except Vagrant, where the
+	// default is 1 CPU and 1 GB of RAM.
+	Size string
+
+	FloatingIP  string
+	DiskSize    int
+	SSHKeys     []string
+	Preemptible bool
+}
+
+// Replicate returns n copies of the machine.
+func (m Machine) Replicate(n int) []Machine {
+	var machines []Machine
+	for i := 0; i < n; i++ {
+		m.SSHKeys = append([]string(nil), m.SSHKeys...)
+		machines = append(machines, m)
+	}
+	return machines
+}
+
+func (m Machine) toBlueprint(role string) (blueprint.Machine, error) {
+	defaultRegion, ok := providerDefaultRegions[m.Provider]
+	if !ok {
+		return blueprint.Machine{}, fmt.Errorf("unknown provider %q: "+
+			"accepted values are Amazon, DigitalOcean, Google, and Vagrant",
+			m.Provider)
+	}
+
+	if m.Region == "" {
+		m.Region = defaultRegion
+	}
+
+	if m.Size == "" {
+		if m.Provider != "Vagrant" {
+			return blueprint.Machine{}, fmt.Errorf("a size is required for "+
+				"%s machines", m.Provider)
+		}
+		m.Size = "1,1"
+	}
+
+	return blueprint.Machine{
+		Provider:    m.Provider,
+		Role:        role,
+		Region:      m.Region,
+		Size:        m.Size,
+		FloatingIP:  m.FloatingIP,
+		DiskSize:    m.DiskSize,
+		SSHKeys:     m.SSHKeys,
+		Preemptible: m.Preemptible,
+	}, nil
+}
+
+// nameGenerator makes names unique by appending a counter to names that have
+// already been used, in the same way as the JavaScript bindings.
+type nameGenerator map[string]int
+
+func (gen nameGenerator) getName(prefix string) string {
+	if _, ok := gen[prefix]; !ok {
+		gen[prefix] = 1
+		return prefix
+	}
+	gen[prefix]++
+	return gen.getName(prefix + strconv.Itoa(gen[prefix]))
+}
@@ -0,0 +1,367 @@
+package bindings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/blueprint"
+)
+
+func TestBlueprint(t *testing.T) {
+	t.Parallel()
+
+	machine := Machine{Provider: "Amazon", Size: "m4.large",
+		SSHKeys: []string{"key"}}
+	infra := NewInfrastructure([]Machine{machine}, machine.Replicate(2))
+	infra.AdminACL = []string{"1.2.3.4/32"}
+
+	data := NewHostPathVolume("data", "/var/data")
+	db := NewContainer("db", "postgres")
+	db.Env["PASSWORD"] = blueprint.NewSecret("password")
+	db.VolumeMounts = []VolumeMount{{Volume: data, MountPath: "/data"}}
+	db.PlaceOn(MachineAttributes{Size: "m4.large"})
+	db.InitContainers = []PodContainer{{
+		Name:  "restore",
+		Image: Image{Name: "restore", Dockerfile: "FROM postgres"},
+		VolumeMounts: []VolumeMount{
+			{Volume: NewHostPathVolume("backups", "/var/backups"),
+				MountPath: "/backups"},
+			{Volume: data, MountPath: "/data"},
+		},
+	}}
+	db.Deploy(infra)
+
+	var webs []*Container
+	web := NewContainer("web", "nginx")
+	web.ReadinessCheck = &blueprint.HealthCheck{
+		HTTPGet: &blueprint.HTTPGetCheck{Path: "/", Port: 80},
+	}
+	web.UpdateStrategy = blueprint.RollingUpdate
+	web.Sidecars = []PodContainer{{
+		Name:  "proxy",
+		Image: Image{Name: "envoy"},
+		Env: map[string]blueprint.ContainerValue{
+			"PORT": blueprint.NewString("8080"),
+		},
+	}}
+	for i := 0; i < 2; i++ {
+		clone := web.Clone()
+		clone.Deploy(infra)
+		webs = append(webs, clone)
+	}
+
+	lb := NewLoadBalancer("web", webs)
+	lb.Deploy(infra)
+
+	infra.AllowTraffic([]Connectable{PublicInternet}, []Connectable{lb},
+		Port(80))
+	infra.AllowTraffic([]Connectable{PublicInternet}, []Connectable{lb},
+		PublicPort(8443, 443))
+	infra.AllowTraffic([]Connectable{webs[0], webs[1]}, []Connectable{db},
+		PortRange{Min: 5432, Max: 5433})
+	infra.AllowTraffic([]Connectable{webs[0]}, []Connectable{db}, UDPPort(53))
+	infra.AllowTraffic([]Connectable{webs[0]}, []Connectable{db}, ICMP)
+
+	bp, err := infra.Blueprint()
+	assert.NoError(t, err)
+
+	// Clones don't share their health checks.
+	webs[1].ReadinessCheck.HTTPGet.Port = 8080
+	assert.Equal(t, 80, webs[0].ReadinessCheck.HTTPGet.Port)
+	webs[1].ReadinessCheck.HTTPGet.Port = 80
+	webs[1].Sidecars[0].Env["PORT"] = blueprint.NewString("9090")
+	assert.Equal(t, blueprint.NewString("8080"), webs[0].Sidecars[0].Env["PORT"])
+	webs[1].Sidecars[0].Env["PORT"] = blueprint.NewString("8080")
+
+	// Clones and load balancers get unique hostnames.
+	assert.Equal(t, "web", webs[0].Hostname())
+	assert.Equal(t, "web2", webs[1].Hostname())
+	assert.Equal(t, "web3", lb.Hostname())
+
+	dbContainer := blueprint.Container{
+		Hostname: "db",
+		Image:    blueprint.Image{Name: "postgres"},
+		Env: map[string]blueprint.ContainerValue{
+			"PASSWORD": blueprint.NewSecret("password"),
+		},
+		VolumeMounts: []blueprint.VolumeMount{
+			{VolumeName: "data", MountPath: "/data"},
+		},
+		InitContainers: []blueprint.PodContainer{{
+			Name: "restore",
+			Image: blueprint.Image{Name: "restore",
+				Dockerfile: "FROM postgres"},
+			VolumeMounts: []blueprint.VolumeMount{
+				{VolumeName: "backups", MountPath: "/backups"},
+				{VolumeName: "data", MountPath: "/data"},
+			},
+		}},
+	}
+	dbContainer.ID = blueprint.ContainerID(dbContainer)
+
+	webContainer := func(hostname string) blueprint.Container {
+		c := blueprint.Container{
+			Hostname: hostname,
+			Image:    blueprint.Image{Name: "nginx"},
+			ReadinessCheck: &blueprint.HealthCheck{
+				HTTPGet: &blueprint.HTTPGetCheck{Path: "/", Port: 80},
+			},
+			UpdateStrategy: blueprint.RollingUpdate,
+			Sidecars: []blueprint.PodContainer{{
+				Name:  "proxy",
+				Image: blueprint.Image{Name: "envoy"},
+				Env: map[string]blueprint.ContainerValue{
+					"PORT": blueprint.NewString("8080"),
+				},
+			}},
+		}
+		c.ID = blueprint.ContainerID(c)
+		return c
+	}
+
+	assert.Equal(t, blueprint.Blueprint{
+		Namespace: "kelda",
+		AdminACL:  []string{"1.2.3.4/32"},
+		Machines: []blueprint.Machine{
+			{Provider: "Amazon", Role: "Master", Region: "us-west-1",
+				Size: "m4.large", SSHKeys: []string{"key"}},
+			{Provider: "Amazon", Role: "Worker", Region: "us-west-1",
+				Size: "m4.large", SSHKeys: []string{"key"}},
+			{Provider: "Amazon", Role: "Worker", Region: "us-west-1",
+				Size: "m4.large", SSHKeys: []string{"key"}},
+		},
+		Volumes: []blueprint.Volume{
+			{Name: "data", Type: "hostPath",
+				Conf: map[string]string{"path": "/var/data"}},
+			{Name: "backups", Type: "hostPath",
+				Conf: map[string]string{"path": "/var/backups"}},
+		},
+		Containers: []blueprint.Container{dbContainer,
+			webContainer("web"), webContainer("web2")},
+		Placements: []blueprint.Placement{
+			{TargetContainer: "db", Size: "m4.large"},
+		},
+		LoadBalancers: []blueprint.LoadBalancer{
+			{Name: "web3", Hostnames: []string{"web", "web2"}},
+		},
+		Connections: []blueprint.Connection{
+			{From: []string{"public"}, To: []string{"web3"}, MinPort: 80,
+				MaxPort: 80},
+			{From: []string{"public"}, To: []string{"web3"}, MinPort: 443,
+				MaxPort: 443, PublicPort: 8443},
+			{From: []string{"web", "web2"}, To: []string{"db"},
+				MinPort: 5432, MaxPort: 5433},
+			{From: []string{"web"}, To: []string{"db"}, MinPort: 53,
+				MaxPort: 53, Protocol: "udp"},
+			{From: []string{"web"}, To: []string{"db"}, Protocol: "icmp"},
+		},
+	}, bp)
+}
+
+func TestDeployTwice(t *testing.T) {
+	t.Parallel()
+
+	infra := NewInfrastructure([]Machine{{Provider: "Vagrant"}},
+		[]Machine{{Provider: "Vagrant"}})
+
+	vol := NewHostPathVolume("data", "/data")
+	c := NewContainer("web", "nginx")
+	c.VolumeMounts = []VolumeMount{{Volume: vol, MountPath: "/data"}}
+	c.Deploy(infra)
+	c.Deploy(infra)
+
+	other := NewContainer("other", "nginx")
+	other.VolumeMounts = []VolumeMount{{Volume: vol, MountPath: "/data"}}
+	other.Deploy(infra)
+
+	bp, err := infra.Blueprint()
+	assert.NoError(t, err)
+	assert.Len(t, bp.Containers, 2)
+	assert.Len(t, bp.Volumes, 1)
+	assert.Equal(t, "web", bp.Containers[0].Hostname)
+	assert.Equal(t, "1,1", bp.Machines[0].Size)
+	assert.Equal(t, "", bp.Machines[0].Region)
+}
+
+func TestStop(t *testing.T) {
+	t.Parallel()
+
+	infra := NewInfrastructure([]Machine{{Provider: "Vagrant"}},
+		[]Machine{{Provider: "Vagrant"}})
+
+	c := NewContainer("db", "postgres")
+	c.Entrypoint = []string{"docker-entrypoint.sh"}
+	c.WorkingDir = "/var/lib/postgresql"
+	c.PreStop = []string{"pg_ctl", "stop"}
+	c.TerminationGracePeriodSeconds = 120
+	clone := c.Clone()
+	c.Deploy(infra)
+
+	// Clones don't share their commands.
+	clone.PreStop[0] = "kill"
+
+	bp, err := infra.Blueprint()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker-entrypoint.sh"},
+		bp.Containers[0].Entrypoint)
+	assert.Equal(t, "/var/lib/postgresql", bp.Containers[0].WorkingDir)
+	assert.Equal(t, []string{"pg_ctl", "stop"}, bp.Containers[0].PreStop)
+	assert.Equal(t, 120, bp.Containers[0].TerminationGracePeriodSeconds)
+}
+
+func TestSecurityContext(t *testing.T) {
+	t.Parallel()
+
+	infra := NewInfrastructure([]Machine{{Provider: "Vagrant"}},
+		[]Machine{{Provider: "Vagrant"}})
+
+	user := 1000
+	c := NewContainer("vpn", "openvpn")
+	c.SecurityContext = &blueprint.SecurityContext{
+		CapAdd:    []string{"NET_ADMIN"},
+		RunAsUser: &user,
+	}
+	clone := c.Clone()
+	c.Deploy(infra)
+
+	// Clones don't share their security contexts.
+	*clone.SecurityContext.RunAsUser = 0
+	clone.SecurityContext.CapAdd[0] = "NET_RAW"
+
+	bp, err := infra.Blueprint()
+	assert.NoError(t, err)
+	assert.Equal(t, &blueprint.SecurityContext{
+		CapAdd:    []string{"NET_ADMIN"},
+		RunAsUser: &user,
+	}, bp.Containers[0].SecurityContext)
+}
+
+func TestCloudVolumes(t *testing.T) {
+	t.Parallel()
+
+	infra := NewInfrastructure([]Machine{{Provider: "Vagrant"}},
+		[]Machine{{Provider: "Vagrant"}})
+
+	data := NewCloudVolume("data", blueprint.GooglePersistentDiskVolume, "10Gi")
+	data.Conf["zone"] = "us-west1-a"
+	c := NewContainer("db", "postgres")
+	c.VolumeMounts = []VolumeMount{
+		{Volume: data, MountPath: "/var/lib/postgresql/data"},
+		{Volume: NewEmptyDirVolume("scratch"), MountPath: "/tmp"},
+	}
+	c.Deploy(infra)
+
+	bp, err := infra.Blueprint()
+	assert.NoError(t, err)
+	assert.Equal(t, []blueprint.Volume{
+		{
+			Name: "data",
+			Type: blueprint.GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "10Gi", "zone": "us-west1-a"},
+		},
+		{
+			Name: "scratch",
+			Type: blueprint.EmptyDirVolume,
+			Conf: map[string]string{},
+		},
+	}, bp.Volumes)
+}
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+
+	infra := NewInfrastructure([]Machine{{Provider: "Vagrant"}},
+		[]Machine{{Provider: "Vagrant"}})
+
+	backoffLimit := 3
+	migrate := NewJob("migrate", "app")
+	migrate.Job.BackoffLimit = &backoffLimit
+	migrate.Deploy(infra)
+
+	etl := NewCronJob("etl", "etl", "0 3 * * *")
+	etl.Deploy(infra)
+
+	// Clones don't share their job settings.
+	clone := migrate.Clone()
+	*clone.Job.BackoffLimit = 5
+	clone.Job.Completions = 2
+	assert.Equal(t, 3, *migrate.Job.BackoffLimit)
+	assert.Equal(t, 0, migrate.Job.Completions)
+
+	bp, err := infra.Blueprint()
+	assert.NoError(t, err)
+	assert.Len(t, bp.Containers, 2)
+	assert.Equal(t, &blueprint.Job{BackoffLimit: &backoffLimit},
+		bp.Containers[0].Job)
+	assert.Equal(t, &blueprint.Job{Schedule: "0 3 * * *"},
+		bp.Containers[1].Job)
+
+	// Jobs have different IDs than the equivalent containers.
+	plain := bp.Containers[1]
+	plain.Job = nil
+	assert.NotEqual(t, bp.Containers[1].ID, blueprint.ContainerID(plain))
+}
+
+func TestContainerIDs(t *testing.T) {
+	t.Parallel()
+
+	build := func(command ...string) string {
+		infra := NewInfrastructure([]Machine{{Provider: "Vagrant"}},
+			[]Machine{{Provider: "Vagrant"}})
+		c := NewContainer("web", "nginx")
+		c.Command = command
+		c.Deploy(infra)
+
+		bp, err := infra.Blueprint()
+		assert.NoError(t, err)
+		return bp.Containers[0].ID
+	}
+
+	// IDs are stable, and change when the container changes.
+	assert.Equal(t, build("nginx"), build("nginx"))
+	assert.NotEqual(t, build("nginx"), build("nginx", "-v"))
+}
+
+func TestBlueprintErrors(t *testing.T) {
+	t.Parallel()
+
+	infra := NewInfrastructure(nil, []Machine{
+		{Provider: "Amazon"},
+		{Provider: "Azure"},
+	})
+	infra.Namespace = "Prod"
+
+	lb := NewLoadBalancer("lb", nil)
+	lb.Deploy(infra)
+
+	// The container isn't deployed.
+	web := NewContainer("web", "nginx")
+	infra.AllowTraffic([]Connectable{lb}, []Connectable{web}, Port(80))
+	infra.AllowTraffic([]Connectable{PublicInternet}, []Connectable{lb},
+		PortRange{Min: 80, Max: 81, Public: 8080})
+
+	_, err := infra.Blueprint()
+	assert.Equal(t, blueprint.ValidationError{
+		"masters must include 1 or more machines",
+		"machine 0: a size is required for Amazon machines",
+		`machine 1: unknown provider "Azure": accepted values are Amazon, ` +
+			"DigitalOcean, Google, and Vagrant",
+		`namespace "Prod" must be lowercase`,
+		`connection from lb to web on ports 80-80: load balancer "lb" ` +
+			"can't make outgoing connections",
+		`connection from lb to web on ports 80-80: unknown hostname "web"`,
+		"connection from public to lb on ports 80-81: port ranges can't be " +
+			"forwarded from a different public port",
+	}, err)
+}
+
+func TestNameGenerator(t *testing.T) {
+	t.Parallel()
+
+	gen := nameGenerator{}
+	assert.Equal(t, "a", gen.getName("a"))
+	assert.Equal(t, "a2", gen.getName("a"))
+	assert.Equal(t, "a3", gen.getName("a"))
+	assert.Equal(t, "a22", gen.getName("a2"))
+}
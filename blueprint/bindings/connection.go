@@ -0,0 +1,89 @@
+package bindings
+
+import (
+	"github.com/kelda/kelda/blueprint"
+)
+
+// A Connectable is something that traffic can be allowed to or from:
+// containers, load balancers, and the public internet.
+type Connectable interface {
+	connectableName() string
+}
+
+type publicInternet struct{}
+
+func (publicInternet) connectableName() string {
+	return blueprint.PublicInternetLabel
+}
+
+// PublicInternet represents all hosts outside of the deployment.
+var PublicInternet Connectable = publicInternet{}
+
+// A PortRange is an inclusive range of ports. If Protocol is empty, the range
+// covers both TCP and UDP ports.
+type PortRange struct {
+	Min, Max int
+	Protocol string
+
+	// For connections from the public internet to a single port, the port on
+	// the machine's public IP that's forwarded to Min. Defaults to Min.
+	Public int
+}
+
+// Port returns the range containing only port `p`.
+func Port(p int) PortRange {
+	return PortRange{Min: p, Max: p}
+}
+
+// TCPPort returns the range containing only TCP port `p`.
+func TCPPort(p int) PortRange {
+	return PortRange{Min: p, Max: p, Protocol: blueprint.TCPProtocol}
+}
+
+// UDPPort returns the range containing only UDP port `p`.
+func UDPPort(p int) PortRange {
+	return PortRange{Min: p, Max: p, Protocol: blueprint.UDPProtocol}
+}
+
+// PublicPort returns the range containing only port `container`, which the
+// public internet reaches through port `public` of the machine's public IP.
+func PublicPort(public, container int) PortRange {
+	return PortRange{Min: container, Max: container, Public: public}
+}
+
+// ICMP allows ICMP traffic, which has no ports.
+var ICMP = PortRange{Protocol: blueprint.ICMPProtocol}
+
+// connection is an allowed connection. Its endpoints are resolved to
+// hostnames when the blueprint is created, after they've been deployed.
+type connection struct {
+	from, to []Connectable
+	ports    PortRange
+}
+
+func (conn connection) toBlueprint() blueprint.Connection {
+	bc := blueprint.Connection{
+		MinPort:    conn.ports.Min,
+		MaxPort:    conn.ports.Max,
+		Protocol:   conn.ports.Protocol,
+		PublicPort: conn.ports.Public,
+	}
+	for _, src := range conn.from {
+		bc.From = append(bc.From, src.connectableName())
+	}
+	for _, dst := range conn.to {
+		bc.To = append(bc.To, dst.connectableName())
+	}
+	return bc
+}
+
+// AllowTraffic allows each of `src` to connect to each of `dst` on the given
+// ports. Load balancers can only receive traffic.
+func (infra *Infrastructure) AllowTraffic(src, dst []Connectable,
+	ports PortRange) {
+	infra.connections = append(infra.connections, connection{
+		from:  append([]Connectable(nil), src...),
+		to:    append([]Connectable(nil), dst...),
+		ports: ports,
+	})
+}
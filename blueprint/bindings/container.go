@@ -0,0 +1,434 @@
+package bindings
+
+import (
+	"github.com/kelda/kelda/blueprint"
+)
+
+// An Image is the Docker image that a container runs. If a Dockerfile is
+// given, the image is built from it and given Name.
+type Image struct {
+	Name       string
+	Dockerfile string
+}
+
+// A Container is a Docker container deployed by Kelda.
+type Container struct {
+	// The requested hostname. If it's already taken by another container or
+	// load balancer, a number is appended when the container is deployed.
+	Name string
+
+	Image      Image
+	Command    []string
+	Privileged bool
+
+	// Overrides of the image's entrypoint and working directory.
+	Entrypoint []string
+	WorkingDir string
+
+	// A command that's run in the container before it's stopped, and how long
+	// the container then has to exit before it's killed. Zero uses the
+	// Kubernetes default of 30 seconds.
+	PreStop                       []string
+	TerminationGracePeriodSeconds int
+
+	// Capabilities, users and profiles for containers that need some
+	// privileges, but shouldn't run fully privileged.
+	SecurityContext *blueprint.SecurityContext
+
+	// Environment variables and files are either strings created with
+	// blueprint.NewString, or secrets created with blueprint.NewSecret.
+	Env               map[string]blueprint.ContainerValue
+	FilepathToContent map[string]blueprint.ContainerValue
+
+	VolumeMounts []VolumeMount
+
+	// The CPU and memory reserved for the container, and the most it may use.
+	// Unset resources aren't reserved or limited.
+	Resources *blueprint.Resources
+
+	// Containers that fail their liveness check are restarted, and
+	// containers that fail their readiness check don't receive traffic from
+	// load balancers.
+	LivenessCheck  *blueprint.HealthCheck
+	ReadinessCheck *blueprint.HealthCheck
+
+	// How the container is replaced when it changes: blueprint.RecreateUpdate
+	// (the default) or blueprint.RollingUpdate.
+	UpdateStrategy string
+
+	// Containers that run in the same pod as the container. Sidecars run
+	// alongside it, and init containers run to completion, in order, before
+	// it starts.
+	Sidecars       []PodContainer
+	InitContainers []PodContainer
+
+	// If set, the container runs to completion, either once or on a
+	// schedule, rather than continuously.
+	Job *blueprint.Job
+
+	hostname   string
+	placements []blueprint.Placement
+}
+
+// NewContainer creates a container with the given name that runs the image.
+func NewContainer(name, image string) *Container {
+	return &Container{
+		Name:              name,
+		Image:             Image{Name: image},
+		Env:               map[string]blueprint.ContainerValue{},
+		FilepathToContent: map[string]blueprint.ContainerValue{},
+	}
+}
+
+// NewJob creates a container with the given name that runs the image to
+// completion once.
+func NewJob(name, image string) *Container {
+	c := NewContainer(name, image)
+	c.Job = &blueprint.Job{}
+	return c
+}
+
+// NewCronJob creates a container with the given name that runs the image to
+// completion each time the cron schedule fires.
+func NewCronJob(name, image, schedule string) *Container {
+	c := NewContainer(name, image)
+	c.Job = &blueprint.Job{Schedule: schedule}
+	return c
+}
+
+// Clone returns an undeployed copy of the container without its placement
+// constraints.
+func (c *Container) Clone() *Container {
+	clone := &Container{
+		Name:              c.Name,
+		Image:             c.Image,
+		Command:           append([]string(nil), c.Command...),
+		Privileged:        c.Privileged,
+		Entrypoint:        append([]string(nil), c.Entrypoint...),
+		WorkingDir:        c.WorkingDir,
+		Env:               map[string]blueprint.ContainerValue{},
+		FilepathToContent: map[string]blueprint.ContainerValue{},
+		VolumeMounts:      append([]VolumeMount(nil), c.VolumeMounts...),
+		UpdateStrategy:    c.UpdateStrategy,
+
+		PreStop:                       append([]string(nil), c.PreStop...),
+		TerminationGracePeriodSeconds: c.TerminationGracePeriodSeconds,
+	}
+	if c.Resources != nil {
+		resources := *c.Resources
+		clone.Resources = &resources
+	}
+	clone.SecurityContext = cloneSecurityContext(c.SecurityContext)
+	clone.LivenessCheck = cloneHealthCheck(c.LivenessCheck)
+	clone.ReadinessCheck = cloneHealthCheck(c.ReadinessCheck)
+	clone.Sidecars = clonePodContainers(c.Sidecars)
+	clone.InitContainers = clonePodContainers(c.InitContainers)
+	if c.Job != nil {
+		job := *c.Job
+		if c.Job.BackoffLimit != nil {
+			backoffLimit := *c.Job.BackoffLimit
+			job.BackoffLimit = &backoffLimit
+		}
+		clone.Job = &job
+	}
+	for key, val := range c.Env {
+		clone.Env[key] = val
+	}
+	for path, content := range c.FilepathToContent {
+		clone.FilepathToContent[path] = content
+	}
+	return clone
+}
+
+func cloneHealthCheck(check *blueprint.HealthCheck) *blueprint.HealthCheck {
+	if check == nil {
+		return nil
+	}
+
+	clone := *check
+	if check.HTTPGet != nil {
+		httpGet := *check.HTTPGet
+		clone.HTTPGet = &httpGet
+	}
+	if check.TCPSocket != nil {
+		tcpSocket := *check.TCPSocket
+		clone.TCPSocket = &tcpSocket
+	}
+	clone.Exec = append([]string(nil), check.Exec...)
+	return &clone
+}
+
+func cloneSecurityContext(sc *blueprint.SecurityContext) *blueprint.SecurityContext {
+	if sc == nil {
+		return nil
+	}
+
+	clone := *sc
+	clone.CapAdd = append([]string(nil), sc.CapAdd...)
+	clone.CapDrop = append([]string(nil), sc.CapDrop...)
+	if sc.RunAsUser != nil {
+		user := *sc.RunAsUser
+		clone.RunAsUser = &user
+	}
+	return &clone
+}
+
+func clonePodContainers(pcs []PodContainer) []PodContainer {
+	var clones []PodContainer
+	for _, pc := range pcs {
+		clone := pc
+		clone.Command = append([]string(nil), pc.Command...)
+		clone.VolumeMounts = append([]VolumeMount(nil), pc.VolumeMounts...)
+		if pc.Env != nil {
+			clone.Env = map[string]blueprint.ContainerValue{}
+			for key, val := range pc.Env {
+				clone.Env[key] = val
+			}
+		}
+		if pc.Resources != nil {
+			resources := *pc.Resources
+			clone.Resources = &resources
+		}
+		clones = append(clones, clone)
+	}
+	return clones
+}
+
+// Hostname returns the hostname of the container. Hostnames are made unique
+// when containers are deployed, so until then it's the requested Name.
+func (c *Container) Hostname() string {
+	if c.hostname == "" {
+		return c.Name
+	}
+	return c.hostname
+}
+
+func (c *Container) connectableName() string {
+	return c.Hostname()
+}
+
+// PlaceOn constrains the container to machines with the given attributes.
+// Unset attributes match any machine.
+func (c *Container) PlaceOn(attrs MachineAttributes) {
+	c.placements = append(c.placements, blueprint.Placement{
+		Provider:   attrs.Provider,
+		Size:       attrs.Size,
+		Region:     attrs.Region,
+		FloatingIP: attrs.FloatingIP,
+	})
+}
+
+// MachineAttributes are the machine properties that containers can be placed
+// by.
+type MachineAttributes struct {
+	Provider   string
+	Size       string
+	Region     string
+	FloatingIP string
+}
+
+// Deploy adds the container, and the volumes it mounts, to the
+// infrastructure. Deploying a container more than once has no effect.
+func (c *Container) Deploy(infra *Infrastructure) {
+	if c.hostname != "" {
+		return
+	}
+
+	c.hostname = infra.hostnames.getName(c.Name)
+	infra.containers = append(infra.containers, c)
+	for _, mount := range c.VolumeMounts {
+		mount.Volume.deploy(infra)
+	}
+	for _, pcs := range [][]PodContainer{c.InitContainers, c.Sidecars} {
+		for _, pc := range pcs {
+			for _, mount := range pc.VolumeMounts {
+				mount.Volume.deploy(infra)
+			}
+		}
+	}
+}
+
+func (c *Container) toBlueprint() blueprint.Container {
+	bc := blueprint.Container{
+		Hostname:          c.hostname,
+		Image:             blueprint.Image(c.Image),
+		Command:           c.Command,
+		Privileged:        c.Privileged,
+		SecurityContext:   c.SecurityContext,
+		Env:               nilIfEmpty(c.Env),
+		FilepathToContent: nilIfEmpty(c.FilepathToContent),
+		VolumeMounts:      toBlueprintMounts(c.VolumeMounts),
+		Resources:         c.Resources,
+		LivenessCheck:     c.LivenessCheck,
+		ReadinessCheck:    c.ReadinessCheck,
+		UpdateStrategy:    c.UpdateStrategy,
+		Sidecars:          toBlueprintPodContainers(c.Sidecars),
+		InitContainers:    toBlueprintPodContainers(c.InitContainers),
+		Job:               c.Job,
+
+		Entrypoint:                    c.Entrypoint,
+		WorkingDir:                    c.WorkingDir,
+		PreStop:                       c.PreStop,
+		TerminationGracePeriodSeconds: c.TerminationGracePeriodSeconds,
+	}
+	bc.ID = blueprint.ContainerID(bc)
+	return bc
+}
+
+func toBlueprintMounts(mounts []VolumeMount) []blueprint.VolumeMount {
+	var bms []blueprint.VolumeMount
+	for _, mount := range mounts {
+		bms = append(bms, blueprint.VolumeMount{
+			VolumeName: mount.Volume.name,
+			MountPath:  mount.MountPath,
+		})
+	}
+	return bms
+}
+
+func toBlueprintPodContainers(pcs []PodContainer) []blueprint.PodContainer {
+	var bpcs []blueprint.PodContainer
+	for _, pc := range pcs {
+		bpcs = append(bpcs, blueprint.PodContainer{
+			Name:         pc.Name,
+			Image:        blueprint.Image(pc.Image),
+			Command:      pc.Command,
+			Env:          nilIfEmpty(pc.Env),
+			VolumeMounts: toBlueprintMounts(pc.VolumeMounts),
+			Resources:    pc.Resources,
+		})
+	}
+	return bpcs
+}
+
+// nilIfEmpty avoids distinguishing between empty and unset maps in the
+// blueprint, which would otherwise change the container IDs.
+func nilIfEmpty(
+	vals map[string]blueprint.ContainerValue) map[string]blueprint.ContainerValue {
+	if len(vals) == 0 {
+		return nil
+	}
+	return vals
+}
+
+// A PodContainer is a sidecar or init container, which runs in the same pod as
+// a Container and shares its hostname, IP and volumes.
+type PodContainer struct {
+	// The name must be unique among the container's sidecars and init
+	// containers, and differ from the container's hostname.
+	Name    string
+	Image   Image
+	Command []string
+	Env     map[string]blueprint.ContainerValue
+
+	VolumeMounts []VolumeMount
+	Resources    *blueprint.Resources
+}
+
+// A LoadBalancer distributes traffic between a set of containers under a
+// single hostname.
+type LoadBalancer struct {
+	// The requested hostname, which is made unique in the same way as
+	// container hostnames.
+	Name       string
+	Containers []*Container
+
+	hostname string
+}
+
+// NewLoadBalancer creates a load balancer in front of the containers.
+func NewLoadBalancer(name string, containers []*Container) *LoadBalancer {
+	return &LoadBalancer{Name: name, Containers: containers}
+}
+
+// Hostname returns the hostname of the load balancer. Like container
+// hostnames, it's only final once the load balancer is deployed.
+func (lb *LoadBalancer) Hostname() string {
+	if lb.hostname == "" {
+		return lb.Name
+	}
+	return lb.hostname
+}
+
+func (lb *LoadBalancer) connectableName() string {
+	return lb.Hostname()
+}
+
+// Deploy adds the load balancer to the infrastructure. The containers behind
+// it must be deployed separately.
+func (lb *LoadBalancer) Deploy(infra *Infrastructure) {
+	if lb.hostname != "" {
+		return
+	}
+
+	lb.hostname = infra.hostnames.getName(lb.Name)
+	infra.loadBalancers = append(infra.loadBalancers, lb)
+}
+
+// A Volume is storage that can be mounted into containers. See the volume types
+// in the blueprint package for the Conf that each type accepts.
+type Volume struct {
+	// The requested name, which is made unique when the volume is deployed.
+	Name string
+	Type string
+	Conf map[string]string
+
+	name string
+}
+
+// NewHostPathVolume creates a volume of the directory at `path` on the
+// machine that the container runs on.
+func NewHostPathVolume(name, path string) *Volume {
+	return &Volume{
+		Name: name,
+		Type: "hostPath",
+		Conf: map[string]string{"path": path},
+	}
+}
+
+// NewEmptyDirVolume creates an empty scratch volume that lasts as long as the
+// pod of the container that mounts it.
+func NewEmptyDirVolume(name string) *Volume {
+	return &Volume{
+		Name: name,
+		Type: blueprint.EmptyDirVolume,
+		Conf: map[string]string{},
+	}
+}
+
+// NewNFSVolume creates a volume of the existing export at `path` on the NFS
+// server at `server`. Kelda doesn't create the server or the export.
+func NewNFSVolume(name, server, path string) *Volume {
+	return &Volume{
+		Name: name,
+		Type: blueprint.NFSVolume,
+		Conf: map[string]string{"server": server, "path": path},
+	}
+}
+
+// NewCloudVolume creates a volume of the given cloud volume type, backed by a
+// disk of `size` that's attached to whichever machine the container is placed
+// on. The disk's zone can be set with Conf["zone"]. Only
+// blueprint.GooglePersistentDiskVolume is supported.
+func NewCloudVolume(name, volumeType, size string) *Volume {
+	return &Volume{
+		Name: name,
+		Type: volumeType,
+		Conf: map[string]string{"size": size},
+	}
+}
+
+func (vol *Volume) deploy(infra *Infrastructure) {
+	if vol.name != "" {
+		return
+	}
+
+	vol.name = infra.volumeNames.getName(vol.Name)
+	infra.volumes = append(infra.volumes, vol)
+}
+
+// A VolumeMount mounts a volume into a container.
+type VolumeMount struct {
+	Volume    *Volume
+	MountPath string
+}
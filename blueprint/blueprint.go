@@ -51,11 +51,147 @@ type Container struct {
 	ID                string                    `json:",omitempty"`
 	Image             Image                     `json:",omitempty"`
 	Command           []string                  `json:",omitempty"`
+	Entrypoint        []string                  `json:",omitempty"`
+	WorkingDir        string                    `json:",omitempty"`
 	Env               map[string]ContainerValue `json:",omitempty"`
 	FilepathToContent map[string]ContainerValue `json:",omitempty"`
 	Hostname          string                    `json:",omitempty"`
 	Privileged        bool                      `json:",omitempty"`
+	SecurityContext   *SecurityContext          `json:",omitempty"`
 	VolumeMounts      []VolumeMount             `json:",omitempty"`
+	Resources         *Resources                `json:",omitempty"`
+	LivenessCheck     *HealthCheck              `json:",omitempty"`
+	ReadinessCheck    *HealthCheck              `json:",omitempty"`
+	UpdateStrategy    string                    `json:",omitempty"`
+	Sidecars          []PodContainer            `json:",omitempty"`
+	InitContainers    []PodContainer            `json:",omitempty"`
+	Job               *Job                      `json:",omitempty"`
+
+	// How the container is stopped. The PreStop command is run in the
+	// container before it's sent SIGTERM, and the container is killed if it
+	// hasn't exited TerminationGracePeriodSeconds after the PreStop command
+	// started. Zero uses the Kubernetes default of 30 seconds.
+	PreStop                       []string `json:",omitempty"`
+	TerminationGracePeriodSeconds int      `json:",omitempty"`
+}
+
+// A SecurityContext grants a container specific privileges, or takes them
+// away, so that containers that need a single capability don't have to run
+// fully privileged.
+type SecurityContext struct {
+	// Linux capabilities, such as NET_ADMIN, to add to or drop from the
+	// container runtime's defaults. "ALL" drops every capability.
+	CapAdd  []string `json:",omitempty"`
+	CapDrop []string `json:",omitempty"`
+
+	// The user ID to run the container as, rather than the image's user.
+	RunAsUser *int `json:",omitempty"`
+
+	ReadOnlyRootFilesystem bool `json:",omitempty"`
+
+	// Whether the container's processes are prevented from gaining more
+	// privileges than their parent, such as through setuid binaries.
+	NoNewPrivileges bool `json:",omitempty"`
+
+	// The seccomp and AppArmor profiles to run the container with. Each may be
+	// "runtime/default", or "localhost/<profile>" for a profile that's
+	// installed on the machines. Seccomp profiles may also be "unconfined", or
+	// "docker/default", which is the same as "runtime/default". Unset profiles
+	// use the defaults of the container runtime.
+	SeccompProfile  string `json:",omitempty"`
+	AppArmorProfile string `json:",omitempty"`
+}
+
+// A Job runs a container to completion, rather than continuously. If the
+// Schedule is set, the container is run each time the schedule fires, and
+// otherwise it's run once. A container's runs are one at a time, because each
+// container has a single IP.
+type Job struct {
+	// A cron schedule in UTC, such as "0 3 * * *" for 3am every day.
+	Schedule string `json:",omitempty"`
+
+	// The number of times the container must exit successfully for a run to
+	// complete. It defaults to 1.
+	Completions int `json:",omitempty"`
+
+	// The number of times the container is retried before the run fails. It
+	// defaults to the Kubernetes default of 6.
+	BackoffLimit *int `json:",omitempty"`
+
+	// How long, in seconds, a run may take before it's stopped and fails.
+	// Zero means there's no deadline.
+	ActiveDeadlineSeconds int `json:",omitempty"`
+}
+
+// A PodContainer runs alongside a Container, and shares its hostname, IP and
+// volumes. Sidecars run for as long as the container does, and are useful for
+// log shippers and proxies. Init containers run to completion, one at a time
+// and in order, before the container and its sidecars start, and are useful
+// for tasks such as schema migrations.
+type PodContainer struct {
+	// The name must be unique among the container's sidecars and init
+	// containers.
+	Name         string                    `json:",omitempty"`
+	Image        Image                     `json:",omitempty"`
+	Command      []string                  `json:",omitempty"`
+	Env          map[string]ContainerValue `json:",omitempty"`
+	VolumeMounts []VolumeMount             `json:",omitempty"`
+	Resources    *Resources                `json:",omitempty"`
+}
+
+// The strategies for replacing a container when it changes. The default,
+// RecreateUpdate, stops the old container before starting the new one.
+// RollingUpdate starts the new container alongside the old one, and only moves
+// the container's hostname and load balancer entries to it, and stops the old
+// container, once the new container is ready.
+const (
+	RecreateUpdate = "Recreate"
+	RollingUpdate  = "Rolling"
+)
+
+// Resources are the CPU and memory reserved for a container, and the most that
+// it may use. The amounts are Kubernetes quantities, e.g. "500m" CPUs or
+// "256Mi" of memory. Containers are only scheduled on machines with enough
+// unreserved resources for their requests, and are throttled or killed if they
+// exceed their limits.
+type Resources struct {
+	CPURequest    string `json:",omitempty"`
+	CPULimit      string `json:",omitempty"`
+	MemoryRequest string `json:",omitempty"`
+	MemoryLimit   string `json:",omitempty"`
+}
+
+// A HealthCheck periodically checks the health of a container by making an
+// HTTP GET request to it, opening a TCP connection to it, or running a command
+// in it. Exactly one of HTTPGet, TCPSocket and Exec must be set. A container
+// that fails its liveness check is restarted, and a container that fails its
+// readiness check doesn't receive traffic from load balancers.
+//
+// The timing fields are in seconds, and the thresholds are the number of
+// consecutive checks that must succeed or fail for the container to be
+// considered healthy or unhealthy. Unset fields use the Kubernetes defaults.
+type HealthCheck struct {
+	HTTPGet   *HTTPGetCheck   `json:",omitempty"`
+	TCPSocket *TCPSocketCheck `json:",omitempty"`
+	Exec      []string        `json:",omitempty"`
+
+	InitialDelaySeconds int `json:",omitempty"`
+	PeriodSeconds       int `json:",omitempty"`
+	TimeoutSeconds      int `json:",omitempty"`
+	SuccessThreshold    int `json:",omitempty"`
+	FailureThreshold    int `json:",omitempty"`
+}
+
+// An HTTPGetCheck succeeds if a GET request to Path on Port returns a status
+// code from 200 to 399.
+type HTTPGetCheck struct {
+	Path string `json:",omitempty"`
+	Port int    `json:",omitempty"`
+}
+
+// A TCPSocketCheck succeeds if a TCP connection can be opened to Port.
+type TCPSocketCheck struct {
+	Port int `json:",omitempty"`
 }
 
 // VolumeMount defines how a volume should be mounted into a container.
@@ -73,6 +209,34 @@ type Volume struct {
 	Conf map[string]string `json:",omitempty"`
 }
 
+// The types of volumes. A HostPathVolume mounts the directory at Conf["path"]
+// on the machine, and an EmptyDirVolume is scratch space that's deleted along
+// with the container. Its Conf["medium"] may be "Memory" to back it with
+// tmpfs, and Conf["sizeLimit"] limits its size.
+//
+// A GooglePersistentDiskVolume is a disk of Conf["size"], such as "10Gi", that
+// is created by Google. It's attached to whichever machine the container that
+// mounts it is placed on, so it persists when the container moves between
+// machines. The disk is created in Conf["zone"] if it's set, and containers are
+// only placed on machines in their disk's zone.
+//
+// An NFSVolume mounts the existing export at Conf["path"] on the NFS server at
+// Conf["server"]. Unlike the other types, it can be mounted read-write by
+// containers on different machines at once. Kelda doesn't run NFS servers, so
+// the export must already exist.
+const (
+	HostPathVolume             = "hostPath"
+	EmptyDirVolume             = "emptyDir"
+	GooglePersistentDiskVolume = "gcePersistentDisk"
+	NFSVolume                  = "nfs"
+)
+
+// IsCloudVolume returns whether the volume is a disk created by a cloud
+// provider.
+func (vol Volume) IsCloudVolume() bool {
+	return vol.Type == GooglePersistentDiskVolume
+}
+
 // ContainerValue is a wrapper for the possible values that can be used in
 // the container Env and FilepathToContent maps. The only permissible types
 // are Secret and string.
@@ -99,6 +263,41 @@ type Connection struct {
 	To      []string `json:",omitempty"`
 	MinPort int      `json:",omitempty"`
 	MaxPort int      `json:",omitempty"`
+
+	// The protocol allowed by the connection: tcp, udp or icmp. Connections
+	// without a protocol allow both TCP and UDP. ICMP connections don't have
+	// ports.
+	Protocol string `json:",omitempty"`
+
+	// For connections from the public internet to a single port, the port on
+	// the machine's public IP that is forwarded to MinPort. Defaults to
+	// MinPort.
+	PublicPort int `json:",omitempty"`
+}
+
+// PublicPorts returns the range of ports on the machine's public IP that the
+// connection forwards to [MinPort, MaxPort].
+func (c Connection) PublicPorts() (int, int) {
+	if c.PublicPort == 0 {
+		return c.MinPort, c.MaxPort
+	}
+	return c.PublicPort, c.PublicPort + c.MaxPort - c.MinPort
+}
+
+// The protocols that connections may be restricted to.
+const (
+	TCPProtocol  = "tcp"
+	UDPProtocol  = "udp"
+	ICMPProtocol = "icmp"
+)
+
+// Protocols returns the protocols allowed by a connection with the given
+// protocol. Connections without a protocol allow both TCP and UDP.
+func Protocols(protocol string) []string {
+	if protocol == "" {
+		return []string{TCPProtocol, UDPProtocol}
+	}
+	return []string{protocol}
 }
 
 // A ConnectionSlice allows for slices of Collections to be used in joins
@@ -128,8 +327,17 @@ func FromFile(filename string) (Blueprint, error) {
 }
 
 // FromFileWithArgs gets a Blueprint handle from a file on disk, passing the
-// given arguments to the node process.
+// given arguments to the node process. Declarative blueprints are loaded
+// without Node.js, and don't accept arguments.
 func FromFileWithArgs(filename string, cmdLineArgs []string) (Blueprint, error) {
+	if IsDeclarative(filename) {
+		if len(cmdLineArgs) != 0 {
+			return Blueprint{}, errors.New(
+				"declarative blueprints don't accept arguments")
+		}
+		return FromDeclarativeFile(filename)
+	}
+
 	nodeBinary, err := util.GetNodeBinary()
 	if err != nil {
 		return Blueprint{}, err
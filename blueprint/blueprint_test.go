@@ -75,3 +75,15 @@ func checkMarshalAndUnmarshal(t *testing.T, toMarshal ContainerValue) {
 	assert.NoError(t, json.Unmarshal(jsonBytes, &unmarshalled))
 	assert.Equal(t, toMarshal, unmarshalled)
 }
+
+func TestConnectionPublicPorts(t *testing.T) {
+	t.Parallel()
+
+	min, max := Connection{MinPort: 8000, MaxPort: 8010}.PublicPorts()
+	assert.Equal(t, 8000, min)
+	assert.Equal(t, 8010, max)
+
+	min, max = Connection{MinPort: 80, MaxPort: 80, PublicPort: 8080}.PublicPorts()
+	assert.Equal(t, 8080, min)
+	assert.Equal(t, 8080, max)
+}
@@ -0,0 +1,132 @@
+package blueprint
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/kelda/kelda/util"
+)
+
+// DeclarativeVersion is the current version of the declarative blueprint
+// format. Declarative blueprints are YAML or JSON files that map directly onto
+// a Blueprint, so they can be deployed without Node.js. Their fields are the
+// same as the Blueprint's, along with a required Version.
+const DeclarativeVersion = 1
+
+// declarativeBlueprint is the schema of declarative blueprint files.
+type declarativeBlueprint struct {
+	Version int
+	Blueprint
+}
+
+// IsDeclarative returns whether the file at the given path is a declarative
+// blueprint, rather than a JavaScript blueprint.
+func IsDeclarative(filename string) bool {
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// FromDeclarativeFile gets a Blueprint handle from a declarative blueprint
+// file on disk.
+func FromDeclarativeFile(filename string) (Blueprint, error) {
+	contents, err := util.ReadFile(filename)
+	if err != nil {
+		return Blueprint{}, err
+	}
+
+	bp, err := FromDeclarative([]byte(contents))
+	if err != nil {
+		return Blueprint{}, fmt.Errorf("%s: %s", filename, err)
+	}
+	return bp, nil
+}
+
+// FromDeclarative gets a Blueprint handle from a declarative blueprint in
+// either YAML or JSON. Fields that aren't part of the format are rejected so
+// that typos aren't silently ignored.
+func FromDeclarative(contents []byte) (Blueprint, error) {
+	// JSON is a subset of YAML, but YAML forbids the tabs that are often
+	// used to indent JSON, so JSON is parsed directly.
+	jsonBytes := contents
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) == 0 ||
+		trimmed[0] != '{' {
+		var err error
+		jsonBytes, err = yaml.YAMLToJSON(contents)
+		if err != nil {
+			return Blueprint{}, fmt.Errorf("unable to parse blueprint: %s",
+				err)
+		}
+	}
+
+	var decl declarativeBlueprint
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&decl); err != nil {
+		return Blueprint{}, fmt.Errorf("unable to parse blueprint: %s", err)
+	}
+
+	switch {
+	case decl.Version == 0:
+		return Blueprint{}, fmt.Errorf("blueprint is missing its Version, "+
+			"which should be %d", DeclarativeVersion)
+	case decl.Version > DeclarativeVersion:
+		return Blueprint{}, fmt.Errorf("unsupported blueprint version %d: "+
+			"this version of Kelda supports up to version %d", decl.Version,
+			DeclarativeVersion)
+	}
+
+	bp := decl.Blueprint
+	for i := range bp.Containers {
+		if bp.Containers[i].ID == "" {
+			bp.Containers[i].ID = ContainerID(bp.Containers[i])
+		}
+	}
+	return bp, nil
+}
+
+// ContainerID derives the ID of a container from its contents. Like with the
+// IDs generated by the JavaScript bindings, changing the container changes
+// its ID. Hostnames are unique, so the IDs of different containers can't
+// collide.
+func ContainerID(c Container) string {
+	c.ID = ""
+	contents, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return fmt.Sprintf("%x", sha1.Sum(contents))
+}
+
+// ToDeclarativeJSON returns the blueprint as a declarative JSON blueprint.
+func (bp Blueprint) ToDeclarativeJSON() ([]byte, error) {
+	contents, err := json.MarshalIndent(bp.toDeclarative(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(contents, '\n'), nil
+}
+
+// ToDeclarativeYAML returns the blueprint as a declarative YAML blueprint.
+func (bp Blueprint) ToDeclarativeYAML() ([]byte, error) {
+	return yaml.Marshal(bp.toDeclarative())
+}
+
+// toDeclarative strips the container IDs from the blueprint, because they're
+// derived when the declarative blueprint is loaded.
+func (bp Blueprint) toDeclarative() declarativeBlueprint {
+	var containers []Container
+	for _, c := range bp.Containers {
+		c.ID = ""
+		containers = append(containers, c)
+	}
+	bp.Containers = containers
+	return declarativeBlueprint{Version: DeclarativeVersion, Blueprint: bp}
+}
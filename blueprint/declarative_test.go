@@ -0,0 +1,149 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/util"
+)
+
+const declarativeYAML = `
+Version: 1
+Namespace: namespace
+Containers:
+- Hostname: web
+  Image:
+    Name: nginx
+  Env:
+    PASSWORD:
+      NameOfSecret: password
+    PORT: "80"
+LoadBalancers:
+- Name: lb
+  Hostnames: [web]
+Connections:
+- From: [public]
+  To: [lb]
+  MinPort: 80
+  MaxPort: 80
+`
+
+func TestFromDeclarative(t *testing.T) {
+	t.Parallel()
+
+	bp, err := FromDeclarative([]byte(declarativeYAML))
+	assert.NoError(t, err)
+
+	assert.Len(t, bp.Containers, 1)
+	assert.NotEmpty(t, bp.Containers[0].ID)
+	bp.Containers[0].ID = ""
+	assert.Equal(t, Blueprint{
+		Namespace: "namespace",
+		Containers: []Container{{
+			Hostname: "web",
+			Image:    Image{Name: "nginx"},
+			Env: map[string]ContainerValue{
+				"PASSWORD": NewSecret("password"),
+				"PORT":     NewString("80"),
+			},
+		}},
+		LoadBalancers: []LoadBalancer{{Name: "lb", Hostnames: []string{"web"}}},
+		Connections: []Connection{{From: []string{"public"},
+			To: []string{"lb"}, MinPort: 80, MaxPort: 80}},
+	}, bp)
+
+	// JSON blueprints may be indented with tabs, which YAML doesn't allow.
+	bp, err = FromDeclarative([]byte("{\n\t\"Version\": 1,\n\t" +
+		"\"Namespace\": \"namespace\"\n}"))
+	assert.NoError(t, err)
+	assert.Equal(t, Blueprint{Namespace: "namespace"}, bp)
+}
+
+func TestFromDeclarativeErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := FromDeclarative([]byte("Namespace: namespace"))
+	assert.EqualError(t, err, "blueprint is missing its Version, which "+
+		"should be 1")
+
+	_, err = FromDeclarative([]byte("Version: 2"))
+	assert.EqualError(t, err, "unsupported blueprint version 2: this "+
+		"version of Kelda supports up to version 1")
+
+	_, err = FromDeclarative([]byte("Version: 1\nContainer: []"))
+	assert.EqualError(t, err, "unable to parse blueprint: json: unknown "+
+		`field "Container"`)
+
+	_, err = FromDeclarative([]byte("Version: 1\nContainers: {"))
+	assert.Error(t, err)
+}
+
+func TestDeclarativeContainerIDs(t *testing.T) {
+	t.Parallel()
+
+	bp, err := FromDeclarative([]byte(`
+Version: 1
+Containers:
+- Hostname: a
+  Image: {Name: nginx}
+- Hostname: b
+  Image: {Name: nginx}
+- Hostname: c
+  ID: explicit
+  Image: {Name: nginx}
+`))
+	assert.NoError(t, err)
+
+	// IDs are stable across loads, and differ between containers.
+	again, err := FromDeclarative([]byte(`
+Version: 1
+Containers:
+- Hostname: a
+  Image: {Name: nginx}
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, bp.Containers[0].ID, again.Containers[0].ID)
+	assert.NotEqual(t, bp.Containers[0].ID, bp.Containers[1].ID)
+	assert.Equal(t, "explicit", bp.Containers[2].ID)
+}
+
+func TestDeclarativeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	for i := range bp.Containers {
+		bp.Containers[i].ID = ContainerID(bp.Containers[i])
+	}
+
+	yamlBytes, err := bp.ToDeclarativeYAML()
+	assert.NoError(t, err)
+	fromYAML, err := FromDeclarative(yamlBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, bp, fromYAML)
+
+	jsonBytes, err := bp.ToDeclarativeJSON()
+	assert.NoError(t, err)
+	fromJSON, err := FromDeclarative(jsonBytes)
+	assert.NoError(t, err)
+	assert.Equal(t, bp, fromJSON)
+}
+
+func TestFromFileDeclarative(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+	assert.NoError(t, util.WriteFile("/bp.yml", []byte(declarativeYAML), 0644))
+
+	bp, err := FromFile("/bp.yml")
+	assert.NoError(t, err)
+	assert.Equal(t, "namespace", bp.Namespace)
+
+	_, err = FromFileWithArgs("/bp.yml", []string{"arg"})
+	assert.EqualError(t, err, "declarative blueprints don't accept arguments")
+
+	assert.NoError(t, util.WriteFile("/bad.json", []byte(`{"Version": 3}`),
+		0644))
+	_, err = FromFile("/bad.json")
+	assert.EqualError(t, err, "/bad.json: unsupported blueprint version 3: "+
+		"this version of Kelda supports up to version 1")
+}
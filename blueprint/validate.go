@@ -0,0 +1,665 @@
+package blueprint
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// A ValidationError lists every problem found in a blueprint. Each problem
+// names the part of the blueprint at fault.
+type ValidationError []string
+
+func (err ValidationError) Error() string {
+	return "invalid blueprint: " + strings.Join(err, "; ")
+}
+
+// Hostnames are used as DNS names, so they must be valid DNS labels.
+var hostnameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// Capabilities are named in upper case, such as NET_ADMIN.
+var capabilityRegex = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+
+// The volume types supported by the minion.
+var volumeTypes = map[string]struct{}{
+	HostPathVolume:             {},
+	EmptyDirVolume:             {},
+	GooglePersistentDiskVolume: {},
+	NFSVolume:                  {},
+}
+
+// Cloud volume types that Kubernetes supports, but that the machines Kelda
+// boots can't provision yet, along with why.
+var unsupportedVolumeTypes = map[string]string{
+	"awsElasticBlockStore": "Amazon machines don't have an IAM role that " +
+		"allows them to create and attach disks",
+	"digitalOceanVolume": "DigitalOcean's CSI driver isn't deployed",
+}
+
+// The roles that machines may have.
+var machineRoles = map[string]struct{}{
+	"Master": {},
+	"Worker": {},
+}
+
+// Validate checks that the blueprint is internally consistent, and that the
+// minions will be able to deploy it. If the blueprint is invalid, a
+// ValidationError describing every problem is returned.
+func Validate(bp Blueprint) error {
+	v := validator{
+		containers:    map[string]struct{}{},
+		loadBalancers: map[string]struct{}{},
+		volumes:       map[string]Volume{},
+		volumeUsers:   map[string]string{},
+		dockerfiles:   map[string]string{},
+	}
+
+	if bp.Namespace != strings.ToLower(bp.Namespace) {
+		v.addf("namespace %q must be lowercase", bp.Namespace)
+	}
+
+	// Volumes and hostnames are collected first, so that references to them
+	// can be checked regardless of the order in which they're declared.
+	for _, vol := range bp.Volumes {
+		v.validateVolume(vol)
+	}
+	for _, c := range bp.Containers {
+		v.addHostname(fmt.Sprintf("container %q", c.Hostname), c.Hostname,
+			v.containers)
+	}
+	for _, lb := range bp.LoadBalancers {
+		v.addHostname(fmt.Sprintf("load balancer %q", lb.Name), lb.Name,
+			v.loadBalancers)
+	}
+
+	for _, c := range bp.Containers {
+		v.validateContainer(c)
+	}
+
+	for _, lb := range bp.LoadBalancers {
+		v.validateLoadBalancer(lb)
+	}
+
+	for _, conn := range bp.Connections {
+		v.validateConnection(conn)
+	}
+
+	for _, plcm := range bp.Placements {
+		if _, ok := v.containers[plcm.TargetContainer]; !ok {
+			v.addf("placement: target container %q does not exist",
+				plcm.TargetContainer)
+		}
+	}
+
+	for i, m := range bp.Machines {
+		v.validateMachine(i, m, bp.Machines[0])
+	}
+
+	if len(v.problems) != 0 {
+		return v.problems
+	}
+	return nil
+}
+
+// validator accumulates the problems found in a blueprint.
+type validator struct {
+	problems ValidationError
+
+	containers    map[string]struct{}
+	loadBalancers map[string]struct{}
+	volumes       map[string]Volume
+
+	// The container that mounts each cloud volume.
+	volumeUsers map[string]string
+
+	// The Dockerfile that each image is built from.
+	dockerfiles map[string]string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// addHostname records the hostname of a container or load balancer in
+// `hostnames`. Containers and load balancers share the same hostnames, so
+// each hostname may only be used once between them.
+func (v *validator) addHostname(desc, hostname string,
+	hostnames map[string]struct{}) {
+	_, isContainer := v.containers[hostname]
+	_, isLoadBalancer := v.loadBalancers[hostname]
+	switch {
+	case hostname == "":
+		v.addf("%s: hostname is required", desc)
+		return
+	case hostname == PublicInternetLabel:
+		v.addf("%s: hostname %q is reserved", desc, hostname)
+	case isContainer || isLoadBalancer:
+		v.addf("%s: hostname is used multiple times", desc)
+	case !hostnameRegex.MatchString(hostname):
+		v.addf("%s: hostname must consist of at most 63 lowercase "+
+			"letters, digits and '-', and start and end with a letter "+
+			"or digit", desc)
+	}
+	hostnames[hostname] = struct{}{}
+}
+
+func (v *validator) validateContainer(c Container) {
+	desc := fmt.Sprintf("container %q", c.Hostname)
+	v.validateImage(desc, c.Image)
+	v.validateVolumeMounts(desc, c.VolumeMounts)
+	for filepath := range c.FilepathToContent {
+		if !path.IsAbs(filepath) {
+			v.addf("container %q: file path %q must be absolute",
+				c.Hostname, filepath)
+		}
+	}
+
+	v.validateResources(desc, c.Resources)
+
+	if c.WorkingDir != "" && !path.IsAbs(c.WorkingDir) {
+		v.addf("%s: working directory %q must be absolute", desc,
+			c.WorkingDir)
+	}
+	if c.TerminationGracePeriodSeconds < 0 {
+		v.addf("%s: termination grace period must not be negative", desc)
+	}
+
+	if c.SecurityContext != nil {
+		v.validateSecurityContext(desc, c.Privileged, *c.SecurityContext)
+	}
+
+	if c.LivenessCheck != nil {
+		v.validateHealthCheck(c.Hostname, "liveness", *c.LivenessCheck)
+
+		// Kubernetes restarts the container after its first failed check
+		// unless the check succeeded once before.
+		if c.LivenessCheck.SuccessThreshold > 1 {
+			v.addf("container %q: liveness check success threshold must "+
+				"be 1", c.Hostname)
+		}
+	}
+	if c.ReadinessCheck != nil {
+		v.validateHealthCheck(c.Hostname, "readiness", *c.ReadinessCheck)
+	}
+
+	switch c.UpdateStrategy {
+	case "", RecreateUpdate, RollingUpdate:
+	default:
+		v.addf("container %q: unknown update strategy %q: must be %s or %s",
+			c.Hostname, c.UpdateStrategy, RecreateUpdate, RollingUpdate)
+	}
+
+	if c.Job != nil {
+		v.validateJob(c)
+	}
+
+	// The container itself is named after its hostname within its pod.
+	names := map[string]struct{}{c.Hostname: {}}
+	validatePodContainers := func(kind string, podContainers []PodContainer) {
+		for _, pc := range podContainers {
+			pcDesc := fmt.Sprintf("%s: %s %q", desc, kind, pc.Name)
+			switch _, ok := names[pc.Name]; {
+			case !hostnameRegex.MatchString(pc.Name):
+				v.addf("%s: name must consist of at most 63 "+
+					"lowercase letters, digits and '-', and start "+
+					"and end with a letter or digit", pcDesc)
+			case ok:
+				v.addf("%s: name is used multiple times", pcDesc)
+			}
+			names[pc.Name] = struct{}{}
+
+			v.validateImage(pcDesc, pc.Image)
+			v.validateVolumeMounts(pcDesc, pc.VolumeMounts)
+			v.validateResources(pcDesc, pc.Resources)
+		}
+	}
+	validatePodContainers("sidecar", c.Sidecars)
+	validatePodContainers("init container", c.InitContainers)
+
+	v.validateCloudVolumeMounts(c)
+}
+
+// validateCloudVolumeMounts checks that the cloud volumes mounted by the
+// container aren't mounted by any other container. Cloud disks can only be
+// attached to one machine at a time, so for the same reason, the container
+// can't be started alongside its replacement by a rolling update.
+func (v *validator) validateCloudVolumeMounts(c Container) {
+	mounts := c.VolumeMounts
+	for _, pc := range append(c.InitContainers, c.Sidecars...) {
+		mounts = append(mounts, pc.VolumeMounts...)
+	}
+
+	// The container and its sidecars may mount the same volume.
+	mounted := map[string]struct{}{}
+	for _, mount := range mounts {
+		vol, ok := v.volumes[mount.VolumeName]
+		if _, seen := mounted[vol.Name]; !ok || seen || !vol.IsCloudVolume() {
+			continue
+		}
+		mounted[vol.Name] = struct{}{}
+
+		if user, ok := v.volumeUsers[vol.Name]; ok {
+			v.addf("container %q: volume %q is already mounted by "+
+				"container %q, and %s volumes can only be mounted by "+
+				"one container", c.Hostname, vol.Name, user, vol.Type)
+			continue
+		}
+		v.volumeUsers[vol.Name] = c.Hostname
+	}
+
+	if len(mounted) != 0 && c.UpdateStrategy == RollingUpdate {
+		v.addf("container %q: containers that mount cloud volumes can't "+
+			"use the %s update strategy", c.Hostname, RollingUpdate)
+	}
+}
+
+// validateJob checks the job settings of the container, and that jobs don't
+// use rolling updates, which rely on the new container becoming ready while
+// the old one is still running.
+func (v *validator) validateJob(c Container) {
+	job := *c.Job
+	if job.Schedule != "" && !validSchedule(job.Schedule) {
+		v.addf("container %q: invalid job schedule %q: must have five "+
+			"fields, or be a macro such as @daily", c.Hostname, job.Schedule)
+	}
+
+	if job.Completions < 0 {
+		v.addf("container %q: job completions must not be negative",
+			c.Hostname)
+	}
+
+	if job.BackoffLimit != nil && *job.BackoffLimit < 0 {
+		v.addf("container %q: job backoff limit must not be negative",
+			c.Hostname)
+	}
+
+	if job.ActiveDeadlineSeconds < 0 {
+		v.addf("container %q: job active deadline must not be negative",
+			c.Hostname)
+	}
+
+	if c.UpdateStrategy == RollingUpdate {
+		v.addf("container %q: jobs can't use the %s update strategy",
+			c.Hostname, RollingUpdate)
+	}
+}
+
+// validSchedule returns whether the cron schedule has five fields, or is one
+// of the macros accepted by Kubernetes. The fields themselves are checked by
+// Kubernetes.
+func validSchedule(schedule string) bool {
+	if strings.HasPrefix(schedule, "@") {
+		_, ok := scheduleMacros[schedule]
+		return ok
+	}
+	return len(strings.Fields(schedule)) == 5
+}
+
+var scheduleMacros = map[string]struct{}{
+	"@yearly": {}, "@annually": {}, "@monthly": {}, "@weekly": {},
+	"@daily": {}, "@midnight": {}, "@hourly": {},
+}
+
+func (v *validator) validateImage(desc string, image Image) {
+	if image.Name == "" {
+		v.addf("%s: image is required", desc)
+		return
+	} else if _, err := reference.ParseAnyReference(image.Name); err != nil {
+		v.addf("%s: could not parse image %s: %s", desc, image.Name, err)
+	}
+
+	dockerfile, ok := v.dockerfiles[image.Name]
+	if ok && dockerfile != image.Dockerfile {
+		v.addf("%s: image %q is built from differing Dockerfiles", desc,
+			image.Name)
+	}
+	v.dockerfiles[image.Name] = image.Dockerfile
+}
+
+func (v *validator) validateVolumeMounts(desc string, mounts []VolumeMount) {
+	mountPaths := map[string]struct{}{}
+	for _, mount := range mounts {
+		if _, ok := v.volumes[mount.VolumeName]; !ok {
+			v.addf("%s: volume mount references unknown volume %q", desc,
+				mount.VolumeName)
+		}
+
+		if !path.IsAbs(mount.MountPath) {
+			v.addf("%s: mount path %q of volume %q must be absolute", desc,
+				mount.MountPath, mount.VolumeName)
+		}
+
+		if _, ok := mountPaths[mount.MountPath]; ok {
+			v.addf("%s: multiple volumes are mounted at %q", desc,
+				mount.MountPath)
+		}
+		mountPaths[mount.MountPath] = struct{}{}
+	}
+}
+
+func (v *validator) validateResources(desc string, res *Resources) {
+	if res != nil {
+		v.validateResource(desc, "CPU", res.CPURequest, res.CPULimit)
+		v.validateResource(desc, "memory", res.MemoryRequest, res.MemoryLimit)
+	}
+}
+
+// validateSecurityContext checks that the capabilities are named the way
+// Kubernetes expects, and that the profiles are ones the kubelet understands.
+func (v *validator) validateSecurityContext(desc string, privileged bool,
+	sc SecurityContext) {
+	for _, capabilities := range [][]string{sc.CapAdd, sc.CapDrop} {
+		for _, capability := range capabilities {
+			switch {
+			case strings.HasPrefix(capability, "CAP_"):
+				v.addf("%s: capability %q must not have the CAP_ prefix",
+					desc, capability)
+			case !capabilityRegex.MatchString(capability):
+				v.addf("%s: invalid capability %q", desc, capability)
+			}
+		}
+	}
+
+	if sc.RunAsUser != nil && *sc.RunAsUser < 0 {
+		v.addf("%s: user ID must not be negative", desc)
+	}
+
+	// Privileged containers can always gain privileges.
+	if privileged && sc.NoNewPrivileges {
+		v.addf("%s: privileged containers can't use no new privileges", desc)
+	}
+
+	switch profile := sc.SeccompProfile; {
+	case profile == "", profile == "runtime/default", profile == "docker/default",
+		profile == "unconfined", isLocalProfile(profile):
+	default:
+		v.addf("%s: invalid seccomp profile %q: must be runtime/default, "+
+			"docker/default, unconfined, or localhost/<profile>",
+			desc, profile)
+	}
+
+	// Unlike seccomp, the kubelet doesn't accept unconfined AppArmor profiles.
+	switch profile := sc.AppArmorProfile; {
+	case profile == "", profile == "runtime/default", isLocalProfile(profile):
+	default:
+		v.addf("%s: invalid AppArmor profile %q: must be runtime/default "+
+			"or localhost/<profile>", desc, profile)
+	}
+}
+
+func isLocalProfile(profile string) bool {
+	return strings.HasPrefix(profile, "localhost/") &&
+		len(profile) > len("localhost/")
+}
+
+// validateHealthCheck checks that the health check has exactly one action,
+// and that its ports and timings are valid.
+func (v *validator) validateHealthCheck(hostname, kind string, check HealthCheck) {
+	var actions int
+	if check.HTTPGet != nil {
+		actions++
+		v.validateHealthCheckPort(hostname, kind, check.HTTPGet.Port)
+		path := check.HTTPGet.Path
+		if path != "" && !strings.HasPrefix(path, "/") {
+			v.addf("container %q: %s check path %q must start with '/'",
+				hostname, kind, path)
+		}
+	}
+	if check.TCPSocket != nil {
+		actions++
+		v.validateHealthCheckPort(hostname, kind, check.TCPSocket.Port)
+	}
+	if len(check.Exec) != 0 {
+		actions++
+	}
+	if actions != 1 {
+		v.addf("container %q: %s check must have exactly one of HTTPGet, "+
+			"TCPSocket and Exec", hostname, kind)
+	}
+
+	timings := []struct {
+		name string
+		val  int
+	}{
+		{"initial delay", check.InitialDelaySeconds},
+		{"period", check.PeriodSeconds},
+		{"timeout", check.TimeoutSeconds},
+		{"success threshold", check.SuccessThreshold},
+		{"failure threshold", check.FailureThreshold},
+	}
+	for _, timing := range timings {
+		if timing.val < 0 {
+			v.addf("container %q: %s check %s must not be negative",
+				hostname, kind, timing.name)
+		}
+	}
+}
+
+func (v *validator) validateHealthCheckPort(hostname, kind string, port int) {
+	if port < 1 || port > 65535 {
+		v.addf("container %q: %s check port %d must be between 1 and 65535",
+			hostname, kind, port)
+	}
+}
+
+// validateResource checks the request and limit of one of a container's
+// resources. Either may be unset.
+func (v *validator) validateResource(desc, name, request, limit string) {
+	parse := func(kind, quantity string) (resource.Quantity, bool) {
+		if quantity == "" {
+			return resource.Quantity{}, false
+		}
+
+		q, err := resource.ParseQuantity(quantity)
+		if err != nil {
+			v.addf("%s: invalid %s %s %q: must be a quantity such as "+
+				"500m or 256Mi", desc, name, kind, quantity)
+			return resource.Quantity{}, false
+		}
+
+		if q.Sign() < 0 {
+			v.addf("%s: %s %s must not be negative", desc, name, kind)
+			return resource.Quantity{}, false
+		}
+		return q, true
+	}
+
+	requestQ, hasRequest := parse("request", request)
+	limitQ, hasLimit := parse("limit", limit)
+	if hasRequest && hasLimit && requestQ.Cmp(limitQ) > 0 {
+		v.addf("%s: %s request %s is greater than its limit %s", desc,
+			name, request, limit)
+	}
+}
+
+func (v *validator) validateLoadBalancer(lb LoadBalancer) {
+	for _, hostname := range lb.Hostnames {
+		if _, ok := v.containers[hostname]; !ok {
+			v.addf("load balancer %q: container %q does not exist",
+				lb.Name, hostname)
+		}
+	}
+}
+
+func (v *validator) validateVolume(vol Volume) {
+	if vol.Name == "" {
+		v.addf("volume: name is required")
+		return
+	}
+
+	if _, ok := v.volumes[vol.Name]; ok {
+		v.addf("volume %q: name is used multiple times", vol.Name)
+	}
+	v.volumes[vol.Name] = vol
+
+	if reason, ok := unsupportedVolumeTypes[vol.Type]; ok {
+		v.addf("volume %q: %s volumes aren't supported yet: %s", vol.Name,
+			vol.Type, reason)
+		return
+	}
+
+	if _, ok := volumeTypes[vol.Type]; !ok {
+		v.addf("volume %q: unsupported type %q", vol.Name, vol.Type)
+		return
+	}
+
+	desc := fmt.Sprintf("volume %q", vol.Name)
+	switch {
+	case vol.Type == HostPathVolume && !path.IsAbs(vol.Conf["path"]):
+		v.addf("%s: hostPath volumes require an absolute path", desc)
+	case vol.Type == EmptyDirVolume:
+		if medium := vol.Conf["medium"]; medium != "" && medium != "Memory" {
+			v.addf("%s: unknown medium %q: must be Memory or unset", desc,
+				medium)
+		}
+		if sizeLimit, ok := vol.Conf["sizeLimit"]; ok {
+			v.validateQuantity(desc, "size limit", sizeLimit)
+		}
+	case vol.Type == NFSVolume:
+		if vol.Conf["server"] == "" {
+			v.addf("%s: nfs volumes require a server", desc)
+		}
+		if !path.IsAbs(vol.Conf["path"]) {
+			v.addf("%s: nfs volumes require an absolute path", desc)
+		}
+	case vol.IsCloudVolume():
+		v.validateCloudVolume(desc, vol)
+	}
+}
+
+// validateCloudVolume checks the configuration of a disk created by a cloud
+// provider. The disk is named after the volume.
+func (v *validator) validateCloudVolume(desc string, vol Volume) {
+	if !hostnameRegex.MatchString(vol.Name) {
+		v.addf("%s: the names of %s volumes must consist of at most 63 "+
+			"lowercase letters, digits and '-', and start and end with a "+
+			"letter or digit", desc, vol.Type)
+	}
+
+	if size, ok := vol.Conf["size"]; ok {
+		v.validateQuantity(desc, "size", size)
+	} else {
+		v.addf("%s: %s volumes require a size", desc, vol.Type)
+	}
+}
+
+// validateQuantity checks that the quantity is a positive amount in the
+// Kubernetes quantity format.
+func (v *validator) validateQuantity(desc, name, quantity string) {
+	q, err := resource.ParseQuantity(quantity)
+	switch {
+	case err != nil:
+		v.addf("%s: invalid %s %q: must be a quantity such as 10Gi", desc,
+			name, quantity)
+	case q.Sign() <= 0:
+		v.addf("%s: %s must be positive", desc, name)
+	}
+}
+
+func (v *validator) validateConnection(conn Connection) {
+	desc := fmt.Sprintf("connection from %s to %s",
+		strings.Join(conn.From, ","), strings.Join(conn.To, ","))
+	switch conn.Protocol {
+	case "":
+		desc += fmt.Sprintf(" on ports %d-%d", conn.MinPort, conn.MaxPort)
+	case ICMPProtocol:
+		desc += " over icmp"
+	default:
+		desc += fmt.Sprintf(" on %s ports %d-%d", conn.Protocol,
+			conn.MinPort, conn.MaxPort)
+	}
+
+	if len(conn.From) == 0 || len(conn.To) == 0 {
+		v.addf("%s: both ends of the connection are required", desc)
+	}
+
+	fromPublic := false
+	for _, hostname := range conn.From {
+		if hostname == PublicInternetLabel {
+			if conn.Protocol == ICMPProtocol {
+				v.addf("%s: icmp connections from the public internet "+
+					"aren't supported", desc)
+			}
+			fromPublic = true
+			continue
+		}
+
+		if _, ok := v.loadBalancers[hostname]; ok {
+			v.addf("%s: load balancer %q can't make outgoing connections",
+				desc, hostname)
+		} else if _, ok := v.containers[hostname]; !ok {
+			v.addf("%s: unknown hostname %q", desc, hostname)
+		}
+	}
+
+	for _, hostname := range conn.To {
+		if hostname == PublicInternetLabel {
+			continue
+		}
+
+		_, isContainer := v.containers[hostname]
+		_, isLoadBalancer := v.loadBalancers[hostname]
+		if !isContainer && !isLoadBalancer {
+			v.addf("%s: unknown hostname %q", desc, hostname)
+		}
+	}
+
+	switch conn.Protocol {
+	case "", TCPProtocol, UDPProtocol:
+	case ICMPProtocol:
+		if conn.MinPort != 0 || conn.MaxPort != 0 || conn.PublicPort != 0 {
+			v.addf("%s: icmp connections don't have ports", desc)
+		}
+		return
+	default:
+		v.addf("%s: unknown protocol %q: must be tcp, udp or icmp", desc,
+			conn.Protocol)
+		return
+	}
+
+	switch {
+	case conn.MinPort < 1 || conn.MaxPort > 65535:
+		v.addf("%s: ports must be between 1 and 65535", desc)
+	case conn.MinPort > conn.MaxPort:
+		v.addf("%s: the minimum port is greater than the maximum port", desc)
+	}
+
+	if conn.PublicPort == 0 {
+		return
+	}
+
+	switch {
+	case !fromPublic:
+		v.addf("%s: only connections from the public internet can have a "+
+			"public port", desc)
+	case conn.PublicPort < 1 || conn.PublicPort > 65535:
+		v.addf("%s: the public port must be between 1 and 65535", desc)
+	case conn.MinPort != conn.MaxPort:
+		// iptables can't shift a range of ports to a different range.
+		v.addf("%s: port ranges can't be forwarded from a different "+
+			"public port", desc)
+	}
+}
+
+// validateMachine checks the i'th machine in the blueprint. All machines must
+// have the same provider and region as `first`.
+func (v *validator) validateMachine(i int, m, first Machine) {
+	desc := fmt.Sprintf("machine %d", i)
+	if _, ok := machineRoles[m.Role]; !ok {
+		v.addf("%s: role must be Master or Worker, not %q", desc, m.Role)
+	}
+
+	if m.Provider != first.Provider || m.Region != first.Region {
+		v.addf("%s: all machines must have the same provider and region, "+
+			"but it is in %s %s rather than %s %s", desc, m.Provider,
+			m.Region, first.Provider, first.Region)
+	}
+
+	if m.DiskSize < 0 {
+		v.addf("%s: disk size must not be negative", desc)
+	}
+}
@@ -0,0 +1,504 @@
+package blueprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func validBlueprint() Blueprint {
+	return Blueprint{
+		Namespace: "namespace",
+		Containers: []Container{
+			{
+				Hostname: "web",
+				Image:    Image{Name: "nginx"},
+				VolumeMounts: []VolumeMount{
+					{VolumeName: "data", MountPath: "/data"},
+				},
+				FilepathToContent: map[string]ContainerValue{
+					"/etc/conf": NewString("conf"),
+				},
+			},
+			{
+				Hostname: "db",
+				Image: Image{Name: "postgres",
+					Dockerfile: "FROM postgres"},
+			},
+		},
+		LoadBalancers: []LoadBalancer{{Name: "lb", Hostnames: []string{"web"}}},
+		Connections: []Connection{
+			{From: []string{"public"}, To: []string{"lb"}, MinPort: 80,
+				MaxPort: 80},
+			{From: []string{"web"}, To: []string{"db"}, MinPort: 5432,
+				MaxPort: 5433},
+		},
+		Placements: []Placement{{TargetContainer: "db", Exclusive: true}},
+		Volumes: []Volume{{Name: "data", Type: "hostPath",
+			Conf: map[string]string{"path": "/var/data"}}},
+		Machines: []Machine{
+			{Provider: "Amazon", Region: "us-west-1", Role: "Master"},
+			{Provider: "Amazon", Region: "us-west-1", Role: "Worker"},
+		},
+	}
+}
+
+func TestValidateValid(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, Validate(validBlueprint()))
+	assert.NoError(t, Validate(Blueprint{}))
+}
+
+func TestValidateContainers(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Namespace = "Namespace"
+	bp.Containers = append(bp.Containers,
+		Container{Image: Image{Name: "nginx"}},
+		Container{Hostname: "web", Image: Image{Name: "nginx"}},
+		Container{Hostname: "public", Image: Image{Name: "nginx"}},
+		Container{Hostname: "Bad_Name", Image: Image{Name: "nginx"}},
+		Container{Hostname: "noimage"},
+		Container{Hostname: "badimage", Image: Image{Name: "Capital"}},
+		Container{Hostname: "rebuilt",
+			Image: Image{Name: "postgres", Dockerfile: "FROM ubuntu"}},
+		Container{
+			Hostname: "mounts",
+			Image:    Image{Name: "nginx"},
+			VolumeMounts: []VolumeMount{
+				{VolumeName: "missing", MountPath: "/missing"},
+				{VolumeName: "data", MountPath: "relative"},
+				{VolumeName: "data", MountPath: "/missing"},
+			},
+			FilepathToContent: map[string]ContainerValue{
+				"conf": NewString("conf"),
+			},
+		})
+
+	assert.Equal(t, ValidationError{
+		`namespace "Namespace" must be lowercase`,
+		`container "": hostname is required`,
+		`container "web": hostname is used multiple times`,
+		`container "public": hostname "public" is reserved`,
+		`container "Bad_Name": hostname must consist of at most 63 ` +
+			`lowercase letters, digits and '-', and start and end with a ` +
+			`letter or digit`,
+		`container "noimage": image is required`,
+		`container "badimage": could not parse image Capital: invalid ` +
+			`reference format: repository name must be lowercase`,
+		`container "rebuilt": image "postgres" is built from differing ` +
+			`Dockerfiles`,
+		`container "mounts": volume mount references unknown volume "missing"`,
+		`container "mounts": mount path "relative" of volume "data" must be ` +
+			`absolute`,
+		`container "mounts": multiple volumes are mounted at "/missing"`,
+		`container "mounts": file path "conf" must be absolute`,
+	}, Validate(bp))
+}
+
+func TestValidateResources(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Containers[0].Resources = &Resources{CPURequest: "500m", CPULimit: "1",
+		MemoryRequest: "256Mi", MemoryLimit: "256Mi"}
+	bp.Containers[1].Resources = &Resources{MemoryLimit: "1Gi"}
+	assert.NoError(t, Validate(bp))
+
+	bp.Containers[0].Resources = &Resources{CPURequest: "2", CPULimit: "1500m",
+		MemoryRequest: "lots", MemoryLimit: "-1Gi"}
+	assert.Equal(t, ValidationError{
+		`container "web": CPU request 2 is greater than its limit 1500m`,
+		`container "web": invalid memory request "lots": must be a quantity ` +
+			`such as 500m or 256Mi`,
+		`container "web": memory limit must not be negative`,
+	}, Validate(bp))
+}
+
+func TestValidateStop(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Containers[1].Entrypoint = []string{"docker-entrypoint.sh"}
+	bp.Containers[1].WorkingDir = "/var/lib/postgresql"
+	bp.Containers[1].PreStop = []string{"pg_ctl", "stop", "-m", "fast"}
+	bp.Containers[1].TerminationGracePeriodSeconds = 120
+	assert.NoError(t, Validate(bp))
+
+	bp.Containers[1].WorkingDir = "data"
+	bp.Containers[1].TerminationGracePeriodSeconds = -1
+	assert.Equal(t, ValidationError{
+		`container "db": working directory "data" must be absolute`,
+		`container "db": termination grace period must not be negative`,
+	}, Validate(bp))
+}
+
+func TestValidateSecurityContext(t *testing.T) {
+	t.Parallel()
+
+	user := 1000
+	bp := validBlueprint()
+	bp.Containers[0].SecurityContext = &SecurityContext{
+		CapAdd:                 []string{"NET_ADMIN"},
+		CapDrop:                []string{"ALL"},
+		RunAsUser:              &user,
+		ReadOnlyRootFilesystem: true,
+		NoNewPrivileges:        true,
+		SeccompProfile:         "runtime/default",
+		AppArmorProfile:        "localhost/kelda-web",
+	}
+	assert.NoError(t, Validate(bp))
+
+	user = -1
+	bp.Containers[0].Privileged = true
+	bp.Containers[0].SecurityContext.CapAdd = []string{"CAP_NET_ADMIN",
+		"net_raw"}
+	bp.Containers[0].SecurityContext.SeccompProfile = "default"
+	bp.Containers[0].SecurityContext.AppArmorProfile = "localhost/"
+	assert.Equal(t, ValidationError{
+		`container "web": capability "CAP_NET_ADMIN" must not have the ` +
+			`CAP_ prefix`,
+		`container "web": invalid capability "net_raw"`,
+		`container "web": user ID must not be negative`,
+		`container "web": privileged containers can't use no new privileges`,
+		`container "web": invalid seccomp profile "default": must be ` +
+			`runtime/default, docker/default, unconfined, or ` +
+			`localhost/<profile>`,
+		`container "web": invalid AppArmor profile "localhost/": must be ` +
+			`runtime/default or localhost/<profile>`,
+	}, Validate(bp))
+
+	bp.Containers[0].Privileged = false
+	bp.Containers[0].SecurityContext = &SecurityContext{
+		SeccompProfile:  "unconfined",
+		AppArmorProfile: "unconfined",
+	}
+	assert.Equal(t, ValidationError{
+		`container "web": invalid AppArmor profile "unconfined": must be ` +
+			`runtime/default or localhost/<profile>`,
+	}, Validate(bp))
+}
+
+func TestValidateHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Containers[0].LivenessCheck = &HealthCheck{
+		HTTPGet:          &HTTPGetCheck{Path: "/healthz", Port: 80},
+		PeriodSeconds:    5,
+		FailureThreshold: 3,
+	}
+	bp.Containers[0].ReadinessCheck = &HealthCheck{
+		TCPSocket:        &TCPSocketCheck{Port: 80},
+		SuccessThreshold: 2,
+	}
+	bp.Containers[1].LivenessCheck = &HealthCheck{
+		Exec: []string{"pg_isready"},
+	}
+	assert.NoError(t, Validate(bp))
+
+	bp.Containers[0].LivenessCheck = &HealthCheck{
+		HTTPGet:          &HTTPGetCheck{Path: "healthz", Port: 0},
+		SuccessThreshold: 2,
+	}
+	bp.Containers[0].ReadinessCheck = &HealthCheck{
+		TCPSocket:     &TCPSocketCheck{Port: 80},
+		Exec:          []string{"true"},
+		PeriodSeconds: -1,
+	}
+	bp.Containers[1].LivenessCheck = &HealthCheck{}
+	assert.Equal(t, ValidationError{
+		`container "web": liveness check port 0 must be between 1 and 65535`,
+		`container "web": liveness check path "healthz" must start with '/'`,
+		`container "web": liveness check success threshold must be 1`,
+		`container "web": readiness check must have exactly one of ` +
+			`HTTPGet, TCPSocket and Exec`,
+		`container "web": readiness check period must not be negative`,
+		`container "db": liveness check must have exactly one of HTTPGet, ` +
+			`TCPSocket and Exec`,
+	}, Validate(bp))
+}
+
+func TestValidateUpdateStrategy(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Containers[0].UpdateStrategy = RollingUpdate
+	bp.Containers[1].UpdateStrategy = RecreateUpdate
+	assert.NoError(t, Validate(bp))
+
+	bp.Containers[0].UpdateStrategy = "BlueGreen"
+	assert.Equal(t, ValidationError{
+		`container "web": unknown update strategy "BlueGreen": must be ` +
+			"Recreate or Rolling",
+	}, Validate(bp))
+}
+
+func TestValidateJobs(t *testing.T) {
+	t.Parallel()
+
+	backoffLimit := 0
+	bp := validBlueprint()
+	bp.Containers[0].Job = &Job{Schedule: "0 3 * * *", Completions: 2,
+		BackoffLimit: &backoffLimit, ActiveDeadlineSeconds: 60}
+	bp.Containers[1].Job = &Job{Schedule: "@daily"}
+	assert.NoError(t, Validate(bp))
+
+	backoffLimit = -1
+	bp.Containers[0].Job = &Job{Schedule: "0 3 * *", Completions: -1,
+		BackoffLimit: &backoffLimit, ActiveDeadlineSeconds: -1}
+	bp.Containers[1].Job = &Job{Schedule: "@sometimes"}
+	bp.Containers[1].UpdateStrategy = RollingUpdate
+	assert.Equal(t, ValidationError{
+		`container "web": invalid job schedule "0 3 * *": must have five ` +
+			`fields, or be a macro such as @daily`,
+		`container "web": job completions must not be negative`,
+		`container "web": job backoff limit must not be negative`,
+		`container "web": job active deadline must not be negative`,
+		`container "db": invalid job schedule "@sometimes": must have five ` +
+			`fields, or be a macro such as @daily`,
+		`container "db": jobs can't use the Rolling update strategy`,
+	}, Validate(bp))
+}
+
+func TestValidatePodContainers(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Containers[0].Sidecars = []PodContainer{{
+		Name:         "log-shipper",
+		Image:        Image{Name: "fluentd"},
+		VolumeMounts: []VolumeMount{{VolumeName: "data", MountPath: "/data"}},
+	}}
+	bp.Containers[0].InitContainers = []PodContainer{{
+		Name:      "migrate",
+		Image:     Image{Name: "postgres", Dockerfile: "FROM postgres"},
+		Command:   []string{"migrate"},
+		Resources: &Resources{CPULimit: "1"},
+	}}
+	assert.NoError(t, Validate(bp))
+
+	bp.Containers[0].Sidecars = []PodContainer{
+		{Name: "web", Image: Image{Name: "nginx"}},
+		{Name: "Proxy", Image: Image{Name: "envoy"},
+			VolumeMounts: []VolumeMount{
+				{VolumeName: "missing", MountPath: "/data"}}},
+	}
+	bp.Containers[0].InitContainers = []PodContainer{
+		{Name: "migrate", Image: Image{Name: "postgres"},
+			Resources: &Resources{CPURequest: "2", CPULimit: "1"}},
+		{Name: "migrate"},
+	}
+	assert.Equal(t, ValidationError{
+		`container "web": sidecar "web": name is used multiple times`,
+		`container "web": sidecar "Proxy": name must consist of at most 63 ` +
+			`lowercase letters, digits and '-', and start and end with a ` +
+			`letter or digit`,
+		`container "web": sidecar "Proxy": volume mount references unknown ` +
+			`volume "missing"`,
+		`container "web": init container "migrate": CPU request 2 is ` +
+			`greater than its limit 1`,
+		`container "web": init container "migrate": name is used multiple ` +
+			`times`,
+		`container "web": init container "migrate": image is required`,
+		`container "db": image "postgres" is built from differing Dockerfiles`,
+	}, Validate(bp))
+}
+
+func TestValidateReferences(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.LoadBalancers = append(bp.LoadBalancers,
+		LoadBalancer{Name: "db"},
+		LoadBalancer{Name: "lb2", Hostnames: []string{"missing", "lb"}})
+	bp.Placements = append(bp.Placements, Placement{TargetContainer: "missing"})
+	bp.Volumes = append(bp.Volumes,
+		Volume{Type: "hostPath"},
+		Volume{Name: "data", Type: "hostPath",
+			Conf: map[string]string{"path": "/var/data"}},
+		Volume{Name: "unknown", Type: "unknown"},
+		Volume{Name: "nopath", Type: "hostPath"})
+
+	assert.Equal(t, ValidationError{
+		`volume: name is required`,
+		`volume "data": name is used multiple times`,
+		`volume "unknown": unsupported type "unknown"`,
+		`volume "nopath": hostPath volumes require an absolute path`,
+		`load balancer "db": hostname is used multiple times`,
+		`load balancer "lb2": container "missing" does not exist`,
+		`load balancer "lb2": container "lb" does not exist`,
+		`placement: target container "missing" does not exist`,
+	}, Validate(bp))
+}
+
+func TestValidateVolumes(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Volumes = append(bp.Volumes,
+		Volume{Name: "scratch", Type: EmptyDirVolume,
+			Conf: map[string]string{"medium": "Memory", "sizeLimit": "1Gi"}},
+		Volume{Name: "pgdata", Type: GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "10Gi", "zone": "us-west1-a"}},
+		Volume{Name: "disk", Type: GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "100G"}},
+		Volume{Name: "Upper_Case", Type: GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "10Gi"}},
+		Volume{Name: "nosize", Type: GooglePersistentDiskVolume},
+		Volume{Name: "badsize", Type: GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "lots"}},
+		Volume{Name: "badmedium", Type: EmptyDirVolume,
+			Conf: map[string]string{"medium": "SSD", "sizeLimit": "0"}},
+		Volume{Name: "shared", Type: NFSVolume,
+			Conf: map[string]string{"server": "10.0.0.5",
+				"path": "/exports"}},
+		Volume{Name: "badnfs", Type: NFSVolume,
+			Conf: map[string]string{"path": "exports"}},
+		Volume{Name: "ebs", Type: "awsElasticBlockStore",
+			Conf: map[string]string{"size": "10Gi"}},
+		Volume{Name: "do", Type: "digitalOceanVolume",
+			Conf: map[string]string{"size": "10Gi"}})
+
+	// Cloud volumes may be mounted by a container and its sidecars, but not
+	// by other containers.
+	bp.Containers[1].VolumeMounts = []VolumeMount{
+		{VolumeName: "pgdata", MountPath: "/var/lib/postgresql"}}
+	bp.Containers[1].Sidecars = []PodContainer{{
+		Name:  "backup",
+		Image: Image{Name: "backup"},
+		VolumeMounts: []VolumeMount{
+			{VolumeName: "pgdata", MountPath: "/data"},
+			{VolumeName: "scratch", MountPath: "/tmp"},
+		},
+	}}
+	assert.Equal(t, ValidationError{
+		`volume "Upper_Case": the names of gcePersistentDisk volumes must ` +
+			`consist of at most 63 lowercase letters, digits and '-', and ` +
+			`start and end with a letter or digit`,
+		`volume "nosize": gcePersistentDisk volumes require a size`,
+		`volume "badsize": invalid size "lots": must be a quantity such as ` +
+			`10Gi`,
+		`volume "badmedium": unknown medium "SSD": must be Memory or unset`,
+		`volume "badmedium": size limit must be positive`,
+		`volume "badnfs": nfs volumes require a server`,
+		`volume "badnfs": nfs volumes require an absolute path`,
+		`volume "ebs": awsElasticBlockStore volumes aren't supported yet: ` +
+			`Amazon machines don't have an IAM role that allows them to ` +
+			`create and attach disks`,
+		`volume "do": digitalOceanVolume volumes aren't supported yet: ` +
+			`DigitalOcean's CSI driver isn't deployed`,
+	}, Validate(bp))
+
+	// Unlike cloud volumes, NFS volumes can be mounted by several containers.
+	bp.Containers[0].VolumeMounts = append(bp.Containers[0].VolumeMounts,
+		VolumeMount{VolumeName: "shared", MountPath: "/shared"})
+	bp.Containers[1].VolumeMounts = append(bp.Containers[1].VolumeMounts,
+		VolumeMount{VolumeName: "shared", MountPath: "/shared"})
+	bp.Volumes = append(bp.Volumes[:5], bp.Volumes[8])
+	bp.Volumes[4].Name = "lower-case"
+	assert.NoError(t, Validate(bp))
+
+	bp.Containers[0].VolumeMounts = append(bp.Containers[0].VolumeMounts,
+		VolumeMount{VolumeName: "pgdata", MountPath: "/pgdata"},
+		VolumeMount{VolumeName: "disk", MountPath: "/disk"})
+	bp.Containers[0].UpdateStrategy = RollingUpdate
+	assert.Equal(t, ValidationError{
+		`container "web": containers that mount cloud volumes can't use ` +
+			`the Rolling update strategy`,
+		`container "db": volume "pgdata" is already mounted by container ` +
+			`"web", and gcePersistentDisk volumes can only be mounted ` +
+			`by one container`,
+	}, Validate(bp))
+}
+
+func TestValidateConnections(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Connections = []Connection{
+		{From: []string{"web"}, To: []string{"missing"}, MinPort: 80,
+			MaxPort: 80},
+		{From: []string{"lb"}, To: []string{"db"}, MinPort: 80, MaxPort: 80},
+		{To: []string{"db"}, MinPort: 80, MaxPort: 80},
+		{From: []string{"web"}, To: []string{"db"}, MinPort: 90, MaxPort: 80},
+		{From: []string{"web"}, To: []string{"db"}, MinPort: 0, MaxPort: 80},
+		{From: []string{"web"}, To: []string{"db"}, MinPort: 80,
+			MaxPort: 70000},
+		{From: []string{"public"}, To: []string{"web"}, MinPort: 80,
+			MaxPort: 90},
+		{From: []string{"web"}, To: []string{"db"}, MinPort: 53,
+			MaxPort: 53, Protocol: "sctp"},
+		{From: []string{"web"}, To: []string{"db"}, MinPort: 80,
+			MaxPort: 80, Protocol: "icmp"},
+		{From: []string{"public"}, To: []string{"web"}, Protocol: "icmp"},
+		{From: []string{"web"}, To: []string{"db"}, MinPort: 0,
+			MaxPort: 80, Protocol: "udp"},
+		{From: []string{"web"}, To: []string{"db"}, Protocol: "icmp"},
+		{From: []string{"public"}, To: []string{"web"}, MinPort: 53,
+			MaxPort: 53, Protocol: "udp"},
+		{From: []string{"web"}, To: []string{"public"}, MinPort: 80,
+			MaxPort: 80, PublicPort: 8080},
+		{From: []string{"public"}, To: []string{"web"}, MinPort: 80,
+			MaxPort: 80, PublicPort: 70000},
+		{From: []string{"public"}, To: []string{"web"}, MinPort: 80,
+			MaxPort: 81, PublicPort: 8080},
+		{From: []string{"public"}, To: []string{"web"}, MinPort: 80,
+			MaxPort: 80, PublicPort: 8080},
+	}
+
+	assert.Equal(t, ValidationError{
+		`connection from web to missing on ports 80-80: unknown hostname ` +
+			`"missing"`,
+		`connection from lb to db on ports 80-80: load balancer "lb" can't ` +
+			`make outgoing connections`,
+		`connection from  to db on ports 80-80: both ends of the ` +
+			`connection are required`,
+		`connection from web to db on ports 90-80: the minimum port is ` +
+			`greater than the maximum port`,
+		`connection from web to db on ports 0-80: ports must be between 1 ` +
+			`and 65535`,
+		`connection from web to db on ports 80-70000: ports must be ` +
+			`between 1 and 65535`,
+		`connection from web to db on sctp ports 53-53: unknown protocol ` +
+			`"sctp": must be tcp, udp or icmp`,
+		`connection from web to db over icmp: icmp connections don't ` +
+			`have ports`,
+		`connection from public to web over icmp: icmp connections from ` +
+			`the public internet aren't supported`,
+		`connection from web to db on udp ports 0-80: ports must be ` +
+			`between 1 and 65535`,
+		`connection from web to public on ports 80-80: only connections ` +
+			`from the public internet can have a public port`,
+		`connection from public to web on ports 80-80: the public port ` +
+			`must be between 1 and 65535`,
+		`connection from public to web on ports 80-81: port ranges can't ` +
+			`be forwarded from a different public port`,
+	}, Validate(bp))
+}
+
+func TestValidateMachines(t *testing.T) {
+	t.Parallel()
+
+	bp := validBlueprint()
+	bp.Machines = append(bp.Machines,
+		Machine{Provider: "Google", Region: "us-east1-b", Role: "Worker"},
+		Machine{Provider: "Amazon", Region: "us-west-1", Role: "worker",
+			DiskSize: -1})
+
+	assert.Equal(t, ValidationError{
+		`machine 2: all machines must have the same provider and region, ` +
+			`but it is in Google us-east1-b rather than Amazon us-west-1`,
+		`machine 3: role must be Master or Worker, not "worker"`,
+		`machine 3: disk size must not be negative`,
+	}, Validate(bp))
+}
+
+func TestValidationError(t *testing.T) {
+	t.Parallel()
+
+	assert.EqualError(t, ValidationError{"first", "second"},
+		"invalid blueprint: first; second")
+}
@@ -13,22 +13,31 @@ import (
 
 // Note the `minion` command is in cli_posix.go as it only runs on posix systems.
 var commands = map[string]command.SubCommand{
-	"daemon":  command.NewDaemonCommand(),
-	"inspect": &inspect.Inspect{},
-	"logs":    command.NewLogCommand(),
+	"daemon":       command.NewDaemonCommand(),
+	"compile":      command.NewCompileCommand(),
+	"cp":           command.NewCopyCommand(),
+	"inspect":      &inspect.Inspect{},
+	"exec":         command.NewExecCommand(),
+	"logs":         command.NewLogCommand(),
+	"port-forward": command.NewPortForwardCommand(),
 
 	"ps":   command.NewShowCommand(),
 	"show": command.NewShowCommand(),
 
-	"secret":              &command.Secret{},
+	"secret":              command.NewSecretCommand(),
 	"run":                 command.NewRunCommand(),
+	"validate":            command.NewValidateCommand(),
 	"configure-provider":  &command.ConfigProvider{},
 	"base-infrastructure": &command.BaseInfra{},
 	"ssh":        command.NewSSHCommand(),
 	"stop":       command.NewStopCommand(),
+	"top":        command.NewTopCommand(),
 	"version":    command.NewVersionCommand(),
 	"debug-logs": command.NewDebugCommand(),
 	"counters":   &command.Counters{},
+	"users":      command.NewUsersCommand(),
+	"history":    command.NewHistoryCommand(),
+	"rollback":   command.NewRollbackCommand(),
 }
 
 // Run parses and runs the cli subcommand given the command line arguments.
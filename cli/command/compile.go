@@ -0,0 +1,108 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kelda/kelda/util"
+)
+
+// Compile contains the options for converting blueprints into declarative
+// blueprints.
+type Compile struct {
+	format        string
+	outputPath    string
+	blueprint     string
+	blueprintArgs []string
+}
+
+// NewCompileCommand creates a new Compile command instance.
+func NewCompileCommand() *Compile {
+	return &Compile{}
+}
+
+var compileCommands = `kelda compile [OPTIONS] BLUEPRINT [BLUEPRINT_ARGS...]`
+var compileExplanation = `Compile a blueprint into a declarative YAML or JSON blueprint.
+
+Declarative blueprints describe the compiled deployment directly, so they can
+be deployed with 'kelda run' without Node.js. Blueprints whose file names end
+in .yaml, .yml or .json are treated as declarative blueprints.
+
+BLUEPRINT_ARGS are the command line arguments that should be passed to the
+blueprint, similar to when the blueprint is run with 'kelda run'.
+
+To convert ./example.js into a YAML blueprint:
+kelda compile -o example.yaml ./example.js`
+
+// InstallFlags sets up parsing for command line flags.
+func (cCmd *Compile) InstallFlags(flags *flag.FlagSet) {
+	flags.StringVar(&cCmd.format, "format", "yaml",
+		"the format of the compiled blueprint: yaml or json")
+	flags.StringVar(&cCmd.outputPath, "o", "",
+		"the file to write the compiled blueprint to, rather than stdout")
+
+	flags.Usage = func() {
+		util.PrintUsageString(compileCommands, compileExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the compile command.
+func (cCmd *Compile) Parse(args []string) error {
+	if cCmd.format != "yaml" && cCmd.format != "json" {
+		return fmt.Errorf("unsupported format %q: must be yaml or json",
+			cCmd.format)
+	}
+
+	if len(args) == 0 {
+		return errors.New("no blueprint specified")
+	}
+
+	cCmd.blueprint = args[0]
+	cCmd.blueprintArgs = args[1:]
+	return nil
+}
+
+// BeforeRun makes any necessary post-parsing transformations.
+func (cCmd *Compile) BeforeRun() error {
+	return nil
+}
+
+// AfterRun performs any necessary post-run cleanup.
+func (cCmd *Compile) AfterRun() error {
+	return nil
+}
+
+// Run compiles the blueprint, and writes it in the declarative format.
+func (cCmd *Compile) Run() int {
+	contents, err := cCmd.compile()
+	if err != nil {
+		log.WithError(err).Error("Failed to compile blueprint")
+		return 1
+	}
+
+	if cCmd.outputPath == "" {
+		fmt.Print(string(contents))
+		return 0
+	}
+
+	if err := util.WriteFile(cCmd.outputPath, contents, 0644); err != nil {
+		log.WithError(err).Errorf("Failed to write %s", cCmd.outputPath)
+		return 1
+	}
+	return 0
+}
+
+func (cCmd *Compile) compile() ([]byte, error) {
+	compiled, err := compile(cCmd.blueprint, cCmd.blueprintArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if cCmd.format == "json" {
+		return compiled.ToDeclarativeJSON()
+	}
+	return compiled.ToDeclarativeYAML()
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/util"
+)
+
+func TestCompileParse(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewCompileCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"-format", "json", "-o",
+		"bp.json", "bp.js", "arg"}))
+	assert.Equal(t, Compile{format: "json", outputPath: "bp.json",
+		blueprint: "bp.js", blueprintArgs: []string{"arg"}}, *cmd)
+
+	cmd = NewCompileCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"bp.js"}))
+	assert.Equal(t, Compile{format: "yaml", blueprint: "bp.js",
+		blueprintArgs: []string{}}, *cmd)
+
+	assert.EqualError(t, parseHelper(NewCompileCommand(), nil),
+		"no blueprint specified")
+	assert.EqualError(t, parseHelper(NewCompileCommand(),
+		[]string{"-format", "xml", "bp.js"}),
+		`unsupported format "xml": must be yaml or json`)
+}
+
+func TestCompileRun(t *testing.T) {
+	oldCompile := compile
+	defer func() {
+		compile = oldCompile
+	}()
+
+	bp := blueprint.Blueprint{
+		Namespace: "namespace",
+		Containers: []blueprint.Container{{
+			ID:       "id",
+			Hostname: "web",
+			Image:    blueprint.Image{Name: "nginx"},
+		}},
+	}
+	compile = func(path string, args []string) (blueprint.Blueprint, error) {
+		assert.Equal(t, "bp.js", path)
+		assert.Equal(t, []string{"arg"}, args)
+		return bp, nil
+	}
+
+	util.AppFs = afero.NewMemMapFs()
+	cmd := Compile{format: "yaml", outputPath: "/bp.yaml", blueprint: "bp.js",
+		blueprintArgs: []string{"arg"}}
+	assert.Equal(t, 0, cmd.Run())
+
+	// The compiled blueprint loads back into the same deployment, apart from
+	// the container IDs, which are derived from the containers.
+	compiled, err := blueprint.FromDeclarativeFile("/bp.yaml")
+	assert.NoError(t, err)
+	assert.NotEqual(t, "id", compiled.Containers[0].ID)
+	compiled.Containers[0].ID = "id"
+	assert.Equal(t, bp, compiled)
+
+	cmd.format = "json"
+	cmd.outputPath = "/bp.json"
+	assert.Equal(t, 0, cmd.Run())
+
+	compiled, err = blueprint.FromDeclarativeFile("/bp.json")
+	assert.NoError(t, err)
+	compiled.Containers[0].ID = "id"
+	assert.Equal(t, bp, compiled)
+
+	compile = func(path string, args []string) (blueprint.Blueprint, error) {
+		return blueprint.Blueprint{}, errors.New("syntax error")
+	}
+	assert.Equal(t, 1, cmd.Run())
+}
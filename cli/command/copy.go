@@ -0,0 +1,332 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+
+	"github.com/kelda/kelda/api"
+	apiUtil "github.com/kelda/kelda/api/util"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/util"
+)
+
+// Copy contains the options for copying files into and out of containers.
+type Copy struct {
+	src, dst copyPath
+
+	connectionHelper
+}
+
+// copyPath is a path on the local machine, or in a container if `container`
+// is set.
+type copyPath struct {
+	container string
+	path      string
+}
+
+// NewCopyCommand creates a new Copy command instance.
+func NewCopyCommand() *Copy {
+	return &Copy{}
+}
+
+var copyCommands = `kelda cp [OPTIONS] CONTAINER:SRC_PATH DEST_PATH
+kelda cp [OPTIONS] SRC_PATH CONTAINER:DEST_PATH`
+var copyExplanation = `Copy files or directories between a container and the
+local machine.
+
+The files are streamed through the Kelda API as a tar archive, so the container
+must have tar installed. If the destination is an existing local directory, or
+a container path ending in '/', the source is copied into it. Otherwise, the
+source is copied to the destination path. Only regular files and directories
+are copied.
+
+To copy a heap dump out of container 8879fd2dbcee:
+kelda cp 8879fd2dbcee:/tmp/heap.hprof .
+
+To copy a config directory into container 8879fd2dbcee:
+kelda cp ./conf 8879fd2dbcee:/etc/app/conf`
+
+// InstallFlags sets up parsing for command line flags.
+func (cCmd *Copy) InstallFlags(flags *flag.FlagSet) {
+	cCmd.connectionHelper.InstallFlags(flags)
+
+	flags.Usage = func() {
+		util.PrintUsageString(copyCommands, copyExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the cp command.
+func (cCmd *Copy) Parse(args []string) error {
+	if len(args) != 2 {
+		return errors.New("must specify a source and destination")
+	}
+
+	cCmd.src = parseCopyPath(args[0])
+	cCmd.dst = parseCopyPath(args[1])
+	if (cCmd.src.container == "") == (cCmd.dst.container == "") {
+		return errors.New("exactly one of the source and destination must " +
+			"be in a container, e.g. CONTAINER:/tmp/file")
+	}
+
+	if cCmd.src.path == "" || cCmd.dst.path == "" {
+		return errors.New("paths must not be empty")
+	}
+	return nil
+}
+
+// parseCopyPath parses a path of the form [CONTAINER:]PATH. Local paths that
+// contain a colon can be prefixed with "./".
+func parseCopyPath(arg string) copyPath {
+	i := strings.Index(arg, ":")
+	if i <= 0 || strings.Contains(arg[:i], "/") {
+		return copyPath{path: arg}
+	}
+	return copyPath{container: arg[:i], path: arg[i+1:]}
+}
+
+// Run copies the files.
+func (cCmd *Copy) Run() int {
+	target := cCmd.src.container
+	if target == "" {
+		target = cCmd.dst.container
+	}
+
+	i, err := apiUtil.FuzzyLookup(cCmd.client, target)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to lookup %s", target)
+		return 1
+	}
+
+	dbc, ok := i.(db.Container)
+	if !ok {
+		log.Error("Files can only be copied to and from containers. " +
+			"Use `kelda ssh` to access files on machines.")
+		return 1
+	}
+
+	if cCmd.src.container != "" {
+		err = cCmd.copyOut(dbc.Hostname)
+	} else {
+		err = cCmd.copyIn(dbc.Hostname)
+	}
+
+	if err != nil {
+		log.WithError(err).Error("Failed to copy files")
+		return 1
+	}
+	return 0
+}
+
+// copyIn copies the local source into the container by extracting a tar
+// archive of it with `tar` in the container.
+func (cCmd *Copy) copyIn(hostname string) error {
+	if _, err := util.Stat(cCmd.src.path); err != nil {
+		return err
+	}
+
+	dir, name := path.Dir(cCmd.dst.path), path.Base(cCmd.dst.path)
+	if strings.HasSuffix(cCmd.dst.path, "/") {
+		dir, name = cCmd.dst.path, filepath.Base(cCmd.src.path)
+	}
+
+	archiveReader, archiveWriter := io.Pipe()
+	tarErr := make(chan error, 1)
+	go func() {
+		err := writeTar(archiveWriter, cCmd.src.path, name)
+		archiveWriter.CloseWithError(err)
+		tarErr <- err
+	}()
+
+	// The directory is passed as an argument to the shell so that it doesn't
+	// need to be quoted.
+	var stderr bytes.Buffer
+	exitCode, err := cCmd.client.Exec(api.ExecOptions{
+		Container: hostname,
+		Command: []string{"sh", "-c", `mkdir -p "$1" && tar -xf - -C "$1"`,
+			"sh", dir},
+		Stdin:  archiveReader,
+		Stdout: ioutil.Discard,
+		Stderr: &stderr,
+	})
+	archiveReader.Close()
+	if err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		return fmt.Errorf("extracting files in the container failed: %s",
+			strings.TrimSpace(stderr.String()))
+	}
+
+	// `tar` may exit before the padding at the end of the archive is read.
+	if err := <-tarErr; err != io.ErrClosedPipe {
+		return err
+	}
+	return nil
+}
+
+// copyOut copies the source in the container to the local machine by
+// extracting the tar archive of it created by `tar` in the container.
+func (cCmd *Copy) copyOut(hostname string) error {
+	srcPath := path.Clean(cCmd.src.path)
+	dir, name := path.Dir(srcPath), path.Base(srcPath)
+
+	dst := cCmd.dst.path
+	if info, err := util.Stat(dst); err == nil && info.IsDir() {
+		dst = filepath.Join(dst, name)
+	}
+
+	archiveReader, archiveWriter := io.Pipe()
+	extractErr := make(chan error, 1)
+	go func() {
+		err := extractTar(archiveReader, name, dst)
+		if err != nil {
+			archiveReader.CloseWithError(err)
+		} else {
+			// Consume the padding after the end of the archive so that
+			// writes to the pipe don't block.
+			io.Copy(ioutil.Discard, archiveReader)
+		}
+		extractErr <- err
+	}()
+
+	var stderr bytes.Buffer
+	exitCode, err := cCmd.client.Exec(api.ExecOptions{
+		Container: hostname,
+		Command:   []string{"tar", "-cf", "-", "-C", dir, name},
+		Stdout:    archiveWriter,
+		Stderr:    &stderr,
+	})
+	archiveWriter.Close()
+	if err != nil {
+		return err
+	}
+
+	if exitCode != 0 {
+		return fmt.Errorf("archiving files in the container failed: %s",
+			strings.TrimSpace(stderr.String()))
+	}
+	return <-extractErr
+}
+
+// writeTar writes a tar archive of the local file or directory at `src` to
+// `w`. The archived paths start with `name` rather than `src`.
+func writeTar(w io.Writer, src, name string) error {
+	tw := tar.NewWriter(w)
+	err := afero.Walk(util.AppFs, src, func(file string, info os.FileInfo,
+		err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			log.WithField("path", file).Warn(
+				"Skipping file that is not a regular file or directory")
+			return nil
+		}
+
+		rel, err := filepath.Rel(src, file)
+		if err != nil {
+			return err
+		}
+
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+
+		hdr.Name = path.Join(name, filepath.ToSlash(rel))
+		if info.IsDir() {
+			hdr.Name += "/"
+			hdr.Typeflag = tar.TypeDir
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := util.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// extractTar extracts the tar archive read from `r` to `dst`. All paths in the
+// archive must be `name`, or within `name`, which is replaced by `dst`.
+func extractTar(r io.Reader, name, dst string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		// Cleaning the path removes any ".." elements after `name`, so
+		// the archive can't write outside of `dst`.
+		entry := path.Clean(hdr.Name)
+		var rel string
+		switch {
+		case entry == name:
+		case strings.HasPrefix(entry, name+"/"):
+			rel = strings.TrimPrefix(entry, name+"/")
+		default:
+			return fmt.Errorf("unexpected path %q in archive", hdr.Name)
+		}
+		file := filepath.Join(dst, filepath.FromSlash(rel))
+
+		mode := os.FileMode(hdr.Mode).Perm()
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := util.AppFs.MkdirAll(file, mode); err != nil {
+				return err
+			}
+		case tar.TypeReg, tar.TypeRegA:
+			if err := extractFile(tr, file, mode); err != nil {
+				return err
+			}
+		default:
+			log.WithField("path", hdr.Name).Warn(
+				"Skipping file that is not a regular file or directory")
+		}
+	}
+}
+
+func extractFile(r io.Reader, file string, mode os.FileMode) error {
+	f, err := util.AppFs.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
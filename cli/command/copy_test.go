@@ -0,0 +1,233 @@
+package command
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/util"
+)
+
+func TestCopyParse(t *testing.T) {
+	t.Parallel()
+
+	checkCopyParse(t, []string{"foo:/tmp/heap", "."}, Copy{
+		src: copyPath{container: "foo", path: "/tmp/heap"},
+		dst: copyPath{path: "."},
+	}, "")
+	checkCopyParse(t, []string{"./a:b", "foo:conf/"}, Copy{
+		src: copyPath{path: "./a:b"},
+		dst: copyPath{container: "foo", path: "conf/"},
+	}, "")
+
+	checkCopyParse(t, []string{"foo:/tmp"}, Copy{},
+		"must specify a source and destination")
+	checkCopyParse(t, []string{"src", "dst"}, Copy{},
+		"exactly one of the source and destination must be in a "+
+			"container, e.g. CONTAINER:/tmp/file")
+	checkCopyParse(t, []string{"foo:src", "bar:dst"}, Copy{},
+		"exactly one of the source and destination must be in a "+
+			"container, e.g. CONTAINER:/tmp/file")
+	checkCopyParse(t, []string{"foo:", "dst"}, Copy{},
+		"paths must not be empty")
+}
+
+func checkCopyParse(t *testing.T, args []string, exp Copy, expErr string) {
+	cmd := NewCopyCommand()
+	err := parseHelper(cmd, args)
+	if expErr != "" {
+		assert.EqualError(t, err, expErr)
+		return
+	}
+
+	assert.NoError(t, err)
+	cmd.connectionHelper = connectionHelper{}
+	assert.Equal(t, exp, *cmd)
+}
+
+// tarEntry is a file or directory in a tar archive.
+type tarEntry struct {
+	name     string
+	contents string
+	mode     int64
+}
+
+func readTarEntries(t *testing.T, r io.Reader) []tarEntry {
+	var entries []tarEntry
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF || !assert.NoError(t, err) {
+			return entries
+		}
+
+		contents, err := ioutil.ReadAll(tr)
+		assert.NoError(t, err)
+		entries = append(entries, tarEntry{hdr.Name, string(contents),
+			hdr.Mode & 0777})
+	}
+}
+
+func writeTarEntries(t *testing.T, entries []tarEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		hdr := &tar.Header{
+			Name:     entry.name,
+			Mode:     entry.mode,
+			Size:     int64(len(entry.contents)),
+			Typeflag: tar.TypeReg,
+		}
+		if entry.name[len(entry.name)-1] == '/' {
+			hdr.Typeflag = tar.TypeDir
+		}
+		assert.NoError(t, tw.WriteHeader(hdr))
+
+		_, err := tw.Write([]byte(entry.contents))
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, tw.Close())
+	return buf.Bytes()
+}
+
+func TestCopyIn(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+	assert.NoError(t, util.AppFs.MkdirAll("/local/conf", 0755))
+	assert.NoError(t, util.AppFs.MkdirAll("/local/conf/sub", 0755))
+	assert.NoError(t, util.WriteFile("/local/conf/app.yml", []byte("app"), 0644))
+	assert.NoError(t, util.WriteFile("/local/conf/sub/key", []byte("key"), 0600))
+
+	var archive []tarEntry
+	var command []string
+	c := &mocks.Client{}
+	c.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		opts := args.Get(0).(api.ExecOptions)
+		command = opts.Command
+		archive = readTarEntries(t, opts.Stdin)
+	}).Return(0, nil).Once()
+
+	cmd := Copy{
+		src: copyPath{path: "/local/conf"},
+		dst: copyPath{container: "foo", path: "/etc/app/config"},
+	}
+	cmd.client = c
+	assert.NoError(t, cmd.copyIn("foo"))
+	assert.Equal(t, []string{"sh", "-c", `mkdir -p "$1" && tar -xf - -C "$1"`,
+		"sh", "/etc/app"}, command)
+	assert.Equal(t, []tarEntry{
+		{"config/", "", 0755},
+		{"config/app.yml", "app", 0644},
+		{"config/sub/", "", 0755},
+		{"config/sub/key", "key", 0600},
+	}, archive)
+
+	// Destinations ending in a slash are copied into.
+	c.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		opts := args.Get(0).(api.ExecOptions)
+		command = opts.Command
+		archive = readTarEntries(t, opts.Stdin)
+	}).Return(0, nil).Once()
+
+	cmd.src.path = "/local/conf/app.yml"
+	cmd.dst.path = "/etc/app/"
+	assert.NoError(t, cmd.copyIn("foo"))
+	assert.Equal(t, "/etc/app/", command[4])
+	assert.Equal(t, []tarEntry{{"app.yml", "app", 0644}}, archive)
+
+	c.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		opts := args.Get(0).(api.ExecOptions)
+		opts.Stderr.Write([]byte("tar: not found\n"))
+	}).Return(127, nil).Once()
+	assert.EqualError(t, cmd.copyIn("foo"),
+		"extracting files in the container failed: tar: not found")
+
+	cmd.src.path = "/local/missing"
+	assert.Error(t, cmd.copyIn("foo"))
+}
+
+func TestCopyOut(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+	assert.NoError(t, util.AppFs.MkdirAll("/local", 0755))
+
+	archive := writeTarEntries(t, []tarEntry{
+		{"dumps/", "", 0755},
+		{"dumps/heap", "heap", 0600},
+	})
+
+	var command []string
+	c := &mocks.Client{}
+	c.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		opts := args.Get(0).(api.ExecOptions)
+		command = opts.Command
+		opts.Stdout.Write(archive)
+
+		// Padding after the end of the archive should be ignored.
+		opts.Stdout.Write(make([]byte, 10240))
+	}).Return(0, nil)
+
+	// Copying into an existing directory keeps the source's name.
+	cmd := Copy{
+		src: copyPath{container: "foo", path: "/tmp/dumps/"},
+		dst: copyPath{path: "/local"},
+	}
+	cmd.client = c
+	assert.NoError(t, cmd.copyOut("foo"))
+	assert.Equal(t, []string{"tar", "-cf", "-", "-C", "/tmp", "dumps"}, command)
+
+	contents, err := util.ReadFile("/local/dumps/heap")
+	assert.NoError(t, err)
+	assert.Equal(t, "heap", contents)
+
+	info, err := util.Stat("/local/dumps/heap")
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Otherwise, the source is renamed to the destination.
+	cmd.dst.path = "/local/renamed"
+	assert.NoError(t, cmd.copyOut("foo"))
+
+	contents, err = util.ReadFile("/local/renamed/heap")
+	assert.NoError(t, err)
+	assert.Equal(t, "heap", contents)
+}
+
+func TestCopyOutErrors(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	c := &mocks.Client{}
+	c.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		opts := args.Get(0).(api.ExecOptions)
+		opts.Stderr.Write([]byte("tar: /tmp/missing: No such file\n"))
+	}).Return(2, nil).Once()
+
+	cmd := Copy{
+		src: copyPath{container: "foo", path: "/tmp/missing"},
+		dst: copyPath{path: "/local"},
+	}
+	cmd.client = c
+	assert.EqualError(t, cmd.copyOut("foo"), "archiving files in the "+
+		"container failed: tar: /tmp/missing: No such file")
+
+	// Archives must not write outside of the destination.
+	archive := writeTarEntries(t, []tarEntry{
+		{"missing/../../etc/passwd", "evil", 0644},
+	})
+	c.On("Exec", mock.Anything).Run(func(args mock.Arguments) {
+		args.Get(0).(api.ExecOptions).Stdout.Write(archive)
+	}).Return(0, nil).Once()
+	assert.EqualError(t, cmd.copyOut("foo"),
+		`unexpected path "missing/../../etc/passwd" in archive`)
+
+	exists, err := util.FileExists("/etc/passwd")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -14,12 +14,14 @@ import (
 
 	"golang.org/x/crypto/ssh"
 
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/api/server"
 	cliPath "github.com/kelda/kelda/cli/path"
 	"github.com/kelda/kelda/cloud"
 	"github.com/kelda/kelda/cloud/foreman"
 	tlsIO "github.com/kelda/kelda/connection/tls/io"
 	"github.com/kelda/kelda/connection/tls/rsa"
+	"github.com/kelda/kelda/counter"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/util"
 	"github.com/kelda/kelda/version"
@@ -29,6 +31,8 @@ import (
 
 // Daemon contains the options for running the Kelda daemon.
 type Daemon struct {
+	metricsAddr string
+
 	*connectionFlags
 }
 
@@ -45,6 +49,9 @@ var daemonExplanation = "Start the kelda daemon, which listens for kelda API req
 // InstallFlags sets up parsing for command line flags
 func (dCmd *Daemon) InstallFlags(flags *flag.FlagSet) {
 	dCmd.connectionFlags.InstallFlags(flags)
+	flags.StringVar(&dCmd.metricsAddr, "metrics-address",
+		fmt.Sprintf(":%d", api.DefaultMetricsPort), "the address to serve "+
+			"Prometheus metrics on. Metrics are disabled if empty.")
 	flags.Usage = func() {
 		util.PrintUsageString(daemonCommands, daemonExplanation, flags)
 	}
@@ -81,6 +88,19 @@ func (dCmd *Daemon) Run() int {
 		}
 	}
 
+	// Daemons whose credentials were generated before users had their own
+	// certificate authority are given one too.
+	userCAPath := tlsIO.UserCACertPath(cliPath.DefaultTLSDir)
+	if _, err := util.Stat(userCAPath); os.IsNotExist(err) {
+		log.WithField("path", userCAPath).Info(
+			"Auto-generating certificate authority for users")
+		if err := setupUserCA(cliPath.DefaultTLSDir); err != nil {
+			log.WithError(err).Error(
+				"User certificate authority generation failed")
+			return 1
+		}
+	}
+
 	if _, err := util.Stat(cliPath.DefaultKubeSecretPath); os.IsNotExist(err) {
 		log.WithField("path", cliPath.DefaultKubeSecretPath).Info(
 			"Auto-generating encryption key for Kubernetes resources")
@@ -113,6 +133,19 @@ func (dCmd *Daemon) Run() int {
 		return 1
 	}
 
+	// Only the daemon's API server trusts user certificates. The minions,
+	// etcd and Kubernetes only trust the cluster's certificate authority.
+	userCA, err := tlsIO.ReadUserCA(cliPath.DefaultTLSDir)
+	if err != nil {
+		log.WithError(err).Error("Failed to parse user certificate authority")
+		return 1
+	}
+	serverCreds, err := creds.WithClientCA(userCA.CertString())
+	if err != nil {
+		log.WithError(err).Error("Failed to trust user certificate authority")
+		return 1
+	}
+
 	kubeSecret, err := util.ReadFile(cliPath.DefaultKubeSecretPath)
 	if err != nil {
 		log.WithError(err).Error("Failed to read Kubernetes encryption key")
@@ -120,7 +153,14 @@ func (dCmd *Daemon) Run() int {
 	}
 
 	conn := db.New()
-	go server.Run(conn, dCmd.host, true, creds)
+	go server.Run(conn, dCmd.host, true, serverCreds)
+	if dCmd.metricsAddr != "" {
+		go func() {
+			err := counter.ServeMetrics(dCmd.metricsAddr)
+			log.WithError(err).WithField("address", dCmd.metricsAddr).Error(
+				"Failed to serve metrics")
+		}()
+	}
 
 	ca, err := tlsIO.ReadCA(cliPath.DefaultTLSDir)
 	if err != nil {
@@ -180,6 +220,22 @@ func setupTLS(outDir string) error {
 	return nil
 }
 
+// setupUserCA generates the certificate authority that signs the certificates
+// issued by `kelda users`, and writes it to disk.
+func setupUserCA(outDir string) error {
+	userCA, err := rsa.NewCertificateAuthority()
+	if err != nil {
+		return fmt.Errorf("failed to create CA: %s", err)
+	}
+
+	for _, f := range tlsIO.UserCAFiles(outDir, userCA) {
+		if err := util.WriteFile(f.Path, []byte(f.Content), f.Mode); err != nil {
+			return fmt.Errorf("failed to write file (%s): %s", f.Path, err)
+		}
+	}
+	return nil
+}
+
 // setupSSHKey generates a new RSA key for use with SSH, and writes it to disk.
 func setupSSHKey(outPath string) error {
 	if err := util.AppFs.MkdirAll(filepath.Dir(outPath), 0700); err != nil {
@@ -81,6 +81,22 @@ func TestSetupTLS(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// Test that the generated user CA can be parsed, and is separate from the
+// cluster's.
+func TestSetupUserCA(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	tlsDir := "tls"
+	assert.NoError(t, setupTLS(tlsDir))
+	assert.NoError(t, setupUserCA(tlsDir))
+
+	ca, err := tlsIO.ReadCA(tlsDir)
+	assert.NoError(t, err)
+	userCA, err := tlsIO.ReadUserCA(tlsDir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, ca.CertString(), userCA.CertString())
+}
+
 // Test that the generated file can be parsed.
 func TestSetupSSHKey(t *testing.T) {
 	util.AppFs = afero.NewMemMapFs()
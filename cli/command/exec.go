@@ -0,0 +1,169 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"os/signal"
+
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/pb"
+	apiUtil "github.com/kelda/kelda/api/util"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/util"
+)
+
+// Exec contains the options for running commands in containers.
+type Exec struct {
+	target      string
+	command     []string
+	allocatePTY bool
+	keepStdin   bool
+
+	connectionHelper
+}
+
+// NewExecCommand creates a new Exec command instance.
+func NewExecCommand() *Exec {
+	return &Exec{}
+}
+
+var execCommands = "kelda exec [OPTIONS] CONTAINER [COMMAND [ARG...]]"
+var execExplanation = `Run a command in a container.
+
+The command is run through the Kelda API, so only the credentials used to
+connect to the daemon are required. The exit code of the command is returned.
+If no command is supplied, an interactive shell is started.
+
+To run a command on container 8879fd2dbcee:
+kelda exec 8879fd2dbcee ls /tmp
+
+To pipe a file into a command:
+kelda exec -i 8879fd2dbcee sh -c 'cat > /tmp/file' < file`
+
+// InstallFlags sets up parsing for command line flags.
+func (eCmd *Exec) InstallFlags(flags *flag.FlagSet) {
+	eCmd.connectionHelper.InstallFlags(flags)
+	flags.BoolVar(&eCmd.allocatePTY, "t", false, "allocate a pseudo-terminal")
+	flags.BoolVar(&eCmd.keepStdin, "i", false,
+		"attach the command's standard input")
+
+	flags.Usage = func() {
+		util.PrintUsageString(execCommands, execExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the exec command.
+func (eCmd *Exec) Parse(args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify a target container")
+	}
+
+	eCmd.target = args[0]
+	eCmd.command = args[1:]
+	if len(eCmd.command) == 0 {
+		eCmd.command = []string{"sh"}
+		eCmd.allocatePTY = true
+		eCmd.keepStdin = true
+	}
+	return nil
+}
+
+// Run runs the command in the given container.
+func (eCmd *Exec) Run() int {
+	if eCmd.allocatePTY && !isTerminal() {
+		log.Error("Cannot allocate pseudo-terminal without a terminal")
+		return 1
+	}
+
+	i, err := apiUtil.FuzzyLookup(eCmd.client, eCmd.target)
+	if err != nil {
+		log.WithError(err).Errorf("Failed to lookup %s", eCmd.target)
+		return 1
+	}
+
+	dbc, ok := i.(db.Container)
+	if !ok {
+		log.Error("Commands can only be executed in containers. " +
+			"Use `kelda ssh` to run commands on machines.")
+		return 1
+	}
+
+	exitCode, err := eCmd.exec(dbc.Hostname)
+	if err != nil {
+		log.WithError(err).Error("Error running command")
+		return 1
+	}
+	return exitCode
+}
+
+func (eCmd *Exec) exec(hostname string) (int, error) {
+	opts := api.ExecOptions{
+		Container: hostname,
+		Command:   eCmd.command,
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		TTY:       eCmd.allocatePTY,
+	}
+	if eCmd.keepStdin {
+		opts.Stdin = os.Stdin
+	}
+
+	if eCmd.allocatePTY {
+		fd := int(os.Stdin.Fd())
+		originalState, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return 0, err
+		}
+		defer terminal.Restore(fd, originalState)
+
+		resize, stop := watchTerminalSize(fd)
+		defer stop()
+		opts.Resize = resize
+	}
+
+	return eCmd.client.Exec(opts)
+}
+
+// watchTerminalSize sends the size of the terminal on the returned channel,
+// and again every time the terminal is resized. It stops once the returned
+// function is called.
+func watchTerminalSize(fd int) (<-chan pb.TerminalSize, func()) {
+	resize := make(chan pb.TerminalSize)
+	sig := make(chan os.Signal, 1)
+	notifyResize(sig)
+
+	done := make(chan struct{})
+	go func() {
+		for {
+			width, height, err := terminal.GetSize(fd)
+			if err != nil {
+				log.WithError(err).Debug("Failed to get terminal size")
+			} else {
+				size := pb.TerminalSize{
+					Height: uint32(height),
+					Width:  uint32(width),
+				}
+				select {
+				case resize <- size:
+				case <-done:
+					return
+				}
+			}
+
+			select {
+			case <-sig:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return resize, func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
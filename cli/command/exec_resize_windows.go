@@ -0,0 +1,7 @@
+package command
+
+import "os"
+
+func notifyResize(sig chan os.Signal) {
+	// Unimplemented
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/db"
+)
+
+func TestExecParse(t *testing.T) {
+	t.Parallel()
+
+	checkExecParse(t, []string{"foo", "ls", "-l"},
+		Exec{target: "foo", command: []string{"ls", "-l"}}, "")
+	checkExecParse(t, []string{"-t", "-i", "foo", "top"},
+		Exec{target: "foo", command: []string{"top"}, allocatePTY: true,
+			keepStdin: true}, "")
+	checkExecParse(t, []string{"foo"},
+		Exec{target: "foo", command: []string{"sh"}, allocatePTY: true,
+			keepStdin: true}, "")
+
+	checkExecParse(t, nil, Exec{}, "must specify a target container")
+}
+
+func checkExecParse(t *testing.T, args []string, exp Exec, expErr string) {
+	cmd := NewExecCommand()
+	err := parseHelper(cmd, args)
+	if expErr != "" {
+		assert.EqualError(t, err, expErr)
+		return
+	}
+
+	assert.NoError(t, err)
+	cmd.connectionHelper = connectionHelper{}
+	assert.Equal(t, exp, *cmd)
+}
+
+func TestExec(t *testing.T) {
+	t.Parallel()
+
+	c := &mocks.Client{}
+	c.On("QueryMachines").Return([]db.Machine{{CloudID: "machine"}}, nil)
+	c.On("QueryContainers").Return([]db.Container{
+		{BlueprintID: "container", Hostname: "foo"},
+		{BlueprintID: "broken", Hostname: "bar"},
+	}, nil)
+	c.On("Exec", mock.MatchedBy(func(opts api.ExecOptions) bool {
+		return opts.Container == "foo" && opts.Stdin == nil && !opts.TTY
+	})).Return(2, nil)
+	c.On("Exec", mock.MatchedBy(func(opts api.ExecOptions) bool {
+		return opts.Container == "bar"
+	})).Return(0, errors.New("error"))
+
+	cmd := Exec{target: "cont", command: []string{"false"}}
+	cmd.client = c
+	assert.Equal(t, 2, cmd.Run())
+
+	cmd = Exec{target: "bro", command: []string{"ls"}}
+	cmd.client = c
+	assert.Equal(t, 1, cmd.Run())
+
+	cmd = Exec{target: "mach", command: []string{"ls"}}
+	cmd.client = c
+	assert.Equal(t, 1, cmd.Run())
+
+	cmd = Exec{target: "missing", command: []string{"ls"}}
+	cmd.client = c
+	assert.Equal(t, 1, cmd.Run())
+}
@@ -0,0 +1,187 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	units "github.com/docker/go-units"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/util"
+)
+
+// History contains the options for inspecting the deployment history.
+type History struct {
+	// The versions to diff. If diffing is not requested, both are zero. If
+	// `to` is zero, `from` is diffed against the current deployment.
+	diff     bool
+	from, to int64
+
+	connectionHelper
+}
+
+// NewHistoryCommand creates a new History command instance.
+func NewHistoryCommand() *History {
+	return &History{}
+}
+
+var historyCommands = `kelda history
+kelda history diff VERSION [VERSION]`
+
+var historyExplanation = `List the blueprints deployed by the daemon.
+
+Each deployment is numbered with a version, and records when it was deployed,
+the user whose credentials deployed it, and a hash of the blueprint file it was
+compiled from. Use ` + "`kelda rollback`" + ` to redeploy a previous version.
+
+The diff action shows the changes between two versions. If only one version is
+given, it is compared against the current deployment.`
+
+// InstallFlags sets up parsing for command line flags.
+func (hCmd *History) InstallFlags(flags *flag.FlagSet) {
+	hCmd.connectionHelper.InstallFlags(flags)
+	flags.Usage = func() {
+		util.PrintUsageString(historyCommands, historyExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the history command.
+func (hCmd *History) Parse(args []string) error {
+	if len(args) == 0 {
+		return nil
+	}
+
+	if args[0] != "diff" {
+		return fmt.Errorf("unknown action %q", args[0])
+	}
+
+	if len(args) != 2 && len(args) != 3 {
+		return errors.New("diff requires one or two versions")
+	}
+
+	hCmd.diff = true
+	var err error
+	if hCmd.from, err = parseVersion(args[1]); err != nil {
+		return err
+	}
+
+	if len(args) == 3 {
+		if hCmd.to, err = parseVersion(args[2]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run prints the deployment history, or the diff between two deployments.
+func (hCmd *History) Run() int {
+	history, err := hCmd.client.DeploymentHistory()
+	if err != nil {
+		log.WithError(err).Error("Failed to get deployment history")
+		return 1
+	}
+
+	if !hCmd.diff {
+		printHistory(os.Stdout, history)
+		return 0
+	}
+
+	diff, err := diffVersions(history, hCmd.from, hCmd.to)
+	if err != nil {
+		log.WithError(err).Error("Failed to diff deployments")
+		return 1
+	}
+
+	if diff == "" {
+		fmt.Println("No change.")
+	} else {
+		fmt.Println(colorizeDiff(diff))
+	}
+	return 0
+}
+
+func printHistory(out io.Writer, history []pb.Deployment) {
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "VERSION\tDEPLOYED\tBY\tSOURCE\tNOTES")
+
+	// Print the most recent deployments first.
+	for i := len(history) - 1; i >= 0; i-- {
+		deployment := history[i]
+
+		source := deployment.SourceHash
+		if len(source) > 12 {
+			source = source[:12]
+		}
+
+		var notes string
+		if deployment.RollbackOf != 0 {
+			notes = fmt.Sprintf("rollback to %d", deployment.RollbackOf)
+		}
+		if i == len(history)-1 {
+			notes = appendNote(notes, "current")
+		}
+
+		deployed := units.HumanDuration(
+			time.Since(time.Unix(deployment.Deployed, 0))) + " ago"
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", deployment.Version, deployed,
+			deployment.DeployedBy, source, notes)
+	}
+}
+
+func appendNote(notes, note string) string {
+	if notes == "" {
+		return note
+	}
+	return notes + ", " + note
+}
+
+// diffVersions returns the diff between the blueprints deployed as versions
+// `from` and `to`. If `to` is zero, `from` is compared to the current
+// deployment.
+func diffVersions(history []pb.Deployment, from, to int64) (string, error) {
+	if len(history) == 0 {
+		return "", errors.New("nothing has been deployed")
+	}
+
+	if to == 0 {
+		to = history[len(history)-1].Version
+	}
+
+	fromDeployment, err := findDeployment(history, from)
+	if err != nil {
+		return "", err
+	}
+
+	toDeployment, err := findDeployment(history, to)
+	if err != nil {
+		return "", err
+	}
+
+	return diffBlueprints(fromDeployment.Blueprint, toDeployment.Blueprint,
+		fmt.Sprintf("Version %d", from), fmt.Sprintf("Version %d", to))
+}
+
+func findDeployment(history []pb.Deployment, version int64) (pb.Deployment, error) {
+	for _, deployment := range history {
+		if deployment.Version == version {
+			return deployment, nil
+		}
+	}
+	return pb.Deployment{}, fmt.Errorf("unknown version %d", version)
+}
+
+func parseVersion(str string) (int64, error) {
+	version, err := strconv.ParseInt(str, 10, 64)
+	if err != nil || version <= 0 {
+		return 0, fmt.Errorf("malformed version %q", str)
+	}
+	return version, nil
+}
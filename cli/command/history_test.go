@@ -0,0 +1,117 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	clientMock "github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/blueprint"
+)
+
+func TestHistoryParse(t *testing.T) {
+	t.Parallel()
+
+	checkHistoryParse(t, nil, History{}, "")
+	checkHistoryParse(t, []string{"diff", "1"}, History{diff: true, from: 1}, "")
+	checkHistoryParse(t, []string{"diff", "1", "3"},
+		History{diff: true, from: 1, to: 3}, "")
+
+	checkHistoryParse(t, []string{"show"}, History{}, `unknown action "show"`)
+	checkHistoryParse(t, []string{"diff"}, History{},
+		"diff requires one or two versions")
+	checkHistoryParse(t, []string{"diff", "0"}, History{},
+		`malformed version "0"`)
+	checkHistoryParse(t, []string{"diff", "1", "two"}, History{},
+		`malformed version "two"`)
+}
+
+func checkHistoryParse(t *testing.T, args []string, exp History, expErr string) {
+	cmd := NewHistoryCommand()
+	err := parseHelper(cmd, args)
+	if expErr != "" {
+		assert.EqualError(t, err, expErr)
+		return
+	}
+
+	assert.NoError(t, err)
+	cmd.connectionHelper = connectionHelper{}
+	assert.Equal(t, exp, *cmd)
+}
+
+func TestPrintHistory(t *testing.T) {
+	t.Parallel()
+
+	deployed := time.Now().Add(-time.Hour).Unix()
+	history := []pb.Deployment{{
+		Version:    1,
+		Deployed:   deployed,
+		DeployedBy: "alice",
+		SourceHash: "0123456789abcdef",
+	}, {
+		Version:    2,
+		Deployed:   deployed,
+		DeployedBy: "bob",
+	}, {
+		Version:    3,
+		Deployed:   deployed,
+		DeployedBy: "alice",
+		SourceHash: "0123456789abcdef",
+		RollbackOf: 1,
+	}}
+
+	var out bytes.Buffer
+	printHistory(&out, history)
+	assert.Equal(t,
+		"VERSION    DEPLOYED             BY       SOURCE          NOTES\n"+
+			"3          About an hour ago    alice    0123456789ab    "+
+			"rollback to 1, current\n"+
+			"2          About an hour ago    bob                      \n"+
+			"1          About an hour ago    alice    0123456789ab    \n",
+		out.String())
+}
+
+func TestDiffVersions(t *testing.T) {
+	t.Parallel()
+
+	history := []pb.Deployment{
+		{Version: 1, Blueprint: blueprint.Blueprint{Namespace: "a"}.String()},
+		{Version: 2, Blueprint: blueprint.Blueprint{Namespace: "b"}.String()},
+		{Version: 3, Blueprint: blueprint.Blueprint{Namespace: "b"}.String()},
+	}
+
+	diff, err := diffVersions(history, 2, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, diff)
+
+	diff, err = diffVersions(history, 1, 2)
+	assert.NoError(t, err)
+	assert.Contains(t, diff, "--- Version 1\n+++ Version 2\n")
+	assert.Contains(t, diff, "-\t\"Namespace\": \"a\"\n")
+	assert.Contains(t, diff, "+\t\"Namespace\": \"b\"\n")
+
+	_, err = diffVersions(history, 4, 0)
+	assert.EqualError(t, err, "unknown version 4")
+
+	_, err = diffVersions(nil, 1, 0)
+	assert.EqualError(t, err, "nothing has been deployed")
+}
+
+func TestHistoryRun(t *testing.T) {
+	t.Parallel()
+
+	c := &clientMock.Client{}
+	c.On("DeploymentHistory").Return(nil, assert.AnError).Once()
+
+	cmd := NewHistoryCommand()
+	cmd.client = c
+	assert.Equal(t, 1, cmd.Run())
+
+	c.On("DeploymentHistory").Return([]pb.Deployment{{Version: 1}}, nil)
+	cmd.diff = true
+	cmd.from = 2
+	assert.Equal(t, 1, cmd.Run())
+}
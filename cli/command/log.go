@@ -1,14 +1,23 @@
 package command
 
 import (
+	"bytes"
 	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/fatih/color"
+
+	"github.com/kelda/kelda/api"
 	apiUtil "github.com/kelda/kelda/api/util"
 	"github.com/kelda/kelda/cli/ssh"
 	"github.com/kelda/kelda/db"
-	"github.com/kelda/kelda/minion/supervisor"
 	"github.com/kelda/kelda/util"
 
 	log "github.com/sirupsen/logrus"
@@ -18,8 +27,13 @@ import (
 type Log struct {
 	privateKey string
 	shouldTail bool
+	since      time.Time
+	tail       int
+	timestamps bool
+	previous   bool
+	label      string
 
-	target string
+	targets []string
 
 	sshGetter ssh.Getter
 
@@ -31,12 +45,21 @@ func NewLogCommand() *Log {
 	return &Log{sshGetter: ssh.New}
 }
 
-var logCommands = `kelda logs [OPTIONS] ID`
-var logExplanation = `Fetch the logs of a container or machine minion. Either a container
-or machine ID must be supplied.
+var logCommands = `kelda logs [OPTIONS] ID...
+kelda logs [OPTIONS] -l LABEL`
+var logExplanation = `Fetch the logs of containers or a machine minion.
+
+Containers can be selected by their IDs or hostnames, by hostname globs such as
+'web-*', or with -l by the name of a load balancer that they belong to. When
+the logs of several containers are fetched, their lines are interleaved and
+prefixed with the containers' hostnames.
+
+To follow the logs of the containers behind the web load balancer:
+kelda logs -f -l web
 
-To get the logs of container 8879fd2dbcee with a specific private key:
-kelda logs -i ~/.ssh/kelda 8879fd2dbcee
+To get the last hour of logs from the instance of container 8879fd2dbcee that
+crashed:
+kelda logs -previous -since 1h 8879fd2dbcee
 
 To follow the logs of the minion on machine 09ed35808a0b:
 kelda logs -f 09ed35808a0b`
@@ -46,8 +69,18 @@ func (lCmd *Log) InstallFlags(flags *flag.FlagSet) {
 	lCmd.connectionHelper.InstallFlags(flags)
 
 	flags.StringVar(&lCmd.privateKey, "i", "",
-		"path to the private key to use when connecting to the host")
+		"path to the private key to use when connecting to a machine")
 	flags.BoolVar(&lCmd.shouldTail, "f", false, "follow log output")
+	flags.Var(sinceFlag{&lCmd.since}, "since", "only show logs written "+
+		"after a relative duration such as 10m, or an RFC3339 timestamp")
+	flags.IntVar(&lCmd.tail, "tail", 0,
+		"only show this many lines from the end of the logs")
+	flags.BoolVar(&lCmd.timestamps, "timestamps", false,
+		"show when each line was written")
+	flags.BoolVar(&lCmd.previous, "previous", false,
+		"show the logs of the previous instance of a restarted container")
+	flags.StringVar(&lCmd.label, "l", "",
+		"fetch the logs of the containers in this load balancer")
 
 	flags.Usage = func() {
 		util.PrintUsageString(logCommands, logExplanation, flags)
@@ -56,62 +89,130 @@ func (lCmd *Log) InstallFlags(flags *flag.FlagSet) {
 
 // Parse parses the command line arguments for the `logs` command.
 func (lCmd *Log) Parse(args []string) error {
-	if len(args) == 0 {
+	if len(args) == 0 && lCmd.label == "" {
 		return errors.New("must specify a target container or machine")
 	}
 
-	lCmd.target = args[0]
+	lCmd.targets = args
 	return nil
 }
 
-// Run finds the target container or machine minion and outputs logs.
+// Run finds the target containers or machine minion and outputs logs.
 func (lCmd *Log) Run() int {
-	i, err := apiUtil.FuzzyLookup(lCmd.client, lCmd.target)
+	containers, machine, err := lCmd.resolveTargets()
 	if err != nil {
-		log.WithError(err).Errorf("Failed to lookup %s", lCmd.target)
+		log.WithError(err).Error("Failed to lookup targets")
+		return 1
+	}
+
+	if machine != nil {
+		return lCmd.machineLogs(*machine)
+	}
+
+	if len(containers) == 1 && containers[0].PodName == "" {
+		log.Error("Container not yet running")
+		return 1
+	}
+
+	if err := lCmd.containerLogs(containers, os.Stdout, os.Stderr); err != nil {
+		log.WithError(err).Error("Failed to fetch logs")
 		return 1
 	}
+	return 0
+}
 
-	var host string
-	var cmd []string
-	switch t := i.(type) {
-	case db.Machine:
-		host = t.PublicIP
-		cmd = []string{"docker", "logs"}
-		if lCmd.shouldTail {
-			cmd = append(cmd, "--follow")
+// resolveTargets returns the containers selected by the command's targets, or
+// the machine if a single machine was targeted.
+func (lCmd *Log) resolveTargets() ([]db.Container, *db.Machine, error) {
+	var containers []db.Container
+	if lCmd.label != "" {
+		matches, err := lCmd.client.SelectContainers(
+			api.Query{Label: lCmd.label})
+		if err != nil {
+			return nil, nil, err
 		}
-		cmd = append(cmd, "minion")
-	case db.Container:
-		if t.PodName == "" {
-			log.Error("Container not yet running")
-			return 1
+
+		if len(matches) == 0 {
+			return nil, nil, fmt.Errorf("no containers in load balancer %q",
+				lCmd.label)
 		}
+		containers = append(containers, matches...)
+	}
 
-		machines, err := lCmd.client.QueryMachines()
-		if err != nil {
-			log.WithError(err).Error("Failed to query machines")
-			return 1
+	for _, target := range lCmd.targets {
+		if strings.ContainsAny(target, "*?[") {
+			matches, err := lCmd.client.SelectContainers(
+				api.Query{Hostname: target})
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if len(matches) == 0 {
+				return nil, nil, fmt.Errorf("no containers match %q",
+					target)
+			}
+			containers = append(containers, matches...)
+			continue
 		}
 
-		host, err = getLeaderIP(machines, lCmd.creds)
+		i, err := apiUtil.FuzzyLookup(lCmd.client, target)
 		if err != nil {
-			log.WithError(err).Error("Failed to find leader machine")
-			return 1
+			return nil, nil, err
 		}
 
-		cmd = []string{
-			"docker", "exec", supervisor.KubeAPIServerName, "kubectl", "logs",
+		switch t := i.(type) {
+		case db.Machine:
+			if len(lCmd.targets) != 1 || lCmd.label != "" {
+				return nil, nil, errors.New("the logs of a machine " +
+					"can't be combined with other logs")
+			}
+			return nil, &t, nil
+		case db.Container:
+			containers = append(containers, t)
+		default:
+			panic("Not Reached")
 		}
-		if lCmd.shouldTail {
-			cmd = append(cmd, "--follow")
-		}
-		cmd = append(cmd, t.PodName)
-	default:
-		panic("Not Reached")
 	}
 
-	sshClient, err := lCmd.sshGetter(host, lCmd.privateKey)
+	// Remove containers that were selected more than once.
+	byHostname := map[string]db.Container{}
+	for _, dbc := range containers {
+		byHostname[dbc.Hostname] = dbc
+	}
+
+	containers = nil
+	for _, dbc := range byHostname {
+		containers = append(containers, dbc)
+	}
+	sort.Slice(containers, func(i, j int) bool {
+		return containers[i].Hostname < containers[j].Hostname
+	})
+	return containers, nil, nil
+}
+
+// machineLogs fetches the logs of the minion on the given machine over SSH.
+func (lCmd *Log) machineLogs(machine db.Machine) int {
+	if lCmd.previous {
+		log.Error("The -previous flag is only supported for containers")
+		return 1
+	}
+
+	cmd := []string{"docker", "logs"}
+	if lCmd.shouldTail {
+		cmd = append(cmd, "--follow")
+	}
+	if !lCmd.since.IsZero() {
+		cmd = append(cmd, fmt.Sprintf("--since=%d", lCmd.since.Unix()))
+	}
+	if lCmd.tail > 0 {
+		cmd = append(cmd, fmt.Sprintf("--tail=%d", lCmd.tail))
+	}
+	if lCmd.timestamps {
+		cmd = append(cmd, "--timestamps")
+	}
+	cmd = append(cmd, "minion")
+
+	sshClient, err := lCmd.sshGetter(machine.PublicIP, lCmd.privateKey)
 	if err != nil {
 		log.WithError(err).Info("Error opening SSH connection")
 		return 1
@@ -125,3 +226,161 @@ func (lCmd *Log) Run() int {
 
 	return 0
 }
+
+// The colors used to distinguish the hostname prefixes of different containers.
+var prefixColors = []*color.Color{
+	color.New(color.FgCyan),
+	color.New(color.FgGreen),
+	color.New(color.FgYellow),
+	color.New(color.FgMagenta),
+	color.New(color.FgBlue),
+	color.New(color.FgRed),
+}
+
+// containerLogs writes the logs of the given containers to `stdout` and
+// `stderr`. If there are several containers, their logs are fetched in
+// parallel, and each line is prefixed with the hostname of the container that
+// wrote it.
+func (lCmd *Log) containerLogs(containers []db.Container, stdout,
+	stderr io.Writer) error {
+	if len(containers) == 1 {
+		return lCmd.client.Logs(lCmd.logsOptions(containers[0].Hostname,
+			stdout, stderr))
+	}
+
+	var width int
+	for _, dbc := range containers {
+		if len(dbc.Hostname) > width {
+			width = len(dbc.Hostname)
+		}
+	}
+
+	var wg sync.WaitGroup
+	var outputLock sync.Mutex
+	var failed []string
+	for i, dbc := range containers {
+		if dbc.PodName == "" {
+			log.WithField("container", dbc.Hostname).Warn(
+				"Container not yet running")
+			continue
+		}
+
+		prefix := prefixColors[i%len(prefixColors)].Sprintf("%-*s | ",
+			width, dbc.Hostname)
+		containerStdout := &prefixWriter{prefix: prefix, out: stdout,
+			lock: &outputLock}
+		containerStderr := &prefixWriter{prefix: prefix, out: stderr,
+			lock: &outputLock}
+
+		wg.Add(1)
+		go func(hostname string) {
+			defer wg.Done()
+			err := lCmd.client.Logs(lCmd.logsOptions(hostname,
+				containerStdout, containerStderr))
+			containerStdout.Flush()
+			containerStderr.Flush()
+
+			if err != nil {
+				log.WithError(err).WithField("container", hostname).Error(
+					"Failed to fetch logs")
+
+				outputLock.Lock()
+				failed = append(failed, hostname)
+				outputLock.Unlock()
+			}
+		}(dbc.Hostname)
+	}
+	wg.Wait()
+
+	if len(failed) != 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("failed to fetch the logs of %s",
+			strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func (lCmd *Log) logsOptions(hostname string, stdout, stderr io.Writer) api.LogsOptions {
+	return api.LogsOptions{
+		Container:  hostname,
+		Follow:     lCmd.shouldTail,
+		Since:      lCmd.since,
+		Tail:       lCmd.tail,
+		Timestamps: lCmd.timestamps,
+		Previous:   lCmd.previous,
+		Stdout:     stdout,
+		Stderr:     stderr,
+	}
+}
+
+// prefixWriter prefixes each line written to it before writing it to `out`.
+// Only complete lines are written, so that lines written by different
+// prefixWriters sharing `lock` aren't interleaved.
+type prefixWriter struct {
+	prefix string
+	out    io.Writer
+	lock   *sync.Mutex
+
+	partial []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.partial = append(w.partial, p...)
+	for {
+		end := bytes.IndexByte(w.partial, '\n')
+		if end < 0 {
+			return len(p), nil
+		}
+
+		if err := w.writeLine(w.partial[:end+1]); err != nil {
+			return 0, err
+		}
+		w.partial = w.partial[end+1:]
+	}
+}
+
+// Flush writes any incomplete line.
+func (w *prefixWriter) Flush() error {
+	if len(w.partial) == 0 {
+		return nil
+	}
+
+	err := w.writeLine(append(w.partial, '\n'))
+	w.partial = nil
+	return err
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+	return err
+}
+
+// sinceFlag parses a relative duration, such as 10m, or an RFC3339 timestamp
+// into a time.
+type sinceFlag struct {
+	since *time.Time
+}
+
+func (f sinceFlag) String() string {
+	if f.since == nil || f.since.IsZero() {
+		return ""
+	}
+	return f.since.Format(time.RFC3339)
+}
+
+func (f sinceFlag) Set(value string) error {
+	if duration, err := time.ParseDuration(value); err == nil {
+		*f.since = time.Now().Add(-duration)
+		return nil
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return fmt.Errorf("malformed time %q: must be a duration such as "+
+			"10m, or an RFC3339 timestamp", value)
+	}
+	*f.since = since
+	return nil
+}
@@ -1,16 +1,20 @@
 package command
 
 import (
+	"bytes"
 	"errors"
-	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/api/client/mocks"
 	"github.com/kelda/kelda/cli/ssh"
 	mockSSH "github.com/kelda/kelda/cli/ssh/mocks"
-	"github.com/kelda/kelda/connection"
 	"github.com/kelda/kelda/db"
 )
 
@@ -19,107 +23,206 @@ func checkLogParsing(t *testing.T, args []string, exp Log, expErr error) {
 	err := parseHelper(logsCmd, args)
 
 	assert.Equal(t, expErr, err)
-	assert.Equal(t, exp.target, logsCmd.target)
+	assert.Equal(t, exp.targets, logsCmd.targets)
 	assert.Equal(t, exp.privateKey, logsCmd.privateKey)
 	assert.Equal(t, exp.shouldTail, logsCmd.shouldTail)
+	assert.Equal(t, exp.since, logsCmd.since)
+	assert.Equal(t, exp.tail, logsCmd.tail)
+	assert.Equal(t, exp.timestamps, logsCmd.timestamps)
+	assert.Equal(t, exp.previous, logsCmd.previous)
+	assert.Equal(t, exp.label, logsCmd.label)
 }
 
 func TestLogFlags(t *testing.T) {
 	t.Parallel()
 
 	checkLogParsing(t, []string{"1"}, Log{
-		target: "1",
+		targets: []string{"1"},
 	}, nil)
 	checkLogParsing(t, []string{"-i", "key", "1"}, Log{
-		target:     "1",
+		targets:    []string{"1"},
 		privateKey: "key",
 	}, nil)
 	checkLogParsing(t, []string{"-f", "1"}, Log{
-		target:     "1",
+		targets:    []string{"1"},
 		shouldTail: true,
 	}, nil)
+	checkLogParsing(t, []string{"-since", "2018-01-02T15:04:05Z", "-tail", "10",
+		"-timestamps", "-previous", "1", "web-*"}, Log{
+		targets:    []string{"1", "web-*"},
+		since:      time.Date(2018, 1, 2, 15, 4, 5, 0, time.UTC),
+		tail:       10,
+		timestamps: true,
+		previous:   true,
+	}, nil)
+	checkLogParsing(t, []string{"-l", "web"}, Log{
+		targets: []string{},
+		label:   "web",
+	}, nil)
 	checkLogParsing(t, []string{}, Log{},
 		errors.New("must specify a target container or machine"))
-}
 
-type logTest struct {
-	cmd           Log
-	expHost       string
-	expSSHCommand string
-}
+	logsCmd := NewLogCommand()
+	assert.NoError(t, parseHelper(logsCmd, []string{"-since", "1h", "1"}))
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), logsCmd.since,
+		time.Minute)
 
-func TestLog(t *testing.T) {
-	targetContainer := "1"
-	targetMachine := "a"
-	leaderHost := "leader"
-	targetContainerPodName := "podName"
+	var since time.Time
+	err := sinceFlag{&since}.Set("yesterday")
+	assert.EqualError(t, err, `malformed time "yesterday": must be a `+
+		`duration such as 10m, or an RFC3339 timestamp`)
+}
 
-	getLeaderIP = func(_ []db.Machine, _ connection.Credentials) (string, error) {
-		return leaderHost, nil
-	}
+func TestMachineLog(t *testing.T) {
+	mockClient := new(mocks.Client)
+	mockClient.On("QueryMachines").Return([]db.Machine{{
+		CloudID:  "a",
+		PublicIP: "machine",
+	}}, nil)
+	mockClient.On("QueryContainers").Return(nil, nil)
 
-	tests := []logTest{
-		// Target container.
+	tests := []struct {
+		cmd           Log
+		expSSHCommand string
+	}{
 		{
-			cmd:     Log{target: targetContainer},
-			expHost: leaderHost,
-			expSSHCommand: fmt.Sprintf(
-				"docker exec kube-apiserver kubectl logs %s",
-				targetContainerPodName),
-		},
-		// Target machine.
-		{
-			cmd:           Log{target: targetMachine},
-			expHost:       "machine",
+			cmd:           Log{targets: []string{"a"}},
 			expSSHCommand: "docker logs minion",
 		},
-		// Tail flag
 		{
 			cmd: Log{
-				target:     targetContainer,
+				targets:    []string{"a"},
 				shouldTail: true,
+				since:      time.Unix(100, 0),
+				tail:       10,
+				timestamps: true,
 			},
-			expHost: leaderHost,
-			expSSHCommand: fmt.Sprintf(
-				"docker exec kube-apiserver kubectl logs --follow %s",
-				targetContainerPodName),
+			expSSHCommand: "docker logs --follow --since=100 --tail=10 " +
+				"--timestamps minion",
 		},
 	}
 
-	mockLocalClient := new(mocks.Client)
-	mockLocalClient.On("QueryMachines").Return([]db.Machine{{
-		CloudID:  targetMachine,
-		PublicIP: "machine",
-	}, {
-		PublicIP:  "container",
-		PrivateIP: "containerPriv",
-	}}, nil)
-	mockLocalClient.On("QueryContainers").Return([]db.Container{{
-		BlueprintID: targetContainer,
-		PodName:     targetContainerPodName,
-		Minion:      "containerPriv",
-	}}, nil)
-	mockLocalClient.On("Close").Return(nil)
-
 	for _, test := range tests {
 		testCmd := test.cmd
 
 		mockSSHClient := new(mockSSH.Client)
 		testCmd.sshGetter = func(host, key string) (ssh.Client, error) {
-			assert.Equal(t, test.expHost, host)
+			assert.Equal(t, "machine", host)
 			assert.Equal(t, "key", key)
 			return mockSSHClient, nil
 		}
 		testCmd.privateKey = "key"
-		testCmd.connectionHelper = connectionHelper{client: mockLocalClient}
+		testCmd.connectionHelper = connectionHelper{client: mockClient}
 
 		mockSSHClient.On("Run", false, test.expSSHCommand).Return(nil)
 		mockSSHClient.On("Close").Return(nil)
 
-		testCmd.Run()
-
+		assert.Equal(t, 0, testCmd.Run())
 		mockSSHClient.AssertExpectations(t)
 	}
+
+	testCmd := Log{targets: []string{"a"}, previous: true}
+	testCmd.connectionHelper = connectionHelper{client: mockClient}
+	assert.Equal(t, 1, testCmd.Run())
+}
+
+func TestContainerLog(t *testing.T) {
+	mockClient := new(mocks.Client)
+	mockClient.On("QueryMachines").Return(nil, nil)
+	mockClient.On("QueryContainers").Return([]db.Container{{
+		BlueprintID: "1",
+		Hostname:    "foo",
+		PodName:     "pod",
+	}}, nil)
+	mockClient.On("Logs", mock.MatchedBy(func(opts api.LogsOptions) bool {
+		return opts.Container == "foo" && opts.Follow && opts.Tail == 10
+	})).Return(nil)
+
+	testCmd := Log{targets: []string{"1"}, shouldTail: true, tail: 10}
+	testCmd.connectionHelper = connectionHelper{client: mockClient}
+	assert.Equal(t, 0, testCmd.Run())
+	mockClient.AssertExpectations(t)
+}
+
+func TestMultipleContainerLogs(t *testing.T) {
+	t.Parallel()
+
+	mockClient := new(mocks.Client)
+	mockClient.On("QueryMachines").Return(nil, nil)
+	mockClient.On("QueryContainers").Return([]db.Container{
+		{BlueprintID: "1", Hostname: "db", PodName: "pod"},
+	}, nil)
+	mockClient.On("SelectContainers", api.Query{Hostname: "web-*"}).Return(
+		[]db.Container{
+			{Hostname: "web-1", PodName: "pod"},
+			{Hostname: "web-2", PodName: "pod"},
+		}, nil)
+	mockClient.On("SelectContainers", api.Query{Label: "web"}).Return(
+		[]db.Container{{Hostname: "web-2", PodName: "pod"}}, nil)
+	mockClient.On("SelectContainers", api.Query{Label: "empty"}).Return(
+		nil, nil)
+
+	writeLogs := func(args mock.Arguments) {
+		opts := args.Get(0).(api.LogsOptions)
+		opts.Stdout.Write([]byte("out1\nou"))
+		opts.Stdout.Write([]byte("t2\n"))
+		opts.Stderr.Write([]byte("partial"))
+	}
+	mockClient.On("Logs", mock.MatchedBy(func(opts api.LogsOptions) bool {
+		return opts.Container != "web-2"
+	})).Run(writeLogs).Return(nil)
+	mockClient.On("Logs", mock.MatchedBy(func(opts api.LogsOptions) bool {
+		return opts.Container == "web-2"
+	})).Run(writeLogs).Return(errors.New("error"))
+
+	testCmd := Log{targets: []string{"1", "web-*"}, label: "web"}
+	testCmd.connectionHelper = connectionHelper{client: mockClient}
+
+	containers, machine, err := testCmd.resolveTargets()
+	assert.NoError(t, err)
+	assert.Nil(t, machine)
+	assert.Equal(t, []db.Container{
+		{BlueprintID: "1", Hostname: "db", PodName: "pod"},
+		{Hostname: "web-1", PodName: "pod"},
+		{Hostname: "web-2", PodName: "pod"},
+	}, containers)
+
+	var stdout, stderr bytes.Buffer
+	err = testCmd.containerLogs(containers, &stdout, &stderr)
+	assert.EqualError(t, err, "failed to fetch the logs of web-2")
+
+	// The prefixes are colored unless color is disabled.
+	prefix := func(i int, hostname string) string {
+		return prefixColors[i].Sprintf("%-5s | ", hostname)
+	}
+	expStdout := []string{
+		prefix(0, "db") + "out1",
+		prefix(0, "db") + "out2",
+		prefix(1, "web-1") + "out1",
+		prefix(1, "web-1") + "out2",
+		prefix(2, "web-2") + "out1",
+		prefix(2, "web-2") + "out2",
+	}
+	expStderr := []string{
+		prefix(0, "db") + "partial",
+		prefix(1, "web-1") + "partial",
+		prefix(2, "web-2") + "partial",
+	}
+	sort.Strings(expStdout)
+	sort.Strings(expStderr)
+
+	stdoutLines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	sort.Strings(stdoutLines)
+	assert.Equal(t, expStdout, stdoutLines)
+
+	stderrLines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+	sort.Strings(stderrLines)
+	assert.Equal(t, expStderr, stderrLines)
+
+	testCmd = Log{label: "empty"}
+	testCmd.connectionHelper = connectionHelper{client: mockClient}
+	_, _, err = testCmd.resolveTargets()
+	assert.EqualError(t, err, `no containers in load balancer "empty"`)
 }
 
 func TestLogAmbiguousID(t *testing.T) {
@@ -134,7 +237,7 @@ func TestLogAmbiguousID(t *testing.T) {
 
 	testCmd := Log{
 		connectionHelper: connectionHelper{client: mockClient},
-		target:           "foo",
+		targets:          []string{"foo"},
 	}
 	assert.Equal(t, 1, testCmd.Run())
 }
@@ -151,7 +254,7 @@ func TestLogNoMatch(t *testing.T) {
 
 	testCmd := Log{
 		connectionHelper: connectionHelper{client: mockClient},
-		target:           "bar",
+		targets:          []string{"bar"},
 	}
 	assert.Equal(t, 1, testCmd.Run())
 }
@@ -166,7 +269,7 @@ func TestLogScheduledContainer(t *testing.T) {
 
 	testCmd := Log{
 		connectionHelper: connectionHelper{client: mockClient},
-		target:           "foo",
+		targets:          []string{"foo"},
 	}
 	assert.Equal(t, 1, testCmd.Run())
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion"
 	"github.com/kelda/kelda/util"
@@ -20,6 +21,7 @@ import (
 type Minion struct {
 	role                            string
 	inboundPubIntf, outboundPubIntf string
+	metricsAddr                     string
 
 	connectionFlags
 }
@@ -43,6 +45,9 @@ func (mCmd *Minion) InstallFlags(flags *flag.FlagSet) {
 		"the interface on which to allow inbound traffic")
 	flags.StringVar(&mCmd.outboundPubIntf, "outbound-pub-intf", "",
 		"the interface on which to allow outbound traffic")
+	flags.StringVar(&mCmd.metricsAddr, "metrics-address",
+		fmt.Sprintf(":%d", api.DefaultMetricsPort), "the address to serve "+
+			"Prometheus metrics on. Metrics are disabled if empty.")
 
 	flags.Usage = func() {
 		util.PrintUsageString(minionCommands, minionExplanation, flags)
@@ -84,6 +89,7 @@ func (mCmd *Minion) run() error {
 		return errors.New("no or improper role specified")
 	}
 
-	minion.Run(role, mCmd.inboundPubIntf, mCmd.outboundPubIntf)
+	minion.Run(role, mCmd.inboundPubIntf, mCmd.outboundPubIntf,
+		mCmd.metricsAddr)
 	return nil
 }
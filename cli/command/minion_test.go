@@ -23,6 +23,13 @@ func TestMinionFlags(t *testing.T) {
 	assert.Equal(t, expRole, cmd.role)
 	assert.Equal(t, expInboundPubIntf, cmd.inboundPubIntf)
 	assert.Equal(t, expOutboundPubIntf, cmd.outboundPubIntf)
+	assert.Equal(t, ":9002", cmd.metricsAddr)
+
+	// Metrics are disabled with an empty address.
+	cmd = NewMinionCommand()
+	err = parseHelper(cmd, []string{"-metrics-address", "", expRole})
+	assert.NoError(t, err)
+	assert.Empty(t, cmd.metricsAddr)
 
 	// Pass the minion role as an argument instead of a flag.
 	expRole = "Master"
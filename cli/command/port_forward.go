@@ -0,0 +1,191 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kelda/kelda/api"
+	apiUtil "github.com/kelda/kelda/api/util"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/util"
+)
+
+// PortForward contains the options for forwarding local ports to containers.
+type PortForward struct {
+	address string
+	target  string
+	ports   []portMapping
+
+	connectionHelper
+}
+
+// portMapping maps a local port to a port in the target container.
+type portMapping struct {
+	local, remote int
+}
+
+// NewPortForwardCommand creates a new PortForward command instance.
+func NewPortForwardCommand() *PortForward {
+	return &PortForward{}
+}
+
+var portForwardCommands = "kelda port-forward [OPTIONS] TARGET " +
+	"[LOCAL_PORT:]REMOTE_PORT..."
+var portForwardExplanation = `Forward local ports to a container or load balancer.
+
+Connections to each local port are tunneled through the Kelda API to the
+remote port on the target, which can be a container or a load balancer. This
+allows connecting to ports that aren't public without changing the blueprint.
+Connections to a load balancer are tunneled to a randomly chosen container in
+the load balancer. If LOCAL_PORT is omitted, the same port as REMOTE_PORT is
+used. If it's 0, a free port is chosen.
+
+To connect to the admin interface on port 8080 of container 8879fd2dbcee at
+localhost:9000:
+kelda port-forward 8879fd2dbcee 9000:8080
+
+To connect to the postgres load balancer on localhost:5432:
+kelda port-forward postgres 5432`
+
+// InstallFlags sets up parsing for command line flags.
+func (pfCmd *PortForward) InstallFlags(flags *flag.FlagSet) {
+	pfCmd.connectionHelper.InstallFlags(flags)
+	flags.StringVar(&pfCmd.address, "address", "127.0.0.1",
+		"the local address to listen on")
+
+	flags.Usage = func() {
+		util.PrintUsageString(portForwardCommands, portForwardExplanation,
+			flags)
+	}
+}
+
+// Parse parses the command line arguments for the port-forward command.
+func (pfCmd *PortForward) Parse(args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify a target container or load balancer")
+	}
+
+	if len(args) == 1 {
+		return errors.New("must specify at least one port")
+	}
+
+	pfCmd.target = args[0]
+	pfCmd.ports = nil
+	for _, arg := range args[1:] {
+		mapping, err := parsePortMapping(arg)
+		if err != nil {
+			return err
+		}
+		pfCmd.ports = append(pfCmd.ports, mapping)
+	}
+	return nil
+}
+
+// parsePortMapping parses a port mapping of the form [LOCAL_PORT:]REMOTE_PORT.
+func parsePortMapping(arg string) (portMapping, error) {
+	localStr, remoteStr := arg, arg
+	if i := strings.Index(arg, ":"); i >= 0 {
+		localStr, remoteStr = arg[:i], arg[i+1:]
+	}
+
+	local, localErr := strconv.Atoi(localStr)
+	remote, remoteErr := strconv.Atoi(remoteStr)
+	if localErr != nil || remoteErr != nil || local < 0 || local > 65535 ||
+		remote <= 0 || remote > 65535 {
+		return portMapping{}, fmt.Errorf("malformed port mapping %q: must "+
+			"be of the form [LOCAL_PORT:]REMOTE_PORT", arg)
+	}
+	return portMapping{local: local, remote: remote}, nil
+}
+
+// Run listens on the local ports, and forwards connections to the target until
+// the command is interrupted.
+func (pfCmd *PortForward) Run() int {
+	target, err := pfCmd.resolveTarget()
+	if err != nil {
+		log.WithError(err).Errorf("Failed to lookup %s", pfCmd.target)
+		return 1
+	}
+
+	var listeners []net.Listener
+	for _, mapping := range pfCmd.ports {
+		listener, err := net.Listen("tcp", net.JoinHostPort(pfCmd.address,
+			strconv.Itoa(mapping.local)))
+		if err != nil {
+			log.WithError(err).Error("Failed to listen on local port")
+			return 1
+		}
+		defer listener.Close()
+		listeners = append(listeners, listener)
+
+		fmt.Printf("Forwarding %s to %s:%d\n", listener.Addr(), target,
+			mapping.remote)
+	}
+
+	errs := make(chan error, len(listeners))
+	for i, listener := range listeners {
+		go func(listener net.Listener, port int) {
+			errs <- pfCmd.serve(listener, target, port)
+		}(listener, pfCmd.ports[i].remote)
+	}
+
+	log.WithError(<-errs).Error("Stopped forwarding connections")
+	return 1
+}
+
+// resolveTarget returns the name of the targeted load balancer, or the
+// hostname of the targeted container.
+func (pfCmd *PortForward) resolveTarget() (string, error) {
+	loadBalancers, err := pfCmd.client.SelectLoadBalancers(
+		api.Query{Label: pfCmd.target})
+	if err != nil {
+		return "", err
+	}
+
+	if len(loadBalancers) != 0 {
+		return loadBalancers[0].Name, nil
+	}
+
+	i, err := apiUtil.FuzzyLookup(pfCmd.client, pfCmd.target)
+	if err != nil {
+		return "", err
+	}
+
+	dbc, ok := i.(db.Container)
+	if !ok {
+		return "", errors.New("ports can only be forwarded to containers " +
+			"and load balancers")
+	}
+	return dbc.Hostname, nil
+}
+
+// serve forwards the connections accepted by `listener` to `port` on `target`
+// until accepting a connection fails.
+func (pfCmd *PortForward) serve(listener net.Listener, target string,
+	port int) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		go func() {
+			defer conn.Close()
+			err := pfCmd.client.PortForward(api.PortForwardOptions{
+				Target: target,
+				Port:   port,
+				Conn:   conn,
+			})
+			if err != nil {
+				log.WithError(err).WithField("target", target).Warn(
+					"Failed to forward connection")
+			}
+		}()
+	}
+}
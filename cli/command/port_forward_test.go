@@ -0,0 +1,124 @@
+package command
+
+import (
+	"io/ioutil"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/db"
+)
+
+func TestPortForwardParse(t *testing.T) {
+	t.Parallel()
+
+	checkPortForwardParse(t, []string{"foo", "80"}, PortForward{
+		address: "127.0.0.1",
+		target:  "foo",
+		ports:   []portMapping{{80, 80}},
+	}, "")
+	checkPortForwardParse(t, []string{"-address", "0.0.0.0", "foo", "9000:8080",
+		"0:443"}, PortForward{
+		address: "0.0.0.0",
+		target:  "foo",
+		ports:   []portMapping{{9000, 8080}, {0, 443}},
+	}, "")
+
+	checkPortForwardParse(t, nil, PortForward{},
+		"must specify a target container or load balancer")
+	checkPortForwardParse(t, []string{"foo"}, PortForward{},
+		"must specify at least one port")
+	for _, mapping := range []string{"http", "80:", ":80", "0", "80:0",
+		"1:2:3", "70000", "-1:80"} {
+		checkPortForwardParse(t, []string{"foo", mapping}, PortForward{},
+			`malformed port mapping "`+mapping+
+				`": must be of the form [LOCAL_PORT:]REMOTE_PORT`)
+	}
+}
+
+func checkPortForwardParse(t *testing.T, args []string, exp PortForward,
+	expErr string) {
+	cmd := NewPortForwardCommand()
+	err := parseHelper(cmd, args)
+	if expErr != "" {
+		assert.EqualError(t, err, expErr)
+		return
+	}
+
+	assert.NoError(t, err)
+	cmd.connectionHelper = connectionHelper{}
+	assert.Equal(t, exp, *cmd)
+}
+
+func TestPortForwardResolveTarget(t *testing.T) {
+	t.Parallel()
+
+	c := &mocks.Client{}
+	c.On("SelectLoadBalancers", api.Query{Label: "lb"}).Return(
+		[]db.LoadBalancer{{Name: "lb"}}, nil)
+	c.On("SelectLoadBalancers", mock.Anything).Return(nil, nil)
+	c.On("QueryMachines").Return([]db.Machine{{CloudID: "machine"}}, nil)
+	c.On("QueryContainers").Return([]db.Container{
+		{BlueprintID: "container", Hostname: "foo"},
+	}, nil)
+
+	cmd := PortForward{target: "lb"}
+	cmd.client = c
+	target, err := cmd.resolveTarget()
+	assert.NoError(t, err)
+	assert.Equal(t, "lb", target)
+
+	cmd.target = "cont"
+	target, err = cmd.resolveTarget()
+	assert.NoError(t, err)
+	assert.Equal(t, "foo", target)
+
+	cmd.target = "mach"
+	_, err = cmd.resolveTarget()
+	assert.EqualError(t, err,
+		"ports can only be forwarded to containers and load balancers")
+}
+
+func TestPortForwardServe(t *testing.T) {
+	t.Parallel()
+
+	c := &mocks.Client{}
+	c.On("PortForward", mock.MatchedBy(func(opts api.PortForwardOptions) bool {
+		return opts.Target == "foo" && opts.Port == 80
+	})).Run(func(args mock.Arguments) {
+		conn := args.Get(0).(api.PortForwardOptions).Conn
+		request, _ := ioutil.ReadAll(conn)
+		conn.Write(append([]byte("response to "), request...))
+	}).Return(nil)
+
+	cmd := PortForward{}
+	cmd.client = c
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	errs := make(chan error)
+	go func() {
+		errs <- cmd.serve(listener, "foo", 80)
+	}()
+
+	// Each connection is forwarded separately.
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		assert.NoError(t, err)
+
+		conn.Write([]byte("request"))
+		conn.(*net.TCPConn).CloseWrite()
+
+		response, err := ioutil.ReadAll(conn)
+		assert.NoError(t, err)
+		assert.Equal(t, "response to request", string(response))
+		conn.Close()
+	}
+
+	listener.Close()
+	assert.Error(t, <-errs)
+}
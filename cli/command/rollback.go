@@ -0,0 +1,126 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/util"
+)
+
+// Rollback contains the options for redeploying a previous blueprint.
+type Rollback struct {
+	// The version to roll back to. Zero means the version before the current
+	// deployment.
+	version int64
+	force   bool
+
+	connectionHelper
+}
+
+// NewRollbackCommand creates a new Rollback command instance.
+func NewRollbackCommand() *Rollback {
+	return &Rollback{}
+}
+
+var rollbackCommands = `kelda rollback [OPTIONS] [VERSION]`
+
+var rollbackExplanation = `Redeploy a blueprint from the deployment history.
+
+If no VERSION is given, the blueprint deployed before the current one is
+redeployed. Use ` + "`kelda history`" + ` to list the available versions. The
+rollback is recorded in the history as a new version, so it can itself be
+rolled back.
+
+Confirmation is required before rolling back. Confirmation can be skipped with
+the -f flag.`
+
+// InstallFlags sets up parsing for command line flags.
+func (rCmd *Rollback) InstallFlags(flags *flag.FlagSet) {
+	rCmd.connectionHelper.InstallFlags(flags)
+	flags.BoolVar(&rCmd.force, "f", false, "roll back without confirming changes")
+	flags.Usage = func() {
+		util.PrintUsageString(rollbackCommands, rollbackExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the rollback command.
+func (rCmd *Rollback) Parse(args []string) (err error) {
+	switch len(args) {
+	case 0:
+	case 1:
+		rCmd.version, err = parseVersion(args[0])
+	default:
+		err = errors.New("too many arguments")
+	}
+	return err
+}
+
+// Run redeploys the requested blueprint.
+func (rCmd *Rollback) Run() int {
+	version := rCmd.version
+	if !rCmd.force {
+		var confirmed bool
+		var err error
+		version, confirmed, err = rCmd.confirmRollback()
+		if err != nil {
+			log.WithError(err).Error("Failed to roll back")
+			return 1
+		}
+
+		if !confirmed {
+			fmt.Println("Rollback aborted by user.")
+			return 0
+		}
+	}
+
+	// The daemon resolves the requested version, so forced rollbacks don't
+	// need the history.
+	if err := rCmd.client.Rollback(version); err != nil {
+		log.WithError(err).Error("Failed to roll back")
+		return 1
+	}
+
+	fmt.Println("Rolling back. Check its status with `kelda show`.")
+	return 0
+}
+
+// confirmRollback shows the changes made by the rollback, and asks the user to
+// confirm them. It returns the version that was confirmed, so that the deployed
+// blueprint is the one the user saw even if someone deploys in the meantime.
+func (rCmd *Rollback) confirmRollback() (version int64, confirmed bool,
+	err error) {
+	history, err := rCmd.client.DeploymentHistory()
+	if err != nil {
+		return 0, false, fmt.Errorf("get deployment history: %s", err)
+	}
+
+	target, err := api.RollbackTarget(history, rCmd.version)
+	if err != nil {
+		return 0, false, err
+	}
+
+	current := history[len(history)-1]
+	diff, err := diffBlueprints(current.Blueprint, target.Blueprint,
+		"Current", fmt.Sprintf("Version %d", target.Version))
+	if err != nil {
+		return 0, false, fmt.Errorf("diff deployments: %s", err)
+	}
+
+	if diff == "" {
+		fmt.Println("No change.")
+	} else {
+		fmt.Println(colorizeDiff(diff))
+	}
+
+	confirmed, err = confirm(os.Stdin, fmt.Sprintf(
+		"Roll back to version %d?", target.Version))
+	if err != nil {
+		return 0, false, fmt.Errorf("get user response: %s", err)
+	}
+	return target.Version, confirmed, nil
+}
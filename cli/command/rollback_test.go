@@ -0,0 +1,89 @@
+package command
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	clientMock "github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/blueprint"
+)
+
+func TestRollbackParse(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRollbackCommand()
+	assert.NoError(t, parseHelper(cmd, nil))
+	assert.Equal(t, int64(0), cmd.version)
+
+	cmd = NewRollbackCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"-f", "3"}))
+	assert.Equal(t, int64(3), cmd.version)
+	assert.True(t, cmd.force)
+
+	cmd = NewRollbackCommand()
+	assert.EqualError(t, parseHelper(cmd, []string{"latest"}),
+		`malformed version "latest"`)
+	assert.EqualError(t, parseHelper(cmd, []string{"1", "2"}),
+		"too many arguments")
+}
+
+func TestRollbackRun(t *testing.T) {
+	oldConfirm := confirm
+	defer func() {
+		confirm = oldConfirm
+	}()
+
+	history := []pb.Deployment{
+		{Version: 1, Blueprint: blueprint.Blueprint{Namespace: "a"}.String()},
+		{Version: 2, Blueprint: blueprint.Blueprint{Namespace: "b"}.String()},
+		{Version: 3, Blueprint: blueprint.Blueprint{Namespace: "c"}.String()},
+	}
+
+	// Without a version, the deployment before the current one is
+	// redeployed.
+	c := &clientMock.Client{}
+	c.On("DeploymentHistory").Return(history, nil)
+	c.On("Rollback", int64(2)).Return(nil).Once()
+	confirm = func(in io.Reader, prompt string) (bool, error) {
+		assert.Equal(t, "Roll back to version 2?", prompt)
+		return true, nil
+	}
+
+	cmd := NewRollbackCommand()
+	cmd.client = c
+	assert.Equal(t, 0, cmd.Run())
+	c.AssertExpectations(t)
+
+	// Declining the confirmation shouldn't roll back.
+	confirm = func(in io.Reader, prompt string) (bool, error) {
+		return false, nil
+	}
+	cmd = NewRollbackCommand()
+	cmd.client = c
+	cmd.version = 1
+	assert.Equal(t, 0, cmd.Run())
+	c.AssertNotCalled(t, "Rollback", int64(1))
+
+	// The force flag skips the confirmation, and leaves resolving the
+	// version to the daemon.
+	c = &clientMock.Client{}
+	c.On("Rollback", int64(0)).Return(nil).Once()
+	cmd = NewRollbackCommand()
+	cmd.client = c
+	cmd.force = true
+	assert.Equal(t, 0, cmd.Run())
+	c.AssertExpectations(t)
+	c.AssertNotCalled(t, "DeploymentHistory")
+
+	// Unknown versions.
+	c = &clientMock.Client{}
+	c.On("DeploymentHistory").Return(history, nil)
+	cmd = NewRollbackCommand()
+	cmd.client = c
+	cmd.version = 5
+	assert.Equal(t, 1, cmd.Run())
+	c.AssertNotCalled(t, "Rollback", int64(5))
+}
@@ -3,6 +3,7 @@ package command
 import (
 	"bufio"
 	"bytes"
+	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"flag"
@@ -113,7 +114,7 @@ func (rCmd *Run) Run() int {
 		}
 	}
 
-	err = rCmd.client.Deploy(deployment)
+	err = rCmd.client.DeployFromSource(deployment, sourceHash(rCmd.blueprint))
 	if err != nil {
 		log.WithError(err).Error("Error while starting run.")
 		return 1
@@ -125,6 +126,18 @@ func (rCmd *Run) Run() int {
 	return 0
 }
 
+// sourceHash returns a hash of the contents of the blueprint file, so that
+// deployments in `kelda history` can be matched up with the files they were
+// compiled from.
+func sourceHash(path string) string {
+	contents, err := util.ReadFile(path)
+	if err != nil {
+		log.WithError(err).Debug("Failed to hash blueprint")
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+}
+
 func getCurrentDeployment(c client.Client) (blueprint.Blueprint, error) {
 	blueprints, err := c.QueryBlueprints()
 	if err != nil {
@@ -141,20 +154,26 @@ func getCurrentDeployment(c client.Client) (blueprint.Blueprint, error) {
 }
 
 func diffDeployment(currRaw, newRaw string) (string, error) {
-	curr, err := prettifyJSON(currRaw)
+	return diffBlueprints(currRaw, newRaw, "Current", "Proposed")
+}
+
+// diffBlueprints returns a unified diff between the JSON representations of two
+// blueprints, labelled with `fromName` and `toName`.
+func diffBlueprints(fromRaw, toRaw, fromName, toName string) (string, error) {
+	from, err := prettifyJSON(fromRaw)
 	if err != nil {
 		return "", err
 	}
-	new, err := prettifyJSON(newRaw)
+	to, err := prettifyJSON(toRaw)
 	if err != nil {
 		return "", err
 	}
 
 	diff := difflib.UnifiedDiff{
-		A:        difflib.SplitLines(curr),
-		B:        difflib.SplitLines(new),
-		FromFile: "Current",
-		ToFile:   "Proposed",
+		A:        difflib.SplitLines(from),
+		B:        difflib.SplitLines(to),
+		FromFile: fromName,
+		ToFile:   toName,
 		Context:  3,
 	}
 	return difflib.GetUnifiedDiffString(diff)
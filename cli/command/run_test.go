@@ -181,6 +181,9 @@ func TestConfirm(t *testing.T) {
 	}
 }
 
+// The SHA-256 hash of an empty blueprint file.
+const emptySHA256 = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
 func TestPromptsUser(t *testing.T) {
 	oldConfirm := confirm
 	defer func() {
@@ -201,7 +204,7 @@ func TestPromptsUser(t *testing.T) {
 		c.On("QueryBlueprints").Return([]db.Blueprint{{
 			Blueprint: blueprint.Blueprint{Namespace: "old"},
 		}}, nil)
-		c.On("Deploy", "{}").Return(nil)
+		c.On("DeployFromSource", "{}", emptySHA256).Return(nil)
 
 		util.WriteFile("test.js", []byte(""), 0644)
 		runCmd := &Run{
@@ -211,9 +214,11 @@ func TestPromptsUser(t *testing.T) {
 		runCmd.Run()
 
 		if confirmResp {
-			c.AssertCalled(t, "Deploy", mock.Anything)
+			c.AssertCalled(t, "DeployFromSource", mock.Anything,
+				mock.Anything)
 		} else {
-			c.AssertNotCalled(t, "Deploy", mock.Anything)
+			c.AssertNotCalled(t, "DeployFromSource", mock.Anything,
+				mock.Anything)
 		}
 	}
 }
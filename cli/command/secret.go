@@ -3,7 +3,16 @@ package command
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	units "github.com/docker/go-units"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/kelda/kelda/util"
@@ -11,17 +20,50 @@ import (
 
 // Secret defines the options for the Secret command.
 type Secret struct {
-	name, value string
+	action, name, value string
+	version             int64
+
+	// The path to read the secret value from. "-" reads from stdin.
+	file  string
+	stdin io.Reader
+
 	connectionHelper
 }
 
-var secretCommands = "kelda secret NAME VALUE"
-var secretExplanation = `Securely set a secret association. This command must
-be run before any containers referencing the associated secret can be started.`
+var secretCommands = `kelda secret [set] NAME VALUE
+kelda secret [set] [-f FILE] NAME
+kelda secret list
+kelda secret delete NAME
+kelda secret rollback NAME [VERSION]`
+
+var secretExplanation = `Securely manage the values of secrets. A secret must be
+set before any containers referencing it can be started.
+
+When setting a secret, the value is read from FILE if -f is given, or from
+stdin if no VALUE is given, so that the value doesn't end up in your shell
+history. The value is used exactly as read, including any trailing newline.
+
+Setting a secret keeps its previous value, and the last 5 values can be
+restored with rollback. If no VERSION is given, rollback restores the value
+from before the most recent change. Containers that reference a secret are
+restarted whenever its value changes.
+
+The list command shows each secret's current version, when it was last
+changed, and which containers reference it. Secret values are never shown.
+
+To set a secret whose name is one of the actions above, use the explicit
+set action, e.g. ` + "`kelda secret set list VALUE`."
+
+// NewSecretCommand creates a new Secret command instance.
+func NewSecretCommand() *Secret {
+	return &Secret{stdin: os.Stdin}
+}
 
 // InstallFlags sets up parsing for command line flags.
 func (secretCmd *Secret) InstallFlags(flags *flag.FlagSet) {
 	secretCmd.connectionHelper.InstallFlags(flags)
+	flags.StringVar(&secretCmd.file, "f", "",
+		"the file to read the secret value from, or - to read from stdin")
 	flags.Usage = func() {
 		util.PrintUsageString(secretCommands, secretExplanation, flags)
 	}
@@ -29,21 +71,117 @@ func (secretCmd *Secret) InstallFlags(flags *flag.FlagSet) {
 
 // Parse parses the command line arguments for the secret command.
 func (secretCmd *Secret) Parse(args []string) error {
-	if len(args) != 2 {
-		return errors.New("a name and value must be supplied")
+	if len(args) == 0 {
+		return errors.New("must specify a secret name")
 	}
 
-	secretCmd.name = args[0]
-	secretCmd.value = args[1]
+	secretCmd.action = "set"
+	switch args[0] {
+	case "set":
+		args = args[1:]
+	case "list":
+		if len(args) != 1 {
+			return errors.New("list takes no arguments")
+		}
+		secretCmd.action = "list"
+		return nil
+	case "delete":
+		if len(args) != 2 {
+			return errors.New("delete requires exactly one secret name")
+		}
+		secretCmd.action = "delete"
+		secretCmd.name = args[1]
+		return nil
+	case "rollback":
+		if len(args) != 2 && len(args) != 3 {
+			return errors.New("rollback requires a secret name and an " +
+				"optional version")
+		}
+		secretCmd.action = "rollback"
+		secretCmd.name = args[1]
+		if len(args) == 3 {
+			version, err := strconv.ParseInt(args[2], 10, 64)
+			if err != nil || version <= 0 {
+				return fmt.Errorf("malformed version %q", args[2])
+			}
+			secretCmd.version = version
+		}
+		return nil
+	}
+
+	switch {
+	case len(args) == 1:
+		secretCmd.name = args[0]
+		if secretCmd.file == "" {
+			secretCmd.file = "-"
+		}
+	case len(args) == 2 && secretCmd.file == "":
+		secretCmd.name = args[0]
+		secretCmd.value = args[1]
+	case len(args) == 2:
+		return errors.New("a value and -f cannot both be supplied")
+	default:
+		return errors.New("a name and value must be supplied")
+	}
 	return nil
 }
 
 // Run implements the secret command.
 func (secretCmd Secret) Run() int {
-	err := secretCmd.client.SetSecret(secretCmd.name, secretCmd.value)
+	var err error
+	switch secretCmd.action {
+	case "set":
+		err = secretCmd.set()
+	case "list":
+		err = secretCmd.list(os.Stdout)
+	case "delete":
+		err = secretCmd.client.DeleteSecret(secretCmd.name)
+	case "rollback":
+		err = secretCmd.client.RollbackSecret(secretCmd.name, secretCmd.version)
+	}
+
 	if err != nil {
-		log.WithError(err).Error("Failed to set secret")
+		log.WithError(err).Errorf("Failed to %s secret", secretCmd.action)
 		return 1
 	}
 	return 0
 }
+
+func (secretCmd Secret) set() error {
+	value := secretCmd.value
+	if secretCmd.file != "" {
+		var err error
+		if secretCmd.file == "-" {
+			var valueBytes []byte
+			valueBytes, err = ioutil.ReadAll(secretCmd.stdin)
+			value = string(valueBytes)
+		} else {
+			value, err = util.ReadFile(secretCmd.file)
+		}
+		if err != nil {
+			return fmt.Errorf("read value: %s", err)
+		}
+	}
+	return secretCmd.client.SetSecret(secretCmd.name, value)
+}
+
+func (secretCmd Secret) list(out io.Writer) error {
+	secrets, err := secretCmd.client.ListSecrets()
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tVERSION\tUPDATED\tCONTAINERS")
+	for _, secret := range secrets {
+		updated := ""
+		if secret.Updated != 0 {
+			updated = units.HumanDuration(
+				time.Since(time.Unix(secret.Updated, 0))) + " ago"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", secret.Name, secret.Version,
+			updated, strings.Join(secret.Containers, ", "))
+	}
+	return nil
+}
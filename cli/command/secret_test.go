@@ -0,0 +1,107 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	clientMock "github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/util"
+)
+
+func TestSecretParse(t *testing.T) {
+	t.Parallel()
+
+	checkSecretParse(t, []string{"name", "value"},
+		Secret{action: "set", name: "name", value: "value"}, "")
+	checkSecretParse(t, []string{"set", "list", "value"},
+		Secret{action: "set", name: "list", value: "value"}, "")
+	checkSecretParse(t, []string{"name"},
+		Secret{action: "set", name: "name", file: "-"}, "")
+	checkSecretParse(t, []string{"-f", "path", "name"},
+		Secret{action: "set", name: "name", file: "path"}, "")
+	checkSecretParse(t, []string{"list"}, Secret{action: "list"}, "")
+	checkSecretParse(t, []string{"delete", "name"},
+		Secret{action: "delete", name: "name"}, "")
+	checkSecretParse(t, []string{"rollback", "name"},
+		Secret{action: "rollback", name: "name"}, "")
+	checkSecretParse(t, []string{"rollback", "name", "3"},
+		Secret{action: "rollback", name: "name", version: 3}, "")
+
+	checkSecretParse(t, nil, Secret{}, "must specify a secret name")
+	checkSecretParse(t, []string{"-f", "path", "name", "value"}, Secret{},
+		"a value and -f cannot both be supplied")
+	checkSecretParse(t, []string{"name", "value", "extra"}, Secret{},
+		"a name and value must be supplied")
+	checkSecretParse(t, []string{"list", "name"}, Secret{},
+		"list takes no arguments")
+	checkSecretParse(t, []string{"delete"}, Secret{},
+		"delete requires exactly one secret name")
+	checkSecretParse(t, []string{"rollback", "name", "latest"}, Secret{},
+		`malformed version "latest"`)
+}
+
+func checkSecretParse(t *testing.T, args []string, exp Secret, expErr string) {
+	cmd := Secret{}
+	err := parseHelper(&cmd, args)
+	if expErr != "" {
+		assert.EqualError(t, err, expErr)
+		return
+	}
+
+	assert.NoError(t, err)
+	cmd.connectionHelper = connectionHelper{}
+	assert.Equal(t, exp, cmd)
+}
+
+func TestSecretSetFromFile(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+	util.WriteFile("key.pem", []byte("file value\n"), 0600)
+
+	c := &clientMock.Client{}
+	c.On("SetSecret", "fromFile", "file value\n").Return(nil).Once()
+	c.On("SetSecret", "fromStdin", "stdin value").Return(nil).Once()
+
+	cmd := Secret{action: "set", name: "fromFile", file: "key.pem"}
+	cmd.client = c
+	assert.Equal(t, 0, cmd.Run())
+
+	cmd = Secret{action: "set", name: "fromStdin", file: "-",
+		stdin: strings.NewReader("stdin value")}
+	cmd.client = c
+	assert.Equal(t, 0, cmd.Run())
+	c.AssertExpectations(t)
+
+	cmd = Secret{action: "set", name: "missing", file: "missing"}
+	cmd.client = c
+	assert.Equal(t, 1, cmd.Run())
+}
+
+func TestSecretList(t *testing.T) {
+	t.Parallel()
+
+	c := &clientMock.Client{}
+	c.On("ListSecrets").Return([]pb.SecretInfo{{
+		Name:       "key",
+		Version:    2,
+		Updated:    time.Now().Add(-time.Hour).Unix(),
+		Containers: []string{"a", "b"},
+	}, {
+		Name:    "unused",
+		Version: 1,
+	}}, nil)
+
+	cmd := Secret{}
+	cmd.client = c
+
+	var out bytes.Buffer
+	assert.NoError(t, cmd.list(&out))
+	assert.Equal(t, "NAME      VERSION    UPDATED              CONTAINERS\n"+
+		"key       2          About an hour ago    a, b\n"+
+		"unused    1                               \n", out.String())
+}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
@@ -11,6 +12,7 @@ import (
 	"time"
 
 	units "github.com/docker/go-units"
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/blueprint"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/util"
@@ -20,9 +22,18 @@ import (
 // An arbitrary length to truncate container commands to.
 const truncLength = 30
 
+// The container fields displayed by `kelda show`. Only these fields are queried
+// so that large fields, such as the contents of files, aren't sent to the CLI.
+// With `--json`, every field is queried so that scripts get complete rows.
+var showContainerFields = []string{"BlueprintID", "Minion", "Image", "Command",
+	"Hostname", "Status", "Created", "Resources", "Retiring", "ExitCode",
+	"TerminationReason", "Completed", "RestartCount", "WaitingReason",
+	"WaitingMessage"}
+
 // Show contains the options for querying machines and containers.
 type Show struct {
 	noTruncate bool
+	json       bool
 
 	connectionHelper
 }
@@ -40,6 +51,8 @@ func (pCmd *Show) InstallFlags(flags *flag.FlagSet) {
 	pCmd.connectionHelper.InstallFlags(flags)
 	flags.BoolVar(&pCmd.noTruncate, "no-trunc", false, "do not truncate container"+
 		" command output")
+	flags.BoolVar(&pCmd.json, "json", false, "print the machines and "+
+		"containers as JSON")
 	flags.Usage = func() {
 		util.PrintUsageString(showCommands, showExplanation, flags)
 	}
@@ -65,9 +78,6 @@ func (pCmd *Show) run() (err error) {
 		return fmt.Errorf("unable to query machines: %s", err)
 	}
 
-	writeMachines(os.Stdout, machines)
-	fmt.Println()
-
 	clusterUp := false
 	for _, m := range machines {
 		if m.Status == db.Connected || m.Status == db.Reconnecting {
@@ -79,35 +89,79 @@ func (pCmd *Show) run() (err error) {
 	// to a machine. If the foreman hasn't connected to any machines, then there's
 	// no way any containers could be running because the deployment hasn't been
 	// sent to the cluster yet.
-	if !clusterUp {
-		return nil
-	}
-
 	var connections []db.Connection
 	var containers []db.Container
+	if clusterUp {
+		fields := showContainerFields
+		if pCmd.json {
+			fields = nil
+		}
+		connections, containers, err = pCmd.queryContainers(fields)
+		if err != nil {
+			return err
+		}
+	}
+
+	if pCmd.json {
+		return writeJSON(os.Stdout, machines, containers)
+	}
+
+	writeMachines(os.Stdout, machines)
+	fmt.Println()
+
+	if clusterUp {
+		writeContainers(os.Stdout, containers, machines, connections,
+			!pCmd.noTruncate)
+	}
+	return nil
+}
+
+func (pCmd *Show) queryContainers(fields []string) (
+	connections []db.Connection, containers []db.Container, err error) {
+
 	connectionErr := make(chan error)
 	containerErr := make(chan error)
 
 	go func() {
+		var err error
 		connections, err = pCmd.client.QueryConnections()
 		connectionErr <- err
 	}()
 
 	go func() {
-		containers, err = pCmd.client.QueryContainers()
+		var err error
+		containers, err = pCmd.client.SelectContainers(
+			api.Query{Fields: fields})
 		containerErr <- err
 	}()
 
-	if err := <-connectionErr; err != nil {
-		return fmt.Errorf("unable to query connections: %s", err)
+	// Wait for both queries before returning so that neither goroutine is
+	// left blocked on its channel.
+	connErr, dbcErr := <-connectionErr, <-containerErr
+	if connErr != nil {
+		return nil, nil, fmt.Errorf("unable to query connections: %s", connErr)
 	}
-	if err := <-containerErr; err != nil {
-		return fmt.Errorf("unable to query containers: %s", err)
+	if dbcErr != nil {
+		return nil, nil, fmt.Errorf("unable to query containers: %s", dbcErr)
 	}
+	return connections, containers, nil
+}
 
-	writeContainers(os.Stdout, containers, machines, connections, !pCmd.noTruncate)
+// writeJSON prints the machines and containers in a form that's easy for
+// scripts to consume. Unlike the table, it includes every field of the rows,
+// such as the containers' IPs, pod names and environments.
+func writeJSON(fd io.Writer, machines []db.Machine,
+	containers []db.Container) error {
 
-	return nil
+	out, err := json.MarshalIndent(struct {
+		Machines   []db.Machine
+		Containers []db.Container
+	}{machines, containers}, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(fd, string(out))
+	return err
 }
 
 func writeMachines(fd io.Writer, machines []db.Machine) {
@@ -132,8 +186,22 @@ func writeContainers(fd io.Writer, containers []db.Container, machines []db.Mach
 	connections []db.Connection, truncate bool) {
 	w := tabwriter.NewWriter(fd, 0, 0, 4, ' ', 0)
 	defer w.Flush()
-	fmt.Fprintln(w, "CONTAINER\tMACHINE\tCOMMAND\tHOSTNAME"+
-		"\tSTATUS\tCREATED\tPUBLIC IP")
+
+	// The resources are only shown if they're set, as most containers
+	// don't reserve or limit resources.
+	var showResources bool
+	for _, dbc := range containers {
+		if dbc.Resources != nil {
+			showResources = true
+		}
+	}
+
+	header := "CONTAINER\tMACHINE\tCOMMAND\tHOSTNAME\tSTATUS\tCREATED" +
+		"\tPUBLIC IP"
+	if showResources {
+		header += "\tRESOURCES (REQUEST/LIMIT)"
+	}
+	fmt.Fprintln(w, header)
 
 	hostnamePublicPorts := connToPorts(connections)
 
@@ -161,7 +229,11 @@ func writeContainers(fd io.Writer, containers []db.Container, machines []db.Mach
 			// Insert a blank line between each machine.
 			// Need to print tabs in a blank line; otherwise, spacing will
 			// change in subsequent lines.
-			fmt.Fprintf(w, "\t\t\t\t\t\t\n")
+			blank := "\t\t\t\t\t\t"
+			if showResources {
+				blank += "\t"
+			}
+			fmt.Fprintln(w, blank)
 		}
 
 		dbcs := machineDBC[machineID]
@@ -176,17 +248,57 @@ func writeContainers(fd io.Writer, containers []db.Container, machines []db.Mach
 				created = fmt.Sprintf("%s ago", duration)
 			}
 
+			// Containers that have run to completion, such as jobs, show
+			// when they exited instead.
+			if !dbc.Completed.IsZero() {
+				completedTime := dbc.Completed.Local()
+				duration := units.HumanDuration(time.Since(completedTime))
+				created = fmt.Sprintf("exited %s ago", duration)
+			}
+
 			publicPorts := hostnamePublicPorts[dbc.Hostname]
 			publicIP := publicIPStr(idMachineMap[machineID], publicPorts)
 
-			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			// Retiring containers are being replaced by a rolling update.
+			status := dbc.Status
+			if dbc.Retiring {
+				status = strings.TrimSpace(status + " (retiring)")
+			}
+			if !dbc.Completed.IsZero() {
+				status = strings.TrimSpace(fmt.Sprintf(
+					"%s (exit code %d)", status, dbc.ExitCode))
+			} else if dbc.RestartCount != 0 {
+				status = strings.TrimSpace(
+					status + " " + restartsStr(dbc))
+			}
+
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v",
 				util.ShortUUID(dbc.BlueprintID),
 				util.ShortUUID(machineID),
-				container, dbc.Hostname, dbc.Status, created, publicIP)
+				container, dbc.Hostname, status, created, publicIP)
+			if showResources {
+				fmt.Fprintf(w, "\t%s", resourcesStr(dbc.Resources))
+			}
+			fmt.Fprintln(w)
 		}
 	}
 }
 
+// restartsStr describes how often a container has been restarted, and why it
+// last exited, e.g. "(3 restarts, last exit code 137: OOMKilled)".
+func restartsStr(dbc db.Container) string {
+	restarts := "restarts"
+	if dbc.RestartCount == 1 {
+		restarts = "restart"
+	}
+
+	lastExit := fmt.Sprintf("last exit code %d", dbc.ExitCode)
+	if dbc.TerminationReason != "" {
+		lastExit += ": " + dbc.TerminationReason
+	}
+	return fmt.Sprintf("(%d %s, %s)", dbc.RestartCount, restarts, lastExit)
+}
+
 func connToPorts(connections []db.Connection) map[string][]string {
 	hostnamePublicPorts := map[string][]string{}
 	for _, c := range connections {
@@ -199,6 +311,12 @@ func connToPorts(connections []db.Connection) map[string][]string {
 			if c.MinPort != c.MaxPort {
 				portStr += fmt.Sprintf("-%d", c.MaxPort)
 			}
+			if c.PublicPort != 0 {
+				portStr = fmt.Sprintf("%d->%s", c.PublicPort, portStr)
+			}
+			if c.Protocol != "" {
+				portStr += "/" + c.Protocol
+			}
 			hostnamePublicPorts[to] = append(hostnamePublicPorts[to],
 				portStr)
 		}
@@ -219,6 +337,34 @@ func containerStr(image string, args []string, truncate bool) string {
 	return container
 }
 
+// resourcesStr describes the requests and limits of the resources that are
+// set, e.g. "cpu 500m/1, memory 256Mi/-".
+func resourcesStr(res *blueprint.Resources) string {
+	if res == nil {
+		return ""
+	}
+
+	var parts []string
+	for _, r := range []struct{ name, request, limit string }{
+		{"cpu", res.CPURequest, res.CPULimit},
+		{"memory", res.MemoryRequest, res.MemoryLimit},
+	} {
+		if r.request == "" && r.limit == "" {
+			continue
+		}
+
+		request, limit := r.request, r.limit
+		if request == "" {
+			request = "-"
+		}
+		if limit == "" {
+			limit = "-"
+		}
+		parts = append(parts, fmt.Sprintf("%s %s/%s", r.name, request, limit))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func publicIPStr(m db.Machine, publicPorts []string) string {
 	// Prefer the floating IP over the public IP if it's defined.
 	hostPublicIP := m.PublicIP
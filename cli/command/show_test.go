@@ -2,6 +2,7 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -10,8 +11,11 @@ import (
 
 	units "github.com/docker/go-units"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 
+	"github.com/kelda/kelda/api"
 	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/blueprint"
 	"github.com/kelda/kelda/db"
 )
 
@@ -31,6 +35,12 @@ func TestShowFlags(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.True(t, cmd.noTruncate)
+
+	cmd = NewShowCommand()
+	err = parseHelper(cmd, []string{"-json"})
+
+	assert.NoError(t, err)
+	assert.True(t, cmd.json)
 }
 
 func TestShowErrors(t *testing.T) {
@@ -42,16 +52,16 @@ func TestShowErrors(t *testing.T) {
 	mockClient := new(mocks.Client)
 	mockClient.On("QueryConnections").Return(nil, nil)
 	mockClient.On("QueryMachines").Return([]db.Machine{{Status: db.Connected}}, nil)
-	mockClient.On("QueryContainers").Return(nil, mockErr)
-	cmd := &Show{false, connectionHelper{client: mockClient}}
+	mockClient.On("SelectContainers", mock.Anything).Return(nil, mockErr)
+	cmd := &Show{false, false, connectionHelper{client: mockClient}}
 	assert.EqualError(t, cmd.run(), "unable to query containers: error")
 
 	// Error querying connections from LeaderClient
 	mockClient = new(mocks.Client)
-	mockClient.On("QueryContainers").Return(nil, nil)
+	mockClient.On("SelectContainers", mock.Anything).Return(nil, nil)
 	mockClient.On("QueryMachines").Return([]db.Machine{{Status: db.Connected}}, nil)
 	mockClient.On("QueryConnections").Return(nil, mockErr)
-	cmd = &Show{false, connectionHelper{client: mockClient}}
+	cmd = &Show{false, false, connectionHelper{client: mockClient}}
 	assert.EqualError(t, cmd.run(), "unable to query connections: error")
 }
 
@@ -60,33 +70,33 @@ func TestMachineOnly(t *testing.T) {
 	t.Parallel()
 
 	mockClient := new(mocks.Client)
-	cmd := &Show{false, connectionHelper{client: mockClient}}
+	cmd := &Show{false, false, connectionHelper{client: mockClient}}
 
 	// Test failing to query machines.
 	mockClient.On("QueryMachines").Once().Return(nil, assert.AnError)
 	cmd.run()
-	mockClient.AssertNotCalled(t, "QueryContainers")
+	mockClient.AssertNotCalled(t, "SelectContainers", mock.Anything)
 
 	// Test no machines in database.
 	mockClient.On("QueryMachines").Once().Return(nil, nil)
 	cmd.run()
-	mockClient.AssertNotCalled(t, "QueryContainers")
+	mockClient.AssertNotCalled(t, "SelectContainers", mock.Anything)
 
 	// Test no connected machines.
 	mockClient.On("QueryMachines").Once().Return(
 		[]db.Machine{{Status: db.Booting}}, nil)
 	cmd.run()
-	mockClient.AssertNotCalled(t, "QueryContainers")
+	mockClient.AssertNotCalled(t, "SelectContainers", mock.Anything)
 }
 
 func TestShowSuccess(t *testing.T) {
 	t.Parallel()
 
 	mockClient := new(mocks.Client)
-	mockClient.On("QueryContainers").Return(nil, nil)
+	mockClient.On("SelectContainers", mock.Anything).Return(nil, nil)
 	mockClient.On("QueryMachines").Return(nil, nil)
 	mockClient.On("QueryConnections").Return(nil, nil)
-	cmd := &Show{false, connectionHelper{client: mockClient}}
+	cmd := &Show{false, false, connectionHelper{client: mockClient}}
 	assert.Equal(t, 0, cmd.Run())
 }
 
@@ -131,6 +141,45 @@ func TestMachineOutput(t *testing.T) {
 	assert.Equal(t, exp, result)
 }
 
+func TestJSONOutput(t *testing.T) {
+	t.Parallel()
+
+	machines := []db.Machine{{CloudID: "1", Status: db.Connected}}
+	containers := []db.Container{{
+		BlueprintID:    "3",
+		IP:             "10.0.0.2",
+		PodName:        "web-7d9f8b6c5-x2x9q",
+		Ready:          true,
+		Status:         "waiting: ImagePullBackOff",
+		WaitingReason:  "ImagePullBackOff",
+		WaitingMessage: "image not found",
+	}}
+
+	mockClient := new(mocks.Client)
+	mockClient.On("QueryMachines").Return(machines, nil)
+	mockClient.On("QueryConnections").Return(nil, nil)
+	mockClient.On("SelectContainers", mock.Anything).Return(containers, nil)
+
+	var b bytes.Buffer
+	assert.NoError(t, writeJSON(&b, machines, containers))
+
+	var actual struct {
+		Machines   []db.Machine
+		Containers []db.Container
+	}
+	assert.NoError(t, json.Unmarshal(b.Bytes(), &actual))
+	assert.Equal(t, machines, actual.Machines)
+	assert.Equal(t, containers, actual.Containers)
+
+	// The JSON includes every field, so the containers shouldn't be
+	// projected.
+	cmd := &Show{false, true, connectionHelper{client: mockClient}}
+	assert.Equal(t, 0, cmd.Run())
+	mockClient.AssertCalled(t, "SelectContainers", api.Query{})
+	mockClient.AssertNotCalled(t, "SelectContainers",
+		api.Query{Fields: showContainerFields})
+}
+
 func checkContainerOutput(t *testing.T, containers []db.Container,
 	machines []db.Machine, connections []db.Connection, truncate bool, exp string) {
 
@@ -236,6 +285,42 @@ ________________________________________________________________________________
 `
 	checkContainerOutput(t, containers, machines, connections, true, expected)
 
+	// Containers that have exited show their exit code, and when they
+	// exited.
+	mockCompletedString := fmt.Sprintf("exited %s ago", humanDuration)
+	mockCompletedString = strings.Replace(mockCompletedString, " ", "_", -1)
+
+	containers = []db.Container{
+		{ID: 1, BlueprintID: "3", Minion: "3.3.3.3", IP: "1.2.3.4",
+			Image: "image1", Command: []string{"cmd", "1"},
+			Status: "terminated: Error", ExitCode: 2,
+			Completed: mockTime.UTC()},
+	}
+
+	expected = `CONTAINER____MACHINE____COMMAND_________HOSTNAME____` +
+		`STATUS_____________________________CREATED_____________________PUBLIC_IP
+3_______________________image1_cmd_1________________terminated:_Error_(exit_code_2)____` +
+		mockCompletedString + `____
+`
+	checkContainerOutput(t, containers, machines, connections, true, expected)
+
+	// Containers that are restarting show how many times they've restarted,
+	// and why they last exited.
+	containers = []db.Container{
+		{ID: 1, BlueprintID: "3", Minion: "3.3.3.3", IP: "1.2.3.4",
+			Image: "image1", Command: []string{"cmd", "1"},
+			Status: "waiting: CrashLoopBackOff", RestartCount: 3,
+			ExitCode: 137, TerminationReason: "OOMKilled"},
+	}
+
+	expected = `CONTAINER____MACHINE____COMMAND_________HOSTNAME____` +
+		`STATUS_____________________________________________________________` +
+		`______CREATED____PUBLIC_IP
+3_______________________image1_cmd_1________________waiting:_CrashLoopBackOff_` +
+		`(3_restarts,_last_exit_code_137:_OOMKilled)_______________
+`
+	checkContainerOutput(t, containers, machines, connections, true, expected)
+
 	// Test that long outputs are truncated when `truncate` is true
 	containers = []db.Container{
 		{ID: 1, BlueprintID: "3", Minion: "3.3.3.3", IP: "1.2.3.4",
@@ -279,16 +364,74 @@ ________________________________________________________________________________
 			MinPort: 80, MaxPort: 80},
 		{ID: 2, From: []string{"public"}, To: []string{"frompub"},
 			MinPort: 100, MaxPort: 101},
+		{ID: 3, From: []string{"public"}, To: []string{"frompub"},
+			MinPort: 53, MaxPort: 53, Protocol: "udp"},
+		{ID: 4, From: []string{"public"}, To: []string{"frompub"},
+			MinPort: 8000, MaxPort: 8000, PublicPort: 8080},
 	}
 
 	expected = `CONTAINER____MACHINE____COMMAND____HOSTNAME____STATUS_______` +
 		`CREATED____PUBLIC_IP
 3____________5__________image1_____frompub_____scheduled_______________` +
-		`7.7.7.7:[80,100-101]
+		`7.7.7.7:[80,100-101,53/udp,8080->8000]
 `
 	checkContainerOutput(t, containers, machines, connections, true, expected)
 }
 
+func TestContainerOutputResources(t *testing.T) {
+	t.Parallel()
+
+	containers := []db.Container{
+		{ID: 1, BlueprintID: "3", Minion: "1.1.1.1", Image: "image1",
+			Hostname: "web", Status: "running",
+			Resources: &blueprint.Resources{CPURequest: "500m",
+				CPULimit: "1", MemoryLimit: "1Gi"}},
+		{ID: 2, BlueprintID: "4", Minion: "1.1.1.1", Image: "image2",
+			Hostname: "db", Status: "running"},
+	}
+	machines := []db.Machine{{CloudID: "5", PrivateIP: "1.1.1.1"}}
+
+	expected := `CONTAINER____MACHINE____COMMAND____HOSTNAME____STATUS_____` +
+		`CREATED____PUBLIC_IP____RESOURCES_(REQUEST/LIMIT)
+3____________5__________image1_____web_________running________________` +
+		`____________cpu_500m/1,_memory_-/1Gi
+4____________5__________image2_____db__________running________________` +
+		`____________
+`
+	checkContainerOutput(t, containers, machines, nil, true, expected)
+}
+
+func TestContainerOutputRetiring(t *testing.T) {
+	t.Parallel()
+
+	containers := []db.Container{
+		{ID: 1, BlueprintID: "3", Minion: "1.1.1.1", Image: "image1",
+			Hostname: "web", Status: "running", Retiring: true},
+		{ID: 2, BlueprintID: "4", Minion: "1.1.1.1", Image: "image2",
+			Hostname: "web", Status: "scheduled"},
+	}
+	machines := []db.Machine{{CloudID: "5", PrivateIP: "1.1.1.1"}}
+
+	expected := `CONTAINER____MACHINE____COMMAND____HOSTNAME____` +
+		`STATUS________________CREATED____PUBLIC_IP
+3____________5__________image1_____web_________running_(retiring)_______________
+4____________5__________image2_____web_________scheduled________________________
+`
+	checkContainerOutput(t, containers, machines, nil, true, expected)
+}
+
+func TestResourcesStr(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "", resourcesStr(nil))
+	assert.Equal(t, "", resourcesStr(&blueprint.Resources{}))
+	assert.Equal(t, "memory 256Mi/-", resourcesStr(&blueprint.Resources{
+		MemoryRequest: "256Mi"}))
+	assert.Equal(t, "cpu 1/2, memory 1Gi/2Gi", resourcesStr(
+		&blueprint.Resources{CPURequest: "1", CPULimit: "2",
+			MemoryRequest: "1Gi", MemoryLimit: "2Gi"}))
+}
+
 func TestContainerStr(t *testing.T) {
 	t.Parallel()
 	assert.Equal(t, "", containerStr("", nil, false))
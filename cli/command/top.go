@@ -0,0 +1,176 @@
+package command
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	units "github.com/docker/go-units"
+
+	"github.com/kelda/kelda/api"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/util"
+)
+
+// The orderings supported by `kelda top`, from the most to the least usage.
+var topSortKeys = map[string]func(db.Stats) uint64{
+	"cpu":    func(s db.Stats) uint64 { return uint64(s.CPUMillicores) },
+	"memory": func(s db.Stats) uint64 { return s.MemoryBytes },
+	"network": func(s db.Stats) uint64 {
+		return s.NetworkRxBytesPerSec + s.NetworkTxBytesPerSec
+	},
+	"disk": func(s db.Stats) uint64 { return s.DiskBytes },
+}
+
+// Top contains the options for displaying resource usage.
+type Top struct {
+	sortBy string
+
+	connectionHelper
+}
+
+// NewTopCommand creates a new Top command instance.
+func NewTopCommand() *Top {
+	return &Top{}
+}
+
+var topCommands = "kelda top [OPTIONS]"
+var topExplanation = `Display the CPU, memory, network and disk usage of
+kelda-managed machines and containers.
+
+Usage is polled from each machine every few seconds. CPU is shown in
+thousandths of a core, and network usage is the rate of bytes received and
+sent since the previous poll. A container's usage includes its sidecars.`
+
+// InstallFlags sets up parsing for command line flags.
+func (tCmd *Top) InstallFlags(flags *flag.FlagSet) {
+	tCmd.connectionHelper.InstallFlags(flags)
+	flags.StringVar(&tCmd.sortBy, "sort", "cpu", "the resource to sort "+
+		"by: cpu, memory, network or disk")
+	flags.Usage = func() {
+		util.PrintUsageString(topCommands, topExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the top command.
+func (tCmd *Top) Parse(args []string) error {
+	if _, ok := topSortKeys[tCmd.sortBy]; !ok {
+		return fmt.Errorf("unknown sort key %q: must be cpu, memory, "+
+			"network or disk", tCmd.sortBy)
+	}
+	return nil
+}
+
+// Run retrieves and prints the resource usage of machines and containers.
+func (tCmd *Top) Run() int {
+	if err := tCmd.run(); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		return 1
+	}
+	return 0
+}
+
+func (tCmd *Top) run() error {
+	machines, err := tCmd.client.QueryMachines()
+	if err != nil {
+		return fmt.Errorf("unable to query machines: %s", err)
+	}
+
+	stats, err := tCmd.client.SelectStats(api.Query{})
+	if err != nil {
+		return fmt.Errorf("unable to query stats: %s", err)
+	}
+
+	containers, err := tCmd.client.SelectContainers(
+		api.Query{Fields: []string{"BlueprintID", "Hostname"}})
+	if err != nil {
+		return fmt.Errorf("unable to query containers: %s", err)
+	}
+
+	sortStats(stats, topSortKeys[tCmd.sortBy])
+	writeMachineStats(os.Stdout, machines, stats)
+	fmt.Println()
+	writeContainerStats(os.Stdout, containers, machines, stats)
+	return nil
+}
+
+// sortStats sorts the stats from the most to the least usage of a resource.
+// Ties are broken by hostname so that the order is stable between runs.
+func sortStats(stats []db.Stats, usage func(db.Stats) uint64) {
+	sort.Slice(stats, func(i, j int) bool {
+		if usage(stats[i]) != usage(stats[j]) {
+			return usage(stats[i]) > usage(stats[j])
+		}
+		return stats[i].Hostname < stats[j].Hostname
+	})
+}
+
+func writeMachineStats(fd io.Writer, machines []db.Machine, stats []db.Stats) {
+	w := tabwriter.NewWriter(fd, 0, 0, 4, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "MACHINE\tROLE\tCPU\tMEMORY\tNET RX/TX\tDISK")
+
+	ipToMachine := map[string]db.Machine{}
+	for _, m := range machines {
+		ipToMachine[m.PrivateIP] = m
+	}
+
+	for _, s := range stats {
+		m, ok := ipToMachine[s.Minion]
+		if s.Hostname != "" || !ok {
+			continue
+		}
+
+		memory := fmt.Sprintf("%s / %s", units.BytesSize(float64(s.MemoryBytes)),
+			units.BytesSize(float64(s.MemoryBytes+s.MemoryAvailableBytes)))
+		disk := fmt.Sprintf("%s / %s", units.BytesSize(float64(s.DiskBytes)),
+			units.BytesSize(float64(s.DiskCapacityBytes)))
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", util.ShortUUID(m.CloudID),
+			m.Role, cpuStr(s), memory, networkStr(s), disk)
+	}
+}
+
+func writeContainerStats(fd io.Writer, containers []db.Container,
+	machines []db.Machine, stats []db.Stats) {
+
+	w := tabwriter.NewWriter(fd, 0, 0, 4, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "CONTAINER\tMACHINE\tHOSTNAME\tCPU\tMEMORY\tNET RX/TX\tDISK")
+
+	ipToID := map[string]string{}
+	for _, m := range machines {
+		ipToID[m.PrivateIP] = m.CloudID
+	}
+
+	hostnameToID := map[string]string{}
+	for _, dbc := range containers {
+		hostnameToID[dbc.Hostname] = dbc.BlueprintID
+	}
+
+	for _, s := range stats {
+		if s.Hostname == "" {
+			continue
+		}
+
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			util.ShortUUID(hostnameToID[s.Hostname]),
+			util.ShortUUID(ipToID[s.Minion]), s.Hostname, cpuStr(s),
+			units.BytesSize(float64(s.MemoryBytes)), networkStr(s),
+			units.BytesSize(float64(s.DiskBytes)))
+	}
+}
+
+func cpuStr(s db.Stats) string {
+	return fmt.Sprintf("%dm", s.CPUMillicores)
+}
+
+// networkStr describes the rates at which bytes were received and sent, e.g.
+// "1.5kB/s / 200B/s".
+func networkStr(s db.Stats) string {
+	return fmt.Sprintf("%s/s / %s/s",
+		units.HumanSize(float64(s.NetworkRxBytesPerSec)),
+		units.HumanSize(float64(s.NetworkTxBytesPerSec)))
+}
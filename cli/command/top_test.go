@@ -0,0 +1,116 @@
+package command
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/kelda/kelda/api/client/mocks"
+	"github.com/kelda/kelda/db"
+)
+
+func TestTopFlags(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewTopCommand()
+	assert.NoError(t, parseHelper(cmd, nil))
+	assert.Equal(t, "cpu", cmd.sortBy)
+
+	cmd = NewTopCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"-sort", "memory"}))
+	assert.Equal(t, "memory", cmd.sortBy)
+
+	cmd = NewTopCommand()
+	assert.EqualError(t, parseHelper(cmd, []string{"-sort", "ram"}),
+		`unknown sort key "ram": must be cpu, memory, network or disk`)
+}
+
+func TestTopErrors(t *testing.T) {
+	t.Parallel()
+
+	mockClient := new(mocks.Client)
+	mockClient.On("QueryMachines").Return(nil, nil)
+	mockClient.On("SelectStats", mock.Anything).Return(nil, assert.AnError)
+	cmd := &Top{"cpu", connectionHelper{client: mockClient}}
+	assert.EqualError(t, cmd.run(), "unable to query stats: "+
+		assert.AnError.Error())
+	assert.Equal(t, 1, cmd.Run())
+
+	mockClient = new(mocks.Client)
+	mockClient.On("QueryMachines").Return(nil, nil)
+	mockClient.On("SelectStats", mock.Anything).Return(nil, nil)
+	mockClient.On("SelectContainers", mock.Anything).Return(nil, nil)
+	cmd = &Top{"cpu", connectionHelper{client: mockClient}}
+	assert.Equal(t, 0, cmd.Run())
+}
+
+func TestSortStats(t *testing.T) {
+	t.Parallel()
+
+	stats := []db.Stats{
+		{Hostname: "b", CPUMillicores: 100, MemoryBytes: 3},
+		{Hostname: "c", CPUMillicores: 500, MemoryBytes: 1},
+		{Hostname: "a", CPUMillicores: 100, MemoryBytes: 2},
+	}
+
+	hostnames := func() (names []string) {
+		for _, s := range stats {
+			names = append(names, s.Hostname)
+		}
+		return names
+	}
+
+	sortStats(stats, topSortKeys["cpu"])
+	assert.Equal(t, []string{"c", "a", "b"}, hostnames())
+
+	sortStats(stats, topSortKeys["memory"])
+	assert.Equal(t, []string{"b", "a", "c"}, hostnames())
+}
+
+func TestStatsOutput(t *testing.T) {
+	t.Parallel()
+
+	machines := []db.Machine{
+		{CloudID: "1", Role: db.Worker, PrivateIP: "10.0.0.1"},
+	}
+	containers := []db.Container{{BlueprintID: "2", Hostname: "web"}}
+	stats := []db.Stats{
+		{
+			Minion:               "10.0.0.1",
+			CPUMillicores:        1500,
+			MemoryBytes:          1024 * 1024 * 1024,
+			MemoryAvailableBytes: 3 * 1024 * 1024 * 1024,
+			NetworkRxBytesPerSec: 1500,
+			NetworkTxBytesPerSec: 200,
+			DiskBytes:            2 * 1024 * 1024 * 1024,
+			DiskCapacityBytes:    8 * 1024 * 1024 * 1024,
+		},
+		{
+			Hostname:      "web",
+			Minion:        "10.0.0.1",
+			CPUMillicores: 250,
+			MemoryBytes:   256 * 1024 * 1024,
+			DiskBytes:     1024,
+		},
+		// Stats for machines that the daemon doesn't know about are
+		// skipped.
+		{Minion: "10.0.0.2"},
+	}
+
+	var b bytes.Buffer
+	writeMachineStats(&b, machines, stats)
+	exp := `MACHINE____ROLE______CPU______MEMORY_________NET_RX/TX___________DISK
+1__________Worker____1500m____1GiB_/_4GiB____1.5kB/s_/_200B/s____2GiB_/_8GiB
+`
+	assert.Equal(t, exp, strings.Replace(b.String(), " ", "_", -1))
+
+	b.Reset()
+	writeContainerStats(&b, containers, machines, stats)
+	exp = `CONTAINER____MACHINE____HOSTNAME____CPU_____MEMORY____NET_RX/TX______DISK
+2____________1__________web_________250m____256MiB____0B/s_/_0B/s____1KiB
+`
+	assert.Equal(t, exp, strings.Replace(b.String(), " ", "_", -1))
+}
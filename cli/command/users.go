@@ -0,0 +1,222 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	units "github.com/docker/go-units"
+
+	"github.com/kelda/kelda/api"
+	cliPath "github.com/kelda/kelda/cli/path"
+	tlsIO "github.com/kelda/kelda/connection/tls/io"
+	"github.com/kelda/kelda/connection/tls/rsa"
+	"github.com/kelda/kelda/util"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Users contains the options for managing the certificates of API users.
+type Users struct {
+	action, name string
+
+	role   string
+	outDir string
+	tlsDir string
+}
+
+var usersCommands = `kelda users [OPTIONS] add NAME
+kelda users list
+kelda users [OPTIONS] revoke NAME`
+
+var usersExplanation = `Issue and revoke the TLS certificates that API clients use to
+connect to the daemon.
+
+Each certificate carries a role that limits which commands its holder may run:
+  viewer    query the deployment, e.g. with ` + "`kelda show`" + `
+  deployer  everything a viewer may do, plus deploy blueprints
+  admin     everything, including setting secrets
+
+The add command writes the new credentials into the directory given by -out.
+The user should copy the directory to ~/.kelda/tls on the machine from which
+they connect to the daemon.
+
+This command must be run on the machine that runs the daemon because it uses
+the daemon's certificate authority. Revocations take effect immediately.`
+
+// NewUsersCommand creates a new Users command instance.
+func NewUsersCommand() *Users {
+	return &Users{tlsDir: cliPath.DefaultTLSDir}
+}
+
+// InstallFlags sets up parsing for command line flags.
+func (uCmd *Users) InstallFlags(flags *flag.FlagSet) {
+	flags.StringVar(&uCmd.role, "role", string(api.ViewerRole),
+		"the role of the user being added (viewer, deployer or admin)")
+	flags.StringVar(&uCmd.outDir, "out", "",
+		"the directory to write the new credentials into (defaults to NAME)")
+	flags.Usage = func() {
+		util.PrintUsageString(usersCommands, usersExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the users command.
+func (uCmd *Users) Parse(args []string) error {
+	if len(args) == 0 {
+		return errors.New("must specify an action")
+	}
+
+	uCmd.action = args[0]
+	switch uCmd.action {
+	case "list":
+		if len(args) != 1 {
+			return errors.New("list takes no arguments")
+		}
+	case "add", "revoke":
+		if len(args) != 2 {
+			return fmt.Errorf("%s requires exactly one user name",
+				uCmd.action)
+		}
+		uCmd.name = args[1]
+	default:
+		return fmt.Errorf("unknown action %q", uCmd.action)
+	}
+
+	if _, err := api.ParseRole(uCmd.role); err != nil {
+		return err
+	}
+
+	if uCmd.outDir == "" {
+		uCmd.outDir = uCmd.name
+	}
+	return nil
+}
+
+// BeforeRun makes any necessary post-parsing transformations.
+func (uCmd *Users) BeforeRun() error {
+	return nil
+}
+
+// AfterRun performs any necessary post-run cleanup.
+func (uCmd *Users) AfterRun() error {
+	return nil
+}
+
+// Run performs the requested action.
+func (uCmd *Users) Run() int {
+	var err error
+	switch uCmd.action {
+	case "add":
+		err = uCmd.add()
+	case "list":
+		err = uCmd.list(os.Stdout)
+	case "revoke":
+		err = uCmd.revoke()
+	}
+
+	if err != nil {
+		log.WithError(err).Errorf("Failed to %s user", uCmd.action)
+		return 1
+	}
+	return 0
+}
+
+func (uCmd *Users) add() error {
+	users, err := tlsIO.ReadUsers(uCmd.tlsDir)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if user.Name == uCmd.name && !user.Revoked {
+			return fmt.Errorf("user %q already exists", uCmd.name)
+		}
+	}
+
+	// The user's certificate is signed by the user certificate authority,
+	// which only the daemon trusts. The user still verifies the daemon with
+	// the cluster's certificate authority.
+	ca, err := tlsIO.ReadCA(uCmd.tlsDir)
+	if err != nil {
+		return fmt.Errorf("read certificate authority: %s", err)
+	}
+
+	userCA, err := tlsIO.ReadUserCA(uCmd.tlsDir)
+	if err != nil {
+		return fmt.Errorf("read user certificate authority: %s", err)
+	}
+
+	role := api.Role(uCmd.role)
+	signed, err := rsa.NewSigned(userCA, api.UserSubject(uCmd.name, role))
+	if err != nil {
+		return fmt.Errorf("create certificate: %s", err)
+	}
+
+	if err := util.AppFs.MkdirAll(uCmd.outDir, 0700); err != nil {
+		return fmt.Errorf("create output directory: %s", err)
+	}
+
+	for _, f := range tlsIO.MinionFiles(uCmd.outDir, ca, signed) {
+		if err := util.WriteFile(f.Path, []byte(f.Content), f.Mode); err != nil {
+			return fmt.Errorf("write file (%s): %s", f.Path, err)
+		}
+	}
+
+	users = append(users, tlsIO.User{
+		Name:    uCmd.name,
+		Role:    string(role),
+		Serial:  signed.SerialNumber(),
+		Created: time.Now(),
+	})
+	if err := tlsIO.WriteUsers(uCmd.tlsDir, users); err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote credentials for %s (%s) to %s\n", uCmd.name, role,
+		uCmd.outDir)
+	return nil
+}
+
+func (uCmd *Users) list(out io.Writer) error {
+	users, err := tlsIO.ReadUsers(uCmd.tlsDir)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 4, ' ', 0)
+	defer w.Flush()
+	fmt.Fprintln(w, "NAME\tROLE\tCREATED\tSTATUS")
+	for _, user := range users {
+		status := "active"
+		if user.Revoked {
+			status = "revoked"
+		}
+		created := units.HumanDuration(time.Since(user.Created)) + " ago"
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", user.Name, user.Role, created, status)
+	}
+	return nil
+}
+
+func (uCmd *Users) revoke() error {
+	users, err := tlsIO.ReadUsers(uCmd.tlsDir)
+	if err != nil {
+		return err
+	}
+
+	var found bool
+	for i, user := range users {
+		if user.Name == uCmd.name && !user.Revoked {
+			users[i].Revoked = true
+			found = true
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no active user %q", uCmd.name)
+	}
+	return tlsIO.WriteUsers(uCmd.tlsDir, users)
+}
@@ -0,0 +1,117 @@
+package command
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/api"
+	tlsIO "github.com/kelda/kelda/connection/tls/io"
+	"github.com/kelda/kelda/connection/tls/rsa"
+	"github.com/kelda/kelda/util"
+)
+
+func TestUsersParse(t *testing.T) {
+	t.Parallel()
+
+	checkUsersParse(t, []string{"list"}, Users{action: "list", role: "viewer"}, "")
+	checkUsersParse(t, []string{"add", "alice"},
+		Users{action: "add", name: "alice", role: "viewer", outDir: "alice"}, "")
+	checkUsersParse(t, []string{"revoke", "alice"},
+		Users{action: "revoke", name: "alice", role: "viewer", outDir: "alice"},
+		"")
+
+	checkUsersParse(t, nil, Users{}, "must specify an action")
+	checkUsersParse(t, []string{"add"}, Users{},
+		"add requires exactly one user name")
+	checkUsersParse(t, []string{"list", "alice"}, Users{},
+		"list takes no arguments")
+	checkUsersParse(t, []string{"delete", "alice"}, Users{},
+		`unknown action "delete"`)
+}
+
+func checkUsersParse(t *testing.T, args []string, exp Users, expErr string) {
+	cmd := Users{role: "viewer"}
+	err := cmd.Parse(args)
+	if expErr != "" {
+		assert.EqualError(t, err, expErr)
+		return
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, exp, cmd)
+}
+
+func TestUsersLifecycle(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	ca, err := rsa.NewCertificateAuthority()
+	assert.NoError(t, err)
+	userCA, err := rsa.NewCertificateAuthority()
+	assert.NoError(t, err)
+	files := append(tlsIO.DaemonFiles("tls", ca, ca),
+		tlsIO.UserCAFiles("tls", userCA)...)
+	for _, f := range files {
+		assert.NoError(t, util.WriteFile(f.Path, []byte(f.Content), f.Mode))
+	}
+
+	cmd := Users{tlsDir: "tls", action: "add", name: "alice",
+		role: "deployer", outDir: "out"}
+	assert.Equal(t, 0, cmd.Run())
+
+	// Adding the same user twice should fail.
+	assert.Equal(t, 1, cmd.Run())
+
+	// The credentials should be signed by the user CA, and carry the user's
+	// role. They shouldn't be signed by the cluster's CA, which is trusted by
+	// the minions and Kubernetes.
+	certStr, err := util.ReadFile(tlsIO.SignedCertPath("out"))
+	assert.NoError(t, err)
+	cert := parseCert(t, certStr)
+	assert.NoError(t, cert.CheckSignatureFrom(parseCert(t, userCA.CertString())))
+	assert.Error(t, cert.CheckSignatureFrom(parseCert(t, ca.CertString())))
+
+	// The user verifies the daemon with the cluster's CA.
+	caStr, err := util.ReadFile(tlsIO.CACertPath("out"))
+	assert.NoError(t, err)
+	assert.Equal(t, ca.CertString(), caStr)
+
+	name, role, ok, err := api.CertificateUser(cert)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "alice", name)
+	assert.Equal(t, api.DeployerRole, role)
+
+	_, err = tlsIO.ReadCredentials("out")
+	assert.NoError(t, err)
+
+	users, err := tlsIO.ReadUsers("tls")
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, cert.SerialNumber.String(), users[0].Serial)
+	assert.False(t, users[0].Revoked)
+
+	var out bytes.Buffer
+	assert.NoError(t, cmd.list(&out))
+	assert.Contains(t, out.String(), "alice")
+	assert.Contains(t, out.String(), "active")
+
+	cmd.action = "revoke"
+	assert.Equal(t, 0, cmd.Run())
+	users, err = tlsIO.ReadUsers("tls")
+	assert.NoError(t, err)
+	assert.True(t, users[0].Revoked)
+
+	// Revoking an already revoked user should fail.
+	assert.Equal(t, 1, cmd.Run())
+}
+
+func parseCert(t *testing.T, certStr string) *x509.Certificate {
+	block, _ := pem.Decode([]byte(certStr))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	assert.NoError(t, err)
+	return cert
+}
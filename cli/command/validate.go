@@ -0,0 +1,101 @@
+package command
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/cloud"
+	"github.com/kelda/kelda/util"
+)
+
+// Validate contains the options for validating blueprints.
+type Validate struct {
+	blueprint     string
+	blueprintArgs []string
+}
+
+// NewValidateCommand creates a new Validate command instance.
+func NewValidateCommand() *Validate {
+	return &Validate{}
+}
+
+var validateCommands = `kelda validate BLUEPRINT [BLUEPRINT_ARGS...]`
+var validateExplanation = `Compile a blueprint, and check it for problems without
+deploying it.
+
+BLUEPRINT_ARGS are the command line arguments that should be passed to the
+blueprint, similar to when the blueprint is run with 'kelda run'.
+
+The same checks are run by the daemon when a blueprint is deployed. All
+problems are reported at once, along with the containers, connections or
+machines at fault.`
+
+// InstallFlags sets up parsing for command line flags.
+func (vCmd *Validate) InstallFlags(flags *flag.FlagSet) {
+	flags.Usage = func() {
+		util.PrintUsageString(validateCommands, validateExplanation, flags)
+	}
+}
+
+// Parse parses the command line arguments for the validate command.
+func (vCmd *Validate) Parse(args []string) error {
+	if len(args) == 0 {
+		return errors.New("no blueprint specified")
+	}
+
+	vCmd.blueprint = args[0]
+	vCmd.blueprintArgs = args[1:]
+	return nil
+}
+
+// BeforeRun makes any necessary post-parsing transformations.
+func (vCmd *Validate) BeforeRun() error {
+	return nil
+}
+
+// AfterRun performs any necessary post-run cleanup.
+func (vCmd *Validate) AfterRun() error {
+	return nil
+}
+
+// Run compiles and validates the blueprint.
+func (vCmd *Validate) Run() int {
+	compiled, err := compile(vCmd.blueprint, vCmd.blueprintArgs)
+	if err != nil {
+		log.Error(err)
+		return 1
+	}
+
+	if !printProblems(os.Stdout, compiled) {
+		return 1
+	}
+	return 0
+}
+
+// printProblems writes the problems with `bp` to `out`, and returns whether
+// the blueprint is valid.
+func printProblems(out io.Writer, bp blueprint.Blueprint) bool {
+	err := cloud.ValidateBlueprint(bp)
+	if err == nil {
+		fmt.Fprintln(out, "The blueprint is valid.")
+		return true
+	}
+
+	problems, ok := err.(blueprint.ValidationError)
+	if !ok {
+		fmt.Fprintln(out, err)
+		return false
+	}
+
+	fmt.Fprintf(out, "Found %d problems in the blueprint:\n", len(problems))
+	for _, problem := range problems {
+		fmt.Fprintf(out, "  - %s\n", problem)
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kelda/kelda/blueprint"
+)
+
+func TestValidateParse(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewValidateCommand()
+	assert.NoError(t, parseHelper(cmd, []string{"bp.js", "arg"}))
+	assert.Equal(t, Validate{blueprint: "bp.js", blueprintArgs: []string{"arg"}},
+		*cmd)
+
+	assert.EqualError(t, parseHelper(NewValidateCommand(), nil),
+		"no blueprint specified")
+}
+
+func TestValidatePrintProblems(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	assert.True(t, printProblems(&out, blueprint.Blueprint{}))
+	assert.Equal(t, "The blueprint is valid.\n", out.String())
+
+	out.Reset()
+	assert.False(t, printProblems(&out, blueprint.Blueprint{
+		Placements: []blueprint.Placement{{TargetContainer: "missing"}},
+		Connections: []blueprint.Connection{{
+			From:    []string{"public"},
+			To:      []string{"missing"},
+			MinPort: 80,
+			MaxPort: 80,
+		}},
+	}))
+	assert.Equal(t, "Found 2 problems in the blueprint:\n"+
+		`  - connection from public to missing on ports 80-80: unknown `+
+		`hostname "missing"`+"\n"+
+		`  - placement: target container "missing" does not exist`+"\n",
+		out.String())
+}
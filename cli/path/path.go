@@ -22,6 +22,10 @@ var (
 	// DefaultKubeSecretPath is the default location for the secret used to
 	// encrypt Kubernetes resources in Etcd.
 	DefaultKubeSecretPath = filepath.Join(keldaHome, "kube_etcd_secret")
+
+	// DefaultDeploymentHistoryPath is where the daemon records the blueprints
+	// it has deployed.
+	DefaultDeploymentHistoryPath = filepath.Join(keldaHome, "deployments.json")
 )
 
 var (
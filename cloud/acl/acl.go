@@ -5,6 +5,18 @@ type ACL struct {
 	CidrIP  string
 	MinPort int
 	MaxPort int
+
+	// The protocol allowed by the ACL: tcp, udp or icmp. If empty, all three
+	// are allowed. ICMP ACLs have no ports.
+	Protocol string
+}
+
+// Protocols returns the protocols allowed by the ACL.
+func (acl ACL) Protocols() []string {
+	if acl.Protocol == "" {
+		return []string{"tcp", "udp", "icmp"}
+	}
+	return []string{acl.Protocol}
 }
 
 // Slice is an alias for []ACL to allow for joins
@@ -208,6 +208,12 @@ func TestNewACLs(t *testing.T) {
 			MinPort: 80,
 			MaxPort: 80,
 		},
+		{
+			CidrIP:   "baz",
+			MinPort:  53,
+			MaxPort:  53,
+			Protocol: "udp",
+		},
 	})
 
 	assert.Nil(t, err)
@@ -258,6 +264,16 @@ func TestNewACLs(t *testing.T) {
 			ToPort:     aws.Int64(-1),
 			IpProtocol: aws.String("icmp"),
 		},
+		{
+			IpRanges: []*ec2.IpRange{
+				{
+					CidrIp: aws.String("baz"),
+				},
+			},
+			FromPort:   aws.Int64(53),
+			ToPort:     aws.Int64(53),
+			IpProtocol: aws.String("udp"),
+		},
 		{
 			IpRanges: []*ec2.IpRange{
 				{
@@ -43,16 +43,19 @@ var c = counter.New("Amazon")
 func (ac awsClient) DescribeInstances(filters []*ec2.Filter) (
 	*ec2.DescribeInstancesOutput, error) {
 	c.Inc("List Instances")
+	defer c.Time("List Instances")()
 	return ac.client.DescribeInstances(&ec2.DescribeInstancesInput{Filters: filters})
 }
 
 func (ac awsClient) RunInstances(in *ec2.RunInstancesInput) (*ec2.Reservation, error) {
 	c.Inc("Run Instances")
+	defer c.Time("Run Instances")()
 	return ac.client.RunInstances(in)
 }
 
 func (ac awsClient) TerminateInstances(ids []string) error {
 	c.Inc("Term Instances")
+	defer c.Time("Term Instances")()
 	_, err := ac.client.TerminateInstances(&ec2.TerminateInstancesInput{
 		InstanceIds: stringSlice(ids)})
 	return err
@@ -61,6 +64,7 @@ func (ac awsClient) TerminateInstances(ids []string) error {
 func (ac awsClient) DescribeSpotInstanceRequests(ids []string, filters []*ec2.Filter) (
 	[]*ec2.SpotInstanceRequest, error) {
 	c.Inc("List Spots")
+	defer c.Time("List Spots")()
 	resp, err := ac.client.DescribeSpotInstanceRequests(
 		&ec2.DescribeSpotInstanceRequestsInput{
 			SpotInstanceRequestIds: stringSlice(ids),
@@ -72,6 +76,7 @@ func (ac awsClient) RequestSpotInstances(spotPrice string, count int64,
 	launchSpec *ec2.RequestSpotLaunchSpecification) (
 	[]*ec2.SpotInstanceRequest, error) {
 	c.Inc("Request Spots")
+	defer c.Time("Request Spots")()
 
 	resp, err := ac.client.RequestSpotInstances(&ec2.RequestSpotInstancesInput{
 		SpotPrice:           &spotPrice,
@@ -84,6 +89,7 @@ func (ac awsClient) RequestSpotInstances(spotPrice string, count int64,
 }
 func (ac awsClient) CancelSpotInstanceRequests(ids []string) error {
 	c.Inc("Cancel Spots")
+	defer c.Time("Cancel Spots")()
 	_, err := ac.client.CancelSpotInstanceRequests(
 		&ec2.CancelSpotInstanceRequestsInput{
 			SpotInstanceRequestIds: stringSlice(ids)})
@@ -92,6 +98,7 @@ func (ac awsClient) CancelSpotInstanceRequests(ids []string) error {
 
 func (ac awsClient) DescribeSecurityGroup(name string) ([]*ec2.SecurityGroup, error) {
 	c.Inc("List Security Groups")
+	defer c.Time("List Security Groups")()
 	resp, err := ac.client.DescribeSecurityGroups(&ec2.DescribeSecurityGroupsInput{
 		Filters: []*ec2.Filter{{
 			Name:   aws.String("group-name"),
@@ -104,6 +111,7 @@ func (ac awsClient) DescribeSecurityGroup(name string) ([]*ec2.SecurityGroup, er
 
 func (ac awsClient) CreateSecurityGroup(name, description string) (string, error) {
 	c.Inc("Create Security Group")
+	defer c.Time("Create Security Group")()
 	csgResp, err := ac.client.CreateSecurityGroup(&ec2.CreateSecurityGroupInput{
 		GroupName:   &name,
 		Description: &description})
@@ -115,6 +123,7 @@ func (ac awsClient) CreateSecurityGroup(name, description string) (string, error
 
 func (ac awsClient) DeleteSecurityGroup(id string) error {
 	c.Inc("Delete Security Group")
+	defer c.Time("Delete Security Group")()
 	_, err := ac.client.DeleteSecurityGroup(
 		&ec2.DeleteSecurityGroupInput{GroupId: &id})
 	return err
@@ -123,6 +132,7 @@ func (ac awsClient) DeleteSecurityGroup(id string) error {
 func (ac awsClient) AuthorizeSecurityGroup(name, src string,
 	ranges []*ec2.IpPermission) error {
 	c.Inc("Authorize Security Group")
+	defer c.Time("Authorize Security Group")()
 
 	var srcPtr *string
 	if src != "" {
@@ -139,6 +149,7 @@ func (ac awsClient) AuthorizeSecurityGroup(name, src string,
 
 func (ac awsClient) RevokeSecurityGroup(name string, ranges []*ec2.IpPermission) error {
 	c.Inc("Revoke Security Group")
+	defer c.Time("Revoke Security Group")()
 	_, err := ac.client.RevokeSecurityGroupIngress(
 		&ec2.RevokeSecurityGroupIngressInput{
 			GroupName:     &name,
@@ -148,6 +159,7 @@ func (ac awsClient) RevokeSecurityGroup(name string, ranges []*ec2.IpPermission)
 
 func (ac awsClient) DescribeAddresses() ([]*ec2.Address, error) {
 	c.Inc("List Addresses")
+	defer c.Time("List Addresses")()
 	resp, err := ac.client.DescribeAddresses(nil)
 	if err != nil {
 		return nil, err
@@ -157,6 +169,7 @@ func (ac awsClient) DescribeAddresses() ([]*ec2.Address, error) {
 
 func (ac awsClient) AssociateAddress(id, allocationID string) error {
 	c.Inc("Associate Address")
+	defer c.Time("Associate Address")()
 	_, err := ac.client.AssociateAddress(&ec2.AssociateAddressInput{
 		InstanceId:   &id,
 		AllocationId: &allocationID})
@@ -165,6 +178,7 @@ func (ac awsClient) AssociateAddress(id, allocationID string) error {
 
 func (ac awsClient) DisassociateAddress(associationID string) error {
 	c.Inc("Disassociate Address")
+	defer c.Time("Disassociate Address")()
 	_, err := ac.client.DisassociateAddress(&ec2.DisassociateAddressInput{
 		AssociationId: &associationID})
 	return err
@@ -172,6 +186,7 @@ func (ac awsClient) DisassociateAddress(associationID string) error {
 
 func (ac awsClient) DescribeVolumes() ([]*ec2.Volume, error) {
 	c.Inc("List Volumes")
+	defer c.Time("List Volumes")()
 	resp, err := ac.client.DescribeVolumes(nil)
 	if err != nil {
 		return nil, err
@@ -40,6 +40,7 @@ type provider interface {
 }
 
 var c = counter.New("Cloud")
+var machineStatusC = counter.New("Machine Status")
 
 type cloud struct {
 	conn db.Conn
@@ -64,6 +65,8 @@ func Run(conn db.Conn, adminSSHKey string) {
 	var ns string
 	stop := make(chan struct{})
 	for range conn.TriggerTick(60, db.BlueprintTable, db.MachineTable).C {
+		updateMachineMetrics(conn)
+
 		newns, _ := conn.GetBlueprintNamespace()
 		if newns == ns {
 			continue
@@ -80,6 +83,25 @@ func Run(conn db.Conn, adminSSHKey string) {
 	}
 }
 
+// updateMachineMetrics sets a gauge for each machine status to the number of
+// machines with that status.
+func updateMachineMetrics(conn db.Conn) {
+	counts := map[string]float64{}
+	for _, status := range []string{db.Stopping, db.Booting, db.Connecting,
+		db.Reconnecting, db.Connected} {
+		counts[status] = 0
+	}
+
+	for _, m := range conn.SelectFromMachine(nil) {
+		status := m.Status
+		if status == "" {
+			status = "unknown"
+		}
+		counts[status]++
+	}
+	machineStatusC.SetAll(counts)
+}
+
 func startClouds(conn db.Conn, ns string, stop chan struct{}) {
 	for _, p := range db.AllProviders {
 		for _, r := range ValidRegions(p) {
@@ -438,3 +460,58 @@ var newProvider = newProviderImpl
 
 // ValidRegions returns a list of supported regions for a given cloud provider
 var ValidRegions = validRegionsImpl
+
+// ValidateBlueprint checks the blueprint with blueprint.Validate, and also
+// checks that the providers and regions of its machines are supported. All
+// problems are reported in a single blueprint.ValidationError.
+func ValidateBlueprint(bp blueprint.Blueprint) error {
+	var problems blueprint.ValidationError
+	if err := blueprint.Validate(bp); err != nil {
+		problems = err.(blueprint.ValidationError)
+	}
+
+	// Only report each unsupported provider and region once.
+	checked := map[[2]string]struct{}{}
+	for i, m := range bp.Machines {
+		key := [2]string{m.Provider, m.Region}
+		if _, ok := checked[key]; ok {
+			continue
+		}
+		checked[key] = struct{}{}
+
+		if !providerSupported(m.Provider) {
+			problems = append(problems, fmt.Sprintf(
+				"machine %d: unsupported provider %q", i, m.Provider))
+			continue
+		}
+
+		if !regionSupported(db.ProviderName(m.Provider), m.Region) {
+			problems = append(problems, fmt.Sprintf(
+				"machine %d: region %q is not supported for provider %s",
+				i, m.Region, m.Provider))
+		}
+	}
+
+	if len(problems) != 0 {
+		return problems
+	}
+	return nil
+}
+
+func providerSupported(provider string) bool {
+	for _, p := range db.AllProviders {
+		if string(p) == provider {
+			return true
+		}
+	}
+	return false
+}
+
+func regionSupported(provider db.ProviderName, region string) bool {
+	for _, r := range ValidRegions(provider) {
+		if r == region {
+			return true
+		}
+	}
+	return false
+}
@@ -419,3 +419,31 @@ func mock() {
 	ValidRegions = fakeValidRegions
 	db.AllProviders = []db.ProviderName{FakeAmazon, FakeVagrant}
 }
+
+func TestValidateBlueprint(t *testing.T) {
+	mock()
+
+	bp := blueprint.Blueprint{Machines: []blueprint.Machine{
+		{Provider: "FakeAmazon", Region: testRegion, Role: "Master"},
+		{Provider: "FakeAmazon", Region: testRegion, Role: "Worker"},
+	}}
+	assert.NoError(t, ValidateBlueprint(bp))
+
+	bp.Machines = []blueprint.Machine{
+		{Provider: "FakeAmazon", Region: "bad region", Role: "Master"},
+		{Provider: "FakeAmazon", Region: "bad region", Role: "Worker"},
+	}
+	assert.EqualError(t, ValidateBlueprint(bp), "invalid blueprint: "+
+		`machine 0: region "bad region" is not supported for provider `+
+		"FakeAmazon")
+
+	// Problems found by blueprint.Validate are reported along with
+	// unsupported providers.
+	bp.Machines = []blueprint.Machine{
+		{Provider: "Azure", Region: testRegion, Role: "Master"},
+	}
+	bp.Placements = []blueprint.Placement{{TargetContainer: "missing"}}
+	assert.EqualError(t, ValidateBlueprint(bp), "invalid blueprint: "+
+		`placement: target container "missing" does not exist; `+
+		`machine 0: unsupported provider "Azure"`)
+}
@@ -98,7 +98,7 @@ func generateAndInstallCerts(machine db.Machine, sshKey ssh.Signer,
 	// The certificate CommonName and Organization is configured to allow
 	// Kubelets to authenticate with the Kubernetes API server.
 	subject := pkix.Name{
-		CommonName:   "system:node:" + machine.PrivateIP,
+		CommonName:   "system:node:" + nodeName(machine),
 		Organization: []string{"system:nodes"},
 	}
 	signed, err := rsa.NewSigned(ca, subject, net.ParseIP(machine.PrivateIP))
@@ -207,3 +207,14 @@ func getSftpFsImpl(host string, sshKey ssh.Signer) (sftpFs, error) {
 
 // Saved in a variable to allow injecting a memory filesystem during unit testing.
 var getSftpFs = getSftpFsImpl
+
+// nodeName returns the name of the machine's Kubernetes node, which must match
+// the name set by the minion's supervisor. Nodes are named by their private IP,
+// except on Google, where Kubernetes' cloud provider requires nodes to be
+// named after their instances.
+func nodeName(machine db.Machine) string {
+	if machine.Provider == db.Google {
+		return machine.CloudID
+	}
+	return machine.PrivateIP
+}
@@ -61,6 +61,17 @@ func TestSyncCredentialsTLS(t *testing.T) {
 	assert.NotEmpty(t, caBytes)
 }
 
+func TestNodeName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "9.9.9.9", nodeName(db.Machine{Provider: db.Amazon,
+		CloudID: "i-1", PrivateIP: "9.9.9.9"}))
+	assert.Equal(t, "9.9.9.9", nodeName(db.Machine{Provider: db.DigitalOcean,
+		CloudID: "1", PrivateIP: "9.9.9.9"}))
+	assert.Equal(t, "kelda-1", nodeName(db.Machine{Provider: db.Google,
+		CloudID: "kelda-1", PrivateIP: "9.9.9.9"}))
+}
+
 func TestExistingTLSCredentialsDontGetOverwritten(t *testing.T) {
 	conn := db.New()
 	mockFs := afero.NewMemMapFs()
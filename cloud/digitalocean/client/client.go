@@ -45,27 +45,32 @@ var c = counter.New("Digital Ocean")
 func (client client) CreateDroplets(req *godo.DropletMultiCreateRequest) ([]godo.Droplet,
 	*godo.Response, error) {
 	c.Inc("Create Droplet")
+	defer c.Time("Create Droplet")()
 	return client.droplets.CreateMultiple(context.Background(), req)
 }
 
 func (client client) DeleteDroplet(id int) (*godo.Response, error) {
 	c.Inc("Delete Droplet")
+	defer c.Time("Delete Droplet")()
 	return client.droplets.Delete(context.Background(), id)
 }
 
 func (client client) GetDroplet(id int) (*godo.Droplet, *godo.Response, error) {
 	c.Inc("Get Droplet")
+	defer c.Time("Get Droplet")()
 	return client.droplets.Get(context.Background(), id)
 }
 
 func (client client) ListDroplets(opt *godo.ListOptions) ([]godo.Droplet,
 	*godo.Response, error) {
 	c.Inc("List Droplets")
+	defer c.Time("List Droplets")()
 	return client.droplets.List(context.Background(), opt)
 }
 
 func (client client) CreateTag(name string) (*godo.Tag, *godo.Response, error) {
 	c.Inc("Create Tag")
+	defer c.Time("Create Tag")()
 	return client.tags.Create(context.Background(),
 		&godo.TagCreateRequest{
 			Name: name,
@@ -76,18 +81,21 @@ func (client client) CreateTag(name string) (*godo.Tag, *godo.Response, error) {
 func (client client) ListFloatingIPs(opt *godo.ListOptions) ([]godo.FloatingIP,
 	*godo.Response, error) {
 	c.Inc("List Floating IPs")
+	defer c.Time("List Floating IPs")()
 	return client.floatingIPs.List(context.Background(), opt)
 }
 
 func (client client) AssignFloatingIP(ip string, id int) (*godo.Action,
 	*godo.Response, error) {
 	c.Inc("Assign Floating IP")
+	defer c.Time("Assign Floating IP")()
 	return client.floatingIPActions.Assign(context.Background(), ip, id)
 }
 
 func (client client) UnassignFloatingIP(ip string) (*godo.Action, *godo.Response,
 	error) {
 	c.Inc("Remove Floating IP")
+	defer c.Time("Remove Floating IP")()
 	return client.floatingIPActions.Unassign(context.Background(), ip)
 }
 
@@ -95,6 +103,7 @@ func (client client) AddRules(id string, rules []godo.InboundRule) (*godo.Respon
 	error) {
 
 	c.Inc("Add Rules")
+	defer c.Time("Add Rules")()
 	return client.acls.AddRules(context.Background(), id, &godo.FirewallRulesRequest{
 		InboundRules: rules,
 	})
@@ -104,6 +113,7 @@ func (client client) RemoveRules(id string, rules []godo.InboundRule) (*godo.Res
 	error) {
 
 	c.Inc("Remove Rules")
+	defer c.Time("Remove Rules")()
 	return client.acls.RemoveRules(context.Background(), id,
 		&godo.FirewallRulesRequest{
 			InboundRules: rules,
@@ -115,6 +125,7 @@ func (client client) CreateFirewall(tag string, outbound []godo.OutboundRule,
 	inbound []godo.InboundRule) (*godo.Firewall, *godo.Response, error) {
 
 	c.Inc("Create Firewall")
+	defer c.Time("Create Firewall")()
 	req := &godo.FirewallRequest{
 		Name:          tag,
 		OutboundRules: outbound,
@@ -126,6 +137,7 @@ func (client client) CreateFirewall(tag string, outbound []godo.OutboundRule,
 
 func (client client) DeleteFirewall(fID string) (*godo.Response, error) {
 	c.Inc("Delete Firewall")
+	defer c.Time("Delete Firewall")()
 	return client.acls.Delete(context.Background(), fID)
 }
 
@@ -133,6 +145,7 @@ func (client client) ListFirewalls(opt *godo.ListOptions) ([]godo.Firewall,
 	*godo.Response, error) {
 
 	c.Inc("List Firewalls")
+	defer c.Time("List Firewalls")()
 	return client.acls.List(context.Background(), opt)
 }
 
@@ -486,6 +486,7 @@ func TestToRules(t *testing.T) {
 		{CidrIP: "3.0.0.0/8", MinPort: 0, MaxPort: 100},
 		{CidrIP: "1.0.0.0/8", MinPort: 4000, MaxPort: 4000},
 		{CidrIP: "1.0.0.0/8", MinPort: 500, MaxPort: 600},
+		{CidrIP: "4.0.0.0/8", MinPort: 53, MaxPort: 53, Protocol: "udp"},
 	}
 	srcForIP := func(ip string) *godo.Sources {
 		return &godo.Sources{Addresses: []string{ip}}
@@ -510,6 +511,9 @@ func TestToRules(t *testing.T) {
 		{Protocol: "tcp", PortRange: "500-600", Sources: srcForIP("1.0.0.0/8")},
 		{Protocol: "udp", PortRange: "500-600", Sources: srcForIP("1.0.0.0/8")},
 		// Nor do we want one here.
+
+		// ACLs with a protocol only allow that protocol.
+		{Protocol: "udp", PortRange: "53", Sources: srcForIP("4.0.0.0/8")},
 	}
 	assert.Equal(t, godoRules, toRules(acls))
 }
@@ -98,28 +98,34 @@ func getProjectID(configStr string) (string, error) {
 
 func (ci *client) GetInstance(zone, id string) (*compute.Instance, error) {
 	c.Inc("Get Instance")
+	defer c.Time("Get Instance")()
 	return ci.gce.Instances.Get(ci.projID, zone, id).Do()
 }
 
 func (ci *client) ListInstances(zone, desc string) (*compute.InstanceList, error) {
 	c.Inc("List Instances")
+	defer c.Time("List Instances")()
 	return ci.gce.Instances.List(ci.projID, zone).Filter(descFilter(desc)).Do()
 }
 
 func (ci *client) InsertInstance(zone string, instance *compute.Instance) (
 	*compute.Operation, error) {
 	c.Inc("Insert Instance")
+	defer c.Time("Insert Instance")()
 	return ci.gce.Instances.Insert(ci.projID, zone, instance).Do()
 }
 
 func (ci *client) DeleteInstance(zone, instance string) (*compute.Operation,
 	error) {
+	c.Inc("Delete Instance")
+	defer c.Time("Delete Instance")()
 	return ci.gce.Instances.Delete(ci.projID, zone, instance).Do()
 }
 
 func (ci *client) AddAccessConfig(zone, instance, networkInterface string,
 	accessConfig *compute.AccessConfig) (*compute.Operation, error) {
 	c.Inc("Add Access Config")
+	defer c.Time("Add Access Config")()
 	return ci.gce.Instances.AddAccessConfig(ci.projID, zone, instance,
 		networkInterface, accessConfig).Do()
 }
@@ -127,62 +133,73 @@ func (ci *client) AddAccessConfig(zone, instance, networkInterface string,
 func (ci *client) DeleteAccessConfig(zone, instance, accessConfig,
 	networkInterface string) (*compute.Operation, error) {
 	c.Inc("Delete Access Config")
+	defer c.Time("Delete Access Config")()
 	return ci.gce.Instances.DeleteAccessConfig(ci.projID, zone, instance,
 		accessConfig, networkInterface).Do()
 }
 
 func (ci *client) GetZone(zone string) (*compute.Zone, error) {
 	c.Inc("Get Zone")
+	defer c.Time("Get Zone")()
 	return ci.gce.Zones.Get(ci.projID, zone).Do()
 }
 
 func (ci *client) GetZoneOperation(zone, operation string) (
 	*compute.Operation, error) {
 	c.Inc("Get Zone Op")
+	defer c.Time("Get Zone Op")()
 	return ci.gce.ZoneOperations.Get(ci.projID, zone, operation).Do()
 }
 
 func (ci *client) GetGlobalOperation(operation string) (*compute.Operation,
 	error) {
 	c.Inc("Get Global Op")
+	defer c.Time("Get Global Op")()
 	return ci.gce.GlobalOperations.Get(ci.projID, operation).Do()
 }
 
 func (ci *client) ListFloatingIPs(region string) (*compute.AddressList, error) {
 	c.Inc("List Floating IPs")
+	defer c.Time("List Floating IPs")()
 	return ci.gce.Addresses.List(ci.projID, region).Do()
 }
 
 func (ci *client) ListFirewalls(description string) (*compute.FirewallList, error) {
 	c.Inc("List Firewalls")
+	defer c.Time("List Firewalls")()
 	return ci.gce.Firewalls.List(ci.projID).Filter(descFilter(description)).Do()
 }
 
 func (ci *client) InsertFirewall(firewall *compute.Firewall) (
 	*compute.Operation, error) {
 	c.Inc("Insert Firewall")
+	defer c.Time("Insert Firewall")()
 	return ci.gce.Firewalls.Insert(ci.projID, firewall).Do()
 }
 
 func (ci *client) DeleteFirewall(firewall string) (
 	*compute.Operation, error) {
 	c.Inc("Delete Firewall")
+	defer c.Time("Delete Firewall")()
 	return ci.gce.Firewalls.Delete(ci.projID, firewall).Do()
 }
 
 func (ci *client) ListNetworks(name string) (*compute.NetworkList, error) {
 	c.Inc("List Networks")
+	defer c.Time("List Networks")()
 	return ci.gce.Networks.List(ci.projID).Filter(
 		fmt.Sprintf("name eq %s", name)).Do()
 }
 
 func (ci *client) InsertNetwork(network *compute.Network) (*compute.Operation, error) {
 	c.Inc("Insert Network")
+	defer c.Time("Insert Network")()
 	return ci.gce.Networks.Insert(ci.projID, network).Do()
 }
 
 func (ci *client) DeleteNetwork(network string) (*compute.Operation, error) {
 	c.Inc("Delete Network")
+	defer c.Time("Delete Network")()
 	return ci.gce.Networks.Delete(ci.projID, network).Do()
 }
 
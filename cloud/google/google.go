@@ -244,14 +244,33 @@ func (prvdr Provider) instanceConfig(name, size, cloudConfig string) *compute.In
 				Value: &cloudConfig,
 			}},
 		},
+		// Kubernetes uses the machine's service account to create and attach
+		// the disks of cloud volumes.
+		ServiceAccounts: []*compute.ServiceAccount{{
+			Email:  "default",
+			Scopes: []string{compute.ComputeScope},
+		}},
 	}
 }
 
 func (prvdr *Provider) parseACL(fw *compute.Firewall) (gACL, error) {
-	if len(fw.SourceRanges) != 1 || len(fw.Allowed) != 3 {
+	if len(fw.SourceRanges) != 1 ||
+		(len(fw.Allowed) != 3 && len(fw.Allowed) != 1) {
 		return gACL{}, errors.New("malformed firewall")
 	}
 
+	acl := gACL{name: fw.Name}
+	acl.CidrIP = fw.SourceRanges[0]
+
+	// Firewalls for ACLs without a protocol allow TCP, UDP and ICMP. The rest
+	// only allow the ACL's protocol.
+	if len(fw.Allowed) == 1 {
+		acl.Protocol = fw.Allowed[0].IPProtocol
+		if acl.Protocol == "icmp" {
+			return acl, nil
+		}
+	}
+
 	var portsStr string
 	for _, allowed := range fw.Allowed {
 		if allowed.IPProtocol == "icmp" {
@@ -278,9 +297,6 @@ func (prvdr *Provider) parseACL(fw *compute.Firewall) (gACL, error) {
 		ports = append(ports, portInt)
 	}
 
-	acl := gACL{name: fw.Name}
-	acl.CidrIP = fw.SourceRanges[0]
-
 	switch len(ports) {
 	case 1:
 		acl.MinPort, acl.MaxPort = ports[0], ports[0]
@@ -347,6 +363,9 @@ func (prvdr *Provider) planSetACLs(cloudFWs []*compute.Firewall, acls []acl.ACL)
 		ip = strings.Replace(ip, "/", "-", -1)
 		name := fmt.Sprintf("%s-%s-%d-%d", prvdr.network, ip,
 			a.MinPort, a.MaxPort)
+		if a.Protocol != "" {
+			name += "-" + a.Protocol
+		}
 		gacls = append(gacls, gACL{name: name, ACL: a})
 	}
 
@@ -359,20 +378,23 @@ func (prvdr *Provider) planSetACLs(cloudFWs []*compute.Firewall, acls []acl.ACL)
 	for _, a := range adds {
 		acl := a.(gACL)
 		ports := fmt.Sprintf("%d-%d", acl.MinPort, acl.MaxPort)
+
+		var allowed []*compute.FirewallAllowed
+		for _, protocol := range acl.Protocols() {
+			rule := &compute.FirewallAllowed{IPProtocol: protocol}
+			if protocol != "icmp" {
+				rule.Ports = []string{ports}
+			}
+			allowed = append(allowed, rule)
+		}
+
 		add = append(add, &compute.Firewall{
 			Name:         acl.name,
 			Network:      prvdr.networkURL(),
 			Description:  prvdr.network,
 			SourceRanges: []string{acl.CidrIP},
-			Allowed: []*compute.FirewallAllowed{{
-				IPProtocol: "tcp",
-				Ports:      []string{ports},
-			}, {
-				IPProtocol: "udp",
-				Ports:      []string{ports},
-			}, {
-				IPProtocol: "icmp",
-			}}})
+			Allowed:      allowed,
+		})
 	}
 
 	return
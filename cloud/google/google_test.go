@@ -3,6 +3,7 @@ package google
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"testing"
 	"time"
 
@@ -197,6 +198,38 @@ func TestParseACL(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, gACL{"name", acl.ACL{CidrIP: "1.2.3.4/32",
 		MinPort: 1, MaxPort: 2}}, gacl)
+
+	// Single protocol
+	gacl, err = gce.parseACL(&compute.Firewall{
+		Name:         "name",
+		SourceRanges: []string{"1.2.3.4/32"},
+		Allowed: []*compute.FirewallAllowed{{
+			IPProtocol: "udp",
+			Ports:      []string{"53-53"},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, gACL{"name", acl.ACL{CidrIP: "1.2.3.4/32",
+		MinPort: 53, MaxPort: 53, Protocol: "udp"}}, gacl)
+
+	gacl, err = gce.parseACL(&compute.Firewall{
+		Name:         "name",
+		SourceRanges: []string{"1.2.3.4/32"},
+		Allowed:      []*compute.FirewallAllowed{{IPProtocol: "icmp"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, gACL{"name", acl.ACL{CidrIP: "1.2.3.4/32",
+		Protocol: "icmp"}}, gacl)
+
+	_, err = gce.parseACL(&compute.Firewall{
+		SourceRanges: []string{"1.2.3.4/32"},
+		Allowed: []*compute.FirewallAllowed{{
+			IPProtocol: "udp",
+		}, {
+			IPProtocol: "tcp",
+		}},
+	})
+	assert.EqualError(t, err, "malformed firewall")
 }
 
 func TestSetACLs(t *testing.T) {
@@ -293,8 +326,14 @@ func TestPlanSetACLs(t *testing.T) {
 		CidrIP:  "9.9.9.9/32",
 		MinPort: 3,
 		MaxPort: 4,
+	}, {
+		CidrIP:   "9.9.9.9/32",
+		MinPort:  53,
+		MaxPort:  53,
+		Protocol: "udp",
 	}})
 	assert.Equal(t, []string{"Unparseable", "Delete"}, remove)
+	sort.Slice(add, func(i, j int) bool { return add[i].Name < add[j].Name })
 	assert.Equal(t, []*compute.Firewall{{
 		Name:         "network-9-9-9-9-32-3-4",
 		Network:      gce.networkURL(),
@@ -309,6 +348,15 @@ func TestPlanSetACLs(t *testing.T) {
 		}, {
 			IPProtocol: "icmp",
 		}},
+	}, {
+		Name:         "network-9-9-9-9-32-53-53-udp",
+		Network:      gce.networkURL(),
+		Description:  gce.network,
+		SourceRanges: []string{"9.9.9.9/32"},
+		Allowed: []*compute.FirewallAllowed{{
+			IPProtocol: "udp",
+			Ports:      []string{"53-53"},
+		}},
 	}}, add)
 }
 
@@ -395,6 +443,10 @@ func TestInstanceConfig(t *testing.T) {
 				Value: &cloudConfig,
 			}},
 		},
+		ServiceAccounts: []*compute.ServiceAccount{{
+			Email:  "default",
+			Scopes: []string{compute.ComputeScope},
+		}},
 	}
 
 	assert.Equal(t, exp, res)
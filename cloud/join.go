@@ -223,10 +223,14 @@ func (cld *cloud) desiredACLs(bp db.Blueprint) map[acl.ACL]struct{} {
 
 	for _, conn := range bp.Connections {
 		if str.SliceContains(conn.From, blueprint.PublicInternetLabel) {
+			// Traffic arrives on the public ports before it's forwarded
+			// to the container.
+			min, max := conn.PublicPorts()
 			acl := acl.ACL{
-				CidrIP:  "0.0.0.0/0",
-				MinPort: conn.MinPort,
-				MaxPort: conn.MaxPort,
+				CidrIP:   "0.0.0.0/0",
+				MinPort:  min,
+				MaxPort:  max,
+				Protocol: conn.Protocol,
 			}
 			aclSet[acl] = struct{}{}
 		}
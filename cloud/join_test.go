@@ -334,6 +334,41 @@ func TestDesiredACLs(t *testing.T) {
 	})
 	exp[acl.ACL{CidrIP: "0.0.0.0/0", MinPort: 1, MaxPort: 2}] = struct{}{}
 	assert.Equal(t, exp, acls)
+
+	// The protocol of connections from public is preserved.
+	acls = cld.desiredACLs(db.Blueprint{
+		Blueprint: blueprint.Blueprint{
+			Connections: []blueprint.Connection{{
+				From:     []string{blueprint.PublicInternetLabel},
+				To:       []string{"bar"},
+				MinPort:  53,
+				MaxPort:  53,
+				Protocol: blueprint.UDPProtocol,
+			}},
+		},
+	})
+	delete(exp, acl.ACL{CidrIP: "0.0.0.0/0", MinPort: 1, MaxPort: 2})
+	exp[acl.ACL{CidrIP: "0.0.0.0/0", MinPort: 53, MaxPort: 53,
+		Protocol: "udp"}] = struct{}{}
+	assert.Equal(t, exp, acls)
+
+	// Connections with a public port open the public port, not the
+	// container's port.
+	acls = cld.desiredACLs(db.Blueprint{
+		Blueprint: blueprint.Blueprint{
+			Connections: []blueprint.Connection{{
+				From:       []string{blueprint.PublicInternetLabel},
+				To:         []string{"bar"},
+				MinPort:    80,
+				MaxPort:    80,
+				PublicPort: 8080,
+			}},
+		},
+	})
+	delete(exp, acl.ACL{CidrIP: "0.0.0.0/0", MinPort: 53, MaxPort: 53,
+		Protocol: "udp"})
+	exp[acl.ACL{CidrIP: "0.0.0.0/0", MinPort: 8080, MaxPort: 8080}] = struct{}{}
+	assert.Equal(t, exp, acls)
 }
 
 // Test that syncDBWithBlueprint properly syncs the SSH key information to the database.
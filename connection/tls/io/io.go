@@ -3,9 +3,11 @@
 package io
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/kelda/kelda/connection/tls"
 	"github.com/kelda/kelda/connection/tls/rsa"
@@ -15,10 +17,23 @@ import (
 const (
 	caCertFilename     = "certificate_authority.crt"
 	caKeyFilename      = "certificate_authority.key"
+	userCACertFilename = "user_certificate_authority.crt"
+	userCAKeyFilename  = "user_certificate_authority.key"
 	signedCertFilename = "kelda.crt"
 	signedKeyFilename  = "kelda.key"
+	usersFilename      = "users.json"
 )
 
+// User records a client certificate issued to a user by the daemon's
+// certificate authority.
+type User struct {
+	Name    string
+	Role    string
+	Serial  string
+	Created time.Time
+	Revoked bool
+}
+
 // File represents a file to be written to the filesystem.
 type File struct {
 	Path    string
@@ -48,17 +63,56 @@ func ReadCredentials(dir string) (tls.TLS, error) {
 
 // ReadCA reads the certificate authority contained with the directory.
 func ReadCA(dir string) (rsa.KeyPair, error) {
-	caCert, err := util.ReadFile(CACertPath(dir))
+	return readKeyPair(CACertPath(dir), CAKeyPath(dir))
+}
+
+// ReadUserCA reads the certificate authority that signs the certificates of
+// users, which is contained within the daemon's directory. It's separate from
+// the cluster's certificate authority so that user certificates are only
+// trusted by the daemon, and not by the minions, etcd or Kubernetes.
+func ReadUserCA(dir string) (rsa.KeyPair, error) {
+	return readKeyPair(UserCACertPath(dir), UserCAKeyPath(dir))
+}
+
+func readKeyPair(certPath, keyPath string) (rsa.KeyPair, error) {
+	cert, err := util.ReadFile(certPath)
 	if err != nil {
 		return rsa.KeyPair{}, fmt.Errorf("read cert: %s", err)
 	}
 
-	caKey, err := util.ReadFile(CAKeyPath(dir))
+	key, err := util.ReadFile(keyPath)
 	if err != nil {
 		return rsa.KeyPair{}, fmt.Errorf("read key: %s", err)
 	}
 
-	return rsa.New(caCert, caKey)
+	return rsa.New(cert, key)
+}
+
+// ReadUsers reads the users that have been issued certificates by the
+// certificate authority contained within the directory. It's not an error for no
+// users to have been issued yet.
+func ReadUsers(dir string) ([]User, error) {
+	usersStr, err := util.ReadFile(UsersPath(dir))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read users: %s", err)
+	}
+
+	var users []User
+	if err := json.Unmarshal([]byte(usersStr), &users); err != nil {
+		return nil, fmt.Errorf("parse users: %s", err)
+	}
+	return users, nil
+}
+
+// WriteUsers overwrites the users recorded in the directory.
+func WriteUsers(dir string, users []User) error {
+	usersJSON, err := json.MarshalIndent(users, "", "    ")
+	if err != nil {
+		return err
+	}
+	return util.WriteFile(UsersPath(dir), usersJSON, 0600)
 }
 
 // MinionFiles defines how files should be written to disk for installation on
@@ -78,6 +132,16 @@ func DaemonFiles(dir string, ca, signed rsa.KeyPair) []File {
 		File{Path: CAKeyPath(dir), Content: ca.PrivateKeyString(), Mode: 0600})
 }
 
+// UserCAFiles defines how the certificate authority for users should be written
+// to disk for use by the daemon.
+func UserCAFiles(dir string, userCA rsa.KeyPair) []File {
+	return []File{
+		{Path: UserCACertPath(dir), Content: userCA.CertString(), Mode: 0644},
+		{Path: UserCAKeyPath(dir), Content: userCA.PrivateKeyString(),
+			Mode: 0600},
+	}
+}
+
 // CACertPath defines where to write the certificate for the certificate authority.
 func CACertPath(dir string) string {
 	return filepath.Join(dir, caCertFilename)
@@ -88,11 +152,29 @@ func CAKeyPath(dir string) string {
 	return filepath.Join(dir, caKeyFilename)
 }
 
+// UserCACertPath defines where to write the certificate for the certificate
+// authority that signs user certificates.
+func UserCACertPath(dir string) string {
+	return filepath.Join(dir, userCACertFilename)
+}
+
+// UserCAKeyPath defines where to write the private key for the certificate
+// authority that signs user certificates.
+func UserCAKeyPath(dir string) string {
+	return filepath.Join(dir, userCAKeyFilename)
+}
+
 // SignedCertPath defines where to write the certificate for the signed certificate.
 func SignedCertPath(dir string) string {
 	return filepath.Join(dir, signedCertFilename)
 }
 
+// UsersPath defines where to record the users issued certificates by the
+// certificate authority.
+func UsersPath(dir string) string {
+	return filepath.Join(dir, usersFilename)
+}
+
 // SignedKeyPath defines where to write the private key for the signed certificate.
 func SignedKeyPath(dir string) string {
 	return filepath.Join(dir, signedKeyFilename)
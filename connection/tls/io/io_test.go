@@ -75,6 +75,28 @@ func TestReadDaemonCerts(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestWriteAndReadUserCA(t *testing.T) {
+	util.AppFs = afero.NewMemMapFs()
+
+	userCA, err := rsa.NewCertificateAuthority()
+	assert.NoError(t, err)
+
+	testDir := "/tls"
+	util.Mkdir(testDir, 0755)
+	for _, f := range UserCAFiles(testDir, userCA) {
+		util.WriteFile(f.Path, []byte(f.Content), f.Mode)
+	}
+
+	parsedCA, err := ReadUserCA(testDir)
+	assert.NoError(t, err)
+	assert.Equal(t, userCA.CertString(), parsedCA.CertString())
+	assert.Equal(t, userCA.PrivateKeyString(), parsedCA.PrivateKeyString())
+
+	// The user CA is kept separate from the cluster's.
+	_, err = ReadCA(testDir)
+	assert.Error(t, err)
+}
+
 func TestReadCAErrors(t *testing.T) {
 	testDir := "/tls"
 
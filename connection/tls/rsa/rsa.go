@@ -42,6 +42,11 @@ func (keyPair KeyPair) CertString() string {
 	}))
 }
 
+// SerialNumber returns the serial number of the certificate in decimal.
+func (keyPair KeyPair) SerialNumber() string {
+	return keyPair.cert.SerialNumber.String()
+}
+
 // New loads the KeyPair defined by the given PEM-encoded cert and key.
 func New(certStr, keyStr string) (KeyPair, error) {
 	keyDER, err := getDER(keyStr)
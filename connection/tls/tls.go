@@ -21,20 +21,42 @@ import (
 // this authentication scheme.
 type TLS struct {
 	keyPair tls.Certificate
+	ca      string
 	caPool  *x509.CertPool
+
+	// The certificate authorities trusted by servers to sign client
+	// certificates. If nil, servers only trust `caPool`.
+	clientCAPool *x509.CertPool
 }
 
 // ServerOpts gets the grpc options for creating a server.
 func (tlsAuth TLS) ServerOpts() []grpc.ServerOption {
+	clientCAs := tlsAuth.caPool
+	if tlsAuth.clientCAPool != nil {
+		clientCAs = tlsAuth.clientCAPool
+	}
 	return []grpc.ServerOption{grpc.Creds(
 		credentials.NewTLS(&tls.Config{
 			Certificates: []tls.Certificate{tlsAuth.keyPair},
-			ClientCAs:    tlsAuth.caPool,
+			ClientCAs:    clientCAs,
 			ClientAuth:   tls.RequireAndVerifyClientCert,
 		}),
 	)}
 }
 
+// WithClientCA returns a copy of the credentials whose servers also accept
+// clients with certificates signed by `clientCA`. Clients still only trust
+// servers signed by the original certificate authority.
+func (tlsAuth TLS) WithClientCA(clientCA string) (TLS, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(tlsAuth.ca)) ||
+		!pool.AppendCertsFromPEM([]byte(clientCA)) {
+		return TLS{}, errors.New("failed to create client CA cert pool")
+	}
+	tlsAuth.clientCAPool = pool
+	return tlsAuth, nil
+}
+
 // ClientOpts gets the grpc options for connecting as a client.
 func (tlsAuth TLS) ClientOpts() []grpc.DialOption {
 	return []grpc.DialOption{grpc.WithTransportCredentials(
@@ -95,5 +117,5 @@ func New(ca, cert, key string) (TLS, error) {
 		return TLS{}, errors.New("failed to create CA cert pool")
 	}
 
-	return TLS{keyPair, caPool}, nil
+	return TLS{keyPair: keyPair, ca: ca, caPool: caPool}, nil
 }
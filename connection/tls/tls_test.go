@@ -1,6 +1,7 @@
 package tls
 
 import (
+	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"testing"
@@ -133,3 +134,44 @@ func tryVerify(tlsCred TLS, cert string) error {
 	der, _ := pem.Decode([]byte(cert))
 	return tlsCred.verifySignedByCA([][]byte{der.Bytes}, nil)
 }
+
+func TestWithClientCA(t *testing.T) {
+	t.Parallel()
+
+	clusterCA, err := rsa.NewCertificateAuthority()
+	assert.NoError(t, err)
+	userCA, err := rsa.NewCertificateAuthority()
+	assert.NoError(t, err)
+
+	server, err := rsa.NewSigned(clusterCA, pkix.Name{})
+	assert.NoError(t, err)
+	user, err := rsa.NewSigned(userCA, pkix.Name{})
+	assert.NoError(t, err)
+
+	tlsCred, err := New(clusterCA.CertString(), server.CertString(),
+		server.PrivateKeyString())
+	assert.NoError(t, err)
+	assert.Nil(t, tlsCred.clientCAPool)
+
+	withUsers, err := tlsCred.WithClientCA(userCA.CertString())
+	assert.NoError(t, err)
+
+	// The server accepts clients signed by either certificate authority.
+	for _, signed := range []rsa.KeyPair{server, user} {
+		der, _ := pem.Decode([]byte(signed.CertString()))
+		cert, err := x509.ParseCertificate(der.Bytes)
+		assert.NoError(t, err)
+		_, err = cert.Verify(x509.VerifyOptions{
+			Roots:     withUsers.clientCAPool,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		})
+		assert.NoError(t, err)
+	}
+
+	// But clients still only trust servers signed by the cluster's.
+	assert.NoError(t, tryVerify(withUsers, server.CertString()))
+	assert.Error(t, tryVerify(withUsers, user.CertString()))
+
+	_, err = tlsCred.WithClientCA("not a certificate")
+	assert.EqualError(t, err, "failed to create client CA cert pool")
+}
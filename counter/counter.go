@@ -1,8 +1,10 @@
 package counter
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/kelda/kelda/api/pb"
 	"golang.org/x/sync/syncmap"
@@ -13,10 +15,36 @@ type Package struct {
 	name string
 }
 
+type key struct{ p, n string }
+
 // XXX: Note syncmap.Map is a prototype that will be upstreamed into the go standard
 // library on the next release.  At that time, we should switch to the standard library
 // version.
 var all = syncmap.Map{}
+var gauges = syncmap.Map{}
+var histograms = syncmap.Map{}
+
+// DefaultBuckets are the upper bounds, in seconds, of the buckets that
+// histogram observations are sorted into.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10,
+	30, 60, 300, 900}
+
+// gauge is a value that may go up and down.  The value is stored as the bits of
+// a float64 so that it can be updated atomically.
+type gauge struct {
+	pkg, name string
+	bits      uint64
+}
+
+// histogram tracks the distribution of observed durations.
+type histogram struct {
+	pkg, name string
+
+	sync.Mutex
+	buckets []uint64 // Cumulative counts, indexed parallel to DefaultBuckets.
+	count   uint64
+	sum     float64
+}
 
 // New creates a new Package with the given Name.
 func New(name string) Package {
@@ -25,11 +53,63 @@ func New(name string) Package {
 
 // Inc increments the counter `name` under the provided package.
 func (p Package) Inc(name string) {
-	key := struct{ p, n string }{p.name, name}
-	c, _ := all.LoadOrStore(key, &pb.Counter{Pkg: p.name, Name: name})
+	c, _ := all.LoadOrStore(key{p.name, name}, &pb.Counter{Pkg: p.name, Name: name})
 	atomic.AddUint64(&c.(*pb.Counter).Value, 1)
 }
 
+// Set sets the gauge `name` under the provided package to `value`.
+func (p Package) Set(name string, value float64) {
+	g, _ := gauges.LoadOrStore(key{p.name, name}, &gauge{pkg: p.name, name: name})
+	atomic.StoreUint64(&g.(*gauge).bits, math.Float64bits(value))
+}
+
+// SetAll sets the gauges under the provided package to `values`.  Gauges in the
+// package that were set previously, but aren't in `values`, are reset to zero.
+func (p Package) SetAll(values map[string]float64) {
+	gauges.Range(func(k, v interface{}) bool {
+		g := v.(*gauge)
+		if _, ok := values[g.name]; g.pkg == p.name && !ok {
+			atomic.StoreUint64(&g.bits, math.Float64bits(0))
+		}
+		return true
+	})
+
+	for name, value := range values {
+		p.Set(name, value)
+	}
+}
+
+// Observe records a duration of `seconds` in the histogram `name` under the
+// provided package.
+func (p Package) Observe(name string, seconds float64) {
+	intf, _ := histograms.LoadOrStore(key{p.name, name}, &histogram{
+		pkg:     p.name,
+		name:    name,
+		buckets: make([]uint64, len(DefaultBuckets)),
+	})
+
+	h := intf.(*histogram)
+	h.Lock()
+	for i, upper := range DefaultBuckets {
+		if seconds <= upper {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+	h.Unlock()
+}
+
+// Time starts timing an event, and returns a function that records the time
+// elapsed since the call to Time in the histogram `name`.  It's intended to be
+// used with defer, e.g. `defer c.Time("List Instances")()`.
+func (p Package) Time(name string) func() {
+	start := time.Now()
+	return func() {
+		p.Observe(name, time.Since(start).Seconds())
+	}
+}
+
 var dumpMutex = sync.Mutex{}
 
 // Dump returns a list of all in no particular order.
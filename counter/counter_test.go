@@ -1,6 +1,7 @@
 package counter
 
 import (
+	"math"
 	"sync"
 	"testing"
 
@@ -63,3 +64,55 @@ func TestCounter(t *testing.T) {
 	assert.Contains(t, res, &pb.Counter{
 		Pkg: "b", Name: "1", Value: 1001000, PrevValue: 1001000})
 }
+
+func TestGauge(t *testing.T) {
+	p := New("gauge")
+	p.Set("x", 1.5)
+	p.Set("y", 2)
+	assert.Equal(t, map[string]float64{"x": 1.5, "y": 2}, gaugeValues("gauge"))
+
+	p.SetAll(map[string]float64{"y": 3, "z": 4})
+	assert.Equal(t, map[string]float64{"x": 0, "y": 3, "z": 4},
+		gaugeValues("gauge"))
+
+	// Gauges in other packages should be unaffected by SetAll.
+	New("other gauge").Set("x", 5)
+	p.SetAll(nil)
+	assert.Equal(t, map[string]float64{"x": 0, "y": 0, "z": 0},
+		gaugeValues("gauge"))
+	assert.Equal(t, map[string]float64{"x": 5}, gaugeValues("other gauge"))
+}
+
+func TestHistogram(t *testing.T) {
+	p := New("histogram")
+	p.Observe("x", 0.001)
+	p.Observe("x", 0.3)
+	p.Observe("x", 1000)
+	p.Time("x")()
+
+	intf, ok := histograms.Load(key{"histogram", "x"})
+	assert.True(t, ok)
+
+	h := intf.(*histogram)
+	assert.Equal(t, uint64(4), h.count)
+	assert.InDelta(t, 1000.301, h.sum, 0.1)
+
+	// The 5ms bucket should hold the first observation and the timed one.
+	assert.Equal(t, uint64(2), h.buckets[0])
+	// The 500ms bucket should also hold the 300ms observation.
+	assert.Equal(t, uint64(3), h.buckets[6])
+	// The 1000 second observation is larger than all the buckets.
+	assert.Equal(t, uint64(3), h.buckets[len(DefaultBuckets)-1])
+}
+
+func gaugeValues(pkg string) map[string]float64 {
+	values := map[string]float64{}
+	gauges.Range(func(_, value interface{}) bool {
+		g := value.(*gauge)
+		if g.pkg == pkg {
+			values[g.name] = math.Float64frombits(g.bits)
+		}
+		return true
+	})
+	return values
+}
@@ -0,0 +1,152 @@
+package counter
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/kelda/kelda/api/pb"
+)
+
+// The names of the metric families exported to Prometheus.  Kelda's counters are
+// identified by a package and a name, both of which are free-form strings, so
+// they're exported as labels rather than as part of the metric name.
+const (
+	counterMetric   = "kelda_counter_total"
+	gaugeMetric     = "kelda_gauge"
+	histogramMetric = "kelda_duration_seconds"
+)
+
+// MetricsPath is the HTTP path on which metrics are served.
+const MetricsPath = "/metrics"
+
+// ServeMetrics serves all counters, gauges and histograms in the Prometheus text
+// format on `addr`.  It blocks until the server fails.
+func ServeMetrics(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle(MetricsPath, Handler())
+	return http.ListenAndServe(addr, mux)
+}
+
+// Handler returns an http.Handler that responds with all counters, gauges and
+// histograms in the Prometheus text format.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w)
+	})
+}
+
+// WritePrometheus writes all counters, gauges and histograms to `w` in the
+// Prometheus text format.  Unlike Dump, it doesn't affect the previous values
+// reported by `kelda counters`.
+func WritePrometheus(w io.Writer) error {
+	var lines []string
+
+	var counters []string
+	all.Range(func(_, value interface{}) bool {
+		c := value.(*pb.Counter)
+		counters = append(counters, fmt.Sprintf("%s%s %d", counterMetric,
+			labels(c.Pkg, c.Name), atomic.LoadUint64(&c.Value)))
+		return true
+	})
+	sort.Strings(counters)
+	lines = append(lines, family(counterMetric, "counter", counters)...)
+
+	var gaugeLines []string
+	gauges.Range(func(_, value interface{}) bool {
+		g := value.(*gauge)
+		val := math.Float64frombits(atomic.LoadUint64(&g.bits))
+		gaugeLines = append(gaugeLines, fmt.Sprintf("%s%s %s", gaugeMetric,
+			labels(g.pkg, g.name), formatFloat(val)))
+		return true
+	})
+	sort.Strings(gaugeLines)
+	lines = append(lines, family(gaugeMetric, "gauge", gaugeLines)...)
+
+	// Histogram samples can't be sorted line by line because the buckets of
+	// each histogram must stay in order.
+	var hists []*histogram
+	histograms.Range(func(_, value interface{}) bool {
+		hists = append(hists, value.(*histogram))
+		return true
+	})
+	sort.Slice(hists, func(i, j int) bool {
+		if hists[i].pkg != hists[j].pkg {
+			return hists[i].pkg < hists[j].pkg
+		}
+		return hists[i].name < hists[j].name
+	})
+
+	var histogramLines []string
+	for _, h := range hists {
+		histogramLines = append(histogramLines, h.prometheusLines()...)
+	}
+	lines = append(lines, family(histogramMetric, "histogram", histogramLines)...)
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
+
+func (h *histogram) prometheusLines() []string {
+	h.Lock()
+	defer h.Unlock()
+
+	var lines []string
+	for i, upper := range DefaultBuckets {
+		lines = append(lines, fmt.Sprintf("%s_bucket%s %d", histogramMetric,
+			labels(h.pkg, h.name, "le", formatFloat(upper)), h.buckets[i]))
+	}
+	return append(lines,
+		fmt.Sprintf("%s_bucket%s %d", histogramMetric,
+			labels(h.pkg, h.name, "le", "+Inf"), h.count),
+		fmt.Sprintf("%s_sum%s %s", histogramMetric, labels(h.pkg, h.name),
+			formatFloat(h.sum)),
+		fmt.Sprintf("%s_count%s %d", histogramMetric, labels(h.pkg, h.name),
+			h.count))
+}
+
+// family prefixes the samples for a metric with a TYPE declaration.  Metrics
+// without samples are omitted.
+func family(name, typ string, samples []string) []string {
+	if len(samples) == 0 {
+		return nil
+	}
+
+	samples = append(samples, "")
+	return append([]string{fmt.Sprintf("# TYPE %s %s", name, typ)}, samples...)
+}
+
+// labels formats the Prometheus label set for the given package and name, followed
+// by any additional label key-value pairs.
+func labels(pkg, name string, extra ...string) string {
+	pairs := []string{
+		fmt.Sprintf("package=%q", escapeLabel(pkg)),
+		fmt.Sprintf("name=%q", escapeLabel(name)),
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", extra[i],
+			escapeLabel(extra[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escapeLabel strips characters that can't be safely formatted by %q in a way
+// that Prometheus understands.  Label values are otherwise free-form.
+func escapeLabel(val string) string {
+	return strings.Map(func(r rune) rune {
+		if r < ' ' || r > '~' {
+			return -1
+		}
+		return r
+	}, val)
+}
+
+func formatFloat(val float64) string {
+	return strconv.FormatFloat(val, 'g', -1, 64)
+}
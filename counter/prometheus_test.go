@@ -0,0 +1,44 @@
+package counter
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	p := New("prom \"test\"\n")
+	p.Set("gauge", 3)
+	p.Observe("hist", 0.2)
+	p.Observe("hist", 2)
+
+	var buf bytes.Buffer
+	assert.NoError(t, WritePrometheus(&buf))
+	out := buf.String()
+
+	lbls := `package="prom \"test\"",name=`
+	assert.Contains(t, out, "# TYPE kelda_gauge gauge\n")
+	assert.Contains(t, out, "kelda_gauge{"+lbls+`"gauge"} 3`+"\n")
+
+	assert.Contains(t, out, "# TYPE kelda_duration_seconds histogram\n")
+	assert.Contains(t, out, "kelda_duration_seconds_bucket{"+lbls+
+		`"hist",le="0.1"} 0`+"\n"+
+		"kelda_duration_seconds_bucket{"+lbls+`"hist",le="0.25"} 1`+"\n")
+	assert.Contains(t, out, "kelda_duration_seconds_bucket{"+lbls+
+		`"hist",le="+Inf"} 2`+"\n"+
+		"kelda_duration_seconds_sum{"+lbls+`"hist"} 2.2`+"\n"+
+		"kelda_duration_seconds_count{"+lbls+`"hist"} 2`+"\n")
+}
+
+func TestHandler(t *testing.T) {
+	New("handler").Set("gauge", 1)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", MetricsPath, nil))
+	assert.Equal(t, 200, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(),
+		`kelda_gauge{package="handler",name="gauge"} 1`)
+}
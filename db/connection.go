@@ -7,14 +7,27 @@ import (
 )
 
 // A Connection allows two hostnames to speak to each other on the port
-// range [MinPort, MaxPort] inclusive.
+// range [MinPort, MaxPort] inclusive. If Protocol is empty, both TCP and UDP are
+// allowed. ICMP connections have no ports. Connections from the public internet
+// may be forwarded from a different PublicPort.
 type Connection struct {
 	ID int `json:"-"`
 
-	From    []string
-	To      []string
-	MinPort int
-	MaxPort int
+	From       []string
+	To         []string
+	MinPort    int
+	MaxPort    int
+	Protocol   string `json:",omitempty"`
+	PublicPort int    `json:",omitempty"`
+}
+
+// PublicPorts returns the range of ports on the machine's public IP that the
+// connection forwards to [MinPort, MaxPort].
+func (c Connection) PublicPorts() (int, int) {
+	if c.PublicPort == 0 {
+		return c.MinPort, c.MaxPort
+	}
+	return c.PublicPort, c.PublicPort + c.MaxPort - c.MinPort
 }
 
 // InsertConnection creates a new connection row and inserts it into the database.
@@ -57,6 +70,18 @@ func (c Connection) String() string {
 		port += fmt.Sprintf("-%d", c.MaxPort)
 	}
 
+	if c.PublicPort != 0 {
+		port = fmt.Sprintf("%d->%s", c.PublicPort, port)
+	}
+
+	switch c.Protocol {
+	case "":
+	case "icmp":
+		port = c.Protocol
+	default:
+		port += "/" + c.Protocol
+	}
+
 	return fmt.Sprintf("Connection-%d{%s->%s:%s}", c.ID, c.From, c.To, port)
 }
 
@@ -74,6 +99,10 @@ func (c Connection) less(r row) bool {
 		return c.MaxPort < o.MaxPort
 	case c.MinPort != o.MinPort:
 		return c.MinPort < o.MinPort
+	case c.Protocol != o.Protocol:
+		return c.Protocol < o.Protocol
+	case c.PublicPort != o.PublicPort:
+		return c.PublicPort < o.PublicPort
 	default:
 		return c.ID < o.ID
 	}
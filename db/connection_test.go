@@ -30,7 +30,15 @@ func TestConnection(t *testing.T) {
 
 	connection.MaxPort = 3
 	assert.Equal(t, "Connection-1{[foo]->[]:0-3}", connection.String())
+	connection.Protocol = "udp"
+	assert.Equal(t, "Connection-1{[foo]->[]:0-3/udp}", connection.String())
+	connection.Protocol = "icmp"
+	assert.Equal(t, "Connection-1{[foo]->[]:icmp}", connection.String())
 	connection.MaxPort = 0
+	connection.Protocol = ""
+	connection.PublicPort = 8080
+	assert.Equal(t, "Connection-1{[foo]->[]:8080->0}", connection.String())
+	connection.PublicPort = 0
 
 	assert.Equal(t, connection, connections.Get(0))
 
@@ -39,6 +47,10 @@ func TestConnection(t *testing.T) {
 		To: []string{"a"}}))
 	assert.True(t, connection.less(Connection{From: []string{"foo"}, MaxPort: 1}))
 	assert.True(t, connection.less(Connection{From: []string{"foo"}, MinPort: 100}))
+	assert.True(t, connection.less(Connection{From: []string{"foo"},
+		Protocol: "tcp"}))
+	assert.True(t, connection.less(Connection{From: []string{"foo"},
+		PublicPort: 80}))
 	assert.True(t, connection.less(Connection{From: []string{"foo"}, ID: id + 1}))
 
 	assert.True(t, connection.less(Connection{From: []string{"foo", "bar"}}))
@@ -46,3 +58,13 @@ func TestConnection(t *testing.T) {
 		To: []string{"baz", "qux"}}))
 
 }
+
+func TestConnectionPublicPorts(t *testing.T) {
+	min, max := Connection{MinPort: 8000, MaxPort: 8010}.PublicPorts()
+	assert.Equal(t, 8000, min)
+	assert.Equal(t, 8010, max)
+
+	min, max = Connection{MinPort: 80, MaxPort: 80, PublicPort: 8080}.PublicPorts()
+	assert.Equal(t, 8080, min)
+	assert.Equal(t, 8080, max)
+}
@@ -20,22 +20,72 @@ type Container struct {
 	PodName           string                              `json:",omitempty"`
 	Status            string                              `json:",omitempty"`
 	Command           []string                            `json:",omitempty"`
+	Entrypoint        []string                            `json:",omitempty"`
+	WorkingDir        string                              `json:",omitempty"`
 	Env               map[string]blueprint.ContainerValue `json:",omitempty"`
 	FilepathToContent map[string]blueprint.ContainerValue `json:",omitempty"`
 	Hostname          string                              `json:",omitempty"`
 	Created           time.Time                           `json:","`
 	Privileged        bool                                `json:",omitempty"`
+	SecurityContext   *blueprint.SecurityContext          `json:",omitempty"`
 	VolumeMounts      []blueprint.VolumeMount             `json:",omitempty"`
+	Resources         *blueprint.Resources                `json:",omitempty"`
+	LivenessCheck     *blueprint.HealthCheck              `json:",omitempty"`
+	ReadinessCheck    *blueprint.HealthCheck              `json:",omitempty"`
+	UpdateStrategy    string                              `json:",omitempty"`
+	Sidecars          []blueprint.PodContainer            `json:",omitempty"`
+	InitContainers    []blueprint.PodContainer            `json:",omitempty"`
+	Job               *blueprint.Job                      `json:",omitempty"`
+
+	PreStop                       []string `json:",omitempty"`
+	TerminationGracePeriodSeconds int      `json:",omitempty"`
+
+	// The exit code of the container, and when it exited, if it's no longer
+	// running. For jobs, these describe the most recent run. If the container
+	// was restarted, ExitCode and TerminationReason describe its last
+	// termination, but Completed is unset.
+	ExitCode          int       `json:",omitempty"`
+	TerminationReason string    `json:",omitempty"`
+	Completed         time.Time `json:","`
+
+	// The number of times the container has been restarted, and why it's
+	// waiting to start, if it is. For example, a crashing container would be
+	// waiting with the reason CrashLoopBackOff.
+	RestartCount   int    `json:",omitempty"`
+	WaitingReason  string `json:",omitempty"`
+	WaitingMessage string `json:",omitempty"`
+
+	// Whether the container is passing its readiness check. Containers that
+	// aren't ready don't receive traffic from load balancers.
+	Ready bool `json:",omitempty"`
+
+	// Whether the container has been replaced in the blueprint by a container
+	// with the same hostname and the rolling update strategy. Retiring
+	// containers keep their hostname and load balancer entries until their
+	// replacement is ready, and are then removed.
+	Retiring bool `json:",omitempty"`
 
 	Image      string `json:",omitempty"`
 	Dockerfile string `json:"-"`
 }
 
 // GetReferencedSecrets returns the names of all Secrets referenced in the Env
-// and FilepathToContent maps.
+// and FilepathToContent maps, and in the environments of the sidecars and init
+// containers.
 func (c Container) GetReferencedSecrets() []string {
-	return append(getReferencedSecrets(c.Env),
+	secrets := append(getReferencedSecrets(c.Env),
 		getReferencedSecrets(c.FilepathToContent)...)
+	for _, pc := range c.PodContainers() {
+		secrets = append(secrets, getReferencedSecrets(pc.Env)...)
+	}
+	return secrets
+}
+
+// PodContainers returns the container's init containers followed by its
+// sidecars.
+func (c Container) PodContainers() []blueprint.PodContainer {
+	return append(append([]blueprint.PodContainer(nil),
+		c.InitContainers...), c.Sidecars...)
 }
 
 func getReferencedSecrets(x map[string]blueprint.ContainerValue) (secrets []string) {
@@ -115,14 +165,79 @@ func (c Container) String() string {
 		tags = append(tags, "Privileged")
 	}
 
+	if c.SecurityContext != nil {
+		tags = append(tags, "SecurityContext")
+	}
+
+	if c.Resources != nil {
+		tags = append(tags, fmt.Sprintf("Resources: %+v", *c.Resources))
+	}
+
+	if c.LivenessCheck != nil {
+		tags = append(tags, "LivenessCheck")
+	}
+
+	if c.ReadinessCheck != nil {
+		tags = append(tags, "ReadinessCheck")
+	}
+
+	for _, pc := range c.Sidecars {
+		tags = append(tags, fmt.Sprintf("Sidecar: %s", pc.Name))
+	}
+
+	for _, pc := range c.InitContainers {
+		tags = append(tags, fmt.Sprintf("InitContainer: %s", pc.Name))
+	}
+
+	if c.Job != nil {
+		if c.Job.Schedule != "" {
+			tags = append(tags, fmt.Sprintf("CronJob: %s", c.Job.Schedule))
+		} else {
+			tags = append(tags, "Job")
+		}
+	}
+
 	if len(c.Status) > 0 {
 		tags = append(tags, fmt.Sprintf("Status: %s", c.Status))
 	}
 
+	if c.UpdateStrategy != "" {
+		tags = append(tags, fmt.Sprintf("UpdateStrategy: %s",
+			c.UpdateStrategy))
+	}
+
+	if c.Ready {
+		tags = append(tags, "Ready")
+	}
+
+	if c.Retiring {
+		tags = append(tags, "Retiring")
+	}
+
 	if !c.Created.IsZero() {
 		tags = append(tags, fmt.Sprintf("Created: %s", c.Created.String()))
 	}
 
+	if !c.Completed.IsZero() {
+		tags = append(tags, fmt.Sprintf("ExitCode: %d", c.ExitCode),
+			fmt.Sprintf("Completed: %s", c.Completed.String()))
+	}
+
+	if c.RestartCount != 0 {
+		tags = append(tags, fmt.Sprintf("RestartCount: %d", c.RestartCount))
+		if c.Completed.IsZero() {
+			tags = append(tags, fmt.Sprintf("ExitCode: %d", c.ExitCode))
+		}
+	}
+
+	if c.TerminationReason != "" {
+		tags = append(tags, "TerminationReason: "+c.TerminationReason)
+	}
+
+	if c.WaitingReason != "" {
+		tags = append(tags, "WaitingReason: "+c.WaitingReason)
+	}
+
 	return fmt.Sprintf("Container-%d{%s}", c.ID, strings.Join(tags, ", "))
 }
 
@@ -65,6 +65,8 @@ func TestGetReferencedSecrets(t *testing.T) {
 	secret2 := "secret2"
 	secret3 := "secret3"
 	secret4 := "secret4"
+	secret5 := "secret5"
+	secret6 := "secret6"
 	dbc := Container{
 		Env: map[string]blueprint.ContainerValue{
 			"key1": blueprint.NewString("ignoreme"),
@@ -76,11 +78,24 @@ func TestGetReferencedSecrets(t *testing.T) {
 			"key3": blueprint.NewSecret(secret3),
 			"key4": blueprint.NewSecret(secret4),
 		},
+		Sidecars: []blueprint.PodContainer{{
+			Env: map[string]blueprint.ContainerValue{
+				"key1": blueprint.NewString("ignoreme"),
+				"key5": blueprint.NewSecret(secret5),
+			},
+		}},
+		InitContainers: []blueprint.PodContainer{{
+			Env: map[string]blueprint.ContainerValue{
+				"key6": blueprint.NewSecret(secret6),
+			},
+		}},
 	}
 	referencedSecrets := dbc.GetReferencedSecrets()
-	assert.Len(t, referencedSecrets, 4)
+	assert.Len(t, referencedSecrets, 6)
 	assert.Contains(t, referencedSecrets, secret1)
 	assert.Contains(t, referencedSecrets, secret2)
 	assert.Contains(t, referencedSecrets, secret3)
 	assert.Contains(t, referencedSecrets, secret4)
+	assert.Contains(t, referencedSecrets, secret5)
+	assert.Contains(t, referencedSecrets, secret6)
 }
@@ -52,6 +52,7 @@ var commitC = counter.New("Database Commit")
 var removeC = counter.New("Database Remove")
 var insertC = counter.New("Database Insert")
 var selectC = counter.New("Database Select")
+var transactC = counter.New("Database Transact")
 
 // New creates a connection to a brand new database.
 func New() Conn {
@@ -83,6 +84,8 @@ func (cn Conn) Txn(tables ...TableType) Transaction {
 // database without conflicting with other transactions.
 func (tr Transaction) Run(do func(db Database) error) error {
 	c.Inc("Transact")
+	defer transactC.Time(tr.String())()
+
 	tr.lockTables()
 	defer tr.unlockTables()
 
@@ -152,15 +155,53 @@ func (cn Conn) TriggerTick(seconds int, tt ...TableType) Trigger {
 // sorted order avoids deadlock between two transactionss requesting intersecting sets of
 // tables.
 func (tr Transaction) lockTables() {
+	for _, tt := range tr.sortedTables() {
+		tr.db.tables[tt].Lock()
+	}
+}
+
+func (tr Transaction) sortedTables() tableSlice {
 	tables := tableSlice{}
 	for tt := range tr.db.tables {
 		tables = append(tables, tt)
 	}
 	sort.Sort(tables)
+	return tables
+}
 
-	for _, tt := range tables {
-		tr.db.tables[tt].Lock()
+// The position of each table in AllTables, so that a set of tables can be
+// identified by a bitmask.
+var tableBits = map[TableType]uint64{}
+
+func init() {
+	for i, tt := range AllTables {
+		tableBits[tt] = 1 << uint(i)
+	}
+}
+
+// transactionNames caches the String of each set of tables, keyed by the set's
+// bitmask, so that timing a transaction doesn't build and sort the names.
+var transactionNames sync.Map
+
+// String returns the sorted, comma separated names of the tables accessible by
+// the Transaction.  It's used to distinguish the timings of different kinds of
+// transactions.
+func (tr Transaction) String() string {
+	var mask uint64
+	for tt := range tr.db.tables {
+		mask |= tableBits[tt]
+	}
+	if name, ok := transactionNames.Load(mask); ok {
+		return name.(string)
+	}
+
+	var names []string
+	for _, tt := range tr.sortedTables() {
+		names = append(names, strings.TrimPrefix(string(tt), "db."))
 	}
+	name := strings.Join(names, ",")
+	transactionNames.Store(mask, name)
+	return name
 }
 
 // Unlock all tables needed by the Transaction to perform a transact. Unlock order is
@@ -53,6 +53,7 @@ func TestAllTablesNoPanic(t *testing.T) {
 		view.InsertPlacement()
 		view.InsertContainer()
 		view.InsertConnection()
+		view.InsertStats()
 
 		return nil
 	})
@@ -405,6 +406,28 @@ func TestStringer(t *testing.T) {
 	}
 }
 
+func TestTransactionString(t *testing.T) {
+	conn := New()
+	for _, test := range []struct {
+		tables []TableType
+		exp    string
+	}{
+		{[]TableType{MachineTable, ContainerTable}, "Container,Machine"},
+		{[]TableType{ContainerTable, MachineTable}, "Container,Machine"},
+		{[]TableType{ContainerTable}, "Container"},
+		{nil, ""},
+	} {
+		// The second call uses the cached name.
+		for i := 0; i < 2; i++ {
+			actual := conn.Txn(test.tables...).String()
+			if actual != test.exp {
+				t.Errorf("Bad transaction name for %v: expected %q, "+
+					"got %q.", test.tables, test.exp, actual)
+			}
+		}
+	}
+}
+
 func TestSliceHelpers(t *testing.T) {
 	containers := []Container{
 		{BlueprintID: "3"},
@@ -9,6 +9,12 @@ import (
 
 func (conn Conn) runLogger() {
 	for _, t := range AllTables {
+		// The stats change every time they're polled, so logging them would
+		// drown out the other tables.
+		if t == StatsTable {
+			continue
+		}
+
 		t := t
 		go func() {
 			trigger := conn.Trigger(t).C
@@ -0,0 +1,98 @@
+package db
+
+import "time"
+
+// A Stats row records the resources used by a container or machine, as reported
+// by the kubelet on the machine. Stats are only tracked by the Kelda masters,
+// which poll the kubelets periodically.
+type Stats struct {
+	ID int
+
+	// The hostname of the container, or empty if the row describes the
+	// machine as a whole.
+	Hostname string `json:",omitempty"`
+
+	// The private IP of the machine.
+	Minion string
+
+	// The CPU used, in thousandths of a core.
+	CPUMillicores int
+
+	// The memory in use, and, for machines, the memory still available.
+	MemoryBytes          uint64
+	MemoryAvailableBytes uint64 `json:",omitempty"`
+
+	// The bytes received and sent since the container or machine started, and
+	// the rates at which they were received and sent since the previous
+	// sample. Containers share their network with their sidecars.
+	NetworkRxBytes       uint64
+	NetworkTxBytes       uint64
+	NetworkRxBytesPerSec uint64
+	NetworkTxBytesPerSec uint64
+
+	// The disk space used by the container's writable layer, or by the
+	// machine's root filesystem. DiskCapacityBytes is only set for machines.
+	DiskBytes         uint64
+	DiskCapacityBytes uint64 `json:",omitempty"`
+
+	// When the stats were collected.
+	Collected time.Time
+}
+
+// InsertStats creates a new stats row and inserts it into the database.
+func (db Database) InsertStats() Stats {
+	result := Stats{ID: db.nextID()}
+	db.insert(result)
+	return result
+}
+
+// SelectFromStats gets all stats in the database that satisfy 'check'.
+func (db Database) SelectFromStats(check func(Stats) bool) []Stats {
+	var result []Stats
+	for _, row := range db.selectRows(StatsTable) {
+		if check == nil || check(row.(Stats)) {
+			result = append(result, row.(Stats))
+		}
+	}
+	return result
+}
+
+// SelectFromStats gets all stats in the database connection that satisfy
+// 'check'.
+func (conn Conn) SelectFromStats(check func(Stats) bool) []Stats {
+	var result []Stats
+	conn.Txn(StatsTable).Run(func(view Database) error {
+		result = view.SelectFromStats(check)
+		return nil
+	})
+	return result
+}
+
+func (stats Stats) getID() int {
+	return stats.ID
+}
+
+func (stats Stats) tt() TableType {
+	return StatsTable
+}
+
+func (stats Stats) String() string {
+	return defaultString(stats)
+}
+
+func (stats Stats) less(r row) bool {
+	return stats.ID < r.(Stats).ID
+}
+
+// StatsSlice is an alias for []Stats to allow for joins
+type StatsSlice []Stats
+
+// Get returns the value contained at the given index
+func (slc StatsSlice) Get(ii int) interface{} {
+	return slc[ii]
+}
+
+// Len returns the number of items in the slice.
+func (slc StatsSlice) Len() int {
+	return len(slc)
+}
@@ -0,0 +1,38 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	conn := New()
+
+	var id int
+	conn.Txn(StatsTable).Run(func(view Database) error {
+		stats := view.InsertStats()
+		id = stats.ID
+		stats.Hostname = "foo"
+		view.Commit(stats)
+		return nil
+	})
+
+	statsSlice := StatsSlice(conn.SelectFromStats(
+		func(s Stats) bool { return true }))
+	assert.Equal(t, 1, statsSlice.Len())
+
+	stats := statsSlice[0]
+	assert.Equal(t, "foo", stats.Hostname)
+	assert.Equal(t, id, stats.getID())
+	assert.Equal(t, StatsTable, stats.tt())
+
+	assert.Equal(t, "Stats-1{Hostname=foo, Collected=0001-01-01 00:00:00 +0000 UTC}",
+		stats.String())
+
+	assert.Equal(t, stats, statsSlice.Get(0))
+
+	assert.True(t, stats.less(Stats{ID: id + 1}))
+}
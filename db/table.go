@@ -38,12 +38,15 @@ var ImageTable = TableType(reflect.TypeOf(Image{}).String())
 // HostnameTable is the type of the Hostname table.
 var HostnameTable = TableType(reflect.TypeOf(Hostname{}).String())
 
+// StatsTable is the type of the stats table.
+var StatsTable = TableType(reflect.TypeOf(Stats{}).String())
+
 // AllTables is a slice of all the db TableTypes. It is used primarily for tests,
 // where there is no reason to put lots of thought into which tables a Transaction
 // should use.
 var AllTables = []TableType{BlueprintTable, MachineTable, ContainerTable, MinionTable,
 	ConnectionTable, LoadBalancerTable, EtcdTable, PlacementTable, ImageTable,
-	HostnameTable}
+	HostnameTable, StatsTable}
 
 type table struct {
 	rows map[int]row
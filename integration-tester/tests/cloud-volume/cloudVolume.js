@@ -0,0 +1,22 @@
+const kelda = require('kelda');
+const infrastructure = require('../../config/infrastructure.js');
+
+const infra = infrastructure.createTestInfrastructure();
+
+// Cloud volumes are only supported on Google.
+if (infra.workers[0].provider === 'Google') {
+  const volume = new kelda.Volume({
+    name: 'disk',
+    type: 'gcePersistentDisk',
+    size: '10Gi',
+  });
+
+  (new kelda.Container({
+    name: 'cloud-volume',
+    image: 'ubuntu',
+    command: ['sh', '-c', 'echo kelda > /data/test && tail -f /dev/null'],
+    volumeMounts: [
+      new kelda.VolumeMount({ volume, mountPath: '/data' }),
+    ],
+  })).deploy(infra);
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/integration-tester/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const containerName = "cloud-volume"
+
+// TestCloudVolume checks that a disk is created and attached to the machine
+// that the mounting container is placed on. Google's cloud provider finds the
+// machine's instance by its Kubernetes node name, so this also checks that
+// the nodes are named after their instances.
+func TestCloudVolume(t *testing.T) {
+	client, _, err := util.GetDefaultDaemonClient()
+	if err != nil {
+		t.Fatalf("couldn't get api client: %s", err)
+	}
+	defer client.Close()
+
+	machines, err := client.QueryMachines()
+	if err != nil {
+		t.Fatalf("couldn't query machines: %s", err)
+	}
+
+	if len(machines) == 0 || machines[0].Provider != db.Google {
+		t.Skip("cloud volumes are only supported on Google")
+	}
+
+	containers, err := client.QueryContainers()
+	if err != nil {
+		t.Fatalf("couldn't query containers: %s", err)
+	}
+
+	var found bool
+	for _, dbc := range containers {
+		if dbc.Hostname == containerName {
+			found = true
+			assert.Equal(t, "running", dbc.Status)
+		}
+	}
+	assert.True(t, found, "the container that mounts the volume should exist")
+
+	output, err := exec.Command("kelda", "ssh", containerName,
+		"cat", "/data/test").CombinedOutput()
+	assert.NoError(t, err, string(output))
+	assert.Equal(t, "kelda", strings.TrimSpace(string(output)))
+}
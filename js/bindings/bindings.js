@@ -653,6 +653,204 @@ function getSecretOrStringMap(argName, arg) {
   return arg;
 }
 
+/**
+ * @private
+ * @param {string} argName - The name of `arg` (for logging).
+ * @param {Object} arg - The resources that might be undefined.
+ * @returns {Object|undefined} Undefined if `arg` is not defined, and otherwise
+ *   ensures that `arg` only contains CPU and memory requests and limits that
+ *   are strings, and then returns a copy of it.
+ */
+function getResources(argName, arg) {
+  if (arg === undefined) {
+    return undefined;
+  }
+  if (typeof arg !== 'object' || arg === null) {
+    throw new Error(`${argName} must be an object (was: ${stringify(arg)})`);
+  }
+
+  const resources = {};
+  Object.keys(arg).forEach((key) => {
+    if (!['cpuRequest', 'cpuLimit', 'memoryRequest', 'memoryLimit']
+      .includes(key)) {
+      throw new Error(`unrecognized key in ${argName}: ${key}`);
+    }
+    resources[key] = getString(`${argName}.${key}`, arg[key]);
+  });
+  return resources;
+}
+
+/**
+ * @private
+ * @param {string} argName - The name of `arg` (for logging).
+ * @param {Object} arg - The health check that might be undefined.
+ * @returns {Object|undefined} Undefined if `arg` is not defined, and otherwise
+ *   ensures that `arg` is a health check with exactly one of an HTTP GET
+ *   request, a TCP connection, or a command, and then returns a copy of it.
+ */
+function getHealthCheck(argName, arg) {
+  if (arg === undefined) {
+    return undefined;
+  }
+  if (typeof arg !== 'object' || arg === null) {
+    throw new Error(`${argName} must be an object (was: ${stringify(arg)})`);
+  }
+
+  // getAction copies the HTTP GET or TCP action `key`, which may only contain
+  // a path and a port.
+  const getAction = (key, allowedKeys) => {
+    const action = arg[key];
+    if (typeof action !== 'object' || action === null) {
+      throw new Error(`${argName}.${key} must be an object ` +
+        `(was: ${stringify(action)})`);
+    }
+
+    const copy = {};
+    Object.keys(action).forEach((actionKey) => {
+      if (!allowedKeys.includes(actionKey)) {
+        throw new Error(`unrecognized key in ${argName}.${key}: ${actionKey}`);
+      }
+    });
+    if (allowedKeys.includes('path')) {
+      copy.path = getString(`${argName}.${key}.path`, action.path);
+    }
+    copy.port = getNumber(`${argName}.${key}.port`, action.port);
+    return copy;
+  };
+
+  const timings = ['initialDelaySeconds', 'periodSeconds', 'timeoutSeconds',
+    'successThreshold', 'failureThreshold'];
+  const check = {};
+  Object.keys(arg).forEach((key) => {
+    if (key === 'httpGet') {
+      check.httpGet = getAction(key, ['path', 'port']);
+    } else if (key === 'tcpSocket') {
+      check.tcpSocket = getAction(key, ['port']);
+    } else if (key === 'exec') {
+      check.exec = _.clone(getStringArray(`${argName}.exec`, arg.exec));
+    } else if (timings.includes(key)) {
+      check[key] = getNumber(`${argName}.${key}`, arg[key]);
+    } else {
+      throw new Error(`unrecognized key in ${argName}: ${key}`);
+    }
+  });
+
+  const actions = ['httpGet', 'tcpSocket', 'exec']
+    .filter(key => check[key] !== undefined);
+  if (actions.length !== 1) {
+    throw new Error(`${argName} must have exactly one of httpGet, tcpSocket ` +
+      'and exec');
+  }
+  return check;
+}
+
+/**
+ * @private
+ * @param {string} argName - The name of `arg` (for logging).
+ * @param {Object} arg - The security context that might be undefined.
+ * @returns {Object|undefined} Undefined if `arg` is not defined, and otherwise
+ *   ensures that `arg` only contains valid security context options, and then
+ *   returns a copy of it.
+ */
+function getSecurityContext(argName, arg) {
+  if (arg === undefined) {
+    return undefined;
+  }
+  if (typeof arg !== 'object' || arg === null) {
+    throw new Error(`${argName} must be an object (was: ${stringify(arg)})`);
+  }
+
+  const sc = {};
+  Object.keys(arg).forEach((key) => {
+    const desc = `${argName}.${key}`;
+    if (key === 'capAdd' || key === 'capDrop') {
+      sc[key] = _.clone(getStringArray(desc, arg[key]));
+    } else if (key === 'runAsUser') {
+      sc[key] = getNumber(desc, arg[key]);
+    } else if (key === 'readOnlyRootFilesystem' || key === 'noNewPrivileges') {
+      sc[key] = getBoolean(desc, arg[key]);
+    } else if (key === 'seccompProfile' || key === 'appArmorProfile') {
+      sc[key] = getString(desc, arg[key]);
+    } else {
+      throw new Error(`unrecognized key in ${argName}: ${key}`);
+    }
+  });
+  return sc;
+}
+
+/**
+ * @private
+ * @param {string} argName - The name of `arg` (for logging).
+ * @param {Object[]} arg - The sidecars or init containers that might be
+ *   undefined.
+ * @returns {Object[]} An empty array if `arg` is not defined, and otherwise
+ *   ensures that each element of `arg` is a valid container to run in the pod
+ *   of another container, and then returns copies of them with their images
+ *   converted to Images.
+ */
+function getPodContainers(argName, arg) {
+  if (arg === undefined) {
+    return [];
+  }
+  if (!Array.isArray(arg)) {
+    throw new Error(`${argName} must be an array of objects ` +
+      `(was: ${stringify(arg)})`);
+  }
+
+  return arg.map((pc, i) => {
+    const desc = `${argName}[${i}]`;
+    if (typeof pc !== 'object' || pc === null) {
+      throw new Error(`${desc} must be an object (was: ${stringify(pc)})`);
+    }
+    Object.keys(pc).forEach((key) => {
+      if (!['name', 'image', 'command', 'env', 'volumeMounts', 'resources']
+        .includes(key)) {
+        throw new Error(`unrecognized key in ${desc}: ${key}`);
+      }
+    });
+    if (pc.name === undefined || pc.image === undefined) {
+      throw new Error(`${desc} must have a name and an image`);
+    }
+
+    let image = pc.image;
+    if (typeof image === 'string') {
+      image = new Image({ name: image });
+    }
+    if (!(image instanceof Image)) {
+      throw new Error(`${desc}.image must be an Image or string (was ` +
+        `${stringify(image)})`);
+    }
+
+    const volumeMounts = pc.volumeMounts || [];
+    assertArrayOfType(`${desc}.volumeMounts`, volumeMounts, VolumeMount);
+
+    return {
+      name: getString(`${desc}.name`, pc.name),
+      image: image.clone(),
+      command: _.clone(getStringArray(`${desc}.command`, pc.command)),
+      env: _.clone(getSecretOrStringMap(`${desc}.env`, pc.env)),
+      volumeMounts: _.clone(volumeMounts),
+      resources: getResources(`${desc}.resources`, pc.resources),
+    };
+  });
+}
+
+/**
+ * @private
+ * @param {Object[]} podContainers - Sidecars or init containers.
+ * @returns {Object[]|undefined} The containers in the format expected by the
+ *   Kelda Go code, or undefined if there are none, so that containers without
+ *   them keep the same IDs.
+ */
+function podContainersRepr(podContainers) {
+  if (podContainers.length === 0) {
+    return undefined;
+  }
+  return podContainers.map(pc => Object.assign({}, pc, {
+    volumeMounts: pc.volumeMounts.map(mount => mount.toKeldaRepresentation()),
+  }));
+}
+
 /**
  * Verifies `arg` is an array of strings or undefined.
  * @private
@@ -1105,7 +1303,16 @@ class Container {
    *   boot, or a string with the name of a Docker image (that exists in
    *   Docker Hub) that the container should boot.
    * @param {string[]} [args.command] - The command to use when starting
-   *   the container.
+   *   the container. It's passed as arguments to the image's entrypoint.
+   * @param {string[]} [args.entrypoint] - Overrides the entrypoint of the
+   *   image.
+   * @param {string} [args.workingDir] - Overrides the working directory of the
+   *   image. It must be an absolute path.
+   * @param {string[]} [args.preStop] - A command that's run in the container
+   *   before it's stopped, such as to flush a database to disk.
+   * @param {number} [args.terminationGracePeriodSeconds] - How long the
+   *   container has to exit after `preStop` starts before it's killed.
+   *   Defaults to 30 seconds.
    * @param {bool} [args.privileged] - Whether the container should be run in
    *   privileged mode. Privileged mode grants the container extended privileges,
    *   such as accessing devices on the host machine. It can be thought of as
@@ -1125,6 +1332,97 @@ class Container {
    * @param {VolumeMount[]} [args.volumeMounts] - A list of volumes to mount
    *   within the container. Referenced volumes are automatically created by
    *   Kelda.
+   * @param {Object} [args.securityContext] - Privileges to grant the
+   *   container, or take away from it, without running it in privileged mode.
+   * @param {string[]} [args.securityContext.capAdd] - Linux capabilities, such
+   *   as 'NET_ADMIN', to add to the container.
+   * @param {string[]} [args.securityContext.capDrop] - Linux capabilities to
+   *   drop from the container. 'ALL' drops every capability.
+   * @param {number} [args.securityContext.runAsUser] - The user ID to run the
+   *   container as, rather than the image's user. The container keeps the
+   *   image's group, which can't be set yet.
+   * @param {boolean} [args.securityContext.readOnlyRootFilesystem] - Whether
+   *   the container's root filesystem is mounted read-only.
+   * @param {boolean} [args.securityContext.noNewPrivileges] - Whether the
+   *   container's processes are prevented from gaining more privileges than
+   *   their parent, such as through setuid binaries.
+   * @param {string} [args.securityContext.seccompProfile] - The seccomp
+   *   profile to run the container with: 'runtime/default', 'unconfined', or
+   *   'localhost/<profile>' for a profile installed on the machines.
+   * @param {string} [args.securityContext.appArmorProfile] - The AppArmor
+   *   profile to run the container with: 'runtime/default', or
+   *   'localhost/<profile>' for a profile installed on the machines.
+   * @param {Object} [args.resources] - The CPU and memory reserved for the
+   *   container, and the most that it may use. The amounts are strings in the
+   *   Kubernetes quantity format, e.g. '500m' CPUs or '256Mi' of memory.
+   *   Containers are only scheduled on machines with enough unreserved
+   *   resources for their requests, and are throttled or killed if they exceed
+   *   their limits.
+   * @param {string} [args.resources.cpuRequest] - The CPUs reserved for the
+   *   container.
+   * @param {string} [args.resources.cpuLimit] - The most CPU time the
+   *   container may use.
+   * @param {string} [args.resources.memoryRequest] - The memory reserved for
+   *   the container.
+   * @param {string} [args.resources.memoryLimit] - The most memory the
+   *   container may use before it's killed.
+   * @param {Object} [args.livenessCheck] - A health check that's run
+   *   periodically in the container. If the check fails, the container is
+   *   restarted. A health check makes an HTTP GET request to the container,
+   *   opens a TCP connection to it, or runs a command in it, and must have
+   *   exactly one of `httpGet`, `tcpSocket` and `exec`.
+   * @param {Object} [args.livenessCheck.httpGet] - Checks that a GET request
+   *   to `httpGet.path` on `httpGet.port` returns a status code from 200 to
+   *   399.
+   * @param {Object} [args.livenessCheck.tcpSocket] - Checks that a TCP
+   *   connection can be opened to `tcpSocket.port`.
+   * @param {string[]} [args.livenessCheck.exec] - Checks that the command
+   *   exits with status 0.
+   * @param {number} [args.livenessCheck.initialDelaySeconds] - How long to
+   *   wait after the container starts before running the first check.
+   * @param {number} [args.livenessCheck.periodSeconds] - How often to run the
+   *   check.
+   * @param {number} [args.livenessCheck.timeoutSeconds] - How long to wait
+   *   for the check to complete before considering it failed.
+   * @param {number} [args.livenessCheck.successThreshold] - The number of
+   *   consecutive successes after a failure before the container is
+   *   considered healthy again. For liveness checks, it must be 1.
+   * @param {number} [args.livenessCheck.failureThreshold] - The number of
+   *   consecutive failures before the container is considered unhealthy.
+   * @param {Object} [args.readinessCheck] - A health check, with the same
+   *   fields as `livenessCheck`, that determines whether the container is ready
+   *   to serve requests. Load balancers only send traffic to containers that
+   *   pass their readiness checks.
+   * @param {string} [args.updateStrategy] - How the container is replaced when
+   *   it changes. The default, 'Recreate', stops the old container before
+   *   starting the new one. 'Rolling' starts the new container alongside the
+   *   old one, and only moves the container's hostname and load balancer
+   *   entries to it, and stops the old container, once the new container is
+   *   ready. The new container is given its own IP address.
+   * @param {Object[]} [args.sidecars] - Containers that run alongside the
+   *   container for as long as it runs, such as log shippers or proxies. They
+   *   share the container's hostname, IP address and volumes, but aren't
+   *   reachable by their own hostnames. Each sidecar is an object with the
+   *   following fields.
+   * @param {string} args.sidecars[].name - A name for the sidecar, which must
+   *   be unique among the container's sidecars and init containers, and differ
+   *   from the container's hostname.
+   * @param {Image|string} args.sidecars[].image - The image that the sidecar
+   *   runs.
+   * @param {string[]} [args.sidecars[].command] - The command to use when
+   *   starting the sidecar.
+   * @param {Object.<string, string|Secret>} [args.sidecars[].env] -
+   *   Environment variables to set in the sidecar.
+   * @param {VolumeMount[]} [args.sidecars[].volumeMounts] - A list of volumes
+   *   to mount within the sidecar.
+   * @param {Object} [args.sidecars[].resources] - The CPU and memory reserved
+   *   for the sidecar, and the most that it may use, with the same fields as
+   *   `resources`.
+   * @param {Object[]} [args.initContainers] - Containers, with the same fields
+   *   as `sidecars`, that run to completion one at a time and in order before
+   *   the container and its sidecars start, such as schema migrations. If an
+   *   init container fails, it's retried, and the container isn't started
+   *   until it succeeds.
    *
    * We only document properties users should care about.
    * @property {Image} image The image of the container.
@@ -1162,14 +1460,47 @@ class Container {
     validateHostname(this.hostname);
 
     this.command = getStringArray('command', args.command);
+
+    // These are left undefined by default so that they don't change the IDs
+    // of existing containers.
+    if (args.entrypoint !== undefined) {
+      this.entrypoint = _.clone(getStringArray('entrypoint', args.entrypoint));
+    }
+    if (args.workingDir !== undefined) {
+      this.workingDir = getString('workingDir', args.workingDir);
+    }
+    if (args.preStop !== undefined) {
+      this.preStop = _.clone(getStringArray('preStop', args.preStop));
+    }
+    if (args.terminationGracePeriodSeconds !== undefined) {
+      this.terminationGracePeriodSeconds = getNumber(
+        'terminationGracePeriodSeconds', args.terminationGracePeriodSeconds);
+    }
     this.env = getSecretOrStringMap('env', args.env);
     this.filepathToContent = getSecretOrStringMap('filepathToContent',
       args.filepathToContent);
     this.privileged = getBoolean('privileged', args.privileged);
+    this.securityContext = getSecurityContext('securityContext',
+      args.securityContext);
+    this.resources = getResources('resources', args.resources);
+    this.livenessCheck = getHealthCheck('livenessCheck', args.livenessCheck);
+    this.readinessCheck = getHealthCheck('readinessCheck',
+      args.readinessCheck);
+    // The update strategy is left undefined by default so that it doesn't
+    // change the IDs of existing containers.
+    this.updateStrategy = args.updateStrategy;
+    if (![undefined, 'Recreate', 'Rolling'].includes(this.updateStrategy)) {
+      throw new Error('updateStrategy must be Recreate or Rolling ' +
+        `(was: ${stringify(this.updateStrategy)})`);
+    }
 
     this.volumeMounts = args.volumeMounts || [];
     assertArrayOfType('VolumeMount', this.volumeMounts, VolumeMount);
 
+    this.sidecars = getPodContainers('sidecars', args.sidecars);
+    this.initContainers = getPodContainers('initContainers',
+      args.initContainers);
+
     // Don't allow callers to modify the arguments by reference.
     this.command = _.clone(this.command);
     this.env = _.clone(this.env);
@@ -1203,9 +1534,21 @@ class Container {
     return stringify({
       image: this.image,
       command: this.command,
+      entrypoint: this.entrypoint,
+      workingDir: this.workingDir,
+      preStop: this.preStop,
+      terminationGracePeriodSeconds: this.terminationGracePeriodSeconds,
       env: this.env,
       filepathToContent: this.filepathToContent,
       hostname: this.hostname,
+      securityContext: this.securityContext,
+      resources: this.resources,
+      livenessCheck: this.livenessCheck,
+      readinessCheck: this.readinessCheck,
+      updateStrategy: this.updateStrategy,
+      sidecars: podContainersRepr(this.sidecars),
+      initContainers: podContainersRepr(this.initContainers),
+      job: this.job,
     });
   }
 
@@ -1250,9 +1593,11 @@ class Container {
    */
   deploy(infrastructure) {
     infrastructure.containers.add(this);
-    this.volumeMounts.forEach((mount) => {
-      infrastructure.volumes.add(mount.volume);
-    });
+    const podContainers = this.sidecars.concat(this.initContainers);
+    this.volumeMounts.concat(...podContainers.map(pc => pc.volumeMounts))
+      .forEach((mount) => {
+        infrastructure.volumes.add(mount.volume);
+      });
   }
 
   /**
@@ -1266,15 +1611,131 @@ class Container {
       id: this.id,
       image: this.image,
       command: this.command,
+      entrypoint: this.entrypoint,
+      workingDir: this.workingDir,
+      preStop: this.preStop,
+      terminationGracePeriodSeconds: this.terminationGracePeriodSeconds,
       env: this.env,
       filepathToContent: this.filepathToContent,
       hostname: this.hostname,
       privileged: this.privileged,
+      securityContext: this.securityContext,
       volumeMounts: this.volumeMounts.map(mount => mount.toKeldaRepresentation()),
+      resources: this.resources,
+      livenessCheck: this.livenessCheck,
+      readinessCheck: this.readinessCheck,
+      updateStrategy: this.updateStrategy,
+      sidecars: podContainersRepr(this.sidecars),
+      initContainers: podContainersRepr(this.initContainers),
+      job: this.job,
     };
   }
 }
 
+// The arguments to Job that configure the job, rather than its container.
+const jobKeys = ['job', 'completions', 'backoffLimit', 'activeDeadlineSeconds'];
+
+class Job extends Container {
+  /**
+   * Creates a new Job, which is a Container that runs to completion once,
+   * rather than continuously, such as a database migration or a batch
+   * computation. Like other containers, a Job gets its own IP address, and can
+   * only connect to and be connected to by the containers that it's allowed to
+   * with {@link allowTraffic}. If the container exits with an error, it's
+   * restarted. A Job's exit code and when it completed are shown by
+   * `kelda show`.
+   *
+   * Changing a Job and re-running the blueprint runs the Job again.
+   *
+   * @constructor
+   * @extends Container
+   *
+   * @example <caption>Create a Job that migrates a database, and is retried at
+   * most three times.</caption>
+   * const migrate = new Job({
+   *   name: 'migrate',
+   *   image: 'my-app',
+   *   command: ['./migrate.sh'],
+   *   backoffLimit: 3,
+   * });
+   *
+   * @param {Object} args - The same arguments as {@link Container}, along
+   *   with the following optional arguments.
+   * @param {number} [args.completions] - The number of times the container
+   *   must exit successfully for the Job to complete. It defaults to 1. The
+   *   runs are one at a time.
+   * @param {number} [args.backoffLimit] - The number of times the container is
+   *   retried before the Job fails. It defaults to 6.
+   * @param {number} [args.activeDeadlineSeconds] - How long, in seconds, the
+   *   Job may take before it's stopped and fails. By default, there's no
+   *   deadline.
+   */
+  constructor(args) {
+    super(_.omit(args, jobKeys));
+
+    // An existing Job is passed as the arguments when it's cloned.
+    if (args.job !== undefined) {
+      this.job = _.clone(args.job);
+      return;
+    }
+
+    this.job = {};
+    if (args.completions !== undefined) {
+      this.job.completions = getNumber('completions', args.completions);
+    }
+    if (args.backoffLimit !== undefined) {
+      this.job.backoffLimit = getNumber('backoffLimit', args.backoffLimit);
+    }
+    if (args.activeDeadlineSeconds !== undefined) {
+      this.job.activeDeadlineSeconds = getNumber('activeDeadlineSeconds',
+        args.activeDeadlineSeconds);
+    }
+  }
+
+  /**
+   * @returns {Job} A new Job with the same attributes.
+   */
+  clone() {
+    return new this.constructor(this);
+  }
+}
+
+class CronJob extends Job {
+  /**
+   * Creates a new CronJob, which is a {@link Job} that's run each time its
+   * schedule fires, such as a nightly ETL. Runs are one at a time, so if a run
+   * is still going when the schedule next fires, that run is skipped. In
+   * between runs, `kelda show` reports the CronJob as waiting for its
+   * schedule.
+   *
+   * @constructor
+   * @extends Job
+   *
+   * @example <caption>Create a CronJob that runs at 3am UTC every
+   * day.</caption>
+   * const etl = new CronJob({
+   *   name: 'etl',
+   *   image: 'my-etl',
+   *   schedule: '0 3 * * *',
+   * });
+   *
+   * @param {Object} args - The same arguments as {@link Job}, along with the
+   *   following required argument.
+   * @param {string} args.schedule - When to run the container, in cron format
+   *   and UTC, or a macro such as '@daily' or '@hourly'.
+   */
+  constructor(args) {
+    if (args.job === undefined) {
+      checkRequiredArguments('CronJob', args, ['schedule']);
+    }
+    super(_.omit(args, 'schedule'));
+
+    if (args.job === undefined) {
+      this.job.schedule = getString('schedule', args.schedule);
+    }
+  }
+}
+
 class Secret {
   /**
    * Secret represents a secret to extract from the Vault secret
@@ -1365,17 +1826,39 @@ function isConnectable(x) {
  * @param {Connectable|Connectable[]} dst - the Connectables that can accept inbound
  *  traffic from those listed in `src`.
  * @param {int|Port|PortRange} portRange - The ports on which Connectables can
- *   send traffic.
+ *   send traffic. Must be omitted for ICMP. Connections from the public
+ *   internet may use a {@link PublicPort} to listen on a different public port
+ *   than the container's.
+ * @param {string} [protocol] - The protocol allowed: 'tcp', 'udp' or 'icmp'.
+ *   If omitted, both TCP and UDP are allowed.
  * @returns {void}
+ *
+ * @example
+ * // Allow DNS queries over UDP, without also opening TCP port 53.
+ * allowTraffic(client, dnsServer, 53, 'udp');
+ * // Allow the client to ping the server.
+ * allowTraffic(client, server, null, 'icmp');
+ * // Forward public port 8080 to port 80 of the web server.
+ * allowTraffic(publicInternet, webServer, new PublicPort(8080, 80));
  */
-function allowTraffic(src, dst, portRange) {
-  if (portRange === undefined || portRange === null) {
+function allowTraffic(src, dst, portRange, protocol) {
+  if (protocol !== undefined &&
+    !['tcp', 'udp', 'icmp'].includes(protocol)) {
+    throw new Error(`unknown protocol ${stringify(protocol)}: must be ` +
+      '"tcp", "udp" or "icmp"');
+  }
+
+  const isICMP = protocol === 'icmp';
+  const hasPorts = portRange !== undefined && portRange !== null;
+  if (isICMP && hasPorts) {
+    throw new Error('icmp connections do not have ports');
+  } else if (!isICMP && !hasPorts) {
     throw new Error('a port or port range is required');
   }
 
   const srcArr = boxConnectable(src);
   const dstArr = boxConnectable(dst);
-  const ports = boxRange(portRange);
+  const ports = boxRange(isICMP ? undefined : portRange);
 
   for (let i = 0; i < srcArr.length; i += 1) {
     if (srcArr[i] instanceof LoadBalancer) {
@@ -1384,18 +1867,24 @@ function allowTraffic(src, dst, portRange) {
     }
   }
 
-  if ((srcArr.includes(publicInternet) || dstArr.includes(publicInternet)) &&
-    (ports.min !== ports.max)) {
-    throw new Error('public internet can only connect to single ports ' +
-      'and not to port ranges');
+  if (ports.publicPort !== undefined && !srcArr.includes(publicInternet)) {
+    throw new Error('only connections from the public internet can have ' +
+      'a public port');
   }
 
-  _connections.push({
+  const conn = {
     from: srcArr.map(c => c.getConnectableName()),
     to: dstArr.map(c => c.getConnectableName()),
     minPort: ports.min,
     maxPort: ports.max,
-  });
+  };
+  if (ports.publicPort !== undefined) {
+    conn.publicPort = ports.publicPort;
+  }
+  if (protocol !== undefined) {
+    conn.protocol = protocol;
+  }
+  _connections.push(conn);
 }
 
 class Volume {
@@ -1409,11 +1898,31 @@ class Volume {
    * @param {Object} args - All required and optional arguments.
    * @param {string} args.name - A human-friendly name for the Volume. The
    *   identifier must be unique among all declared volumes.
-   * @param {string} args.type - The type of volume. Right now, only "hostPath"
-   *   is supported.
+   * @param {string} args.type - The type of volume: "hostPath", "emptyDir",
+   *   "nfs", or the cloud volume type "gcePersistentDisk". Cloud volumes are
+   *   backed by a disk that's created by the cloud provider and attached to
+   *   whichever machine the mounting container is placed on. Amazon and
+   *   DigitalOcean machines can't create disks yet, so "awsElasticBlockStore"
+   *   and "digitalOceanVolume" volumes aren't supported. Cloud volumes can
+   *   only be mounted by one container, and their disks are deleted when the
+   *   volume is removed from the blueprint.
    * @param {string} [args.path] - Required only if the volume type is
-   *   "hostPath". The path on the host that should be made available to the
-   *   mounting container.
+   *   "hostPath" or "nfs". For "hostPath" volumes, the path on the host that
+   *   should be made available to the mounting container. For "nfs" volumes,
+   *   the path of the export on the NFS server.
+   * @param {string} [args.server] - Required only if the volume type is "nfs".
+   *   The address of an existing NFS server. NFS volumes can be mounted
+   *   read-write by containers on different machines. Kelda doesn't create
+   *   NFS servers, so the export must already exist.
+   * @param {string} [args.medium] - Only for "emptyDir" volumes. Set to
+   *   "Memory" to back the volume with a tmpfs rather than the machine's disk.
+   * @param {string} [args.sizeLimit] - Only for "emptyDir" volumes. The
+   *   maximum size of the volume, such as "1Gi".
+   * @param {string} [args.size] - Required for cloud volumes. The size of the
+   *   disk, such as "10Gi".
+   * @param {string} [args.zone] - Only for cloud volumes. The zone to create
+   *   the disk in. Containers that mount the volume are only placed on
+   *   machines in that zone.
    *
    * We only list properties that the user should care about.
    * @property {string} name - A human-friendly name for the Volume.
@@ -1427,8 +1936,21 @@ class Volume {
       case 'hostPath':
         checkRequiredArguments('Volume', args, ['path']);
         break;
+      case 'emptyDir':
+        break;
+      case 'nfs':
+        checkRequiredArguments('Volume', args, ['server', 'path']);
+        break;
+      case 'gcePersistentDisk':
+        checkRequiredArguments('Volume', args, ['size']);
+        break;
+      case 'awsElasticBlockStore':
+      case 'digitalOceanVolume':
+        throw new Error(`${args.type} volumes aren't supported yet. Use ` +
+          'gcePersistentDisk volumes on Google machines instead');
       default:
-        throw new Error(`invalid volume type "${args.type}". Only hostPath is supported`);
+        throw new Error(`invalid volume type "${args.type}". Must be one of ` +
+          'hostPath, emptyDir, nfs, or gcePersistentDisk');
     }
 
     Object.assign(this, args);
@@ -1550,6 +2072,21 @@ function Port(p) {
   return new PortRange(p, p);
 }
 
+/**
+ * Creates a Port that is exposed to the public internet on a different port.
+ * Traffic from the public internet to `publicPort` on the machine's public IP
+ * is forwarded to `containerPort`.
+ * @constructor
+ *
+ * @param {integer} publicPort - The port on the machine's public IP.
+ * @param {integer} containerPort - The port the container listens on.
+ */
+function PublicPort(publicPort, containerPort) {
+  const port = new PortRange(containerPort, containerPort);
+  port.publicPort = publicPort;
+  return port;
+}
+
 /**
  * @returns {Infrastructure} The global infrastructure object.
  */
@@ -1599,11 +2136,14 @@ function resetGlobals() {
 
 module.exports = {
   Container,
+  CronJob,
   Infrastructure,
   Image,
+  Job,
   Machine,
   Port,
   PortRange,
+  PublicPort,
   Range,
   Secret,
   LoadBalancer,
@@ -686,6 +686,333 @@ describe('Bindings', () => {
         privileged: false,
       }]);
     });
+
+    it('resources', () => {
+      const resources = { cpuRequest: '500m', memoryLimit: '1Gi' };
+      const container = new b.Container({
+        name: hostname,
+        image,
+        resources,
+      });
+      container.deploy(infra);
+      checkContainers([{
+        hostname,
+        image,
+        resources,
+      }]);
+    });
+
+    it('resources change the container ID', () => {
+      const container = new b.Container({ name: hostname, image });
+      const withResources = new b.Container({
+        name: 'other',
+        image,
+        resources: { cpuLimit: '1' },
+      });
+      withResources.hostname = hostname;
+      expect(container.hash()).to.not.equal(withResources.hash());
+    });
+
+    it('invalid resources', () => {
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        resources: { cpu: '1' },
+      })).to.throw('unrecognized key in resources: cpu');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        resources: { cpuLimit: 1 },
+      })).to.throw('resources.cpuLimit must be a string (was: 1)');
+    });
+
+    it('entrypoint, working directory, and stop behaviour', () => {
+      const container = new b.Container({
+        name: hostname,
+        image,
+        command: ['postgres'],
+        entrypoint: ['docker-entrypoint.sh'],
+        workingDir: '/var/lib/postgresql',
+        preStop: ['pg_ctl', 'stop', '-m', 'fast'],
+        terminationGracePeriodSeconds: 120,
+      });
+      container.deploy(infra);
+      checkContainers([{
+        hostname,
+        image,
+        command: ['postgres'],
+        entrypoint: ['docker-entrypoint.sh'],
+        workingDir: '/var/lib/postgresql',
+        preStop: ['pg_ctl', 'stop', '-m', 'fast'],
+        terminationGracePeriodSeconds: 120,
+      }]);
+
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        terminationGracePeriodSeconds: '120',
+      })).to.throw('terminationGracePeriodSeconds must be a number');
+    });
+
+    it('security context', () => {
+      const securityContext = {
+        capAdd: ['NET_ADMIN'],
+        capDrop: ['ALL'],
+        runAsUser: 1000,
+        readOnlyRootFilesystem: true,
+        noNewPrivileges: true,
+        seccompProfile: 'runtime/default',
+      };
+      const container = new b.Container({
+        name: hostname,
+        image,
+        securityContext,
+      });
+      container.deploy(infra);
+      checkContainers([{
+        hostname,
+        image,
+        securityContext,
+      }]);
+    });
+
+    it('invalid security context', () => {
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        securityContext: { capabilities: ['NET_ADMIN'] },
+      })).to.throw('unrecognized key in securityContext: capabilities');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        securityContext: { capAdd: 'NET_ADMIN' },
+      })).to.throw('securityContext.capAdd must be an array of strings');
+    });
+
+    it('health checks', () => {
+      const livenessCheck = {
+        httpGet: { path: '/healthz', port: 8080 },
+        periodSeconds: 5,
+      };
+      const readinessCheck = { tcpSocket: { port: 80 } };
+      const container = new b.Container({
+        name: hostname,
+        image,
+        livenessCheck,
+        readinessCheck,
+      });
+      container.deploy(infra);
+      checkContainers([{
+        hostname,
+        image,
+        livenessCheck,
+        readinessCheck,
+      }]);
+    });
+
+    it('invalid health checks', () => {
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        livenessCheck: { periodSeconds: 5 },
+      })).to.throw('livenessCheck must have exactly one of httpGet, ' +
+        'tcpSocket and exec');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        readinessCheck: { exec: ['true'], tcpSocket: { port: 80 } },
+      })).to.throw('readinessCheck must have exactly one of httpGet, ' +
+        'tcpSocket and exec');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        readinessCheck: { tcpSocket: { port: '80' } },
+      })).to.throw('readinessCheck.tcpSocket.port must be a number ' +
+        '(was: "80")');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        livenessCheck: { exec: ['true'], interval: 5 },
+      })).to.throw('unrecognized key in livenessCheck: interval');
+    });
+
+    it('update strategy', () => {
+      const container = new b.Container({
+        name: hostname,
+        image,
+        updateStrategy: 'Rolling',
+      });
+      container.deploy(infra);
+      checkContainers([{
+        hostname,
+        image,
+        updateStrategy: 'Rolling',
+      }]);
+    });
+
+    it('invalid update strategy', () => {
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        updateStrategy: 'BlueGreen',
+      })).to.throw('updateStrategy must be Recreate or Rolling ' +
+        '(was: "BlueGreen")');
+    });
+
+    it('sidecars and init containers', () => {
+      const volume = new b.Volume({
+        name: 'logs',
+        type: 'hostPath',
+        path: '/var/log',
+      });
+      const container = new b.Container({
+        name: hostname,
+        image,
+        sidecars: [{
+          name: 'log-shipper',
+          image: 'fluentd',
+          env: { LEVEL: 'info' },
+          volumeMounts: [new b.VolumeMount({ volume, mountPath: '/logs' })],
+        }],
+        initContainers: [{
+          name: 'migrate',
+          image: new b.Image({ name: 'migrate', dockerfile: 'FROM postgres' }),
+          command: ['migrate', 'up'],
+          resources: { cpuLimit: '1' },
+        }],
+      });
+      container.deploy(infra);
+      checkContainers([{
+        hostname,
+        image,
+        sidecars: [{
+          name: 'log-shipper',
+          image: { name: 'fluentd', dockerfile: '' },
+          command: [],
+          env: { LEVEL: 'info' },
+          volumeMounts: [{ volumeName: 'logs', mountPath: '/logs' }],
+        }],
+        initContainers: [{
+          name: 'migrate',
+          image: { name: 'migrate', dockerfile: 'FROM postgres' },
+          command: ['migrate', 'up'],
+          resources: { cpuLimit: '1' },
+        }],
+      }]);
+
+      // Volumes that are only mounted by sidecars are deployed too.
+      expect(infra.toKeldaRepresentation().volumes).to.have.lengthOf(1);
+    });
+
+    it('sidecars change the container ID', () => {
+      const container = new b.Container({ name: hostname, image });
+      const withSidecar = new b.Container({
+        name: 'other',
+        image,
+        sidecars: [{ name: 'proxy', image: 'envoy' }],
+      });
+      withSidecar.hostname = hostname;
+      expect(container.hash()).to.not.equal(withSidecar.hash());
+    });
+
+    it('invalid sidecars and init containers', () => {
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        sidecars: { name: 'proxy', image: 'envoy' },
+      })).to.throw('sidecars must be an array of objects ' +
+        '(was: {"image":"envoy","name":"proxy"})');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        initContainers: [{ name: 'migrate' }],
+      })).to.throw('initContainers[0] must have a name and an image');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        sidecars: [{ name: 'proxy', image: 'envoy', privileged: true }],
+      })).to.throw('unrecognized key in sidecars[0]: privileged');
+      expect(() => new b.Container({
+        name: hostname,
+        image,
+        sidecars: [{ name: 'proxy', image: 'envoy', volumeMounts: ['/a'] }],
+      })).to.throw('sidecars[0].volumeMounts is not an array of ' +
+        'VolumeMounts');
+    });
+  });
+
+  describe('Job', () => {
+    beforeEach(createBasicInfra);
+
+    it('jobs', () => {
+      const job = new b.Job({
+        name: 'migrate',
+        image: 'my-app',
+        command: ['./migrate.sh'],
+        backoffLimit: 0,
+      });
+      const cronJob = new b.CronJob({
+        name: 'etl',
+        image: 'my-etl',
+        schedule: '0 3 * * *',
+        completions: 2,
+        activeDeadlineSeconds: 3600,
+      });
+      job.deploy(infra);
+      cronJob.deploy(infra);
+      checkContainers([{
+        hostname: 'migrate',
+        command: ['./migrate.sh'],
+        job: { backoffLimit: 0 },
+      }, {
+        hostname: 'etl',
+        job: {
+          schedule: '0 3 * * *',
+          completions: 2,
+          activeDeadlineSeconds: 3600,
+        },
+      }]);
+    });
+
+    it('containers aren\'t jobs', () => {
+      new b.Container({ name: 'web', image: 'nginx' }).deploy(infra);
+      const { containers } = infra.toKeldaRepresentation();
+      expect(containers[0].job).to.equal(undefined);
+    });
+
+    it('clone', () => {
+      const cronJob = new b.CronJob({
+        name: 'etl',
+        image: 'my-etl',
+        schedule: '@daily',
+      });
+      const clone = cronJob.clone();
+      expect(clone).to.be.an.instanceof(b.CronJob);
+      expect(clone.job).to.deep.equal({ schedule: '@daily' });
+      expect(clone.hostname).to.not.equal(cronJob.hostname);
+    });
+
+    it('jobs change the container ID', () => {
+      const container = new b.Container({ name: 'a', image: 'nginx' });
+      const job = new b.Job({ name: 'b', image: 'nginx' });
+      job.hostname = container.hostname;
+      expect(container.hash()).to.not.equal(job.hash());
+    });
+
+    it('invalid jobs', () => {
+      expect(() => new b.CronJob({ name: 'etl', image: 'my-etl' }))
+        .to.throw('CronJob requires \'schedule\'');
+      expect(() => new b.Job({
+        name: 'etl',
+        image: 'my-etl',
+        schedule: '@daily',
+      })).to.throw('Unrecognized keys passed to Job constructor: schedule');
+      expect(() => new b.Job({
+        name: 'migrate',
+        image: 'my-app',
+        completions: '2',
+      })).to.throw('completions must be a number (was: "2")');
+    });
   });
 
   describe('Placement', () => {
@@ -861,6 +1188,39 @@ describe('Bindings', () => {
       expect(() => b.allowTraffic(foo, bar)).to
         .throw('a port or port range is required');
     });
+    it('protocol', () => {
+      b.allowTraffic(foo, bar, 53, 'udp');
+      checkConnections([{
+        from: ['foo'],
+        to: ['bar'],
+        minPort: 53,
+        maxPort: 53,
+        protocol: 'udp',
+      }]);
+    });
+    it('no protocol', () => {
+      b.allowTraffic(foo, bar, 80);
+      const { connections } = infra.toKeldaRepresentation();
+      expect(connections[0]).to.not.have.property('protocol');
+    });
+    it('icmp', () => {
+      b.allowTraffic(foo, bar, null, 'icmp');
+      checkConnections([{
+        from: ['foo'],
+        to: ['bar'],
+        minPort: 0,
+        maxPort: 0,
+        protocol: 'icmp',
+      }]);
+    });
+    it('icmp with ports', () => {
+      expect(() => b.allowTraffic(foo, bar, 80, 'icmp')).to
+        .throw('icmp connections do not have ports');
+    });
+    it('unknown protocol', () => {
+      expect(() => b.allowTraffic(foo, bar, 80, 'sctp')).to
+        .throw('unknown protocol "sctp": must be "tcp", "udp" or "icmp"');
+    });
     it('connect to invalid port range', () => {
       expect(() => b.allowTraffic(foo, bar, true)).to
         .throw('Input argument must be a number or a Range');
@@ -899,22 +1259,48 @@ describe('Bindings', () => {
           'item at index 0 is not valid');
     });
     it('connect to publicInternet port range', () => {
-      expect(() =>
-        b.allowTraffic(foo, b.publicInternet, new b.PortRange(80, 81))).to
-        .throw('public internet can only connect to single ports ' +
-          'and not to port ranges');
+      b.allowTraffic(foo, b.publicInternet, new b.PortRange(80, 81));
+      checkConnections([{
+        from: ['foo'],
+        to: ['public'],
+        minPort: 80,
+        maxPort: 81,
+      }]);
     });
     it('connect from publicInternet port range', () => {
-      expect(() =>
-        b.allowTraffic(b.publicInternet, foo, new b.PortRange(80, 81))).to
-        .throw('public internet can only connect to single ports ' +
-          'and not to port ranges');
+      b.allowTraffic(b.publicInternet, foo, new b.PortRange(80, 81));
+      checkConnections([{
+        from: ['public'],
+        to: ['foo'],
+        minPort: 80,
+        maxPort: 81,
+      }]);
     });
     it('connect from publicInternet port range with others', () => {
+      b.allowTraffic([b.publicInternet, bar], foo, new b.PortRange(80, 81));
+      checkConnections([{
+        from: ['public', 'bar'],
+        to: ['foo'],
+        minPort: 80,
+        maxPort: 81,
+      }]);
+    });
+    it('public port', () => {
+      b.allowTraffic(b.publicInternet, foo, new b.PublicPort(8080, 80), 'tcp');
+      checkConnections([{
+        from: ['public'],
+        to: ['foo'],
+        minPort: 80,
+        maxPort: 80,
+        publicPort: 8080,
+        protocol: 'tcp',
+      }]);
+    });
+    it('public port not from publicInternet', () => {
       expect(() =>
-        b.allowTraffic([b.publicInternet, bar], foo, new b.PortRange(80, 81))).to
-        .throw('public internet can only connect to single ports ' +
-          'and not to port ranges');
+        b.allowTraffic(bar, foo, new b.PublicPort(8080, 80))).to
+        .throw('only connections from the public internet can have ' +
+          'a public port');
     });
     it('does not allow connections between non-Connectables', () => {
       expect(() => b.allowTraffic(10, 10, 10)).to
@@ -1347,6 +1733,49 @@ describe('Bindings', () => {
         .to.not.throw();
     });
 
+    it('should require a size for cloud volumes', () => {
+      const createVolume = args => () => new b.Volume(args);
+      expect(createVolume({ name: 'name', type: 'emptyDir' })).to.not.throw();
+      expect(createVolume({ name: 'name', type: 'nfs', path: '/exports' }))
+        .to.throw();
+      expect(createVolume({
+        name: 'name', type: 'nfs', server: '10.0.0.5', path: '/exports',
+      })).to.not.throw();
+      expect(createVolume({ name: 'name', type: 'gcePersistentDisk' }))
+        .to.throw();
+      expect(createVolume({
+        name: 'name', type: 'gcePersistentDisk', size: '10Gi', zone: 'zone',
+      })).to.not.throw();
+    });
+
+    it('should reject unsupported cloud volumes', () => {
+      expect(() => new b.Volume({
+        name: 'name', type: 'awsElasticBlockStore', size: '10Gi',
+      })).to.throw('awsElasticBlockStore volumes aren\'t supported yet. Use ' +
+        'gcePersistentDisk volumes on Google machines instead');
+      expect(() => new b.Volume({
+        name: 'name', type: 'digitalOceanVolume', size: '10Gi',
+      })).to.throw('digitalOceanVolume volumes aren\'t supported yet. Use ' +
+        'gcePersistentDisk volumes on Google machines instead');
+    });
+
+    it('should convert cloud volume options to conf', () => {
+      const volume = new b.Volume({
+        name: 'data',
+        type: 'gcePersistentDisk',
+        size: '10Gi',
+        zone: 'us-west1-a',
+      });
+      expect(volume.toKeldaRepresentation()).to.deep.equal({
+        name: 'data',
+        type: 'gcePersistentDisk',
+        conf: {
+          size: '10Gi',
+          zone: 'us-west1-a',
+        },
+      });
+    });
+
     it('should handle multiple volumes with the same name', () => {
       const volumeArgs = {
         name: 'volume',
@@ -3,8 +3,10 @@ package docker
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +17,7 @@ import (
 
 	dkc "github.com/fsouza/go-dockerclient"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 var pullCacheTimeout = time.Minute
@@ -81,6 +84,40 @@ type RunOptions struct {
 	Mounts      []dkc.HostMount
 }
 
+// ExecOptions changes the behavior of the Exec function.
+type ExecOptions struct {
+	Cmd []string
+
+	// If Stdin is nil, the command's standard input isn't attached.
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// If TTY is set, a pseudo-terminal is allocated for the command, and its
+	// size is updated with each size read from Resize.
+	TTY    bool
+	Resize <-chan TerminalSize
+}
+
+// TerminalSize is the size of an exec's pseudo-terminal, in characters.
+type TerminalSize struct {
+	Height, Width int
+}
+
+// LogsOptions changes the behavior of the Logs function.
+type LogsOptions struct {
+	Follow     bool
+	Since      time.Time
+	Tail       int
+	Timestamps bool
+
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// Cancelling Context stops following the logs. May be nil.
+	Context context.Context
+}
+
 type client interface {
 	StartContainer(id string, hostConfig *dkc.HostConfig) error
 	UploadToContainer(id string, opts dkc.UploadToContainerOptions) error
@@ -95,6 +132,12 @@ type client interface {
 	CreateContainer(dkc.CreateContainerOptions) (*dkc.Container, error)
 	CreateNetwork(dkc.CreateNetworkOptions) (*dkc.Network, error)
 	ListNetworks() ([]dkc.Network, error)
+	CreateExec(dkc.CreateExecOptions) (*dkc.Exec, error)
+	StartExecNonBlocking(id string, opts dkc.StartExecOptions) (
+		dkc.CloseWaiter, error)
+	ResizeExecTTY(id string, height, width int) error
+	InspectExec(id string) (*dkc.ExecInspect, error)
+	Logs(opts dkc.LogsOptions) error
 }
 
 var c = counter.New("Docker")
@@ -177,6 +220,94 @@ func (dk Client) ConfigureNetwork(driver string) error {
 	return err
 }
 
+// Exec runs a command in the container with the given ID, and returns the
+// command's exit code once it exits.
+func (dk Client) Exec(id string, opts ExecOptions) (int, error) {
+	c.Inc("Exec")
+	exec, err := dk.CreateExec(dkc.CreateExecOptions{
+		Container:    id,
+		Cmd:          opts.Cmd,
+		AttachStdin:  opts.Stdin != nil,
+		AttachStdout: true,
+		AttachStderr: true,
+		Tty:          opts.TTY,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	cw, err := dk.StartExecNonBlocking(exec.ID, dkc.StartExecOptions{
+		InputStream:  opts.Stdin,
+		OutputStream: opts.Stdout,
+		ErrorStream:  opts.Stderr,
+		Tty:          opts.TTY,
+		RawTerminal:  opts.TTY,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case size, ok := <-opts.Resize:
+				if !ok {
+					return
+				}
+
+				err := dk.ResizeExecTTY(exec.ID, size.Height, size.Width)
+				if err != nil {
+					log.WithError(err).Debug(
+						"Failed to resize exec TTY")
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := cw.Wait(); err != nil {
+		return 0, err
+	}
+
+	inspect, err := dk.InspectExec(exec.ID)
+	if err != nil {
+		return 0, err
+	}
+	return inspect.ExitCode, nil
+}
+
+// Logs writes the logs of the container with the given ID to opts.Stdout and
+// opts.Stderr. If opts.Follow is set, Logs blocks until the container exits or
+// opts.Context is cancelled.
+func (dk Client) Logs(id string, opts LogsOptions) error {
+	c.Inc("Logs")
+	tail := "all"
+	if opts.Tail > 0 {
+		tail = strconv.Itoa(opts.Tail)
+	}
+
+	var since int64
+	if !opts.Since.IsZero() {
+		since = opts.Since.Unix()
+	}
+
+	return dk.client.Logs(dkc.LogsOptions{
+		Context:      opts.Context,
+		Container:    id,
+		OutputStream: opts.Stdout,
+		ErrorStream:  opts.Stderr,
+		Stdout:       true,
+		Stderr:       true,
+		Follow:       opts.Follow,
+		Since:        since,
+		Tail:         tail,
+		Timestamps:   opts.Timestamps,
+	})
+}
+
 // Remove stops and deletes the container with the given name.
 func (dk Client) Remove(name string) error {
 	id, err := dk.getID(name)
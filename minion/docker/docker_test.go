@@ -1,7 +1,9 @@
 package docker
 
 import (
+	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -306,6 +308,55 @@ func TestRemove(t *testing.T) {
 	assert.Zero(t, len(containers))
 }
 
+func TestExec(t *testing.T) {
+	t.Parallel()
+	md, dk := NewMock()
+
+	id, err := dk.Run(RunOptions{Name: "name"})
+	assert.NoError(t, err)
+
+	md.ExecExitCode = 3
+	resize := make(chan TerminalSize, 1)
+	resize <- TerminalSize{Height: 24, Width: 80}
+
+	var stdout bytes.Buffer
+	exitCode, err := dk.Exec(id, ExecOptions{
+		Cmd:    []string{"cat", "-"},
+		Stdin:  strings.NewReader("input"),
+		Stdout: &stdout,
+		TTY:    true,
+		Resize: resize,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "input", stdout.String())
+	assert.Equal(t, map[string][]string{id: {"cat -"}}, md.Executions)
+
+	_, err = dk.Exec("unknown", ExecOptions{Cmd: []string{"ls"}})
+	assert.EqualError(t, err, "unknown container")
+
+	md.StartExecError = true
+	_, err = dk.Exec(id, ExecOptions{Cmd: []string{"ls"}})
+	assert.EqualError(t, err, "start exec error")
+}
+
+func TestLogs(t *testing.T) {
+	t.Parallel()
+	md, dk := NewMock()
+
+	id, err := dk.Run(RunOptions{Name: "name"})
+	assert.NoError(t, err)
+	md.LogOutput[id] = "logs\n"
+
+	var stdout bytes.Buffer
+	err = dk.Logs(id, LogsOptions{Tail: 10, Stdout: &stdout})
+	assert.NoError(t, err)
+	assert.Equal(t, "logs\n", stdout.String())
+
+	err = dk.Logs("unknown", LogsOptions{Stdout: &stdout})
+	assert.Equal(t, ErrNoSuchContainer, err)
+}
+
 func TestBuild(t *testing.T) {
 	t.Parallel()
 	md, dk := NewMock()
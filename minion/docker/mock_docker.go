@@ -44,6 +44,12 @@ type MockClient struct {
 	createdExecs map[string]dkc.CreateExecOptions
 	Executions   map[string][]string
 
+	// The exit code returned by all executions.
+	ExecExitCode int
+
+	// The logs written by each container, keyed by container ID.
+	LogOutput map[string]string
+
 	CreateError           bool
 	CreateNetworkError    bool
 	ListNetworksError     bool
@@ -74,6 +80,7 @@ func NewMock() (*MockClient, Client) {
 		Images:       map[string]*dkc.Image{},
 		createdExecs: map[string]dkc.CreateExecOptions{},
 		Executions:   map[string][]string{},
+		LogOutput:    map[string]string{},
 	}
 	return md, Client{md, &sync.Mutex{}, map[string]*cacheEntry{}}
 }
@@ -234,11 +241,13 @@ func (dk MockClient) ListContainers(opts dkc.ListContainersOptions) ([]dkc.APICo
 	}
 
 	var name string
+	var labels []string
 	if opts.Filters != nil {
 		names := opts.Filters["name"]
 		if len(names) == 1 {
 			name = names[0]
 		}
+		labels = opts.Filters["label"]
 	}
 
 	var apics []dkc.APIContainers
@@ -251,11 +260,28 @@ func (dk MockClient) ListContainers(opts dkc.ListContainersOptions) ([]dkc.APICo
 			continue
 		}
 
+		if !hasLabels(container.Config.Labels, labels) {
+			continue
+		}
+
 		apics = append(apics, dkc.APIContainers{ID: id})
 	}
 	return apics, nil
 }
 
+// hasLabels returns whether `containerLabels` contains all of the `key=value`
+// pairs in `labels`.
+func hasLabels(containerLabels map[string]string, labels []string) bool {
+	for _, label := range labels {
+		kv := strings.SplitN(label, "=", 2)
+		val, ok := containerLabels[kv[0]]
+		if !ok || (len(kv) == 2 && val != kv[1]) {
+			return false
+		}
+	}
+	return true
+}
+
 // CreateNetwork creates a network according to opts.
 func (dk MockClient) CreateNetwork(opts dkc.CreateNetworkOptions) (*dkc.Network, error) {
 	dk.Lock()
@@ -388,6 +414,74 @@ func (dk MockClient) StartExec(id string, opts dkc.StartExecOptions) error {
 	return nil
 }
 
+// StartExecNonBlocking starts the supplied execution object. The execution
+// echoes its standard input to its standard output.
+func (dk MockClient) StartExecNonBlocking(id string, opts dkc.StartExecOptions) (
+	dkc.CloseWaiter, error) {
+	if err := dk.StartExec(id, opts); err != nil {
+		return nil, err
+	}
+
+	return mockCloseWaiter(func() error {
+		if opts.InputStream == nil || opts.OutputStream == nil {
+			return nil
+		}
+		_, err := io.Copy(opts.OutputStream, opts.InputStream)
+		return err
+	}), nil
+}
+
+type mockCloseWaiter func() error
+
+func (wait mockCloseWaiter) Wait() error {
+	return wait()
+}
+
+func (wait mockCloseWaiter) Close() error {
+	return nil
+}
+
+// ResizeExecTTY resizes the TTY of the supplied execution object.
+func (dk MockClient) ResizeExecTTY(id string, height, width int) error {
+	dk.Lock()
+	defer dk.Unlock()
+
+	if _, ok := dk.createdExecs[id]; !ok {
+		return errors.New("unknown exec")
+	}
+	return nil
+}
+
+// InspectExec returns the details of the supplied execution object.
+func (dk MockClient) InspectExec(id string) (*dkc.ExecInspect, error) {
+	dk.Lock()
+	defer dk.Unlock()
+
+	exec, ok := dk.createdExecs[id]
+	if !ok {
+		return nil, errors.New("unknown exec")
+	}
+
+	return &dkc.ExecInspect{
+		ID:          id,
+		ExitCode:    dk.ExecExitCode,
+		ContainerID: exec.Container,
+	}, nil
+}
+
+// Logs writes the logs of the given container to the output stream.
+func (dk MockClient) Logs(opts dkc.LogsOptions) error {
+	dk.Lock()
+	defer dk.Unlock()
+
+	if _, ok := dk.Containers[opts.Container]; !ok {
+		return ErrNoSuchContainer
+	}
+
+	_, err := io.WriteString(opts.OutputStream, dk.LogOutput[opts.Container])
+	return err
+}
+
 // ResetExec clears the list of created and started executions, for use by the unit
 // tests.
 func (dk *MockClient) ResetExec() {
@@ -20,7 +20,9 @@ var updatePolicyTables = []db.TableType{db.BlueprintTable, db.ConnectionTable,
 
 func syncPolicy(conn db.Conn) {
 	loopLog := util.NewEventTimer("Minion-Update")
-	for range conn.Trigger(db.EtcdTable).C {
+	// The policy is also updated when containers change, so that retiring
+	// containers are removed once their replacements are ready.
+	for range conn.Trigger(db.EtcdTable, db.ContainerTable).C {
 		loopLog.LogStart()
 		conn.Txn(updatePolicyTables...).Run(func(view db.Database) error {
 			updatePolicy(view)
@@ -51,40 +53,68 @@ func updatePolicy(view db.Database) {
 }
 
 // `portPlacements` creates exclusive placement rules such that no two
-// containers listening on the same public port get placed on the same machine.
+// containers listening on overlapping public ports of the same protocol get
+// placed on the same machine.
 // It produces the same placement rules regardless of the order of connections
 // by creating rules for both containers affected by the placement.
 // This way, if `portPlacements` is called multiple times for the same
 // blueprint, the placement rules will not change unnecessarily.
 func portPlacements(connections []db.Connection) (placements []db.Placement) {
-	ports := make(map[int][]string)
+	type publicPorts struct {
+		protocol  string
+		min, max  int
+		hostnames []string
+	}
+
+	var ports []publicPorts
 	for _, conn := range connections {
 		if !str.SliceContains(conn.From, blueprint.PublicInternetLabel) {
 			continue
 		}
 
-		// XXX: Public connections do not currently support ranges, so we
-		// can safely consider just the MinPort.
-		ports[conn.MinPort] = append(ports[conn.MinPort],
-			str.SliceFilterOut(conn.To, blueprint.PublicInternetLabel)...)
+		min, max := conn.PublicPorts()
+		hostnames := str.SliceFilterOut(conn.To, blueprint.PublicInternetLabel)
+		for _, protocol := range blueprint.Protocols(conn.Protocol) {
+			// ICMP doesn't use ports, so any number of containers on
+			// the same machine can accept it.
+			if protocol == blueprint.ICMPProtocol {
+				continue
+			}
+			ports = append(ports, publicPorts{protocol, min, max, hostnames})
+		}
 	}
 
 	// Create placement rules for all combinations of containers that listen on
-	// the same port.
-	for _, cids := range ports {
-		for i, tgt := range cids {
-			for j, other := range cids {
-				if i == j {
-					continue
-				}
+	// overlapping ports. Containers that conflict on several ports only get
+	// one rule for each pair.
+	type pair struct{ tgt, other string }
+	seen := map[pair]struct{}{}
+	exclude := func(tgt, other string) {
+		if _, ok := seen[pair{tgt, other}]; ok || tgt == other {
+			return
+		}
+		seen[pair{tgt, other}] = struct{}{}
+
+		placements = append(placements,
+			db.Placement{
+				Exclusive:       true,
+				TargetContainer: tgt,
+				OtherContainer:  other,
+			},
+		)
+	}
 
-				placements = append(placements,
-					db.Placement{
-						Exclusive:       true,
-						TargetContainer: tgt,
-						OtherContainer:  other,
-					},
-				)
+	for i, a := range ports {
+		for _, b := range ports[i:] {
+			if a.protocol != b.protocol || a.max < b.min || b.max < a.min {
+				continue
+			}
+
+			for _, tgt := range a.hostnames {
+				for _, other := range b.hostnames {
+					exclude(tgt, other)
+					exclude(other, tgt)
+				}
 			}
 		}
 	}
@@ -182,10 +212,12 @@ func updateConnections(view db.Database, bp blueprint.Blueprint) {
 		for _, to := range c.To {
 			if lb, ok := loadBalancers[to]; ok {
 				scs = append(scs, blueprint.Connection{
-					From:    c.From,
-					To:      lb.Hostnames,
-					MinPort: c.MinPort,
-					MaxPort: c.MaxPort,
+					From:       c.From,
+					To:         lb.Hostnames,
+					MinPort:    c.MinPort,
+					MaxPort:    c.MaxPort,
+					Protocol:   c.Protocol,
+					PublicPort: c.PublicPort,
 				})
 			}
 		}
@@ -193,12 +225,14 @@ func updateConnections(view db.Database, bp blueprint.Blueprint) {
 
 	dbcKey := func(val interface{}) interface{} {
 		c := val.(db.Connection)
-		return fmt.Sprintf("%s %s %d %d", c.From, c.To, c.MinPort, c.MaxPort)
+		return fmt.Sprintf("%s %s %d %d %s %d", c.From, c.To, c.MinPort,
+			c.MaxPort, c.Protocol, c.PublicPort)
 	}
 
 	bpKey := func(val interface{}) interface{} {
 		c := val.(blueprint.Connection)
-		return fmt.Sprintf("%s %s %d %d", c.From, c.To, c.MinPort, c.MaxPort)
+		return fmt.Sprintf("%s %s %d %d %s %d", c.From, c.To, c.MinPort,
+			c.MaxPort, c.Protocol, c.PublicPort)
 	}
 
 	vcs := view.SelectFromConnection(nil)
@@ -216,6 +250,8 @@ func updateConnections(view db.Database, bp blueprint.Blueprint) {
 		dbc.To = bpc.To
 		dbc.MinPort = bpc.MinPort
 		dbc.MaxPort = bpc.MaxPort
+		dbc.Protocol = bpc.Protocol
+		dbc.PublicPort = bpc.PublicPort
 		view.Commit(dbc)
 	}
 }
@@ -226,13 +262,26 @@ func queryContainers(bp blueprint.Blueprint) []db.Container {
 		containers[c.Hostname] = &db.Container{
 			BlueprintID:       c.ID,
 			Command:           c.Command,
+			Entrypoint:        c.Entrypoint,
+			WorkingDir:        c.WorkingDir,
 			Env:               c.Env,
 			FilepathToContent: c.FilepathToContent,
 			Image:             c.Image.Name,
 			Dockerfile:        c.Image.Dockerfile,
 			Hostname:          c.Hostname,
 			Privileged:        c.Privileged,
+			SecurityContext:   c.SecurityContext,
 			VolumeMounts:      c.VolumeMounts,
+			Resources:         c.Resources,
+			LivenessCheck:     c.LivenessCheck,
+			ReadinessCheck:    c.ReadinessCheck,
+			UpdateStrategy:    c.UpdateStrategy,
+			Sidecars:          c.Sidecars,
+			InitContainers:    c.InitContainers,
+			Job:               c.Job,
+
+			PreStop:                       c.PreStop,
+			TerminationGracePeriodSeconds: c.TerminationGracePeriodSeconds,
 		}
 	}
 
@@ -252,8 +301,37 @@ func updateContainers(view db.Database, bp blueprint.Blueprint) {
 	pairs, news, dbcs := join.HashJoin(db.ContainerSlice(queryContainers(bp)),
 		db.ContainerSlice(view.SelectFromContainer(nil)), key, key)
 
-	for _, dbc := range dbcs {
-		view.Remove(dbc.(db.Container))
+	// Track which hostnames use the rolling update strategy, and which have a
+	// ready container, both among the containers in the blueprint and among
+	// all containers that aren't retiring.
+	rolling := map[string]bool{}
+	currentReady := map[string]bool{}
+	newerReady := map[string]bool{}
+	for _, pair := range pairs {
+		newc := pair.L.(db.Container)
+		dbc := pair.R.(db.Container)
+		rolling[newc.Hostname] = newc.UpdateStrategy == blueprint.RollingUpdate
+		currentReady[dbc.Hostname] = currentReady[dbc.Hostname] || dbc.Ready
+	}
+	for _, intf := range news {
+		newc := intf.(db.Container)
+		rolling[newc.Hostname] = newc.UpdateStrategy == blueprint.RollingUpdate
+	}
+	for _, dbc := range view.SelectFromContainer(nil) {
+		if !dbc.Retiring {
+			newerReady[dbc.Hostname] = newerReady[dbc.Hostname] || dbc.Ready
+		}
+	}
+
+	for _, intf := range dbcs {
+		dbc := intf.(db.Container)
+		if retire(dbc, rolling[dbc.Hostname], currentReady[dbc.Hostname],
+			newerReady[dbc.Hostname]) {
+			dbc.Retiring = true
+			view.Commit(dbc)
+			continue
+		}
+		view.Remove(dbc)
 	}
 
 	for _, new := range news {
@@ -265,6 +343,8 @@ func updateContainers(view db.Database, bp blueprint.Blueprint) {
 		dbc := pair.R.(db.Container)
 
 		dbc.Command = newc.Command
+		dbc.Entrypoint = newc.Entrypoint
+		dbc.WorkingDir = newc.WorkingDir
 		dbc.Image = newc.Image
 		dbc.Dockerfile = newc.Dockerfile
 		dbc.Env = newc.Env
@@ -272,11 +352,40 @@ func updateContainers(view db.Database, bp blueprint.Blueprint) {
 		dbc.BlueprintID = newc.BlueprintID
 		dbc.Hostname = newc.Hostname
 		dbc.Privileged = newc.Privileged
+		dbc.SecurityContext = newc.SecurityContext
 		dbc.VolumeMounts = newc.VolumeMounts
+		dbc.Resources = newc.Resources
+		dbc.LivenessCheck = newc.LivenessCheck
+		dbc.ReadinessCheck = newc.ReadinessCheck
+		dbc.UpdateStrategy = newc.UpdateStrategy
+		dbc.Sidecars = newc.Sidecars
+		dbc.InitContainers = newc.InitContainers
+		dbc.Job = newc.Job
+		dbc.PreStop = newc.PreStop
+		dbc.TerminationGracePeriodSeconds = newc.TerminationGracePeriodSeconds
+		dbc.Retiring = false
 		view.Commit(dbc)
 	}
 }
 
+// retire returns whether a container that's no longer in the blueprint should
+// keep running until its replacement is ready, rather than being removed
+// immediately. This is only the case if its replacement uses the rolling
+// update strategy. Containers that weren't ready when they were replaced
+// aren't worth keeping, and a retiring container is removed once a newer
+// container with its hostname is ready, even if that container has since been
+// replaced itself.
+func retire(dbc db.Container, rolling, replacementReady, newerReady bool) bool {
+	if !rolling || replacementReady || dbc.IP == "" {
+		return false
+	}
+
+	if dbc.Retiring {
+		return !newerReady
+	}
+	return dbc.Ready
+}
+
 func updateImages(view db.Database, bp blueprint.Blueprint) {
 	dbImageKey := func(intf interface{}) interface{} {
 		return blueprint.Image{
@@ -303,14 +412,24 @@ func updateImages(view db.Database, bp blueprint.Blueprint) {
 
 func queryImages(bp blueprint.Blueprint) (images []blueprint.Image) {
 	addedImages := map[blueprint.Image]struct{}{}
-	for _, c := range bp.Containers {
-		_, addedImage := addedImages[c.Image]
-		if c.Image.Dockerfile == "" || addedImage {
-			continue
+	addImage := func(image blueprint.Image) {
+		_, addedImage := addedImages[image]
+		if image.Dockerfile == "" || addedImage {
+			return
 		}
 
-		images = append(images, c.Image)
-		addedImages[c.Image] = struct{}{}
+		images = append(images, image)
+		addedImages[image] = struct{}{}
+	}
+
+	for _, c := range bp.Containers {
+		addImage(c.Image)
+		for _, pc := range c.InitContainers {
+			addImage(pc.Image)
+		}
+		for _, pc := range c.Sidecars {
+			addImage(pc.Image)
+		}
 	}
 	return images
 }
@@ -238,6 +238,128 @@ func TestContainerTxn(t *testing.T) {
 	bp.Containers[0].Privileged = true
 	testContainerTxn(t, conn, bp)
 	assert.True(t, fired(trigg))
+
+	// And changing its entrypoint.
+	bp.Containers[0].Entrypoint = []string{"/bin/sh", "-c"}
+	testContainerTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+	assert.Len(t, conn.SelectFromContainer(func(dbc db.Container) bool {
+		return len(dbc.Entrypoint) == 2
+	}), 1)
+
+	// As is changing its security context.
+	bp.Containers[0].SecurityContext = &blueprint.SecurityContext{
+		CapAdd: []string{"NET_ADMIN"},
+	}
+	testContainerTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+	assert.Len(t, conn.SelectFromContainer(func(dbc db.Container) bool {
+		return dbc.SecurityContext != nil
+	}), 1)
+
+	// Changing the resources of a container is also a change.
+	bp.Containers[0].Resources = &blueprint.Resources{MemoryLimit: "1Gi"}
+	testContainerTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+	assert.Len(t, conn.SelectFromContainer(func(dbc db.Container) bool {
+		return dbc.Resources != nil && dbc.Resources.MemoryLimit == "1Gi"
+	}), 1)
+
+	// So is changing its health checks.
+	bp.Containers[0].ReadinessCheck = &blueprint.HealthCheck{
+		TCPSocket: &blueprint.TCPSocketCheck{Port: 80},
+	}
+	testContainerTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+	assert.Len(t, conn.SelectFromContainer(func(dbc db.Container) bool {
+		return dbc.ReadinessCheck != nil
+	}), 1)
+
+	// And turning it into a job.
+	bp.Containers[0].Job = &blueprint.Job{Schedule: "@daily"}
+	testContainerTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+	assert.Len(t, conn.SelectFromContainer(func(dbc db.Container) bool {
+		return dbc.Job != nil && dbc.Job.Schedule == "@daily"
+	}), 1)
+}
+
+func TestRollingUpdate(t *testing.T) {
+	conn := db.New()
+
+	container := func(id string, strategy string) blueprint.Blueprint {
+		return blueprint.Blueprint{Containers: []blueprint.Container{{
+			ID:             id,
+			Hostname:       "web",
+			Image:          blueprint.Image{Name: id},
+			UpdateStrategy: strategy,
+		}}}
+	}
+	setReady := func(id string, ip string) {
+		conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+			for _, dbc := range view.SelectFromContainer(nil) {
+				if dbc.BlueprintID == id {
+					dbc.IP = ip
+					dbc.Ready = true
+					view.Commit(dbc)
+				}
+			}
+			return nil
+		})
+	}
+	containers := func() map[string]bool {
+		idToRetiring := map[string]bool{}
+		for _, dbc := range conn.SelectFromContainer(nil) {
+			idToRetiring[dbc.BlueprintID] = dbc.Retiring
+		}
+		return idToRetiring
+	}
+
+	testUpdatePolicy(conn, container("1", blueprint.RollingUpdate))
+	setReady("1", "10.0.0.2")
+
+	// The old container keeps running until its replacement is ready.
+	testUpdatePolicy(conn, container("2", blueprint.RollingUpdate))
+	assert.Equal(t, map[string]bool{"1": true, "2": false}, containers())
+	testUpdatePolicy(conn, container("2", blueprint.RollingUpdate))
+	assert.Equal(t, map[string]bool{"1": true, "2": false}, containers())
+
+	// Replacing the replacement before it's ready removes it, but the
+	// original container keeps running.
+	testUpdatePolicy(conn, container("3", blueprint.RollingUpdate))
+	assert.Equal(t, map[string]bool{"1": true, "3": false}, containers())
+
+	setReady("3", "10.0.0.3")
+	testUpdatePolicy(conn, container("3", blueprint.RollingUpdate))
+	assert.Equal(t, map[string]bool{"3": false}, containers())
+
+	// Containers replaced by a container with the recreate strategy are
+	// removed immediately.
+	testUpdatePolicy(conn, container("4", blueprint.RecreateUpdate))
+	assert.Equal(t, map[string]bool{"4": false}, containers())
+
+	// As are containers that weren't ready.
+	testUpdatePolicy(conn, container("5", blueprint.RollingUpdate))
+	assert.Equal(t, map[string]bool{"5": false}, containers())
+}
+
+func TestRetire(t *testing.T) {
+	t.Parallel()
+
+	running := db.Container{IP: "10.0.0.2", Ready: true}
+	assert.True(t, retire(running, true, false, false))
+	assert.False(t, retire(running, false, false, false))
+	assert.False(t, retire(running, true, true, true))
+
+	notReady := db.Container{IP: "10.0.0.2"}
+	assert.False(t, retire(notReady, true, false, false))
+
+	noIP := db.Container{Ready: true}
+	assert.False(t, retire(noIP, true, false, false))
+
+	retiring := db.Container{IP: "10.0.0.2", Retiring: true}
+	assert.True(t, retire(retiring, true, false, false))
+	assert.False(t, retire(retiring, true, false, true))
 }
 
 func testContainerTxn(t *testing.T, conn db.Conn, bp blueprint.Blueprint) {
@@ -288,6 +410,21 @@ func TestConnectionTxn(t *testing.T) {
 	testConnectionTxn(t, conn, bp)
 	assert.False(t, fired(trigg))
 
+	// Changing only the protocol replaces the connection.
+	bp.Connections[0].Protocol = blueprint.UDPProtocol
+	testConnectionTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+
+	testConnectionTxn(t, conn, bp)
+	assert.False(t, fired(trigg))
+
+	bp.Connections[0].PublicPort = 8080
+	testConnectionTxn(t, conn, bp)
+	assert.True(t, fired(trigg))
+
+	testConnectionTxn(t, conn, bp)
+	assert.False(t, fired(trigg))
+
 	bp.Connections = []blueprint.Connection{
 		{From: []string{"b"}, To: []string{"a"}, MinPort: 90, MaxPort: 90},
 		{From: []string{"b"}, To: []string{"c"}, MinPort: 90, MaxPort: 90},
@@ -317,7 +454,9 @@ func testConnectionTxn(t *testing.T, conn db.Conn, bp blueprint.Blueprint) {
 		found := false
 		for i, c := range connections {
 			if str.SliceEq(e.From, c.From) && str.SliceEq(e.To, c.To) &&
-				e.MinPort == c.MinPort && e.MaxPort == c.MaxPort {
+				e.MinPort == c.MinPort && e.MaxPort == c.MaxPort &&
+				e.Protocol == c.Protocol &&
+				e.PublicPort == c.PublicPort {
 				connections = append(
 					connections[:i], connections[i+1:]...)
 				found = true
@@ -527,6 +666,34 @@ func TestImageTxn(t *testing.T) {
 			Dockerfile: "2",
 		},
 	)
+
+	// Images used by sidecars and init containers are built too.
+	checkImage(t, db.New(), blueprint.Blueprint{
+		Containers: []blueprint.Container{
+			{
+				ID:    "96189e4ea36c80171fd842ccc4c3438d06061991",
+				Image: blueprint.Image{Name: "a", Dockerfile: "1"},
+				Sidecars: []blueprint.PodContainer{{
+					Name: "sidecar",
+					Image: blueprint.Image{Name: "b",
+						Dockerfile: "1"},
+				}},
+				InitContainers: []blueprint.PodContainer{{
+					Name: "init",
+					Image: blueprint.Image{Name: "c",
+						Dockerfile: "1"},
+				}, {
+					Name: "init2",
+					Image: blueprint.Image{Name: "a",
+						Dockerfile: "1"},
+				}},
+			},
+		},
+	},
+		db.Image{Name: "a", Dockerfile: "1"},
+		db.Image{Name: "b", Dockerfile: "1"},
+		db.Image{Name: "c", Dockerfile: "1"},
+	)
 }
 
 func checkLoadBalancer(t *testing.T, conn db.Conn, bp blueprint.Blueprint,
@@ -629,6 +796,79 @@ func TestPortPlacementsIgnoresConnectionOrder(t *testing.T) {
 			"resulting placement rules should be the same")
 }
 
+func TestPortPlacementsProtocols(t *testing.T) {
+	t.Parallel()
+
+	newInboundConn := func(dst, protocol string) db.Connection {
+		return db.Connection{From: []string{blueprint.PublicInternetLabel},
+			To: []string{dst}, MinPort: 53, MaxPort: 53,
+			Protocol: protocol}
+	}
+
+	// Containers listening on the same port with different protocols don't
+	// conflict.
+	assert.Empty(t, portPlacements([]db.Connection{
+		newInboundConn("tcp", blueprint.TCPProtocol),
+		newInboundConn("udp", blueprint.UDPProtocol),
+	}))
+
+	// Connections without a protocol conflict with both TCP and UDP, but each
+	// pair of containers only gets one rule.
+	res := portPlacements([]db.Connection{
+		newInboundConn("both", ""),
+		newInboundConn("udp", blueprint.UDPProtocol),
+		newInboundConn("both", blueprint.TCPProtocol),
+	})
+	assert.Len(t, res, 2)
+	assert.Contains(t, res, db.Placement{Exclusive: true,
+		TargetContainer: "both", OtherContainer: "udp"})
+	assert.Contains(t, res, db.Placement{Exclusive: true,
+		TargetContainer: "udp", OtherContainer: "both"})
+
+	// ICMP doesn't use ports, so it never conflicts.
+	assert.Empty(t, portPlacements([]db.Connection{
+		{From: []string{blueprint.PublicInternetLabel}, To: []string{"a"},
+			Protocol: blueprint.ICMPProtocol},
+		{From: []string{blueprint.PublicInternetLabel}, To: []string{"b"},
+			Protocol: blueprint.ICMPProtocol},
+	}))
+}
+
+func TestPortPlacementsPublicPorts(t *testing.T) {
+	t.Parallel()
+
+	newInboundConn := func(dst string, min, max, public int) db.Connection {
+		return db.Connection{From: []string{blueprint.PublicInternetLabel},
+			To: []string{dst}, MinPort: min, MaxPort: max,
+			PublicPort: public}
+	}
+
+	// Containers listening on the same port conflict only if it's exposed on
+	// the same public port.
+	assert.Empty(t, portPlacements([]db.Connection{
+		newInboundConn("a", 80, 80, 0),
+		newInboundConn("b", 80, 80, 8080),
+	}))
+
+	res := portPlacements([]db.Connection{
+		newInboundConn("a", 80, 80, 8080),
+		newInboundConn("b", 8080, 8080, 0),
+	})
+	assert.Len(t, res, 2)
+	assert.Contains(t, res, db.Placement{Exclusive: true,
+		TargetContainer: "a", OtherContainer: "b"})
+
+	// Overlapping ranges conflict, even if they don't start on the same port.
+	res = portPlacements([]db.Connection{
+		newInboundConn("a", 8000, 8010, 0),
+		newInboundConn("b", 8005, 8005, 0),
+		newInboundConn("c", 8011, 8020, 0),
+	})
+	assert.Len(t, res, 2)
+	assert.Contains(t, res, db.Placement{Exclusive: true,
+		TargetContainer: "b", OtherContainer: "a"})
+}
+
 func testUpdatePolicy(conn db.Conn, bp blueprint.Blueprint) {
 	conn.Txn(db.AllTables...).Run(func(view db.Database) error {
 		bpRow, err := view.GetBlueprint()
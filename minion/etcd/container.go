@@ -83,20 +83,40 @@ func joinContainers(view db.Database, etcdDBCs []db.Container) {
 			BlueprintID       string
 			Image             string
 			Command           string
+			Entrypoint        string
+			WorkingDir        string
 			Env               string
 			FilepathToContent string
 			Privileged        bool
+			SecurityContext   string
 			VolumeMounts      string
+			Resources         string
+			LivenessCheck     string
+			ReadinessCheck    string
+			PodContainers     string
+			Job               string
+			PreStop           string
+			GracePeriod       int
 		}{
 			Hostname:          dbc.Hostname,
 			IP:                dbc.IP,
 			BlueprintID:       dbc.BlueprintID,
 			Image:             dbc.Image,
 			Command:           fmt.Sprintf("%v", dbc.Command),
+			Entrypoint:        fmt.Sprintf("%v", dbc.Entrypoint),
+			WorkingDir:        dbc.WorkingDir,
 			Env:               containerValueMapKey(dbc.Env),
 			FilepathToContent: containerValueMapKey(dbc.FilepathToContent),
 			Privileged:        dbc.Privileged,
+			SecurityContext:   securityContextKey(dbc.SecurityContext),
 			VolumeMounts:      fmt.Sprintf("%v", dbc.VolumeMounts),
+			Resources:         fmt.Sprintf("%+v", dbc.Resources),
+			LivenessCheck:     healthCheckKey(dbc.LivenessCheck),
+			ReadinessCheck:    healthCheckKey(dbc.ReadinessCheck),
+			PodContainers:     podContainersKey(dbc),
+			Job:               jobKey(dbc.Job),
+			PreStop:           fmt.Sprintf("%v", dbc.PreStop),
+			GracePeriod:       dbc.TerminationGracePeriodSeconds,
 		}
 	}
 
@@ -122,15 +142,81 @@ func joinContainers(view db.Database, etcdDBCs []db.Container) {
 		dbc.BlueprintID = edbc.BlueprintID
 		dbc.Image = edbc.Image
 		dbc.Command = edbc.Command
+		dbc.Entrypoint = edbc.Entrypoint
+		dbc.WorkingDir = edbc.WorkingDir
 		dbc.Env = edbc.Env
 		dbc.FilepathToContent = edbc.FilepathToContent
 		dbc.Hostname = edbc.Hostname
 		dbc.Privileged = edbc.Privileged
+		dbc.SecurityContext = edbc.SecurityContext
 		dbc.VolumeMounts = edbc.VolumeMounts
+		dbc.Resources = edbc.Resources
+		dbc.LivenessCheck = edbc.LivenessCheck
+		dbc.ReadinessCheck = edbc.ReadinessCheck
+		dbc.UpdateStrategy = edbc.UpdateStrategy
+		dbc.Sidecars = edbc.Sidecars
+		dbc.InitContainers = edbc.InitContainers
+		dbc.Job = edbc.Job
+		dbc.PreStop = edbc.PreStop
+		dbc.TerminationGracePeriodSeconds = edbc.TerminationGracePeriodSeconds
+		dbc.Retiring = edbc.Retiring
 		view.Commit(dbc)
 	}
 }
 
+// healthCheckKey converts the given health check into a consistent string.
+// It can't be formatted with fmt because its actions are pointers.
+func healthCheckKey(check *blueprint.HealthCheck) string {
+	if check == nil {
+		return ""
+	}
+
+	// Marshalling can't fail because health checks only contain strings and
+	// numbers.
+	bytes, _ := json.Marshal(check)
+	return string(bytes)
+}
+
+// securityContextKey converts the container's security context into a
+// consistent string.
+func securityContextKey(sc *blueprint.SecurityContext) string {
+	if sc == nil {
+		return ""
+	}
+
+	// Marshalling can't fail because security contexts only contain strings,
+	// numbers and booleans.
+	bytes, _ := json.Marshal(sc)
+	return string(bytes)
+}
+
+// podContainersKey converts the container's sidecars and init containers into
+// a consistent string.
+func podContainersKey(dbc db.Container) string {
+	if len(dbc.Sidecars) == 0 && len(dbc.InitContainers) == 0 {
+		return ""
+	}
+
+	bytes, err := json.Marshal([][]blueprint.PodContainer{
+		dbc.Sidecars, dbc.InitContainers})
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal pod containers")
+	}
+	return string(bytes)
+}
+
+// jobKey converts the given job settings into a consistent string. It can't
+// be formatted with fmt because the backoff limit is a pointer.
+func jobKey(job *blueprint.Job) string {
+	if job == nil {
+		return ""
+	}
+
+	// Marshalling can't fail because jobs only contain strings and numbers.
+	bytes, _ := json.Marshal(job)
+	return string(bytes)
+}
+
 // containerValueMapKey converts the given map of strings to ContainerValues
 // into a consistent string.
 func containerValueMapKey(x map[string]blueprint.ContainerValue) string {
@@ -81,6 +81,7 @@ func TestRunContainerOnce(t *testing.T) {
         "Hostname": "host",
         "Created": "0001-01-01T00:00:00Z",
         "Privileged": true,
+        "Completed": "0001-01-01T00:00:00Z",
         "Image": "ubuntu"
     }
 ]`
@@ -177,3 +178,43 @@ func TestRunContainerOnce(t *testing.T) {
 	dbcs = conn.SelectFromContainer(nil)
 	assert.Len(t, dbcs, 0)
 }
+
+func TestHealthCheckKey(t *testing.T) {
+	t.Parallel()
+
+	check := func(port int) *blueprint.HealthCheck {
+		return &blueprint.HealthCheck{
+			HTTPGet:       &blueprint.HTTPGetCheck{Path: "/", Port: port},
+			PeriodSeconds: 5,
+		}
+	}
+
+	assert.Equal(t, "", healthCheckKey(nil))
+	assert.Equal(t, healthCheckKey(check(80)), healthCheckKey(check(80)))
+	assert.NotEqual(t, healthCheckKey(check(80)), healthCheckKey(check(81)))
+}
+
+func TestPodContainersKey(t *testing.T) {
+	t.Parallel()
+
+	sidecar := blueprint.PodContainer{Name: "proxy",
+		Image: blueprint.Image{Name: "envoy"}}
+	assert.Equal(t, "", podContainersKey(db.Container{}))
+	assert.NotEqual(t,
+		podContainersKey(db.Container{Sidecars: []blueprint.PodContainer{
+			sidecar}}),
+		podContainersKey(db.Container{InitContainers: []blueprint.PodContainer{
+			sidecar}}))
+}
+
+func TestJobKey(t *testing.T) {
+	t.Parallel()
+
+	job := func(backoffLimit int) *blueprint.Job {
+		return &blueprint.Job{Schedule: "@daily", BackoffLimit: &backoffLimit}
+	}
+
+	assert.Equal(t, "", jobKey(nil))
+	assert.Equal(t, jobKey(job(1)), jobKey(job(1)))
+	assert.NotEqual(t, jobKey(job(1)), jobKey(job(2)))
+}
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	"crypto/sha1"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -14,7 +15,9 @@ import (
 	log "github.com/sirupsen/logrus"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	appsclient "k8s.io/client-go/kubernetes/typed/apps/v1"
 	"k8s.io/client-go/util/retry"
 )
@@ -61,11 +64,20 @@ func updateDeployments(conn db.Conn, deploymentsClient appsclient.DeploymentInte
 		key, key)
 
 	for _, pair := range pairs {
+		// Rolling updates run the old and new pods side by side, so they're
+		// only safe if the new pod has a different IP. Other changes, such as
+		// to the value of a secret, recreate the pod instead.
+		deployment := pair.L.(appsv1.Deployment)
+		current := pair.R.(appsv1.Deployment)
+		if deployment.Spec.Template.Annotations[keldaIPKey] ==
+			current.Spec.Template.Annotations[keldaIPKey] {
+			deployment.Spec.Strategy = recreateStrategy
+		}
+
 		// Retry updating the deployment if the apiserver reports that there's
 		// a conflict. Conflicts are benign -- for example, there might be a
 		// conflict if Kubernetes updated the deployment to change the pod
 		// status.
-		deployment := pair.L.(appsv1.Deployment)
 		c.Inc("Update deployment")
 		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 			_, err := deploymentsClient.Update(&deployment)
@@ -105,11 +117,76 @@ const (
 	filesHashKey      = "files-hash"
 	dockerfileHashKey = "dockerfile-hash"
 	imageKey          = "friendly-image"
+	podSpecHashKey    = "pod-spec-hash"
+	keldaIPKey        = "keldaIP"
+
+	// The pod annotations that select the seccomp and AppArmor profiles of
+	// each container. Kubernetes doesn't have fields for them yet.
+	apparmorKeyPrefix = "container.apparmor.security.beta.kubernetes.io/"
 )
 
+// Roll out pods by destroying the previous ones before creating the new ones,
+// rather than trying to create the new pod version before destroying the old
+// one. This way, there are never two pods with the same keldaIP, which can
+// cause issues for the CNI plugin.
+var recreateStrategy = appsv1.DeploymentStrategy{
+	Type: appsv1.RecreateDeploymentStrategyType,
+}
+
+// Containers with the rolling update strategy are replaced by a container with
+// a new IP, so the new pod is started before the old one is destroyed. The old
+// pod is only destroyed once the new one is ready.
+var rollingStrategy = appsv1.DeploymentStrategy{
+	Type: appsv1.RollingUpdateDeploymentStrategyType,
+	RollingUpdate: &appsv1.RollingUpdateDeployment{
+		MaxUnavailable: &intstrZero,
+		MaxSurge:       &intstrOne,
+	},
+}
+
+var intstrZero, intstrOne = intstr.FromInt(0), intstr.FromInt(1)
+
 func makeDesiredDeployments(conn db.Conn, secretClient SecretClient) (
 	[]appsv1.Deployment, error) {
 
+	pods, err := makeDesiredPods(conn, secretClient)
+	if err != nil {
+		return nil, err
+	}
+
+	// Retiring containers share their deployment with their replacement,
+	// which rolls out the replacement's pod. If the replacement's pod can't be
+	// created yet, for example because its image is still building, the
+	// retiring container's pod is left running instead.
+	var deployments []appsv1.Deployment
+	deployed := map[string]bool{}
+	for _, retiring := range []bool{false, true} {
+		for _, pod := range pods {
+			dbc := pod.dbc
+			if dbc.Job != nil || dbc.Retiring != retiring ||
+				deployed[dbc.Hostname] {
+				continue
+			}
+
+			deployments = append(deployments, makeDeployment(dbc, pod.spec))
+			deployed[dbc.Hostname] = true
+		}
+	}
+	return deployments, nil
+}
+
+// A desiredPod is the pod that runs a container.
+type desiredPod struct {
+	dbc  db.Container
+	spec corev1.PodSpec
+}
+
+// makeDesiredPods returns the pods for the containers in the database that
+// have been assigned an IP. Containers whose pods can't be created yet are
+// skipped.
+func makeDesiredPods(conn db.Conn, secretClient SecretClient) (
+	[]desiredPod, error) {
+
 	var containers []db.Container
 	var images []db.Image
 	var idToAffinity map[string]*corev1.Affinity
@@ -141,55 +218,78 @@ func makeDesiredDeployments(conn db.Conn, secretClient SecretClient) (
 			return nil, err
 		}
 	}
+	addVolumeAffinities(idToAffinity, volumes, containers)
 
-	var deployments []appsv1.Deployment
+	var pods []desiredPod
 	for _, dbc := range containers {
-		pod, ok := makePod(images, idToAffinity, secretClient, volumeMap, dbc)
+		spec, ok := makePod(images, idToAffinity, secretClient, volumeMap,
+			dbc)
 		if ok {
-			deployments = append(deployments, makeDeployment(dbc, pod))
+			pods = append(pods, desiredPod{dbc, spec})
 		}
 	}
-	return deployments, nil
+	return pods, nil
 }
 
 func makeDeployment(dbc db.Container, pod corev1.PodSpec) appsv1.Deployment {
-	// These annotations are used by the join in `updateStatuses` to match
-	// up Kubernetes pods with the containers in the database.
-	annotations := map[string]string{
-		dockerfileHashKey: hashStr(dbc.Dockerfile),
-		filesHashKey:      hashContainerValueMap(dbc.FilepathToContent),
-		envHashKey:        hashContainerValueMap(dbc.Env),
-		imageKey:          dbc.Image,
-		"keldaIP":         dbc.IP,
+	strategy := recreateStrategy
+	if dbc.UpdateStrategy == blueprint.RollingUpdate {
+		strategy = rollingStrategy
 	}
 	return appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: dbc.Hostname,
 		},
 		Spec: appsv1.DeploymentSpec{
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						hostnameKey: dbc.Hostname,
-					},
-					Annotations: annotations,
-				},
-				Spec: pod,
-			},
+			Template: makePodTemplate(dbc, pod),
 			Selector: &metav1.LabelSelector{
 				MatchLabels: map[string]string{
 					hostnameKey: dbc.Hostname,
 				},
 			},
-			// Roll out pods by destroying the previous ones before creating
-			// the new ones, rather than trying to create the new pod version
-			// before destroying the old one. This way, there are never two
-			// pods with the same keldaIP, which can cause issues for the CNI
-			// plugin.
-			Strategy: appsv1.DeploymentStrategy{
-				Type: appsv1.RecreateDeploymentStrategyType,
+			Strategy: strategy,
+		},
+	}
+}
+
+// makePodTemplate labels and annotates the container's pod for deployments
+// and jobs.
+func makePodTemplate(dbc db.Container, pod corev1.PodSpec) corev1.PodTemplateSpec {
+	// These annotations are used by the join in `updateStatuses` to match
+	// up Kubernetes pods with the containers in the database.
+	annotations := map[string]string{
+		dockerfileHashKey: hashStr(dbc.Dockerfile),
+		filesHashKey:      hashContainerValueMap(dbc.FilepathToContent),
+		envHashKey:        hashContainerValueMap(dbc.Env),
+		imageKey:          dbc.Image,
+		keldaIPKey:        dbc.IP,
+	}
+	if needsPodSpecHash(dbc) {
+		annotations[podSpecHashKey] = hashSpec(pod)
+	}
+	if sc := dbc.SecurityContext; sc != nil {
+		if profile := sc.SeccompProfile; profile != "" {
+			// The kubelet calls the runtime's default seccomp profile
+			// docker/default.
+			if profile == "runtime/default" {
+				profile = "docker/default"
+			}
+			annotations[corev1.SeccompContainerAnnotationKeyPrefix+
+				dbc.Hostname] = profile
+		}
+		if sc.AppArmorProfile != "" {
+			annotations[apparmorKeyPrefix+dbc.Hostname] = sc.AppArmorProfile
+		}
+	}
+
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				hostnameKey: dbc.Hostname,
 			},
+			Annotations: annotations,
 		},
+		Spec: pod,
 	}
 }
 
@@ -202,45 +302,76 @@ func makePod(images []db.Image, idToAffinity map[string]*corev1.Affinity,
 	secretClient SecretClient, volumeMap map[string]corev1.Volume,
 	dbc db.Container) (corev1.PodSpec, bool) {
 
-	// If the container isn't built by Kelda, the image doesn't have to be
-	// rewritten to the version hosted by the local registry.
-	image := dbc.Image
-	if dbc.Dockerfile != "" {
-		var foundImage bool
-		for _, img := range images {
-			if img.Name == dbc.Image && img.Dockerfile == dbc.Dockerfile &&
-				img.Status == db.Built {
-				foundImage = true
-				image = img.RepoDigest
-				break
-			}
-		}
-		if !foundImage {
-			return corev1.PodSpec{}, false
-		}
+	image, ok := resolveImage(images, blueprint.Image{
+		Name:       dbc.Image,
+		Dockerfile: dbc.Dockerfile,
+	})
+	if !ok {
+		return corev1.PodSpec{}, false
 	}
 
 	env, missing := makeSecretHashEnvVars(secretClient, dbc.GetReferencedSecrets())
 	if len(missing) != 0 {
 		return corev1.PodSpec{}, false
 	}
+
+	resources, err := makeResourceRequirements(dbc.Resources)
+	if err != nil {
+		log.WithError(err).WithField("container", dbc.Hostname).
+			Warn("Invalid container resources")
+		return corev1.PodSpec{}, false
+	}
 	env = append(env, makePodEnvVars(dbc.Env)...)
 
+	// Volumes may be mounted by both the container and its sidecars and init
+	// containers, but each is only added to the pod once.
 	volumes, volumeMounts := makeVolumesForFilepathToContent(dbc.FilepathToContent)
-	for _, volumeMount := range dbc.VolumeMounts {
-		volume, ok := volumeMap[volumeMount.VolumeName]
+	podVolumes := map[string]corev1.Volume{}
+	mountVolumes := func(mounts []blueprint.VolumeMount) (
+		[]corev1.VolumeMount, bool) {
+		var volumeMounts []corev1.VolumeMount
+		for _, volumeMount := range mounts {
+			volume, ok := volumeMap[volumeMount.VolumeName]
+			if !ok {
+				log.WithField("volume", volumeMount.VolumeName).
+					WithField("container", dbc.Hostname).
+					Warn("Unknown volume reference")
+				return nil, false
+			}
+
+			podVolumes[volumeMount.VolumeName] = volume
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				MountPath: volumeMount.MountPath,
+				Name:      volumeMount.VolumeName,
+			})
+		}
+		return volumeMounts, true
+	}
+
+	mounts, ok := mountVolumes(dbc.VolumeMounts)
+	if !ok {
+		return corev1.PodSpec{}, false
+	}
+	volumeMounts = append(volumeMounts, mounts...)
+
+	var initContainers, sidecars []corev1.Container
+	for _, pc := range dbc.InitContainers {
+		container, ok := makePodContainer(images, mountVolumes, pc)
 		if !ok {
-			log.WithField("volume", volumeMount.VolumeName).
-				WithField("container", dbc.Hostname).
-				Warn("Unknown volume reference")
 			return corev1.PodSpec{}, false
 		}
+		initContainers = append(initContainers, container)
+	}
+	for _, pc := range dbc.Sidecars {
+		container, ok := makePodContainer(images, mountVolumes, pc)
+		if !ok {
+			return corev1.PodSpec{}, false
+		}
+		sidecars = append(sidecars, container)
+	}
 
+	for _, volume := range podVolumes {
 		volumes = append(volumes, volume)
-		volumeMounts = append(volumeMounts, corev1.VolumeMount{
-			MountPath: volumeMount.MountPath,
-			Name:      volumeMount.VolumeName,
-		})
 	}
 
 	// Sort the volumes and volume mounts so that the pod config is
@@ -250,26 +381,232 @@ func makePod(images []db.Image, idToAffinity map[string]*corev1.Affinity,
 	sort.Sort(volumeSlice(volumes))
 	sort.Sort(envVarSlice(env))
 
-	return corev1.PodSpec{
-		Hostname: dbc.Hostname,
-		Containers: []corev1.Container{
-			{
-				Name:         dbc.Hostname,
-				Image:        image,
-				Env:          env,
-				Args:         dbc.Command,
-				VolumeMounts: volumeMounts,
-				SecurityContext: &corev1.SecurityContext{
-					Privileged: &dbc.Privileged,
-				},
-			},
+	// The container itself must be first, so that its pod can be matched
+	// with it in `updateStatuses`.
+	containers := append([]corev1.Container{
+		{
+			Name:           dbc.Hostname,
+			Image:          image,
+			Env:            env,
+			Command:        dbc.Entrypoint,
+			Args:           dbc.Command,
+			WorkingDir:     dbc.WorkingDir,
+			Lifecycle:      makeLifecycle(dbc.PreStop),
+			VolumeMounts:   volumeMounts,
+			Resources:      resources,
+			LivenessProbe:  makeProbe(dbc.LivenessCheck),
+			ReadinessProbe: makeProbe(dbc.ReadinessCheck),
+			SecurityContext: makeSecurityContext(dbc.Privileged,
+				dbc.SecurityContext),
 		},
-		Affinity:  idToAffinity[dbc.Hostname],
-		DNSPolicy: corev1.DNSDefault,
-		Volumes:   volumes,
+	}, sidecars...)
+
+	spec := corev1.PodSpec{
+		Hostname:       dbc.Hostname,
+		Containers:     containers,
+		InitContainers: initContainers,
+		Affinity:       idToAffinity[dbc.Hostname],
+		DNSPolicy:      corev1.DNSDefault,
+		Volumes:        volumes,
+	}
+	if dbc.TerminationGracePeriodSeconds != 0 {
+		gracePeriod := int64(dbc.TerminationGracePeriodSeconds)
+		spec.TerminationGracePeriodSeconds = &gracePeriod
+	}
+	return spec, true
+}
+
+// makeLifecycle returns the hooks that run the container's PreStop command
+// before it's stopped.
+func makeLifecycle(preStop []string) *corev1.Lifecycle {
+	if len(preStop) == 0 {
+		return nil
+	}
+	return &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: preStop},
+		},
+	}
+}
+
+// makeSecurityContext converts the container's security context into the
+// Kubernetes representation. The profiles are set by annotations instead, in
+// `makePodTemplate`.
+func makeSecurityContext(privileged bool,
+	sc *blueprint.SecurityContext) *corev1.SecurityContext {
+
+	kubeSC := &corev1.SecurityContext{Privileged: &privileged}
+	if sc == nil {
+		return kubeSC
+	}
+
+	if len(sc.CapAdd) != 0 || len(sc.CapDrop) != 0 {
+		kubeSC.Capabilities = &corev1.Capabilities{}
+		for _, capability := range sc.CapAdd {
+			kubeSC.Capabilities.Add = append(kubeSC.Capabilities.Add,
+				corev1.Capability(capability))
+		}
+		for _, capability := range sc.CapDrop {
+			kubeSC.Capabilities.Drop = append(kubeSC.Capabilities.Drop,
+				corev1.Capability(capability))
+		}
+	}
+	if sc.RunAsUser != nil {
+		user := int64(*sc.RunAsUser)
+		kubeSC.RunAsUser = &user
+	}
+	if sc.ReadOnlyRootFilesystem {
+		kubeSC.ReadOnlyRootFilesystem = &sc.ReadOnlyRootFilesystem
+	}
+	if sc.NoNewPrivileges {
+		allowEscalation := false
+		kubeSC.AllowPrivilegeEscalation = &allowEscalation
+	}
+	return kubeSC
+}
+
+// makePodContainer converts a sidecar or init container into a Kubernetes
+// container. Volumes are mounted with `mountVolumes`, which adds them to the
+// pod.
+func makePodContainer(images []db.Image,
+	mountVolumes func([]blueprint.VolumeMount) ([]corev1.VolumeMount, bool),
+	pc blueprint.PodContainer) (corev1.Container, bool) {
+
+	image, ok := resolveImage(images, pc.Image)
+	if !ok {
+		return corev1.Container{}, false
+	}
+
+	resources, err := makeResourceRequirements(pc.Resources)
+	if err != nil {
+		log.WithError(err).WithField("container", pc.Name).
+			Warn("Invalid container resources")
+		return corev1.Container{}, false
+	}
+
+	volumeMounts, ok := mountVolumes(pc.VolumeMounts)
+	if !ok {
+		return corev1.Container{}, false
+	}
+
+	env := makePodEnvVars(pc.Env)
+	sort.Sort(volumeMountSlice(volumeMounts))
+	sort.Sort(envVarSlice(env))
+	return corev1.Container{
+		Name:         pc.Name,
+		Image:        image,
+		Env:          env,
+		Args:         pc.Command,
+		VolumeMounts: volumeMounts,
+		Resources:    resources,
 	}, true
 }
 
+// resolveImage returns the name that the image should be pulled by. Images
+// that aren't built by Kelda don't have to be rewritten to the version hosted
+// by the local registry. The returned boolean is false if the image hasn't
+// been built yet.
+func resolveImage(images []db.Image, image blueprint.Image) (string, bool) {
+	if image.Dockerfile == "" {
+		return image.Name, true
+	}
+
+	for _, img := range images {
+		if img.Name == image.Name && img.Dockerfile == image.Dockerfile &&
+			img.Status == db.Built {
+			return img.RepoDigest, true
+		}
+	}
+	return "", false
+}
+
+// makeProbe converts a health check into a Kubernetes probe. Health checks
+// are validated when the blueprint is loaded, so exactly one action is set.
+func makeProbe(check *blueprint.HealthCheck) *corev1.Probe {
+	if check == nil {
+		return nil
+	}
+
+	probe := corev1.Probe{
+		InitialDelaySeconds: int32(check.InitialDelaySeconds),
+		PeriodSeconds:       int32(check.PeriodSeconds),
+		TimeoutSeconds:      int32(check.TimeoutSeconds),
+		SuccessThreshold:    int32(check.SuccessThreshold),
+		FailureThreshold:    int32(check.FailureThreshold),
+	}
+	switch {
+	case check.HTTPGet != nil:
+		probe.HTTPGet = &corev1.HTTPGetAction{
+			Path: check.HTTPGet.Path,
+			Port: intstr.FromInt(check.HTTPGet.Port),
+		}
+	case check.TCPSocket != nil:
+		probe.TCPSocket = &corev1.TCPSocketAction{
+			Port: intstr.FromInt(check.TCPSocket.Port),
+		}
+	default:
+		probe.Exec = &corev1.ExecAction{Command: check.Exec}
+	}
+	return &probe
+}
+
+// makeResourceRequirements converts the CPU and memory requests and limits of
+// a container into their Kubernetes representation.
+func makeResourceRequirements(res *blueprint.Resources) (
+	corev1.ResourceRequirements, error) {
+	if res == nil {
+		return corev1.ResourceRequirements{}, nil
+	}
+
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	quantities := []struct {
+		list     corev1.ResourceList
+		name     corev1.ResourceName
+		quantity string
+	}{
+		{requests, corev1.ResourceCPU, res.CPURequest},
+		{limits, corev1.ResourceCPU, res.CPULimit},
+		{requests, corev1.ResourceMemory, res.MemoryRequest},
+		{limits, corev1.ResourceMemory, res.MemoryLimit},
+	}
+	for _, q := range quantities {
+		if q.quantity == "" {
+			continue
+		}
+
+		parsed, err := resource.ParseQuantity(q.quantity)
+		if err != nil {
+			return corev1.ResourceRequirements{}, fmt.Errorf(
+				"invalid %s quantity %q: %s", q.name, q.quantity, err)
+		}
+		q.list[q.name] = parsed
+	}
+
+	var reqs corev1.ResourceRequirements
+	if len(requests) != 0 {
+		reqs.Requests = requests
+	}
+	if len(limits) != 0 {
+		reqs.Limits = limits
+	}
+	return reqs, nil
+}
+
+// needsPodSpecHash returns whether the container's pod template is annotated
+// with the hash of its pod spec, so that it can be matched with its pod. The
+// pod's own spec can't be compared because Kubernetes fills in defaults, such
+// as setting unset resource requests to the limits, or the timings of health
+// checks. Only containers that use those fields are annotated, so that
+// upgrading Kelda doesn't change the pod templates of other containers, which
+// would restart them.
+func needsPodSpecHash(dbc db.Container) bool {
+	return dbc.Resources != nil || dbc.LivenessCheck != nil ||
+		dbc.ReadinessCheck != nil || len(dbc.Sidecars) != 0 ||
+		len(dbc.InitContainers) != 0 || dbc.SecurityContext != nil ||
+		len(dbc.PreStop) != 0 || dbc.TerminationGracePeriodSeconds != 0
+}
+
 // makeSecretHashEnvVars creates environment variables that represent the value
 // of the secrets referenced by the container. This way, if a secret value
 // changes, these environment variables will change, and Kubernetes will
@@ -385,11 +722,34 @@ func makeVolume(volume blueprint.Volume) (corev1.Volume, error) {
 	kubeVolume := corev1.Volume{
 		Name: volume.Name,
 	}
-	switch volume.Type {
-	case "hostPath":
+	switch {
+	case volume.Type == blueprint.HostPathVolume:
 		kubeVolume.HostPath = &corev1.HostPathVolumeSource{
 			Path: volume.Conf["path"],
 		}
+	case volume.Type == blueprint.EmptyDirVolume:
+		emptyDir := &corev1.EmptyDirVolumeSource{
+			Medium: corev1.StorageMedium(volume.Conf["medium"]),
+		}
+		if sizeLimit, ok := volume.Conf["sizeLimit"]; ok {
+			q, err := resource.ParseQuantity(sizeLimit)
+			if err != nil {
+				return corev1.Volume{}, fmt.Errorf(
+					"invalid size limit for volume %s: %s",
+					volume.Name, err)
+			}
+			emptyDir.SizeLimit = &q
+		}
+		kubeVolume.EmptyDir = emptyDir
+	case volume.Type == blueprint.NFSVolume:
+		kubeVolume.NFS = &corev1.NFSVolumeSource{
+			Server: volume.Conf["server"],
+			Path:   volume.Conf["path"],
+		}
+	case volume.IsCloudVolume():
+		// The disk is created by the claim made in updateVolumes.
+		kubeVolume.PersistentVolumeClaim =
+			&corev1.PersistentVolumeClaimVolumeSource{ClaimName: volume.Name}
 	default:
 		return corev1.Volume{}, fmt.Errorf("unknown volume type: %s", volume.Type)
 	}
@@ -400,6 +760,17 @@ func hashStr(toHash string) string {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(toHash)))
 }
 
+// hashSpec hashes a spec that Kelda generated, such as the spec of a pod or a
+// job. The spec stored by Kubernetes can't be compared directly because
+// Kubernetes fills in defaults, and adds labels to pod templates.
+func hashSpec(spec interface{}) string {
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		log.WithError(err).Error("Failed to marshal spec")
+	}
+	return hashStr(string(specJSON))
+}
+
 func hashContainerValueMap(containerValMap map[string]blueprint.ContainerValue) string {
 	strValMap := map[string]string{}
 	for k, v := range containerValMap {
@@ -11,7 +11,9 @@ import (
 	"github.com/stretchr/testify/mock"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 func TestUpdateDeployments(t *testing.T) {
@@ -35,7 +37,7 @@ func TestUpdateDeployments(t *testing.T) {
 		filesHashKey:      hashContainerValueMap(nil),
 		envHashKey:        hashContainerValueMap(nil),
 		imageKey:          "image",
-		"keldaIP":         "ip",
+		keldaIPKey:        "ip",
 	}
 	deployment := appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
@@ -125,6 +127,82 @@ func TestUpdateDeployments(t *testing.T) {
 	deploymentsClient.AssertExpectations(t)
 }
 
+func TestRollingDeployments(t *testing.T) {
+	t.Parallel()
+	conn := db.New()
+	deploymentsClient := &mocks.DeploymentInterface{}
+
+	conn.Txn(db.ContainerTable, db.BlueprintTable).Run(func(view db.Database) error {
+		retiring := view.InsertContainer()
+		retiring.Hostname = "web"
+		retiring.Image = "old"
+		retiring.IP = "10.0.0.2"
+		retiring.Retiring = true
+		view.Commit(retiring)
+
+		replacement := view.InsertContainer()
+		replacement.Hostname = "web"
+		replacement.Image = "new"
+		replacement.IP = "10.0.0.3"
+		replacement.UpdateStrategy = blueprint.RollingUpdate
+		view.Commit(replacement)
+
+		view.InsertBlueprint()
+		return nil
+	})
+
+	// The deployment rolls out the replacement's pod.
+	deployments, err := makeDesiredDeployments(conn, nil)
+	assert.NoError(t, err)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, "10.0.0.3",
+		deployments[0].Spec.Template.Annotations[keldaIPKey])
+	assert.Equal(t, rollingStrategy, deployments[0].Spec.Strategy)
+
+	// Pods are updated with the rolling strategy when their IP changes...
+	current := makeDeployment(db.Container{Hostname: "web", IP: "10.0.0.2"},
+		corev1.PodSpec{})
+	deploymentsClient.On("List", mock.Anything).Return(
+		&appsv1.DeploymentList{Items: []appsv1.Deployment{current}},
+		nil).Once()
+	deploymentsClient.On("Update", mock.MatchedBy(
+		func(deployment *appsv1.Deployment) bool {
+			return deployment.Spec.Strategy.Type ==
+				appsv1.RollingUpdateDeploymentStrategyType
+		})).Return(nil, nil).Once()
+	updateDeployments(conn, deploymentsClient, nil)
+	deploymentsClient.AssertExpectations(t)
+
+	// ... and recreated otherwise, so that two pods never share an IP.
+	current = deployments[0]
+	deploymentsClient.On("List", mock.Anything).Return(
+		&appsv1.DeploymentList{Items: []appsv1.Deployment{current}},
+		nil).Once()
+	deploymentsClient.On("Update", mock.MatchedBy(
+		func(deployment *appsv1.Deployment) bool {
+			return deployment.Spec.Strategy == recreateStrategy
+		})).Return(nil, nil).Once()
+	updateDeployments(conn, deploymentsClient, nil)
+	deploymentsClient.AssertExpectations(t)
+
+	// If the replacement's pod can't be created yet, the retiring container's
+	// pod is left running.
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		for _, dbc := range view.SelectFromContainer(nil) {
+			if !dbc.Retiring {
+				dbc.Dockerfile = "FROM unbuilt"
+				view.Commit(dbc)
+			}
+		}
+		return nil
+	})
+	deployments, err = makeDesiredDeployments(conn, nil)
+	assert.NoError(t, err)
+	assert.Len(t, deployments, 1)
+	assert.Equal(t, "10.0.0.2",
+		deployments[0].Spec.Template.Annotations[keldaIPKey])
+}
+
 // The pod spec should be exactly the same everytime it's built. Otherwise,
 // Kubernetes will think we're creating a different pod, and destroy the
 // old one.
@@ -351,6 +429,293 @@ func TestMakePodHostPathVolume(t *testing.T) {
 	assert.False(t, ok)
 }
 
+func TestMakePodResources(t *testing.T) {
+	t.Parallel()
+
+	pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{})
+	assert.True(t, ok)
+	assert.Equal(t, corev1.ResourceRequirements{}, pod.Containers[0].Resources)
+
+	pod, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{Resources: &blueprint.Resources{
+			CPURequest:  "500m",
+			MemoryLimit: "1Gi",
+		}})
+	assert.True(t, ok)
+	assert.Equal(t, corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("500m"),
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}, pod.Containers[0].Resources)
+
+	_, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{Resources: &blueprint.Resources{CPULimit: "lots"}})
+	assert.False(t, ok)
+}
+
+func TestPodSpecHashAnnotation(t *testing.T) {
+	t.Parallel()
+
+	// Containers that don't use any of the fields that Kubernetes fills in
+	// defaults for aren't annotated with their spec hash, so that their pod
+	// templates don't change when Kelda is upgraded.
+	dbc := db.Container{Hostname: "hostname"}
+	pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+	assert.True(t, ok)
+	assert.NotContains(t, makePodTemplate(dbc, pod).Annotations,
+		podSpecHashKey)
+
+	check := &blueprint.HealthCheck{Exec: []string{"true"}}
+	for _, dbc := range []db.Container{
+		{Resources: &blueprint.Resources{CPURequest: "1"}},
+		{LivenessCheck: check},
+		{ReadinessCheck: check},
+		{Sidecars: []blueprint.PodContainer{{Name: "sidecar",
+			Image: blueprint.Image{Name: "envoy"}}}},
+		{InitContainers: []blueprint.PodContainer{{Name: "init",
+			Image: blueprint.Image{Name: "alpine"}}}},
+	} {
+		pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+		assert.True(t, ok)
+		assert.Equal(t, hashSpec(pod),
+			makePodTemplate(dbc, pod).Annotations[podSpecHashKey])
+	}
+}
+
+func TestMakePodStop(t *testing.T) {
+	t.Parallel()
+
+	dbc := db.Container{
+		Hostname:                      "hostname",
+		Command:                       []string{"postgres"},
+		Entrypoint:                    []string{"docker-entrypoint.sh"},
+		WorkingDir:                    "/var/lib/postgresql",
+		PreStop:                       []string{"pg_ctl", "stop"},
+		TerminationGracePeriodSeconds: 120,
+	}
+	pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+	assert.True(t, ok)
+
+	container := pod.Containers[0]
+	assert.Equal(t, []string{"docker-entrypoint.sh"}, container.Command)
+	assert.Equal(t, []string{"postgres"}, container.Args)
+	assert.Equal(t, "/var/lib/postgresql", container.WorkingDir)
+	assert.Equal(t, &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"pg_ctl", "stop"}},
+		},
+	}, container.Lifecycle)
+	assert.Equal(t, int64(120), *pod.TerminationGracePeriodSeconds)
+	assert.Equal(t, hashSpec(pod),
+		makePodTemplate(dbc, pod).Annotations[podSpecHashKey])
+
+	// Unset fields are left to the image and Kubernetes' defaults.
+	pod, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{Hostname: "hostname"})
+	assert.True(t, ok)
+	assert.Nil(t, pod.Containers[0].Command)
+	assert.Nil(t, pod.Containers[0].Lifecycle)
+	assert.Nil(t, pod.TerminationGracePeriodSeconds)
+}
+
+func TestMakePodSecurityContext(t *testing.T) {
+	t.Parallel()
+
+	user := 1000
+	dbc := db.Container{
+		Hostname: "hostname",
+		SecurityContext: &blueprint.SecurityContext{
+			CapAdd:                 []string{"NET_ADMIN"},
+			CapDrop:                []string{"ALL"},
+			RunAsUser:              &user,
+			ReadOnlyRootFilesystem: true,
+			NoNewPrivileges:        true,
+			SeccompProfile:         "runtime/default",
+			AppArmorProfile:        "localhost/kelda",
+		},
+	}
+	pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+	assert.True(t, ok)
+
+	falseRef, trueRef, userRef := false, true, int64(1000)
+	assert.Equal(t, &corev1.SecurityContext{
+		Privileged: &falseRef,
+		Capabilities: &corev1.Capabilities{
+			Add:  []corev1.Capability{"NET_ADMIN"},
+			Drop: []corev1.Capability{"ALL"},
+		},
+		RunAsUser:                &userRef,
+		ReadOnlyRootFilesystem:   &trueRef,
+		AllowPrivilegeEscalation: &falseRef,
+	}, pod.Containers[0].SecurityContext)
+
+	// The profiles are set by annotations on the pod.
+	annotations := makePodTemplate(dbc, pod).Annotations
+	seccompKey := "container.seccomp.security.alpha.kubernetes.io/hostname"
+	apparmorKey := "container.apparmor.security.beta.kubernetes.io/hostname"
+	assert.Equal(t, "docker/default", annotations[seccompKey])
+	assert.Equal(t, "localhost/kelda", annotations[apparmorKey])
+	assert.Equal(t, hashSpec(pod), annotations[podSpecHashKey])
+
+	// Containers without a security context are only configured with whether
+	// they're privileged, so that their pods don't change.
+	dbc.SecurityContext = nil
+	pod, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+	assert.True(t, ok)
+	assert.Equal(t, &corev1.SecurityContext{Privileged: &falseRef},
+		pod.Containers[0].SecurityContext)
+	assert.NotContains(t, makePodTemplate(dbc, pod).Annotations, seccompKey)
+	assert.NotContains(t, makePodTemplate(dbc, pod).Annotations,
+		podSpecHashKey)
+}
+
+func TestMakePodHealthChecks(t *testing.T) {
+	t.Parallel()
+
+	pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{})
+	assert.True(t, ok)
+	assert.Nil(t, pod.Containers[0].LivenessProbe)
+	assert.Nil(t, pod.Containers[0].ReadinessProbe)
+
+	pod, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{
+			LivenessCheck: &blueprint.HealthCheck{
+				HTTPGet: &blueprint.HTTPGetCheck{
+					Path: "/healthz",
+					Port: 8080,
+				},
+				InitialDelaySeconds: 10,
+				FailureThreshold:    3,
+			},
+			ReadinessCheck: &blueprint.HealthCheck{
+				TCPSocket:     &blueprint.TCPSocketCheck{Port: 80},
+				PeriodSeconds: 5,
+			},
+		})
+	assert.True(t, ok)
+	assert.Equal(t, &corev1.Probe{
+		Handler: corev1.Handler{
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromInt(8080),
+			},
+		},
+		InitialDelaySeconds: 10,
+		FailureThreshold:    3,
+	}, pod.Containers[0].LivenessProbe)
+	assert.Equal(t, &corev1.Probe{
+		Handler: corev1.Handler{
+			TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(80)},
+		},
+		PeriodSeconds: 5,
+	}, pod.Containers[0].ReadinessProbe)
+
+	pod, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		db.Container{
+			LivenessCheck: &blueprint.HealthCheck{
+				Exec:           []string{"pg_isready"},
+				TimeoutSeconds: 2,
+			},
+		})
+	assert.True(t, ok)
+	assert.Equal(t, &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{Command: []string{"pg_isready"}},
+		},
+		TimeoutSeconds: 2,
+	}, pod.Containers[0].LivenessProbe)
+	assert.Nil(t, pod.Containers[0].ReadinessProbe)
+}
+
+func TestMakePodSidecars(t *testing.T) {
+	t.Parallel()
+
+	images := []db.Image{{
+		Name:       "migrate",
+		Dockerfile: "FROM postgres",
+		RepoDigest: "migrateDigest",
+		Status:     db.Built,
+	}}
+	volumeMap := map[string]corev1.Volume{
+		"data": {Name: "data"},
+		"logs": {Name: "logs"},
+	}
+
+	dbc := db.Container{
+		Hostname: "web",
+		Image:    "nginx",
+		VolumeMounts: []blueprint.VolumeMount{
+			{VolumeName: "logs", MountPath: "/var/log/nginx"},
+		},
+		Sidecars: []blueprint.PodContainer{{
+			Name:    "log-shipper",
+			Image:   blueprint.Image{Name: "fluentd"},
+			Command: []string{"-c", "/etc/fluentd.conf"},
+			Env: map[string]blueprint.ContainerValue{
+				"LEVEL": blueprint.NewString("info"),
+			},
+			VolumeMounts: []blueprint.VolumeMount{
+				{VolumeName: "logs", MountPath: "/logs"},
+			},
+		}},
+		InitContainers: []blueprint.PodContainer{{
+			Name: "migrate",
+			Image: blueprint.Image{Name: "migrate",
+				Dockerfile: "FROM postgres"},
+			VolumeMounts: []blueprint.VolumeMount{
+				{VolumeName: "data", MountPath: "/data"},
+			},
+			Resources: &blueprint.Resources{CPULimit: "1"},
+		}},
+	}
+	pod, ok := makePod(images, map[string]*corev1.Affinity{}, nil, volumeMap,
+		dbc)
+	assert.True(t, ok)
+
+	// The container itself is first, and volumes shared between containers
+	// are only added to the pod once.
+	assert.Len(t, pod.Containers, 2)
+	assert.Equal(t, "web", pod.Containers[0].Name)
+	assert.Equal(t, corev1.Container{
+		Name:  "log-shipper",
+		Image: "fluentd",
+		Args:  []string{"-c", "/etc/fluentd.conf"},
+		Env:   []corev1.EnvVar{{Name: "LEVEL", Value: "info"}},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "logs", MountPath: "/logs"},
+		},
+	}, pod.Containers[1])
+	assert.Equal(t, []corev1.Container{{
+		Name:  "migrate",
+		Image: "migrateDigest",
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "data", MountPath: "/data"},
+		},
+		Resources: corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("1"),
+			},
+		},
+	}}, pod.InitContainers)
+	assert.Equal(t, []corev1.Volume{volumeMap["data"], volumeMap["logs"]},
+		pod.Volumes)
+
+	// Pods aren't created until the images of their init containers and
+	// sidecars are built.
+	_, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, volumeMap, dbc)
+	assert.False(t, ok)
+
+	dbc.InitContainers = nil
+	dbc.Sidecars[0].VolumeMounts[0].VolumeName = "unknown"
+	_, ok = makePod(nil, map[string]*corev1.Affinity{}, nil, volumeMap, dbc)
+	assert.False(t, ok)
+}
+
 func TestMakeVolume(t *testing.T) {
 	t.Parallel()
 
@@ -370,6 +735,64 @@ func TestMakeVolume(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, exp, actual)
 
+	sizeLimit := resource.MustParse("1Gi")
+	exp = corev1.Volume{
+		Name: "scratch",
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				Medium:    corev1.StorageMediumMemory,
+				SizeLimit: &sizeLimit,
+			},
+		},
+	}
+	actual, err = makeVolume(blueprint.Volume{
+		Name: "scratch",
+		Type: blueprint.EmptyDirVolume,
+		Conf: map[string]string{"medium": "Memory", "sizeLimit": "1Gi"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, exp, actual)
+
+	_, err = makeVolume(blueprint.Volume{
+		Name: "scratch",
+		Type: blueprint.EmptyDirVolume,
+		Conf: map[string]string{"sizeLimit": "lots"},
+	})
+	assert.Error(t, err)
+
+	exp = corev1.Volume{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: "data",
+			},
+		},
+	}
+	actual, err = makeVolume(blueprint.Volume{
+		Name: "data",
+		Type: blueprint.GooglePersistentDiskVolume,
+		Conf: map[string]string{"size": "10Gi"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, exp, actual)
+
+	exp = corev1.Volume{
+		Name: "shared",
+		VolumeSource: corev1.VolumeSource{
+			NFS: &corev1.NFSVolumeSource{
+				Server: "10.0.0.5",
+				Path:   "/exports/shared",
+			},
+		},
+	}
+	actual, err = makeVolume(blueprint.Volume{
+		Name: "shared",
+		Type: blueprint.NFSVolume,
+		Conf: map[string]string{"server": "10.0.0.5", "path": "/exports/shared"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, exp, actual)
+
 	_, err = makeVolume(blueprint.Volume{Type: "unsupported"})
 	assert.EqualError(t, err, "unknown volume type: unsupported")
 }
@@ -0,0 +1,252 @@
+package kubernetes
+
+import (
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/join"
+
+	log "github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	batchclient "k8s.io/client-go/kubernetes/typed/batch/v1"
+	batchbetaclient "k8s.io/client-go/kubernetes/typed/batch/v1beta1"
+	"k8s.io/client-go/util/retry"
+)
+
+// The annotation on jobs that records the hash of their spec. Unlike
+// deployments, the pods of jobs can't be updated, so jobs whose spec changes
+// are deleted and recreated instead.
+const jobHashKey = "job-hash"
+
+// Delete the pods of deleted jobs before the jobs themselves, so that a
+// recreated job's pod never runs alongside the old pod with the same keldaIP.
+var foregroundDeletion = metav1.DeletePropagationForeground
+
+// updateJobs syncs the containers that the user specified as jobs into
+// Kubernetes jobs and cron jobs.
+func updateJobs(conn db.Conn, jobsClient batchclient.JobInterface,
+	cronJobsClient batchbetaclient.CronJobInterface, secretClient SecretClient) {
+
+	currentJobs, err := jobsClient.List(metav1.ListOptions{})
+	if err != nil {
+		log.WithError(err).Error("Failed to list current jobs")
+		return
+	}
+
+	currentCronJobs, err := cronJobsClient.List(metav1.ListOptions{})
+	if err != nil {
+		log.WithError(err).Error("Failed to list current cron jobs")
+		return
+	}
+
+	pods, err := makeDesiredPods(conn, secretClient)
+	if err != nil {
+		if err != errNoBlueprint {
+			log.WithError(err).Error("Failed to create desired jobs")
+		}
+		return
+	}
+
+	var desiredJobs []batchv1.Job
+	var desiredCronJobs []batchv1beta1.CronJob
+	for _, pod := range pods {
+		switch {
+		case pod.dbc.Job == nil:
+		case pod.dbc.Job.Schedule == "":
+			desiredJobs = append(desiredJobs, makeJob(pod.dbc, pod.spec))
+		default:
+			desiredCronJobs = append(desiredCronJobs,
+				makeCronJob(pod.dbc, pod.spec))
+		}
+	}
+
+	// Jobs started by cron jobs are managed by their cron job.
+	var keldaJobs []batchv1.Job
+	for _, job := range currentJobs.Items {
+		if len(job.OwnerReferences) == 0 {
+			keldaJobs = append(keldaJobs, job)
+		}
+	}
+
+	syncJobs(jobsClient, desiredJobs, keldaJobs)
+	syncCronJobs(cronJobsClient, desiredCronJobs, currentCronJobs.Items)
+}
+
+func syncJobs(jobsClient batchclient.JobInterface, desired,
+	current []batchv1.Job) {
+
+	key := func(intf interface{}) interface{} {
+		return intf.(batchv1.Job).Name
+	}
+	pairs, toCreate, toDelete := join.HashJoin(jobSlice(desired),
+		jobSlice(current), key, key)
+
+	// Jobs that have changed are deleted, and recreated once the deletion
+	// completes. Jobs that haven't changed are left alone, so that completed
+	// jobs aren't run again.
+	for _, pair := range pairs {
+		job := pair.L.(batchv1.Job)
+		current := pair.R.(batchv1.Job)
+		if job.Annotations[jobHashKey] != current.Annotations[jobHashKey] {
+			toDelete = append(toDelete, current)
+		}
+	}
+
+	for _, intf := range toCreate {
+		job := intf.(batchv1.Job)
+		log.WithField("job", job.Name).Info("Creating job")
+		c.Inc("Create job")
+		if _, err := jobsClient.Create(&job); err != nil {
+			log.WithError(err).WithField("job", job.Name).
+				Error("Failed to create job")
+		}
+	}
+
+	for _, intf := range toDelete {
+		job := intf.(batchv1.Job)
+		if job.DeletionTimestamp != nil {
+			continue
+		}
+
+		log.WithField("job", job.Name).Info("Deleting job")
+		c.Inc("Delete job")
+		err := jobsClient.Delete(job.Name, &metav1.DeleteOptions{
+			PropagationPolicy: &foregroundDeletion,
+		})
+		if err != nil {
+			log.WithError(err).WithField("job", job.Name).
+				Error("Failed to delete job")
+		}
+	}
+}
+
+func syncCronJobs(cronJobsClient batchbetaclient.CronJobInterface, desired,
+	current []batchv1beta1.CronJob) {
+
+	key := func(intf interface{}) interface{} {
+		return intf.(batchv1beta1.CronJob).Name
+	}
+	pairs, toCreate, toDelete := join.HashJoin(cronJobSlice(desired),
+		cronJobSlice(current), key, key)
+
+	// Unlike jobs, cron jobs can be updated. The change applies from the
+	// next run.
+	for _, pair := range pairs {
+		cronJob := pair.L.(batchv1beta1.CronJob)
+		if cronJob.Annotations[jobHashKey] ==
+			pair.R.(batchv1beta1.CronJob).Annotations[jobHashKey] {
+			continue
+		}
+
+		c.Inc("Update cron job")
+		err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+			_, err := cronJobsClient.Update(&cronJob)
+			return err
+		})
+		if err != nil {
+			log.WithError(err).WithField("cronJob", cronJob.Name).
+				Error("Failed to update cron job")
+		}
+	}
+
+	for _, intf := range toCreate {
+		cronJob := intf.(batchv1beta1.CronJob)
+		log.WithField("cronJob", cronJob.Name).Info("Creating cron job")
+		c.Inc("Create cron job")
+		if _, err := cronJobsClient.Create(&cronJob); err != nil {
+			log.WithError(err).WithField("cronJob", cronJob.Name).
+				Error("Failed to create cron job")
+		}
+	}
+
+	for _, intf := range toDelete {
+		cronJob := intf.(batchv1beta1.CronJob)
+		log.WithField("cronJob", cronJob.Name).Info("Deleting cron job")
+		c.Inc("Delete cron job")
+		err := cronJobsClient.Delete(cronJob.Name, &metav1.DeleteOptions{
+			PropagationPolicy: &foregroundDeletion,
+		})
+		if err != nil {
+			log.WithError(err).WithField("cronJob", cronJob.Name).
+				Error("Failed to delete cron job")
+		}
+	}
+}
+
+func makeJob(dbc db.Container, pod corev1.PodSpec) batchv1.Job {
+	spec := makeJobSpec(dbc, pod)
+	return batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        dbc.Hostname,
+			Annotations: map[string]string{jobHashKey: hashSpec(spec)},
+		},
+		Spec: spec,
+	}
+}
+
+func makeCronJob(dbc db.Container, pod corev1.PodSpec) batchv1beta1.CronJob {
+	spec := batchv1beta1.CronJobSpec{
+		Schedule: dbc.Job.Schedule,
+
+		// Runs of the same container would share its IP.
+		ConcurrencyPolicy: batchv1beta1.ForbidConcurrent,
+		JobTemplate: batchv1beta1.JobTemplateSpec{
+			Spec: makeJobSpec(dbc, pod),
+		},
+	}
+	return batchv1beta1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        dbc.Hostname,
+			Annotations: map[string]string{jobHashKey: hashSpec(spec)},
+		},
+		Spec: spec,
+	}
+}
+
+// makeJobSpec returns the spec of the job that runs the container's pod to
+// completion. The container is restarted within its pod when it fails, rather
+// than being replaced by a new pod, so that there's only ever one pod with the
+// container's IP.
+func makeJobSpec(dbc db.Container, pod corev1.PodSpec) batchv1.JobSpec {
+	pod.RestartPolicy = corev1.RestartPolicyOnFailure
+	parallelism := int32(1)
+	spec := batchv1.JobSpec{
+		Parallelism: &parallelism,
+		Template:    makePodTemplate(dbc, pod),
+	}
+
+	if dbc.Job.Completions != 0 {
+		completions := int32(dbc.Job.Completions)
+		spec.Completions = &completions
+	}
+	if dbc.Job.BackoffLimit != nil {
+		backoffLimit := int32(*dbc.Job.BackoffLimit)
+		spec.BackoffLimit = &backoffLimit
+	}
+	if dbc.Job.ActiveDeadlineSeconds != 0 {
+		deadline := int64(dbc.Job.ActiveDeadlineSeconds)
+		spec.ActiveDeadlineSeconds = &deadline
+	}
+	return spec
+}
+
+type jobSlice []batchv1.Job
+
+func (slc jobSlice) Get(ii int) interface{} {
+	return slc[ii]
+}
+
+func (slc jobSlice) Len() int {
+	return len(slc)
+}
+
+type cronJobSlice []batchv1beta1.CronJob
+
+func (slc cronJobSlice) Get(ii int) interface{} {
+	return slc[ii]
+}
+
+func (slc cronJobSlice) Len() int {
+	return len(slc)
+}
@@ -0,0 +1,208 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/kubernetes/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateJobs(t *testing.T) {
+	t.Parallel()
+	conn := db.New()
+	jobsClient := &mocks.JobInterface{}
+	cronJobsClient := &mocks.CronJobInterface{}
+
+	// No actions should be taken if we were unable to list the current jobs.
+	jobsClient.On("List", mock.Anything).Return(nil, assert.AnError).Once()
+	updateJobs(conn, jobsClient, cronJobsClient, nil)
+	jobsClient.AssertExpectations(t)
+	cronJobsClient.AssertExpectations(t)
+
+	conn.Txn(db.ContainerTable, db.BlueprintTable).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.Hostname = "job"
+		dbc.Image = "image"
+		dbc.IP = "ip"
+		dbc.Job = &blueprint.Job{}
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.Hostname = "cronjob"
+		dbc.Image = "image"
+		dbc.IP = "ip2"
+		dbc.Job = &blueprint.Job{Schedule: "@daily"}
+		view.Commit(dbc)
+
+		dbc = view.InsertContainer()
+		dbc.Hostname = "deployment"
+		dbc.Image = "image"
+		dbc.IP = "ip3"
+		view.Commit(dbc)
+
+		view.InsertBlueprint()
+		return nil
+	})
+
+	getDesired := func() (batchv1.Job, batchv1beta1.CronJob) {
+		pods, err := makeDesiredPods(conn, nil)
+		assert.NoError(t, err)
+
+		var job batchv1.Job
+		var cronJob batchv1beta1.CronJob
+		for _, pod := range pods {
+			switch pod.dbc.Hostname {
+			case "job":
+				job = makeJob(pod.dbc, pod.spec)
+			case "cronjob":
+				cronJob = makeCronJob(pod.dbc, pod.spec)
+			}
+		}
+		return job, cronJob
+	}
+	job, cronJob := getDesired()
+
+	// Test creating jobs. Containers that aren't jobs should be ignored.
+	jobsClient.On("List", mock.Anything).Return(&batchv1.JobList{}, nil).Once()
+	cronJobsClient.On("List", mock.Anything).Return(
+		&batchv1beta1.CronJobList{}, nil).Once()
+	jobsClient.On("Create", &job).Return(nil, nil).Once()
+	cronJobsClient.On("Create", &cronJob).Return(nil, nil).Once()
+	updateJobs(conn, jobsClient, cronJobsClient, nil)
+	jobsClient.AssertExpectations(t)
+	cronJobsClient.AssertExpectations(t)
+
+	// Jobs that haven't changed should be left alone. The jobs started by cron
+	// jobs should be ignored.
+	cronRun := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cronjob-1234",
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "CronJob", Name: "cronjob"},
+			},
+		},
+	}
+	jobsClient.On("List", mock.Anything).Return(&batchv1.JobList{
+		Items: []batchv1.Job{job, cronRun},
+	}, nil).Once()
+	cronJobsClient.On("List", mock.Anything).Return(
+		&batchv1beta1.CronJobList{
+			Items: []batchv1beta1.CronJob{cronJob},
+		}, nil).Once()
+	updateJobs(conn, jobsClient, cronJobsClient, nil)
+	jobsClient.AssertExpectations(t)
+	cronJobsClient.AssertExpectations(t)
+
+	// When the jobs change, the job should be deleted so that it's recreated,
+	// and the cron job should be updated.
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		for _, dbc := range view.SelectFromContainer(nil) {
+			dbc.Command = []string{"new", "command"}
+			view.Commit(dbc)
+		}
+		return nil
+	})
+	_, changedCronJob := getDesired()
+	jobsClient.On("List", mock.Anything).Return(&batchv1.JobList{
+		Items: []batchv1.Job{job},
+	}, nil).Once()
+	cronJobsClient.On("List", mock.Anything).Return(
+		&batchv1beta1.CronJobList{
+			Items: []batchv1beta1.CronJob{cronJob},
+		}, nil).Once()
+	jobsClient.On("Delete", job.Name, &metav1.DeleteOptions{
+		PropagationPolicy: &foregroundDeletion,
+	}).Return(nil).Once()
+	cronJobsClient.On("Update", &changedCronJob).Return(nil, nil).Once()
+	updateJobs(conn, jobsClient, cronJobsClient, nil)
+	jobsClient.AssertExpectations(t)
+	cronJobsClient.AssertExpectations(t)
+
+	// Jobs that are already being deleted shouldn't be deleted again.
+	deletingJob := job
+	deletingJob.DeletionTimestamp = &metav1.Time{}
+	jobsClient.On("List", mock.Anything).Return(&batchv1.JobList{
+		Items: []batchv1.Job{deletingJob},
+	}, nil).Once()
+	cronJobsClient.On("List", mock.Anything).Return(
+		&batchv1beta1.CronJobList{
+			Items: []batchv1beta1.CronJob{changedCronJob},
+		}, nil).Once()
+	updateJobs(conn, jobsClient, cronJobsClient, nil)
+	jobsClient.AssertExpectations(t)
+	cronJobsClient.AssertExpectations(t)
+
+	// When the containers are removed, their jobs should be removed.
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		for _, dbc := range view.SelectFromContainer(nil) {
+			view.Remove(dbc)
+		}
+		return nil
+	})
+	jobsClient.On("List", mock.Anything).Return(&batchv1.JobList{
+		Items: []batchv1.Job{job},
+	}, nil).Once()
+	cronJobsClient.On("List", mock.Anything).Return(
+		&batchv1beta1.CronJobList{
+			Items: []batchv1beta1.CronJob{changedCronJob},
+		}, nil).Once()
+	jobsClient.On("Delete", job.Name, mock.Anything).Return(nil).Once()
+	cronJobsClient.On("Delete", cronJob.Name, mock.Anything).Return(nil).Once()
+	updateJobs(conn, jobsClient, cronJobsClient, nil)
+	jobsClient.AssertExpectations(t)
+	cronJobsClient.AssertExpectations(t)
+}
+
+func TestMakeJob(t *testing.T) {
+	t.Parallel()
+
+	backoffLimit := 3
+	dbc := db.Container{
+		Hostname: "hostname",
+		Image:    "image",
+		IP:       "ip",
+		Job: &blueprint.Job{
+			Completions:           2,
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: 60,
+		},
+	}
+	pod, ok := makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+	assert.True(t, ok)
+
+	job := makeJob(dbc, pod)
+	assert.Equal(t, "hostname", job.Name)
+	assert.Equal(t, hashSpec(job.Spec), job.Annotations[jobHashKey])
+	assert.Equal(t, int32(1), *job.Spec.Parallelism)
+	assert.Equal(t, int32(2), *job.Spec.Completions)
+	assert.Equal(t, int32(3), *job.Spec.BackoffLimit)
+	assert.Equal(t, int64(60), *job.Spec.ActiveDeadlineSeconds)
+	assert.Equal(t, corev1.RestartPolicyOnFailure,
+		job.Spec.Template.Spec.RestartPolicy)
+	assert.Equal(t, "hostname", job.Spec.Template.Labels[hostnameKey])
+	assert.Equal(t, "ip", job.Spec.Template.Annotations[keldaIPKey])
+
+	// Unset options should be left to Kubernetes' defaults.
+	dbc.Job = &blueprint.Job{Schedule: "*/5 * * * *"}
+	cronJob := makeCronJob(dbc, pod)
+	assert.Equal(t, "*/5 * * * *", cronJob.Spec.Schedule)
+	assert.Equal(t, batchv1beta1.ForbidConcurrent,
+		cronJob.Spec.ConcurrencyPolicy)
+	assert.Equal(t, hashSpec(cronJob.Spec),
+		cronJob.Annotations[jobHashKey])
+
+	jobSpec := cronJob.Spec.JobTemplate.Spec
+	assert.Nil(t, jobSpec.Completions)
+	assert.Nil(t, jobSpec.BackoffLimit)
+	assert.Nil(t, jobSpec.ActiveDeadlineSeconds)
+	assert.Equal(t, corev1.RestartPolicyOnFailure,
+		jobSpec.Template.Spec.RestartPolicy)
+}
@@ -21,13 +21,14 @@ import (
 
 var c = counter.New("Kubernetes")
 
-// Run converts the containers specified by the user into deployments in the
-// Kubernetes cluster. It also syncs the status of the deployment into the
-// database.
+// Run converts the containers specified by the user into deployments, jobs and
+// cron jobs in the Kubernetes cluster. It also syncs the status of the
+// deployment into the database.
 // The module is implemented as several goroutines. One goroutine creates the
-// ConfigMap and deployment objects for Kubernetes to deploy. Another goroutine
-// tags the Kubernetes workers with metadata to be used by placement rules. The
-// final goroutine syncs the status of the deployment into the database.
+// ConfigMap, deployment and job objects for Kubernetes to deploy. Another
+// goroutine tags the Kubernetes workers with metadata to be used by placement
+// rules, and another polls the workers for their resource usage. The final
+// goroutine syncs the status of the deployment into the database.
 func Run(conn db.Conn, dk docker.Client) {
 	var clientset *kubernetes.Clientset
 	var err error
@@ -66,6 +67,11 @@ func Run(conn db.Conn, dk docker.Client) {
 
 	configMapsClient := clientset.CoreV1().ConfigMaps(corev1.NamespaceDefault)
 	deploymentsClient := clientset.AppsV1().Deployments(corev1.NamespaceDefault)
+	jobsClient := clientset.BatchV1().Jobs(corev1.NamespaceDefault)
+	cronJobsClient := clientset.BatchV1beta1().CronJobs(corev1.NamespaceDefault)
+	storageClassesClient := clientset.StorageV1().StorageClasses()
+	claimsClient := clientset.CoreV1().PersistentVolumeClaims(
+		corev1.NamespaceDefault)
 	nodesClient := clientset.CoreV1().Nodes()
 	podsClient := clientset.CoreV1().Pods(corev1.NamespaceDefault)
 	secretClient := secretClientImpl{
@@ -75,11 +81,15 @@ func Run(conn db.Conn, dk docker.Client) {
 			conn.TriggerTick(60, db.ContainerTable, db.PlacementTable,
 				db.EtcdTable, db.ImageTable).C)
 		for range trig {
-			// Update config maps before updating deployments. This way, any
-			// config maps referenced in updateDeployments will most likely
-			// exist.
+			labelSecrets(conn, secretClient)
+
+			// Update config maps and volumes before updating deployments.
+			// This way, any config maps and volume claims referenced in
+			// updateDeployments will most likely exist.
+			updateVolumes(conn, storageClassesClient, claimsClient)
 			if updateConfigMaps(conn, configMapsClient) {
 				updateDeployments(conn, deploymentsClient, secretClient)
+				updateJobs(conn, jobsClient, cronJobsClient, secretClient)
 			}
 		}
 	}()
@@ -90,6 +100,13 @@ func Run(conn db.Conn, dk docker.Client) {
 		}
 	}()
 
+	go func() {
+		getSummary := newSummaryGetter(clientset.CoreV1().RESTClient())
+		for range time.Tick(statsInterval) {
+			updateStats(conn, nodesClient, getSummary)
+		}
+	}()
+
 	trig := util.JoinNotifiers(toStructChan(podWatcher.ResultChan()),
 		toStructChan(secretWatcher.ResultChan()),
 		conn.TriggerTick(60, db.ImageTable, db.ContainerTable).C)
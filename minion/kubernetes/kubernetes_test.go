@@ -3,6 +3,10 @@
 //go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/core/v1 -name NodeInterface
 //go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/core/v1 -name PodInterface
 //go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/apps/v1 -name DeploymentInterface
+//go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/core/v1 -name PersistentVolumeClaimInterface
+//go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/batch/v1 -name JobInterface
+//go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/batch/v1beta1 -name CronJobInterface
+//go:generate mockery -dir ../../vendor/k8s.io/client-go/kubernetes/typed/storage/v1 -name StorageClassInterface
 //go:generate mockery -name=SecretClient
 package kubernetes
 
@@ -2,12 +2,27 @@
 package mocks
 
 import mock "github.com/stretchr/testify/mock"
+import pb "github.com/kelda/kelda/api/pb"
 
 // SecretClient is an autogenerated mock type for the SecretClient type
 type SecretClient struct {
 	mock.Mock
 }
 
+// Delete provides a mock function with given fields: name
+func (_m *SecretClient) Delete(name string) error {
+	ret := _m.Called(name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Exists provides a mock function with given fields: name
 func (_m *SecretClient) Exists(name string) bool {
 	ret := _m.Called(name)
@@ -43,6 +58,43 @@ func (_m *SecretClient) Get(name string) (string, error) {
 	return r0, r1
 }
 
+// List provides a mock function with given fields:
+func (_m *SecretClient) List() ([]pb.SecretInfo, error) {
+	ret := _m.Called()
+
+	var r0 []pb.SecretInfo
+	if rf, ok := ret.Get(0).(func() []pb.SecretInfo); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]pb.SecretInfo)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Rollback provides a mock function with given fields: name, version
+func (_m *SecretClient) Rollback(name string, version int64) error {
+	ret := _m.Called(name, version)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, int64) error); ok {
+		r0 = rf(name, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Set provides a mock function with given fields: name, val
 func (_m *SecretClient) Set(name string, val string) error {
 	ret := _m.Called(name, val)
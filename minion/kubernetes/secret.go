@@ -2,9 +2,19 @@ package kubernetes
 
 import (
 	"crypto/sha1"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/db"
+
+	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	coreclient "k8s.io/client-go/kubernetes/typed/core/v1"
 )
@@ -18,10 +28,34 @@ type SecretClient interface {
 	// Get returns the secret value associated with the given name.
 	Get(name string) (string, error)
 
-	// Set associates the given name with the secret value.
+	// Set associates the given name with the secret value. The previous value,
+	// if any, is kept so that it can be restored by Rollback.
 	Set(name, val string) error
+
+	// List returns metadata about all secrets that have been set. It never
+	// returns secret values.
+	List() ([]pb.SecretInfo, error)
+
+	// Delete removes the secret and all of its prior versions.
+	Delete(name string) error
+
+	// Rollback restores the value that the secret had at the given version.
+	// If version is zero, the most recent prior version is restored.
+	// Rolling back creates a new version, so it can itself be rolled back.
+	Rollback(name string, version int64) error
 }
 
+// The number of prior values kept for each secret.
+const maxPriorVersions = 5
+
+const (
+	secretLabelKey            = "kelda.io/secret"
+	secretNameAnnotation      = "kelda.io/secret.name"
+	secretVersionAnnotation   = "kelda.io/secret.version"
+	secretUpdatedAnnotation   = "kelda.io/secret.updated"
+	secretPriorVersionKeyBase = "version-"
+)
+
 type secretClientImpl struct {
 	client coreclient.SecretInterface
 }
@@ -59,17 +93,54 @@ func (sc secretClientImpl) Get(name string) (string, error) {
 
 func (sc secretClientImpl) Set(name, val string) error {
 	kubeName, key := secretRef(name)
+	current, err := sc.client.Get(kubeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		current = nil
+	} else if err != nil {
+		return fmt.Errorf("query secret: %s", err)
+	}
+
 	desiredSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: kubeName,
+			Labels: map[string]string{
+				secretLabelKey: "true",
+			},
+			Annotations: map[string]string{
+				secretNameAnnotation: name,
+			},
 		},
 		Data: map[string][]byte{
 			key: []byte(val),
 		},
 	}
 
-	var err error
-	if sc.Exists(name) {
+	version := int64(1)
+	if current != nil {
+		info := parseSecretInfo(*current)
+		version = info.Version + 1
+
+		// Carry forward the most recent prior versions, and save the value
+		// that's being replaced as a new prior version.
+		if oldVal, ok := current.Data[key]; ok {
+			desiredSecret.Data[priorVersionKey(info.Version)] = oldVal
+		}
+		priors := info.PriorVersions
+		if len(priors) > maxPriorVersions-1 {
+			priors = priors[len(priors)-(maxPriorVersions-1):]
+		}
+		for _, v := range priors {
+			desiredSecret.Data[priorVersionKey(v)] =
+				current.Data[priorVersionKey(v)]
+		}
+	}
+
+	desiredSecret.Annotations[secretVersionAnnotation] =
+		strconv.FormatInt(version, 10)
+	desiredSecret.Annotations[secretUpdatedAnnotation] =
+		now().UTC().Format(time.RFC3339)
+
+	if current != nil {
 		_, err = sc.client.Update(&desiredSecret)
 	} else {
 		_, err = sc.client.Create(&desiredSecret)
@@ -77,9 +148,153 @@ func (sc secretClientImpl) Set(name, val string) error {
 	return err
 }
 
+func (sc secretClientImpl) List() ([]pb.SecretInfo, error) {
+	secrets, err := sc.client.List(metav1.ListOptions{
+		LabelSelector: secretLabelKey + "=true",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list secrets: %s", err)
+	}
+
+	var infos []pb.SecretInfo
+	for _, secret := range secrets.Items {
+		infos = append(infos, parseSecretInfo(secret))
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
+
+func (sc secretClientImpl) Delete(name string) error {
+	kubeName, _ := secretRef(name)
+	if err := sc.client.Delete(kubeName, &metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("delete secret: %s", err)
+	}
+	return nil
+}
+
+func (sc secretClientImpl) Rollback(name string, version int64) error {
+	kubeName, _ := secretRef(name)
+	secret, err := sc.client.Get(kubeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("query secret: %s", err)
+	}
+
+	info := parseSecretInfo(*secret)
+	if len(info.PriorVersions) == 0 {
+		return errors.New("no prior versions to roll back to")
+	}
+
+	if version == 0 {
+		version = info.PriorVersions[len(info.PriorVersions)-1]
+	}
+
+	val, ok := secret.Data[priorVersionKey(version)]
+	if !ok {
+		return fmt.Errorf("unknown version %d (available versions: %s)",
+			version, joinInts(info.PriorVersions))
+	}
+	return sc.Set(name, string(val))
+}
+
+// labelSecrets labels and names the secrets that were set before Kelda labelled
+// secrets, so that `kelda secret list` shows them. Kubernetes secret names are
+// hashes, so only the secrets referenced by containers can be named. The rest
+// are labelled when they're next set.
+func labelSecrets(conn db.Conn, sc secretClientImpl) {
+	names := map[string]struct{}{}
+	for _, dbc := range conn.SelectFromContainer(nil) {
+		for _, name := range dbc.GetReferencedSecrets() {
+			names[name] = struct{}{}
+		}
+	}
+
+	for name := range names {
+		kubeName, _ := secretRef(name)
+		secret, err := sc.client.Get(kubeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			log.WithError(err).WithField("secret", name).Error(
+				"Failed to query secret")
+			continue
+		}
+
+		if secret.Labels[secretLabelKey] == "true" {
+			continue
+		}
+
+		if secret.Labels == nil {
+			secret.Labels = map[string]string{}
+		}
+		if secret.Annotations == nil {
+			secret.Annotations = map[string]string{}
+		}
+		secret.Labels[secretLabelKey] = "true"
+		secret.Annotations[secretNameAnnotation] = name
+		if _, err := sc.client.Update(secret); err != nil {
+			log.WithError(err).WithField("secret", name).Error(
+				"Failed to label secret")
+		}
+	}
+}
+
+// parseSecretInfo extracts the metadata stored in the annotations and keys of a
+// Kubernetes secret. Secrets that were set before versions were tracked are
+// version 1.
+func parseSecretInfo(secret corev1.Secret) pb.SecretInfo {
+	info := pb.SecretInfo{
+		Name:    secret.Annotations[secretNameAnnotation],
+		Version: 1,
+	}
+
+	version, err := strconv.ParseInt(
+		secret.Annotations[secretVersionAnnotation], 10, 64)
+	if err == nil {
+		info.Version = version
+	}
+
+	updated, err := time.Parse(time.RFC3339,
+		secret.Annotations[secretUpdatedAnnotation])
+	if err == nil {
+		info.Updated = updated.Unix()
+	}
+
+	for key := range secret.Data {
+		if !strings.HasPrefix(key, secretPriorVersionKeyBase) {
+			continue
+		}
+		v, err := strconv.ParseInt(
+			strings.TrimPrefix(key, secretPriorVersionKeyBase), 10, 64)
+		if err == nil {
+			info.PriorVersions = append(info.PriorVersions, v)
+		}
+	}
+	sort.Slice(info.PriorVersions, func(i, j int) bool {
+		return info.PriorVersions[i] < info.PriorVersions[j]
+	})
+	return info
+}
+
+func priorVersionKey(version int64) string {
+	return secretPriorVersionKeyBase + strconv.FormatInt(version, 10)
+}
+
+func joinInts(ints []int64) string {
+	var strs []string
+	for _, i := range ints {
+		strs = append(strs, strconv.FormatInt(i, 10))
+	}
+	return strings.Join(strs, ", ")
+}
+
 // Each secret name maps to a unique Kubernetes secret. Because a Kubernetes
-// secret is a map of values rather than a single value, we only use a single
-// key in the map.
+// secret is a map of values rather than a single value, we use a single key
+// for the current value, and keep prior values under versioned keys.
 func secretRef(name string) (kubeSecretName, key string) {
 	return "kelda-" + fmt.Sprintf("%x", sha1.Sum([]byte(name))), "value"
 }
+
+// Saved in a variable so that unit tests can control the time.
+var now = time.Now
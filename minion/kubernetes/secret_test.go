@@ -3,17 +3,24 @@ package kubernetes
 import (
 	"errors"
 	"testing"
+	"time"
 
+	"github.com/kelda/kelda/api/pb"
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion/kubernetes/mocks"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 func TestSecretSet(t *testing.T) {
-	t.Parallel()
+	updated := time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC)
+	now = func() time.Time { return updated }
 
 	kubeClient := &mocks.SecretInterface{}
 	secretClient := secretClientImpl{kubeClient}
@@ -24,6 +31,14 @@ func TestSecretSet(t *testing.T) {
 	kubeSecret := corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: kubeSecretName,
+			Labels: map[string]string{
+				"kelda.io/secret": "true",
+			},
+			Annotations: map[string]string{
+				"kelda.io/secret.name":    secretName,
+				"kelda.io/secret.version": "1",
+				"kelda.io/secret.updated": "2018-01-02T03:04:05Z",
+			},
 		},
 		Data: map[string][]byte{
 			"value": []byte(secretVal),
@@ -32,23 +47,54 @@ func TestSecretSet(t *testing.T) {
 
 	// Test creating a new secret.
 	kubeClient.On("Get", kubeSecretName, mock.Anything).
-		Return(nil, errors.New("does not exist")).Once()
+		Return(nil, notFound(kubeSecretName)).Once()
 	kubeClient.On("Create", &kubeSecret).Return(nil, nil).Once()
 	err := secretClient.Set(secretName, secretVal)
 	assert.NoError(t, err)
 	kubeClient.AssertExpectations(t)
 
-	// Test updating a secret that's already been created.
-	secretVal = "changed"
+	// Other errors when querying the secret shouldn't be mistaken for the
+	// secret not existing, which would lose its prior versions.
+	kubeClient.On("Get", kubeSecretName, mock.Anything).
+		Return(nil, errors.New("timeout")).Once()
+	err = secretClient.Set(secretName, secretVal)
+	assert.EqualError(t, err, "query secret: timeout")
+	kubeClient.AssertExpectations(t)
+
+	// Test updating a secret that's already been created. The old value
+	// should be kept as a prior version.
 	changedKubeSecret := copySecret(kubeSecret)
-	changedKubeSecret.Data["value"] = []byte(secretVal)
+	changedKubeSecret.Annotations["kelda.io/secret.version"] = "2"
+	changedKubeSecret.Data["value"] = []byte("changed")
+	changedKubeSecret.Data["version-1"] = []byte(secretVal)
 
 	kubeClient.On("Get", kubeSecretName, mock.Anything).
 		Return(&kubeSecret, nil).Once()
 	kubeClient.On("Update", &changedKubeSecret).Return(nil, nil).Once()
-	err = secretClient.Set(secretName, secretVal)
+	err = secretClient.Set(secretName, "changed")
+	assert.NoError(t, err)
+	kubeClient.AssertExpectations(t)
+
+	// Test that only the most recent prior versions are kept.
+	manyVersions := copySecret(kubeSecret)
+	manyVersions.Annotations["kelda.io/secret.version"] = "7"
+	for i := 1; i < 7; i++ {
+		manyVersions.Data[priorVersionKey(int64(i))] = []byte("old")
+	}
+
+	kubeClient.On("Get", kubeSecretName, mock.Anything).
+		Return(&manyVersions, nil).Once()
+	kubeClient.On("Update", mock.Anything).Return(nil, nil).Once()
+	err = secretClient.Set(secretName, "changed")
 	assert.NoError(t, err)
 	kubeClient.AssertExpectations(t)
+
+	updatedSecret := kubeClient.Calls[len(kubeClient.Calls)-1].Arguments.
+		Get(0).(*corev1.Secret)
+	assert.Equal(t, []int64{3, 4, 5, 6, 7},
+		parseSecretInfo(*updatedSecret).PriorVersions)
+	assert.Equal(t, "8", updatedSecret.Annotations["kelda.io/secret.version"])
+	assert.Equal(t, []byte(secretVal), updatedSecret.Data["version-7"])
 }
 
 func TestSecretGet(t *testing.T) {
@@ -131,12 +177,199 @@ func TestSecretExists(t *testing.T) {
 	kubeClient.AssertExpectations(t)
 }
 
+func TestSecretList(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := &mocks.SecretInterface{}
+	secretClient := secretClientImpl{kubeClient}
+
+	kubeClient.On("List", metav1.ListOptions{
+		LabelSelector: "kelda.io/secret=true",
+	}).Return(&corev1.SecretList{
+		Items: []corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"kelda.io/secret": "true"},
+				Annotations: map[string]string{
+					"kelda.io/secret.name":    "b",
+					"kelda.io/secret.version": "3",
+					"kelda.io/secret.updated": "2018-01-02T03:04:05Z",
+				},
+			},
+			Data: map[string][]byte{
+				"value":     []byte("val"),
+				"version-2": []byte("val"),
+				"version-1": []byte("val"),
+			},
+		}, {
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{"kelda.io/secret": "true"},
+				Annotations: map[string]string{
+					"kelda.io/secret.name": "a",
+				},
+			},
+			Data: map[string][]byte{
+				"value": []byte("val"),
+			},
+		}}}, nil).Once()
+
+	secrets, err := secretClient.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []pb.SecretInfo{
+		{Name: "a", Version: 1},
+		{Name: "b", Version: 3, PriorVersions: []int64{1, 2},
+			Updated: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC).Unix()},
+	}, secrets)
+
+	kubeClient.On("List", mock.Anything).Return(nil, assert.AnError).Once()
+	_, err = secretClient.List()
+	assert.NotNil(t, err)
+}
+
+func TestLabelSecrets(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := &mocks.SecretInterface{}
+	secretClient := secretClientImpl{kubeClient}
+
+	conn := db.New()
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.Env = map[string]blueprint.ContainerValue{
+			"OLD":      blueprint.NewSecret("old"),
+			"LABELLED": blueprint.NewSecret("labelled"),
+			"UNSET":    blueprint.NewSecret("unset"),
+			"BROKEN":   blueprint.NewSecret("broken"),
+		}
+		view.Commit(dbc)
+		return nil
+	})
+
+	// Secrets set before they were labelled are labelled and named.
+	oldKubeName, _ := secretRef("old")
+	oldSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: oldKubeName},
+		Data:       map[string][]byte{"value": []byte("val")},
+	}
+	kubeClient.On("Get", oldKubeName, mock.Anything).
+		Return(&oldSecret, nil).Once()
+	kubeClient.On("Update", &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        oldKubeName,
+			Labels:      map[string]string{"kelda.io/secret": "true"},
+			Annotations: map[string]string{"kelda.io/secret.name": "old"},
+		},
+		Data: map[string][]byte{"value": []byte("val")},
+	}).Return(nil, nil).Once()
+
+	// Labelled secrets, secrets that haven't been set, and secrets that
+	// can't be queried are left alone.
+	labelledKubeName, _ := secretRef("labelled")
+	kubeClient.On("Get", labelledKubeName, mock.Anything).
+		Return(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   labelledKubeName,
+				Labels: map[string]string{"kelda.io/secret": "true"},
+			},
+		}, nil).Once()
+	unsetKubeName, _ := secretRef("unset")
+	kubeClient.On("Get", unsetKubeName, mock.Anything).
+		Return(nil, notFound(unsetKubeName)).Once()
+	brokenKubeName, _ := secretRef("broken")
+	kubeClient.On("Get", brokenKubeName, mock.Anything).
+		Return(nil, assert.AnError).Once()
+
+	labelSecrets(conn, secretClient)
+	kubeClient.AssertExpectations(t)
+}
+
+func notFound(kubeName string) error {
+	return apierrors.NewNotFound(
+		schema.GroupResource{Resource: "secrets"}, kubeName)
+}
+
+func TestSecretDelete(t *testing.T) {
+	t.Parallel()
+
+	kubeClient := &mocks.SecretInterface{}
+	secretClient := secretClientImpl{kubeClient}
+
+	kubeSecretName, _ := secretRef("secretName")
+	kubeClient.On("Delete", kubeSecretName, mock.Anything).Return(nil).Once()
+	assert.NoError(t, secretClient.Delete("secretName"))
+
+	kubeClient.On("Delete", kubeSecretName, mock.Anything).
+		Return(assert.AnError).Once()
+	assert.NotNil(t, secretClient.Delete("secretName"))
+	kubeClient.AssertExpectations(t)
+}
+
+func TestSecretRollback(t *testing.T) {
+	now = time.Now
+
+	kubeClient := &mocks.SecretInterface{}
+	secretClient := secretClientImpl{kubeClient}
+
+	secretName := "secretName"
+	kubeSecretName, _ := secretRef(secretName)
+	kubeSecret := corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: kubeSecretName,
+			Annotations: map[string]string{
+				"kelda.io/secret.name":    secretName,
+				"kelda.io/secret.version": "3",
+			},
+		},
+		Data: map[string][]byte{
+			"value":     []byte("three"),
+			"version-2": []byte("two"),
+			"version-1": []byte("one"),
+		},
+	}
+
+	rolledBack := func(expVal string) interface{} {
+		return mock.MatchedBy(func(secret *corev1.Secret) bool {
+			return string(secret.Data["value"]) == expVal &&
+				string(secret.Data["version-3"]) == "three" &&
+				secret.Annotations["kelda.io/secret.version"] == "4"
+		})
+	}
+
+	// Rolling back without a version restores the most recent prior value.
+	kubeClient.On("Get", kubeSecretName, mock.Anything).
+		Return(&kubeSecret, nil).Twice()
+	kubeClient.On("Update", rolledBack("two")).Return(nil, nil).Once()
+	assert.NoError(t, secretClient.Rollback(secretName, 0))
+	kubeClient.AssertExpectations(t)
+
+	kubeClient.On("Get", kubeSecretName, mock.Anything).
+		Return(&kubeSecret, nil).Twice()
+	kubeClient.On("Update", rolledBack("one")).Return(nil, nil).Once()
+	assert.NoError(t, secretClient.Rollback(secretName, 1))
+	kubeClient.AssertExpectations(t)
+
+	// Unknown versions.
+	kubeClient.On("Get", kubeSecretName, mock.Anything).
+		Return(&kubeSecret, nil).Once()
+	err := secretClient.Rollback(secretName, 5)
+	assert.EqualError(t, err, "unknown version 5 (available versions: 1, 2)")
+
+	// Secrets without any prior versions.
+	kubeClient.On("Get", kubeSecretName, mock.Anything).
+		Return(&corev1.Secret{}, nil).Once()
+	err = secretClient.Rollback(secretName, 0)
+	assert.EqualError(t, err, "no prior versions to roll back to")
+}
+
 func copySecret(src corev1.Secret) (copy corev1.Secret) {
 	dataCopy := map[string][]byte{}
 	for k, v := range src.Data {
 		dataCopy[k] = v
 	}
 	copy.ObjectMeta = src.ObjectMeta
+	copy.Annotations = map[string]string{}
+	for k, v := range src.Annotations {
+		copy.Annotations[k] = v
+	}
 	copy.Data = dataCopy
 	return copy
 }
@@ -0,0 +1,217 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/join"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// How often the kubelets are polled for resource usage.
+const statsInterval = 10 * time.Second
+
+// The subset of the kubelet's stats summary used by Kelda. The full definition
+// is in k8s.io/kubernetes/pkg/kubelet/apis/stats/v1alpha1, which isn't
+// vendored. Missing stats decode as zero.
+type statsSummary struct {
+	Node struct {
+		CPU     cpuStats     `json:"cpu"`
+		Memory  memoryStats  `json:"memory"`
+		Network networkStats `json:"network"`
+		Fs      fsStats      `json:"fs"`
+	} `json:"node"`
+	Pods []podStats `json:"pods"`
+}
+
+type podStats struct {
+	PodRef struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"podRef"`
+	Containers []containerStats `json:"containers"`
+	Network    networkStats     `json:"network"`
+}
+
+type containerStats struct {
+	Name   string      `json:"name"`
+	CPU    cpuStats    `json:"cpu"`
+	Memory memoryStats `json:"memory"`
+	Rootfs fsStats     `json:"rootfs"`
+}
+
+type cpuStats struct {
+	UsageNanoCores uint64 `json:"usageNanoCores"`
+}
+
+type memoryStats struct {
+	AvailableBytes  uint64 `json:"availableBytes"`
+	WorkingSetBytes uint64 `json:"workingSetBytes"`
+}
+
+type networkStats struct {
+	RxBytes uint64 `json:"rxBytes"`
+	TxBytes uint64 `json:"txBytes"`
+}
+
+type fsStats struct {
+	CapacityBytes uint64 `json:"capacityBytes"`
+	UsedBytes     uint64 `json:"usedBytes"`
+}
+
+// summaryGetter fetches the stats summary of the named node.
+type summaryGetter func(node string) (statsSummary, error)
+
+// newSummaryGetter returns a summaryGetter that fetches summaries through the
+// API server's proxy to each kubelet, so that the kubelets don't need to expose
+// their stats to the rest of the network.
+func newSummaryGetter(restClient rest.Interface) summaryGetter {
+	return func(node string) (statsSummary, error) {
+		var summary statsSummary
+		body, err := restClient.Get().Resource("nodes").Name(node).
+			SubResource("proxy").Suffix("stats/summary").DoRaw()
+		if err != nil {
+			return summary, err
+		}
+		return summary, json.Unmarshal(body, &summary)
+	}
+}
+
+// updateStats polls the kubelet on each node for the resources used by the node
+// and the Kelda containers running on it, and writes them to the stats table.
+// The stats of nodes that can't be polled are removed rather than left stale.
+func updateStats(conn db.Conn, nodesClient clientv1.NodeInterface,
+	getSummary summaryGetter) {
+
+	nodes, err := nodesClient.List(metav1.ListOptions{})
+	if err != nil {
+		log.WithError(err).Error("Failed to list current nodes")
+		return
+	}
+
+	collected := time.Now()
+	ipToSummary := map[string]statsSummary{}
+	for _, node := range nodes.Items {
+		privateIP, err := getPrivateIP(node)
+		if err != nil {
+			log.WithError(err).WithField("node", node.Name).Error(
+				"Failed to get private IP")
+			continue
+		}
+
+		summary, err := getSummary(node.Name)
+		if err != nil {
+			log.WithError(err).WithField("node", node.Name).Warn(
+				"Failed to get resource usage")
+			continue
+		}
+		ipToSummary[privateIP] = summary
+	}
+
+	conn.Txn(db.ContainerTable, db.StatsTable).Run(func(view db.Database) error {
+		podToHostname := map[string]string{}
+		for _, dbc := range view.SelectFromContainer(nil) {
+			if dbc.PodName != "" {
+				podToHostname[dbc.PodName] = dbc.Hostname
+			}
+		}
+
+		var target []db.Stats
+		for ip, summary := range ipToSummary {
+			target = append(target,
+				summaryToStats(ip, summary, podToHostname)...)
+		}
+
+		key := func(intf interface{}) interface{} {
+			stats := intf.(db.Stats)
+			return struct{ minion, hostname string }{
+				stats.Minion, stats.Hostname}
+		}
+		pairs, toAdd, toRemove := join.HashJoin(db.StatsSlice(target),
+			db.StatsSlice(view.SelectFromStats(nil)), key, key)
+
+		for _, intf := range toRemove {
+			view.Remove(intf.(db.Stats))
+		}
+
+		for _, intf := range toAdd {
+			pairs = append(pairs, join.Pair{L: intf, R: view.InsertStats()})
+		}
+
+		for _, pair := range pairs {
+			stats := pair.L.(db.Stats)
+			prev := pair.R.(db.Stats)
+			stats.ID = prev.ID
+			stats.Collected = collected
+			if !prev.Collected.IsZero() {
+				elapsed := collected.Sub(prev.Collected)
+				stats.NetworkRxBytesPerSec = rate(prev.NetworkRxBytes,
+					stats.NetworkRxBytes, elapsed)
+				stats.NetworkTxBytesPerSec = rate(prev.NetworkTxBytes,
+					stats.NetworkTxBytes, elapsed)
+			}
+			view.Commit(stats)
+		}
+		return nil
+	})
+}
+
+// summaryToStats converts the summary of the node with the given IP into stats
+// for the node, and for each Kelda container on it. Containers are identified
+// by the name of their pod, and their usage includes their sidecars'.
+func summaryToStats(ip string, summary statsSummary,
+	podToHostname map[string]string) []db.Stats {
+
+	node := summary.Node
+	stats := []db.Stats{{
+		Minion:               ip,
+		CPUMillicores:        millicores(node.CPU),
+		MemoryBytes:          node.Memory.WorkingSetBytes,
+		MemoryAvailableBytes: node.Memory.AvailableBytes,
+		NetworkRxBytes:       node.Network.RxBytes,
+		NetworkTxBytes:       node.Network.TxBytes,
+		DiskBytes:            node.Fs.UsedBytes,
+		DiskCapacityBytes:    node.Fs.CapacityBytes,
+	}}
+
+	for _, pod := range summary.Pods {
+		hostname, ok := podToHostname[pod.PodRef.Name]
+		if !ok || pod.PodRef.Namespace != corev1.NamespaceDefault {
+			continue
+		}
+
+		podStats := db.Stats{
+			Hostname:       hostname,
+			Minion:         ip,
+			NetworkRxBytes: pod.Network.RxBytes,
+			NetworkTxBytes: pod.Network.TxBytes,
+		}
+		for _, container := range pod.Containers {
+			podStats.CPUMillicores += millicores(container.CPU)
+			podStats.MemoryBytes += container.Memory.WorkingSetBytes
+			podStats.DiskBytes += container.Rootfs.UsedBytes
+		}
+		stats = append(stats, podStats)
+	}
+	return stats
+}
+
+func millicores(cpu cpuStats) int {
+	return int(cpu.UsageNanoCores / 1000000)
+}
+
+// rate returns the bytes per second transferred between two samples of a
+// counter. Counters reset when their container restarts, in which case the rate
+// is unknown and reported as zero.
+func rate(prev, curr uint64, elapsed time.Duration) uint64 {
+	if curr < prev || elapsed <= 0 {
+		return 0
+	}
+	return uint64(float64(curr-prev) / elapsed.Seconds())
+}
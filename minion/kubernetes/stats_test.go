@@ -0,0 +1,175 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/kubernetes/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const summaryJSON = `{
+  "node": {
+    "nodeName": "10.0.0.1",
+    "cpu": {"usageNanoCores": 1500000000},
+    "memory": {"availableBytes": 3000, "workingSetBytes": 1000},
+    "network": {"name": "eth0", "rxBytes": 100, "txBytes": 200},
+    "fs": {"capacityBytes": 50000, "usedBytes": 20000}
+  },
+  "pods": [
+    {
+      "podRef": {"name": "web-pod", "namespace": "default"},
+      "containers": [
+        {
+          "name": "web",
+          "cpu": {"usageNanoCores": 250000000},
+          "memory": {"workingSetBytes": 400},
+          "rootfs": {"usedBytes": 10}
+        },
+        {
+          "name": "sidecar",
+          "cpu": {"usageNanoCores": 50000000},
+          "memory": {"workingSetBytes": 100},
+          "rootfs": {"usedBytes": 5}
+        }
+      ],
+      "network": {"name": "eth0", "rxBytes": 1000, "txBytes": 2000}
+    },
+    {
+      "podRef": {"name": "kube-dns", "namespace": "kube-system"},
+      "containers": [{"name": "dns"}]
+    }
+  ]
+}`
+
+func TestSummaryToStats(t *testing.T) {
+	t.Parallel()
+
+	var summary statsSummary
+	assert.NoError(t, json.Unmarshal([]byte(summaryJSON), &summary))
+
+	// Pods that don't belong to Kelda containers should be ignored, even if
+	// they have the same name as a Kelda pod.
+	summary.Pods = append(summary.Pods, podStats{})
+	summary.Pods[2].PodRef.Name = "web-pod"
+	summary.Pods[2].PodRef.Namespace = "kube-system"
+
+	stats := summaryToStats("10.0.0.1", summary, map[string]string{
+		"web-pod":  "web",
+		"kube-dns": "dns",
+	})
+	assert.Equal(t, []db.Stats{
+		{
+			Minion:               "10.0.0.1",
+			CPUMillicores:        1500,
+			MemoryBytes:          1000,
+			MemoryAvailableBytes: 3000,
+			NetworkRxBytes:       100,
+			NetworkTxBytes:       200,
+			DiskBytes:            20000,
+			DiskCapacityBytes:    50000,
+		},
+		{
+			Hostname:       "web",
+			Minion:         "10.0.0.1",
+			CPUMillicores:  300,
+			MemoryBytes:    500,
+			NetworkRxBytes: 1000,
+			NetworkTxBytes: 2000,
+			DiskBytes:      15,
+		},
+	}, stats)
+}
+
+func TestUpdateStats(t *testing.T) {
+	t.Parallel()
+
+	conn := db.New()
+	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+		dbc := view.InsertContainer()
+		dbc.Hostname = "web"
+		dbc.PodName = "web-pod"
+		view.Commit(dbc)
+		return nil
+	})
+
+	nodesClient := &mocks.NodeInterface{}
+	nodesClient.On("List", mock.Anything).Return(nil, assert.AnError).Once()
+	updateStats(conn, nodesClient, nil)
+	assert.Empty(t, conn.SelectFromStats(nil))
+
+	nodes := &corev1.NodeList{Items: []corev1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "10.0.0.1"},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{{
+					Type:    corev1.NodeInternalIP,
+					Address: "10.0.0.1",
+				}},
+			},
+		},
+	}}
+	nodesClient.On("List", mock.Anything).Return(nodes, nil)
+
+	var summary statsSummary
+	assert.NoError(t, json.Unmarshal([]byte(summaryJSON), &summary))
+	getSummary := func(node string) (statsSummary, error) {
+		assert.Equal(t, "10.0.0.1", node)
+		return summary, nil
+	}
+
+	getStats := func() []db.Stats {
+		stats := conn.SelectFromStats(nil)
+		sort.Slice(stats, func(i, j int) bool {
+			return stats[i].Hostname < stats[j].Hostname
+		})
+		return stats
+	}
+
+	// The first sample has nothing to compute rates against.
+	updateStats(conn, nodesClient, getSummary)
+	stats := getStats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, "", stats[0].Hostname)
+	assert.Equal(t, "web", stats[1].Hostname)
+	assert.Zero(t, stats[1].NetworkRxBytesPerSec)
+	assert.False(t, stats[1].Collected.IsZero())
+
+	// Rewind the previous sample so that the rates are predictable.
+	conn.Txn(db.StatsTable).Run(func(view db.Database) error {
+		for _, s := range view.SelectFromStats(nil) {
+			s.Collected = s.Collected.Add(-10 * time.Second)
+			view.Commit(s)
+		}
+		return nil
+	})
+
+	summary.Pods[0].Network.RxBytes += 10000
+	updateStats(conn, nodesClient, getSummary)
+	updated := getStats()
+	assert.Len(t, updated, 2)
+	assert.Equal(t, stats[1].ID, updated[1].ID)
+	assert.InDelta(t, 1000, updated[1].NetworkRxBytesPerSec, 1)
+	assert.Zero(t, updated[1].NetworkTxBytesPerSec)
+
+	// Stats for nodes that can't be polled should be removed.
+	updateStats(conn, nodesClient, func(string) (statsSummary, error) {
+		return statsSummary{}, assert.AnError
+	})
+	assert.Empty(t, conn.SelectFromStats(nil))
+}
+
+func TestRate(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, uint64(50), rate(100, 600, 10*time.Second))
+	assert.Equal(t, uint64(0), rate(600, 100, 10*time.Second))
+	assert.Equal(t, uint64(0), rate(100, 600, 0))
+}
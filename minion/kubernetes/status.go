@@ -3,8 +3,10 @@ package kubernetes
 import (
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
+	"github.com/kelda/kelda/counter"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/join"
 
@@ -14,6 +16,8 @@ import (
 	clientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
 )
 
+var containerStatusC = counter.New("Container Status")
+
 var (
 	joinContainersToPods = joinContainersToPodsImpl
 	statusForPod         = statusForPodImpl
@@ -33,14 +37,34 @@ func updateContainerStatuses(conn db.Conn, podsClient clientv1.PodInterface,
 		return
 	}
 
+	desiredPods, err := makeDesiredPods(conn, secretClient)
+	if err != nil && err != errNoBlueprint {
+		log.WithError(err).Error("Failed to make desired pods")
+		return
+	}
+
+	// Containers are matched with pods that have the spec Kelda would
+	// currently generate for them. Containers whose pods can't be created yet
+	// don't have a spec hash, so if they need one, they don't match any pod.
+	specHashes := map[int]string{}
+	for _, pod := range desiredPods {
+		template := makePodTemplate(pod.dbc, pod.spec)
+		if pod.dbc.Job != nil {
+			template = makeJobSpec(pod.dbc, pod.spec).Template
+		}
+		specHashes[pod.dbc.ID] = template.Annotations[podSpecHashKey]
+	}
+
 	conn.Txn(db.ImageTable, db.ContainerTable).Run(func(view db.Database) error {
 		pairs, noInfoContainers := joinContainersToPods(
-			view.SelectFromContainer(nil), pods.Items)
+			view.SelectFromContainer(nil), specHashes, pods.Items)
 		for _, pair := range pairs {
 			dbc := pair.L.(db.Container)
 			pod := pair.R.(corev1.Pod)
 
 			dbc.Status, dbc.Created = statusForPod(pod)
+			dbc.Ready = podReady(pod)
+			setExitStatus(&dbc, pod)
 			dbc.PodName = pod.GetName()
 			dbc.Minion = pod.Status.HostIP
 			view.Commit(dbc)
@@ -57,33 +81,62 @@ func updateContainerStatuses(conn db.Conn, podsClient clientv1.PodInterface,
 		for _, intf := range noInfoContainers {
 			dbc := intf.(db.Container)
 			dbc.Status = statusForContainer(imageMap, secretClient, dbc)
-			// Unset the Created field in case it was set when the container
-			// was running in the past.
+			dbc.Ready = false
+			// Unset the Created field and exit status in case they were set
+			// when the container was running in the past.
 			dbc.Created = time.Time{}
+			setExitStatus(&dbc, corev1.Pod{})
 			view.Commit(dbc)
 		}
+
+		updateContainerMetrics(view.SelectFromContainer(nil))
 		return nil
 	})
 }
 
+// updateContainerMetrics sets a gauge for each kind of container status to the
+// number of containers with that status. Statuses are grouped by the text before
+// the first colon so that, for example, all waiting containers are counted
+// together regardless of the reason they're waiting.
+func updateContainerMetrics(dbcs []db.Container) {
+	counts := map[string]float64{}
+	for _, dbc := range dbcs {
+		status := strings.SplitN(dbc.Status, ":", 2)[0]
+		if status == "" {
+			status = "unknown"
+		}
+		counts[status]++
+	}
+	containerStatusC.SetAll(counts)
+}
+
 // joinContainersToPods tries to match the given containers with the given pods.
-func joinContainersToPodsImpl(dbcs []db.Container, pods []corev1.Pod) (
-	pairs []join.Pair, noInfoContainers []interface{}) {
+// `specHashes` maps the ID of each container to the hash of its desired pod
+// spec, if its pod template is annotated with one.
+func joinContainersToPodsImpl(dbcs []db.Container, specHashes map[int]string,
+	pods []corev1.Pod) (pairs []join.Pair, noInfoContainers []interface{}) {
 	type joinKey struct {
 		Hostname              string
+		IP                    string
 		Image                 string
 		Command               string
+		Entrypoint            string
+		WorkingDir            string
 		EnvHash               string
 		FilepathToContentHash string
 		DockerfileHash        string
 		Privileged            bool
+		PodSpecHash           string
 	}
 	dbcKey := func(intf interface{}) interface{} {
 		dbc := intf.(db.Container)
 		return joinKey{
-			Hostname: dbc.Hostname,
-			Image:    dbc.Image,
-			Command:  fmt.Sprintf("%v", dbc.Command),
+			Hostname:   dbc.Hostname,
+			IP:         dbc.IP,
+			Image:      dbc.Image,
+			Command:    fmt.Sprintf("%v", dbc.Command),
+			Entrypoint: fmt.Sprintf("%v", dbc.Entrypoint),
+			WorkingDir: dbc.WorkingDir,
 
 			// These fields should be calculated in the same way as the
 			// annotations fields in updateDeployments.
@@ -92,31 +145,47 @@ func joinContainersToPodsImpl(dbcs []db.Container, pods []corev1.Pod) (
 				dbc.FilepathToContent),
 			DockerfileHash: hashStr(dbc.Dockerfile),
 			Privileged:     dbc.Privileged,
+			PodSpecHash:    specHashes[dbc.ID],
 		}
 	}
 	podKey := func(intf interface{}) interface{} {
 		pod := intf.(corev1.Pod)
-		if len(pod.Spec.Containers) != 1 {
+		if len(pod.Spec.Containers) == 0 {
 			log.WithField("pod", pod.Name).Error("Pods managed by Kelda " +
-				"should have exactly one container. Ignoring.")
+				"should have at least one container. Ignoring.")
 			return nil
 		}
 
+		// The container itself is always first, followed by its sidecars.
 		var privileged bool
 		if pod.Spec.Containers[0].SecurityContext != nil {
 			privileged = *pod.Spec.Containers[0].SecurityContext.Privileged
 		}
 		return joinKey{
 			Hostname: pod.Spec.Hostname,
+			IP:       pod.Annotations[keldaIPKey],
 			Command: fmt.Sprintf("%v",
 				pod.Spec.Containers[0].Args),
+			Entrypoint: fmt.Sprintf("%v",
+				pod.Spec.Containers[0].Command),
+			WorkingDir:            pod.Spec.Containers[0].WorkingDir,
 			Image:                 pod.Annotations[imageKey],
 			EnvHash:               pod.Annotations[envHashKey],
 			FilepathToContentHash: pod.Annotations[filesHashKey],
 			DockerfileHash:        pod.Annotations[dockerfileHashKey],
 			Privileged:            privileged,
+			PodSpecHash:           pod.Annotations[podSpecHashKey],
 		}
 	}
+
+	// A container may have several pods, such as the runs of a cron job, or
+	// the old pod of a deployment that's being updated. Join against the
+	// newest one.
+	pods = append([]corev1.Pod(nil), pods...)
+	sort.SliceStable(pods, func(i, j int) bool {
+		return pods[j].CreationTimestamp.Before(&pods[i].CreationTimestamp)
+	})
+
 	pairs, noInfoContainers, _ = join.HashJoin(
 		db.ContainerSlice(dbcs), podSlice(pods), dbcKey, podKey)
 	return pairs, noInfoContainers
@@ -132,23 +201,41 @@ func statusForContainerImpl(imageMap map[db.Image]db.Image, secretClient SecretC
 		return fmt.Sprintf("Waiting for secrets: %v", missing)
 	}
 
-	// Check for image information.
-	img, ok := imageMap[db.Image{
-		Name:       dbc.Image,
-		Dockerfile: dbc.Dockerfile,
-	}]
-	if ok {
-		return img.Status
+	// Check for image information. If the container's image is built, but
+	// the image of one of its sidecars or init containers isn't, report the
+	// status of that image instead.
+	images := []db.Image{{Name: dbc.Image, Dockerfile: dbc.Dockerfile}}
+	for _, pc := range dbc.PodContainers() {
+		images = append(images, db.Image{
+			Name:       pc.Image.Name,
+			Dockerfile: pc.Image.Dockerfile,
+		})
+	}
+	for _, key := range images {
+		img, ok := imageMap[key]
+		if !ok {
+			continue
+		}
+
+		status = img.Status
+		if status != db.Built {
+			break
+		}
+	}
+
+	// Cron jobs don't have a pod in between runs. Only report the image
+	// status if the image is still being built.
+	isCronJob := dbc.Job != nil && dbc.Job.Schedule != ""
+	if isCronJob && (status == "" || status == db.Built) {
+		return "waiting for schedule"
 	}
-	return ""
+	return status
 }
 
 // statusForPod parses the status information for the given pod into a single
 // string. If the status is running, it also returns when the pod was started.
 func statusForPodImpl(pod corev1.Pod) (status string, createdTime time.Time) {
-	// Try to get the status of the actual container.
-	if len(pod.Status.ContainerStatuses) == 1 {
-		status := pod.Status.ContainerStatuses[0]
+	if status, ok := mainContainerStatus(pod); ok {
 		switch {
 		case status.State.Running != nil:
 			return "running", status.State.Running.StartedAt.Time
@@ -173,6 +260,58 @@ func statusForPodImpl(pod corev1.Pod) (status string, createdTime time.Time) {
 	return "no status information", time.Time{}
 }
 
+// setExitStatus copies the restart count, the exit code and reason of the most
+// recent termination, and the reason the container is waiting, if any, from
+// the status of the pod's main container. Completed is only set if the
+// container is currently terminated, rather than restarting after a crash.
+func setExitStatus(dbc *db.Container, pod corev1.Pod) {
+	dbc.RestartCount = 0
+	dbc.ExitCode, dbc.TerminationReason, dbc.Completed = 0, "", time.Time{}
+	dbc.WaitingReason, dbc.WaitingMessage = "", ""
+
+	status, ok := mainContainerStatus(pod)
+	if !ok {
+		return
+	}
+
+	dbc.RestartCount = int(status.RestartCount)
+	if term := status.State.Terminated; term != nil {
+		dbc.ExitCode = int(term.ExitCode)
+		dbc.TerminationReason = term.Reason
+		dbc.Completed = term.FinishedAt.Time
+	} else if term := status.LastTerminationState.Terminated; term != nil {
+		dbc.ExitCode = int(term.ExitCode)
+		dbc.TerminationReason = term.Reason
+	}
+
+	if wait := status.State.Waiting; wait != nil {
+		dbc.WaitingReason = wait.Reason
+		dbc.WaitingMessage = wait.Message
+	}
+}
+
+// mainContainerStatus returns the status of the actual container, rather than
+// its sidecars. It's named after the pod's hostname.
+func mainContainerStatus(pod corev1.Pod) (corev1.ContainerStatus, bool) {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == pod.Spec.Hostname {
+			return status, true
+		}
+	}
+	return corev1.ContainerStatus{}, false
+}
+
+// podReady returns whether the pod is passing its readiness check. Pods
+// without a readiness check are ready once they're running.
+func podReady(pod corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
 type podSlice []corev1.Pod
 
 func (slc podSlice) Get(ii int) interface{} {
@@ -1,12 +1,15 @@
 package kubernetes
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
 	"sort"
 	"testing"
 	"time"
 
 	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/counter"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/join"
 	"github.com/kelda/kelda/minion/kubernetes/mocks"
@@ -14,6 +17,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,6 +30,9 @@ func TestUpdateStatus(t *testing.T) {
 		// Set a blueprint ID so that the order is deterministic when sorting.
 		BlueprintID: "1",
 		Hostname:    "runningContainer",
+		Image:       "image",
+		IP:          "10.0.0.1",
+		Resources:   &blueprint.Resources{CPURequest: "1"},
 	}
 	runningContainerPod := corev1.Pod{
 		Spec: corev1.PodSpec{
@@ -33,6 +40,31 @@ func TestUpdateStatus(t *testing.T) {
 		},
 	}
 
+	completedJob := db.Container{
+		BlueprintID: "3",
+		Hostname:    "completedJob",
+		Image:       "image",
+		IP:          "10.0.0.3",
+		Resources:   &blueprint.Resources{CPURequest: "1"},
+		Job:         &blueprint.Job{},
+	}
+	completedJobPod := corev1.Pod{
+		Spec: corev1.PodSpec{
+			Hostname: completedJob.Hostname,
+		},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: completedJob.Hostname,
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode:   1,
+						FinishedAt: metav1.NewTime(mockTime),
+					},
+				},
+			}},
+		},
+	}
+
 	rebuildingContainer := db.Container{
 		BlueprintID: "2",
 		Hostname:    "wasRunningNowRebuilding",
@@ -41,17 +73,36 @@ func TestUpdateStatus(t *testing.T) {
 		Created:     time.Now(),
 	}
 	conn := db.New()
-	conn.Txn(db.ContainerTable).Run(func(view db.Database) error {
+	conn.Txn(db.ContainerTable, db.BlueprintTable).Run(func(view db.Database) error {
+		view.InsertBlueprint()
+
 		runningContainer.ID = view.InsertContainer().ID
 		view.Commit(runningContainer)
 
 		rebuildingContainer.ID = view.InsertContainer().ID
 		view.Commit(rebuildingContainer)
+
+		completedJob.ID = view.InsertContainer().ID
+		view.Commit(completedJob)
 		return nil
 	})
 
-	joinContainersToPods = func(dbcs []db.Container, _ []corev1.Pod) (
-		pairs []join.Pair, noInfoContainers []interface{}) {
+	// The rebuilding container doesn't have a desired pod because its image
+	// isn't built.
+	runningPod, _ := makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		runningContainer)
+	completedPod, _ := makePod(nil, map[string]*corev1.Affinity{}, nil, nil,
+		completedJob)
+	expSpecHashes := map[int]string{
+		runningContainer.ID: makePodTemplate(runningContainer, runningPod).
+			Annotations[podSpecHashKey],
+		completedJob.ID: makeJobSpec(completedJob, completedPod).Template.
+			Annotations[podSpecHashKey],
+	}
+
+	joinContainersToPods = func(dbcs []db.Container, specHashes map[int]string,
+		_ []corev1.Pod) (pairs []join.Pair, noInfoContainers []interface{}) {
+		assert.Equal(t, expSpecHashes, specHashes)
 		for _, dbc := range dbcs {
 			switch dbc.Hostname {
 			case runningContainer.Hostname:
@@ -59,6 +110,11 @@ func TestUpdateStatus(t *testing.T) {
 					L: runningContainer,
 					R: runningContainerPod,
 				})
+			case completedJob.Hostname:
+				pairs = append(pairs, join.Pair{
+					L: completedJob,
+					R: completedJobPod,
+				})
 			case rebuildingContainer.Hostname:
 				noInfoContainers = append(noInfoContainers,
 					rebuildingContainer)
@@ -71,11 +127,15 @@ func TestUpdateStatus(t *testing.T) {
 	}
 
 	statusForPod = func(pod corev1.Pod) (string, time.Time) {
-		if pod.Spec.Hostname != runningContainer.Hostname {
-			assert.FailNow(t, "unexpected call to statusForPod "+
-				"for %s", pod.Spec.Hostname)
+		switch pod.Spec.Hostname {
+		case runningContainer.Hostname:
+			return "running", mockTime
+		case completedJob.Hostname:
+			return "terminated: Error", time.Time{}
 		}
-		return "running", mockTime
+		assert.FailNow(t, "unexpected call to statusForPod "+
+			"for %s", pod.Spec.Hostname)
+		return "", time.Time{}
 	}
 
 	statusForContainer = func(_ map[db.Image]db.Image, _ SecretClient,
@@ -97,10 +157,75 @@ func TestUpdateStatus(t *testing.T) {
 	runningContainer.Created = mockTime
 	rebuildingContainer.Status = "building"
 	rebuildingContainer.Created = time.Time{}
+	completedJob.Status = "terminated: Error"
+	completedJob.ExitCode = 1
+	completedJob.Completed = mockTime
 
 	actualDbcs := conn.SelectFromContainer(nil)
 	sort.Sort(db.ContainerSlice(actualDbcs))
-	assert.Equal(t, []db.Container{runningContainer, rebuildingContainer}, actualDbcs)
+	assert.Equal(t, []db.Container{runningContainer, rebuildingContainer,
+		completedJob}, actualDbcs)
+}
+
+func TestSetExitStatus(t *testing.T) {
+	t.Parallel()
+
+	mockTime := time.Now()
+	pod := corev1.Pod{
+		Spec: corev1.PodSpec{Hostname: "host"},
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "host",
+				RestartCount: 3,
+				State: corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{
+						Reason: "CrashLoopBackOff",
+						Message: "Back-off 40s restarting " +
+							"failed container",
+					},
+				},
+				LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode:   137,
+						Reason:     "OOMKilled",
+						FinishedAt: metav1.NewTime(mockTime),
+					},
+				},
+			}},
+		},
+	}
+
+	// A crash-looping container should report its last termination, but it
+	// shouldn't be considered completed.
+	var dbc db.Container
+	setExitStatus(&dbc, pod)
+	assert.Equal(t, db.Container{
+		RestartCount:      3,
+		ExitCode:          137,
+		TerminationReason: "OOMKilled",
+		WaitingReason:     "CrashLoopBackOff",
+		WaitingMessage:    "Back-off 40s restarting failed container",
+	}, dbc)
+
+	// The current termination takes precedence over the last one.
+	status := &pod.Status.ContainerStatuses[0]
+	status.State = corev1.ContainerState{
+		Terminated: &corev1.ContainerStateTerminated{
+			ExitCode:   0,
+			Reason:     "Completed",
+			FinishedAt: metav1.NewTime(mockTime),
+		},
+	}
+	setExitStatus(&dbc, pod)
+	assert.Equal(t, db.Container{
+		RestartCount:      3,
+		TerminationReason: "Completed",
+		Completed:         mockTime,
+	}, dbc)
+
+	// Everything should be cleared when there's no status for the container.
+	setExitStatus(&dbc, corev1.Pod{})
+	assert.Equal(t, db.Container{}, dbc)
 }
 
 // Test that if the list fails, nothing changes.
@@ -227,6 +352,24 @@ func TestStatusForPod(t *testing.T) {
 				},
 			},
 		},
+	}, {
+		// The status of sidecars is ignored.
+		expStatus: "waiting: PodInitializing",
+		pod: corev1.Pod{
+			Spec: corev1.PodSpec{Hostname: "web"},
+			Status: corev1.PodStatus{
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "proxy", State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					}},
+					{Name: "web", State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{
+							Reason: "PodInitializing",
+						},
+					}},
+				},
+			},
+		},
 	}, {
 		expStatus: "no status information",
 		pod:       corev1.Pod{Status: corev1.PodStatus{}},
@@ -280,6 +423,50 @@ func TestStatusForContainer(t *testing.T) {
 		Image:      builtImage,
 	}, images, secrets, "built")
 
+	// The images of sidecars and init containers must be built too.
+	checkStatusForContainer(t, db.Container{
+		Hostname:   "hostname",
+		Dockerfile: builtDockerfile,
+		Image:      builtImage,
+		Sidecars: []blueprint.PodContainer{{
+			Name: "sidecar",
+			Image: blueprint.Image{Name: buildingImage,
+				Dockerfile: buildingDockerfile},
+		}},
+	}, images, secrets, "building")
+
+	checkStatusForContainer(t, db.Container{
+		Hostname: "hostname",
+		Image:    "nginx",
+		InitContainers: []blueprint.PodContainer{{
+			Name: "init",
+			Image: blueprint.Image{Name: builtImage,
+				Dockerfile: builtDockerfile},
+		}},
+	}, images, secrets, "built")
+
+	// Cron jobs wait for their schedule between runs.
+	checkStatusForContainer(t, db.Container{
+		Hostname: "hostname",
+		Image:    "nginx",
+		Job:      &blueprint.Job{Schedule: "@hourly"},
+	}, images, secrets, "waiting for schedule")
+
+	checkStatusForContainer(t, db.Container{
+		Hostname:   "hostname",
+		Dockerfile: buildingDockerfile,
+		Image:      buildingImage,
+		Job:        &blueprint.Job{Schedule: "@hourly"},
+	}, images, secrets, "building")
+
+	// Once a cron job's image is built, it waits for its schedule.
+	checkStatusForContainer(t, db.Container{
+		Hostname:   "hostname",
+		Dockerfile: builtDockerfile,
+		Image:      builtImage,
+		Job:        &blueprint.Job{Schedule: "@hourly"},
+	}, images, secrets, "waiting for schedule")
+
 	// Test waiting for secrets.
 	checkStatusForContainer(t, db.Container{
 		Hostname: "hostname",
@@ -325,17 +512,20 @@ func TestJoinContainersToPods(t *testing.T) {
 
 	// A container that will be matched up with a pod.
 	matchContainer := db.Container{
+		ID:       1,
 		Hostname: "hostname1",
 		Image:    "custom-image",
 		Command:  []string{"arg1", "arg2"},
 		FilepathToContent: map[string]blueprint.ContainerValue{
 			"key": blueprint.NewString("value"),
 		},
-		IP: "ignored",
+		Resources: &blueprint.Resources{CPURequest: "0.5"},
+		IP:        "ignored",
 	}
 
 	// A container that won't be matched up with a pod.
 	unmatchedContainerA := db.Container{
+		ID:       2,
 		Hostname: "hostname2",
 		Image:    "no-matching-pod",
 		Command:  []string{"args"},
@@ -346,17 +536,114 @@ func TestJoinContainersToPods(t *testing.T) {
 	// container that shouldn't be matched into a pod with a different command.
 	unmatchedContainerB := unmatchedContainerA
 	unmatchedContainerB.Command = []string{"different", "args"}
-	pods, ok := dbcsToPods([]db.Container{matchContainer, unmatchedContainerB})
+
+	// A container with a sidecar, whose pod has multiple containers.
+	sidecarContainer := db.Container{
+		ID:       3,
+		Hostname: "hostname3",
+		Image:    "nginx",
+		IP:       "ignored",
+		Sidecars: []blueprint.PodContainer{{
+			Name:  "proxy",
+			Image: blueprint.Image{Name: "envoy"},
+		}},
+	}
+	pods, ok := dbcsToPods([]db.Container{matchContainer, unmatchedContainerB,
+		sidecarContainer})
 	assert.True(t, ok)
 
 	// Also test a malformed pod without any containers.
 	pods = append(pods, corev1.Pod{})
 
-	pairs, noInfoContainers := joinContainersToPodsImpl([]db.Container{
-		matchContainer, unmatchedContainerA,
-	}, pods)
-	assert.Equal(t, []join.Pair{{L: matchContainer, R: pods[0]}}, pairs)
-	assert.Equal(t, []interface{}{unmatchedContainerA}, noInfoContainers)
+	// Kubernetes may fill in defaults for the pod's resources, which
+	// shouldn't prevent the pod from matching.
+	pods[0].Spec.Containers[0].Resources.Limits = corev1.ResourceList{
+		corev1.ResourceCPU: resource.MustParse("1"),
+	}
+
+	// Quantities are matched regardless of how they're written, but changing
+	// the resources of a container prevents it from matching its old pod.
+	equivalentContainer := matchContainer
+	equivalentContainer.Resources = &blueprint.Resources{CPURequest: "500m"}
+	resizedContainer := matchContainer
+	resizedContainer.ID = 4
+	resizedContainer.Resources = &blueprint.Resources{CPURequest: "1"}
+
+	// Containers that need a spec hash, but whose pods can't be created yet,
+	// don't match.
+	unbuiltContainer := matchContainer
+	unbuiltContainer.ID = 5
+
+	// Adding a health check also prevents a container from matching its old
+	// pod.
+	checkedContainer := matchContainer
+	checkedContainer.ID = 6
+	checkedContainer.ReadinessCheck = &blueprint.HealthCheck{
+		Exec: []string{"true"},
+	}
+
+	// As does changing its sidecars.
+	changedSidecarContainer := sidecarContainer
+	changedSidecarContainer.ID = 7
+	changedSidecarContainer.Sidecars = []blueprint.PodContainer{{
+		Name:  "proxy",
+		Image: blueprint.Image{Name: "haproxy"},
+	}}
+
+	// Or changing how it's stopped.
+	gracefulContainer := matchContainer
+	gracefulContainer.ID = 8
+	gracefulContainer.TerminationGracePeriodSeconds = 60
+
+	// And adding a capability.
+	capableContainer := sidecarContainer
+	capableContainer.ID = 9
+	capableContainer.SecurityContext = &blueprint.SecurityContext{
+		CapAdd: []string{"NET_ADMIN"},
+	}
+
+	dbcs := []db.Container{equivalentContainer, sidecarContainer,
+		unmatchedContainerA, resizedContainer, checkedContainer,
+		changedSidecarContainer, capableContainer, gracefulContainer}
+	pairs, noInfoContainers := joinContainersToPodsImpl(
+		append(dbcs, unbuiltContainer), dbcsToSpecHashes(dbcs), pods)
+	assert.Equal(t, []join.Pair{
+		{L: equivalentContainer, R: pods[0]},
+		{L: sidecarContainer, R: pods[2]},
+	}, pairs)
+
+	// Unmatched containers are returned in a random order.
+	expNoInfo := []interface{}{unmatchedContainerA, resizedContainer,
+		checkedContainer, changedSidecarContainer, capableContainer,
+		gracefulContainer, unbuiltContainer}
+	assert.Len(t, noInfoContainers, len(expNoInfo))
+	assert.Subset(t, noInfoContainers, expNoInfo)
+}
+
+func TestJoinContainersToNewestPod(t *testing.T) {
+	t.Parallel()
+
+	cronJob := db.Container{
+		ID:       1,
+		Hostname: "hostname",
+		Image:    "image",
+		Job:      &blueprint.Job{Schedule: "@daily"},
+	}
+	pods, ok := dbcsToPods([]db.Container{cronJob, cronJob, cronJob})
+	assert.True(t, ok)
+
+	now := time.Now()
+	pods[0].CreationTimestamp = metav1.NewTime(now.Add(-2 * time.Hour))
+	pods[1].CreationTimestamp = metav1.NewTime(now)
+	pods[2].CreationTimestamp = metav1.NewTime(now.Add(-time.Hour))
+	for i := range pods {
+		pods[i].Name = fmt.Sprintf("run-%d", i)
+	}
+
+	pairs, noInfoContainers := joinContainersToPodsImpl([]db.Container{cronJob},
+		dbcsToSpecHashes([]db.Container{cronJob}), pods)
+	assert.Equal(t, []join.Pair{{L: cronJob, R: pods[1]}}, pairs)
+	assert.Empty(t, noInfoContainers)
 }
 
 func dbcsToPods(dbcs []db.Container) (pods []corev1.Pod, ok bool) {
@@ -376,3 +663,65 @@ func dbcsToPods(dbcs []db.Container) (pods []corev1.Pod, ok bool) {
 	}
 	return pods, true
 }
+
+func dbcsToSpecHashes(dbcs []db.Container) map[int]string {
+	specHashes := map[int]string{}
+	for _, dbc := range dbcs {
+		pod, _ := makePod(nil, map[string]*corev1.Affinity{}, nil, nil, dbc)
+		specHashes[dbc.ID] = makeDeployment(dbc, pod).Spec.Template.
+			Annotations[podSpecHashKey]
+	}
+	return specHashes
+}
+
+func TestPodReady(t *testing.T) {
+	t.Parallel()
+
+	podWithConditions := func(conds ...corev1.PodCondition) corev1.Pod {
+		return corev1.Pod{Status: corev1.PodStatus{Conditions: conds}}
+	}
+
+	assert.False(t, podReady(podWithConditions()))
+	assert.False(t, podReady(podWithConditions(corev1.PodCondition{
+		Type:   corev1.PodScheduled,
+		Status: corev1.ConditionTrue,
+	})))
+	assert.False(t, podReady(podWithConditions(corev1.PodCondition{
+		Type:   corev1.PodReady,
+		Status: corev1.ConditionFalse,
+	})))
+	assert.True(t, podReady(podWithConditions(
+		corev1.PodCondition{
+			Type:   corev1.PodScheduled,
+			Status: corev1.ConditionTrue,
+		},
+		corev1.PodCondition{
+			Type:   corev1.PodReady,
+			Status: corev1.ConditionTrue,
+		})))
+}
+
+func TestUpdateContainerMetrics(t *testing.T) {
+	updateContainerMetrics([]db.Container{
+		{Status: "running"},
+		{Status: "running"},
+		{Status: "waiting: ContainerCreating"},
+		{Status: "waiting: ErrImagePull"},
+		{},
+	})
+
+	var buf bytes.Buffer
+	assert.NoError(t, counter.WritePrometheus(&buf))
+	out := buf.String()
+	for status, count := range map[string]int{
+		"running": 2, "waiting": 2, "unknown": 1} {
+		assert.Contains(t, out, fmt.Sprintf("kelda_gauge{package=\"Container "+
+			"Status\",name=%q} %d\n", status, count))
+	}
+
+	updateContainerMetrics(nil)
+	buf.Reset()
+	assert.NoError(t, counter.WritePrometheus(&buf))
+	assert.Contains(t, buf.String(), "kelda_gauge{package=\"Container "+
+		"Status\",name=\"running\"} 0\n")
+}
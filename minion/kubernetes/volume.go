@@ -0,0 +1,240 @@
+package kubernetes
+
+import (
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/join"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientv1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	storageclient "k8s.io/client-go/kubernetes/typed/storage/v1"
+)
+
+// The provisioners, built into Kubernetes, that create the disks of each type
+// of cloud volume.
+var provisioners = map[string]string{
+	blueprint.GooglePersistentDiskVolume: "kubernetes.io/gce-pd",
+}
+
+// The label on the storage classes created by Kelda. Storage classes aren't
+// namespaced, so the label distinguishes them from the cluster's defaults.
+const storageClassKey = "kelda.io/volume"
+
+// The label that Kubernetes gives to nodes and disks in each zone.
+const zoneKey = "failure-domain.beta.kubernetes.io/zone"
+
+// updateVolumes creates a persistent volume claim for each cloud volume in
+// the blueprint, and a storage class that describes how to create its disk.
+// Kubernetes creates the disk, and attaches it to whichever machine the pod
+// that mounts the claim is scheduled on.
+//
+// Disks can't be changed once they're created, so existing claims are left
+// alone when their volume changes. Claims, and so disks, are deleted when
+// their volume is removed from the blueprint.
+func updateVolumes(conn db.Conn, storageClassesClient storageclient.StorageClassInterface,
+	claimsClient clientv1.PersistentVolumeClaimInterface) {
+
+	currentClasses, err := storageClassesClient.List(metav1.ListOptions{
+		LabelSelector: storageClassKey,
+	})
+	if err != nil {
+		log.WithError(err).Error("Failed to list current storage classes")
+		return
+	}
+
+	currentClaims, err := claimsClient.List(metav1.ListOptions{})
+	if err != nil {
+		log.WithError(err).Error("Failed to list current volume claims")
+		return
+	}
+
+	var volumes []blueprint.Volume
+	err = conn.Txn(db.BlueprintTable).Run(func(view db.Database) error {
+		bp, err := view.GetBlueprint()
+		volumes = bp.Volumes
+		return err
+	})
+	if err != nil {
+		return
+	}
+
+	var classes []storagev1.StorageClass
+	var claims []corev1.PersistentVolumeClaim
+	for _, vol := range volumes {
+		if !vol.IsCloudVolume() {
+			continue
+		}
+
+		claim, err := makeVolumeClaim(vol)
+		if err != nil {
+			log.WithError(err).WithField("volume", vol.Name).
+				Error("Failed to make volume claim")
+			continue
+		}
+		classes = append(classes, makeStorageClass(vol))
+		claims = append(claims, claim)
+	}
+
+	// Create the storage classes before the claims that use them, and delete
+	// them after.
+	classKey := func(intf interface{}) interface{} {
+		return intf.(storagev1.StorageClass).Name
+	}
+	_, classesToCreate, classesToDelete := join.HashJoin(
+		storageClassSlice(classes), storageClassSlice(currentClasses.Items),
+		classKey, classKey)
+
+	for _, intf := range classesToCreate {
+		class := intf.(storagev1.StorageClass)
+		c.Inc("Create storage class")
+		if _, err := storageClassesClient.Create(&class); err != nil {
+			log.WithError(err).WithField("storageClass", class.Name).
+				Error("Failed to create storage class")
+		}
+	}
+
+	claimKey := func(intf interface{}) interface{} {
+		return intf.(corev1.PersistentVolumeClaim).Name
+	}
+	_, claimsToCreate, claimsToDelete := join.HashJoin(
+		volumeClaimSlice(claims), volumeClaimSlice(currentClaims.Items),
+		claimKey, claimKey)
+
+	for _, intf := range claimsToCreate {
+		claim := intf.(corev1.PersistentVolumeClaim)
+		log.WithField("volume", claim.Name).Info("Creating volume claim")
+		c.Inc("Create volume claim")
+		if _, err := claimsClient.Create(&claim); err != nil {
+			log.WithError(err).WithField("volume", claim.Name).
+				Error("Failed to create volume claim")
+		}
+	}
+
+	for _, intf := range claimsToDelete {
+		claim := intf.(corev1.PersistentVolumeClaim)
+		log.WithField("volume", claim.Name).Info("Deleting volume claim")
+		c.Inc("Delete volume claim")
+		err := claimsClient.Delete(claim.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			log.WithError(err).WithField("volume", claim.Name).
+				Error("Failed to delete volume claim")
+		}
+	}
+
+	for _, intf := range classesToDelete {
+		class := intf.(storagev1.StorageClass)
+		c.Inc("Delete storage class")
+		err := storageClassesClient.Delete(class.Name, &metav1.DeleteOptions{})
+		if err != nil {
+			log.WithError(err).WithField("storageClass", class.Name).
+				Error("Failed to delete storage class")
+		}
+	}
+}
+
+// makeStorageClass returns the storage class of the volume's disk. Each volume
+// has its own storage class so that its disk can be created in its zone.
+func makeStorageClass(vol blueprint.Volume) storagev1.StorageClass {
+	deletePolicy := corev1.PersistentVolumeReclaimDelete
+	class := storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   storageClassName(vol),
+			Labels: map[string]string{storageClassKey: vol.Name},
+		},
+		Provisioner:   provisioners[vol.Type],
+		ReclaimPolicy: &deletePolicy,
+	}
+	if zone, ok := vol.Conf["zone"]; ok {
+		class.Parameters = map[string]string{"zone": zone}
+	}
+	return class
+}
+
+func makeVolumeClaim(vol blueprint.Volume) (corev1.PersistentVolumeClaim, error) {
+	size, err := resource.ParseQuantity(vol.Conf["size"])
+	if err != nil {
+		return corev1.PersistentVolumeClaim{}, err
+	}
+
+	className := storageClassName(vol)
+	return corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: vol.Name},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			// Disks can only be attached to one machine at a time.
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			StorageClassName: &className,
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}, nil
+}
+
+func storageClassName(vol blueprint.Volume) string {
+	return "kelda-" + vol.Name
+}
+
+// addVolumeAffinities constrains the containers that mount cloud volumes with a
+// zone to machines in that zone, which are the only machines that their disks
+// can be attached to.
+func addVolumeAffinities(idToAffinity map[string]*corev1.Affinity,
+	volumes []blueprint.Volume, dbcs []db.Container) {
+
+	zones := map[string]string{}
+	for _, vol := range volumes {
+		if zone, ok := vol.Conf["zone"]; ok && vol.IsCloudVolume() {
+			zones[vol.Name] = zone
+		}
+	}
+
+	for _, dbc := range dbcs {
+		mounts := dbc.VolumeMounts
+		for _, pc := range dbc.PodContainers() {
+			mounts = append(mounts, pc.VolumeMounts...)
+		}
+
+		constrained := map[string]struct{}{}
+		for _, mount := range mounts {
+			zone, ok := zones[mount.VolumeName]
+			if _, seen := constrained[zone]; !ok || seen {
+				continue
+			}
+			constrained[zone] = struct{}{}
+
+			affinity, ok := idToAffinity[dbc.Hostname]
+			if !ok {
+				affinity = &corev1.Affinity{}
+				idToAffinity[dbc.Hostname] = affinity
+			}
+			handleNodeAffinity(affinity, zoneKey, zone, false)
+		}
+	}
+}
+
+type storageClassSlice []storagev1.StorageClass
+
+func (slc storageClassSlice) Get(ii int) interface{} {
+	return slc[ii]
+}
+
+func (slc storageClassSlice) Len() int {
+	return len(slc)
+}
+
+type volumeClaimSlice []corev1.PersistentVolumeClaim
+
+func (slc volumeClaimSlice) Get(ii int) interface{} {
+	return slc[ii]
+}
+
+func (slc volumeClaimSlice) Len() int {
+	return len(slc)
+}
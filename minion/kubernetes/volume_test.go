@@ -0,0 +1,191 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/kelda/kelda/blueprint"
+	"github.com/kelda/kelda/db"
+	"github.com/kelda/kelda/minion/kubernetes/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestUpdateVolumes(t *testing.T) {
+	t.Parallel()
+	conn := db.New()
+	classesClient := &mocks.StorageClassInterface{}
+	claimsClient := &mocks.PersistentVolumeClaimInterface{}
+
+	// No actions should be taken if we were unable to list the current
+	// storage classes.
+	classesClient.On("List", mock.Anything).Return(nil, assert.AnError).Once()
+	updateVolumes(conn, classesClient, claimsClient)
+	classesClient.AssertExpectations(t)
+	claimsClient.AssertExpectations(t)
+
+	vol := blueprint.Volume{
+		Name: "data",
+		Type: blueprint.GooglePersistentDiskVolume,
+		Conf: map[string]string{"size": "10Gi", "zone": "us-west1-a"},
+	}
+	conn.Txn(db.BlueprintTable).Run(func(view db.Database) error {
+		bp := view.InsertBlueprint()
+		bp.Volumes = []blueprint.Volume{
+			vol,
+			{
+				Name: "scratch",
+				Type: blueprint.EmptyDirVolume,
+			},
+		}
+		view.Commit(bp)
+		return nil
+	})
+	class := makeStorageClass(vol)
+	claim, err := makeVolumeClaim(vol)
+	assert.NoError(t, err)
+
+	// Test creating the claim and storage class of the cloud volume. Other
+	// volumes should be ignored.
+	classesClient.On("List", mock.Anything).Return(
+		&storagev1.StorageClassList{}, nil).Once()
+	claimsClient.On("List", mock.Anything).Return(
+		&corev1.PersistentVolumeClaimList{}, nil).Once()
+	classesClient.On("Create", &class).Return(nil, nil).Once()
+	claimsClient.On("Create", &claim).Return(nil, nil).Once()
+	updateVolumes(conn, classesClient, claimsClient)
+	classesClient.AssertExpectations(t)
+	claimsClient.AssertExpectations(t)
+
+	// Existing claims should be left alone, even if the volume changed.
+	conn.Txn(db.BlueprintTable).Run(func(view db.Database) error {
+		bp, _ := view.GetBlueprint()
+		bp.Volumes[0].Conf = map[string]string{"size": "20Gi"}
+		view.Commit(bp)
+		return nil
+	})
+	classesClient.On("List", mock.Anything).Return(&storagev1.StorageClassList{
+		Items: []storagev1.StorageClass{class},
+	}, nil).Once()
+	claimsClient.On("List", mock.Anything).Return(
+		&corev1.PersistentVolumeClaimList{
+			Items: []corev1.PersistentVolumeClaim{claim},
+		}, nil).Once()
+	updateVolumes(conn, classesClient, claimsClient)
+	classesClient.AssertExpectations(t)
+	claimsClient.AssertExpectations(t)
+
+	// When the volume is removed, its claim and storage class should be
+	// deleted.
+	conn.Txn(db.BlueprintTable).Run(func(view db.Database) error {
+		bp, _ := view.GetBlueprint()
+		bp.Volumes = nil
+		view.Commit(bp)
+		return nil
+	})
+	classesClient.On("List", mock.Anything).Return(&storagev1.StorageClassList{
+		Items: []storagev1.StorageClass{class},
+	}, nil).Once()
+	claimsClient.On("List", mock.Anything).Return(
+		&corev1.PersistentVolumeClaimList{
+			Items: []corev1.PersistentVolumeClaim{claim},
+		}, nil).Once()
+	claimsClient.On("Delete", claim.Name, mock.Anything).Return(nil).Once()
+	classesClient.On("Delete", class.Name, mock.Anything).Return(nil).Once()
+	updateVolumes(conn, classesClient, claimsClient)
+	classesClient.AssertExpectations(t)
+	claimsClient.AssertExpectations(t)
+}
+
+func TestMakeVolumeClaim(t *testing.T) {
+	t.Parallel()
+
+	vol := blueprint.Volume{
+		Name: "data",
+		Type: blueprint.GooglePersistentDiskVolume,
+		Conf: map[string]string{"size": "10Gi", "zone": "us-east1-b"},
+	}
+
+	class := makeStorageClass(vol)
+	assert.Equal(t, "kelda-data", class.Name)
+	assert.Equal(t, "data", class.Labels[storageClassKey])
+	assert.Equal(t, "kubernetes.io/gce-pd", class.Provisioner)
+	assert.Equal(t, corev1.PersistentVolumeReclaimDelete, *class.ReclaimPolicy)
+	assert.Equal(t, map[string]string{"zone": "us-east1-b"}, class.Parameters)
+
+	claim, err := makeVolumeClaim(vol)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", claim.Name)
+	assert.Equal(t, "kelda-data", *claim.Spec.StorageClassName)
+	assert.Equal(t, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		claim.Spec.AccessModes)
+	assert.Equal(t, resource.MustParse("10Gi"),
+		claim.Spec.Resources.Requests[corev1.ResourceStorage])
+
+	// Volumes without a zone should be created wherever the provider chooses.
+	vol.Conf = map[string]string{"size": "10Gi"}
+	class = makeStorageClass(vol)
+	assert.Nil(t, class.Parameters)
+
+	vol.Conf["size"] = "lots"
+	_, err = makeVolumeClaim(vol)
+	assert.Error(t, err)
+}
+
+func TestAddVolumeAffinities(t *testing.T) {
+	t.Parallel()
+
+	volumes := []blueprint.Volume{
+		{
+			Name: "zoned",
+			Type: blueprint.GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "10Gi", "zone": "us-west1-a"},
+		},
+		{
+			Name: "unzoned",
+			Type: blueprint.GooglePersistentDiskVolume,
+			Conf: map[string]string{"size": "10Gi"},
+		},
+		{
+			Name: "scratch",
+			Type: blueprint.EmptyDirVolume,
+			Conf: map[string]string{"zone": "ignored"},
+		},
+	}
+	dbcs := []db.Container{
+		{
+			Hostname: "main",
+			VolumeMounts: []blueprint.VolumeMount{
+				{VolumeName: "zoned", MountPath: "/data"},
+				{VolumeName: "scratch", MountPath: "/tmp"},
+			},
+			// The zone should only be added once, even though the volume is
+			// mounted again by the sidecar.
+			Sidecars: []blueprint.PodContainer{
+				{
+					Name: "sidecar",
+					VolumeMounts: []blueprint.VolumeMount{
+						{VolumeName: "zoned", MountPath: "/data"},
+					},
+				},
+			},
+		},
+		{
+			Hostname: "unconstrained",
+			VolumeMounts: []blueprint.VolumeMount{
+				{VolumeName: "unzoned", MountPath: "/data"},
+				{VolumeName: "scratch", MountPath: "/tmp"},
+			},
+		},
+	}
+
+	idToAffinity := map[string]*corev1.Affinity{}
+	addVolumeAffinities(idToAffinity, volumes, dbcs)
+
+	exp := &corev1.Affinity{}
+	handleNodeAffinity(exp, zoneKey, "us-west1-a", false)
+	assert.Equal(t, map[string]*corev1.Affinity{"main": exp}, idToAffinity)
+}
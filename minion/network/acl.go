@@ -27,17 +27,22 @@ type connection struct {
 
 	minPort int
 	maxPort int
+
+	// Either tcp, udp or icmp, or empty if both TCP and UDP are allowed.
+	protocol string
 }
 
+// updateACLs allows the traffic permitted by `dbConns`. Hostnames may refer to
+// several IPs while containers are being replaced by rolling updates.
 func updateACLs(client ovsdb.Client, dbConns []db.Connection,
-	hostnameToIP map[string]string) {
+	hostnameToIPs map[string][]string) {
 
-	connections, addressSets := resolveConnections(dbConns, hostnameToIP)
+	connections, addressSets := resolveConnections(dbConns, hostnameToIPs)
 	syncAddressSets(client, addressSets)
 	syncACLs(client, connections)
 }
 
-func resolveConnections(dbConns []db.Connection, hostnameToIP map[string]string) (
+func resolveConnections(dbConns []db.Connection, hostnameToIPs map[string][]string) (
 	[]connection, []ovsdb.AddressSet) {
 
 	var conns []connection
@@ -49,21 +54,22 @@ func resolveConnections(dbConns []db.Connection, hostnameToIP map[string]string)
 	for _, dbConn := range dbConns {
 		from := str.SliceFilterOut(dbConn.From, blueprint.PublicInternetLabel)
 		from = uniqueStrings(from)
-		from = resolveHostnames(from, hostnameToIP)
+		from = resolveHostnames(from, hostnameToIPs)
 
 		to := str.SliceFilterOut(dbConn.To, blueprint.PublicInternetLabel)
 		to = uniqueStrings(to)
-		to = resolveHostnames(to, hostnameToIP)
+		to = resolveHostnames(to, hostnameToIPs)
 
 		if len(from) == 0 || len(to) == 0 {
 			continue // Either from or to contained only `public`.
 		}
 
 		conns = append(conns, connection{
-			minPort: dbConn.MinPort,
-			maxPort: dbConn.MaxPort,
-			from:    endpointName(from, addressSets),
-			to:      endpointName(to, addressSets),
+			minPort:  dbConn.MinPort,
+			maxPort:  dbConn.MaxPort,
+			protocol: dbConn.Protocol,
+			from:     endpointName(from, addressSets),
+			to:       endpointName(to, addressSets),
 		})
 	}
 
@@ -78,15 +84,15 @@ func resolveConnections(dbConns []db.Connection, hostnameToIP map[string]string)
 	return conns, result
 }
 
-func resolveHostnames(hostnames []string, hostnameToIP map[string]string) []string {
+func resolveHostnames(hostnames []string, hostnameToIPs map[string][]string) []string {
 	var res []string
 	for _, m := range hostnames {
-		ip, ok := hostnameToIP[m]
+		ips, ok := hostnameToIPs[m]
 		if !ok {
 			log.WithField("hostname", m).Debug("Unknown hostname in ACL")
 			continue
 		}
-		res = append(res, ip)
+		res = append(res, ips...)
 	}
 	return res
 }
@@ -163,15 +169,17 @@ func syncACLs(ovsdbClient ovsdb.Client, connections []connection) {
 
 	icmpMatches := map[string]struct{}{}
 	for _, conn := range connections {
-		matchStr := getMatchString(conn)
-		expACLs = append(expACLs, directedACLs(
-			ovsdb.ACL{
-				Core: ovsdb.ACLCore{
-					Action:   "allow",
-					Match:    matchStr,
-					Priority: 1,
-				},
-			})...)
+		// ICMP connections are fully covered by the ICMP rule below.
+		if conn.protocol != blueprint.ICMPProtocol {
+			expACLs = append(expACLs, directedACLs(
+				ovsdb.ACL{
+					Core: ovsdb.ACLCore{
+						Action:   "allow",
+						Match:    getMatchString(conn),
+						Priority: 1,
+					},
+				})...)
+		}
 
 		icmpMatch := and(from(conn.from), to(conn.to), "icmp")
 		if _, ok := icmpMatches[icmpMatch]; !ok {
@@ -228,13 +236,21 @@ func getMatchString(conn connection) string {
 	return or(
 		and(
 			from(conn.from), to(conn.to),
-			portConstraint(conn.minPort, conn.maxPort, "dst")),
+			portConstraint(conn.protocol, conn.minPort, conn.maxPort,
+				"dst")),
 		and(
 			from(conn.to), to(conn.from),
-			portConstraint(conn.minPort, conn.maxPort, "src")))
+			portConstraint(conn.protocol, conn.minPort, conn.maxPort,
+				"src")))
 }
 
-func portConstraint(minPort, maxPort int, direction string) string {
+// portConstraint matches packets of the given protocol whose `direction` port
+// is in the range. An empty protocol matches both UDP and TCP.
+func portConstraint(protocol string, minPort, maxPort int, direction string) string {
+	if protocol != "" {
+		return fmt.Sprintf("(%d <= %s.%s <= %d)", minPort, protocol,
+			direction, maxPort)
+	}
 	return fmt.Sprintf("(%[1]d <= udp.%[2]s <= %[3]d || "+
 		"%[1]d <= tcp.%[2]s <= %[3]d)", minPort, direction, maxPort)
 }
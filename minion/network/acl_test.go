@@ -40,10 +40,16 @@ func TestResolveConenctions(t *testing.T) {
 		To:      []string{"a", "b", "c"},
 		MinPort: 7,
 		MaxPort: 8,
-	}}, map[string]string{
-		"a": "1.1.1.1",
-		"b": "2.2.2.2",
-		"c": "3.3.3.3",
+	}, {
+		From:     []string{"b"},
+		To:       []string{"c"},
+		MinPort:  53,
+		MaxPort:  53,
+		Protocol: "udp",
+	}}, map[string][]string{
+		"a": {"1.1.1.1"},
+		"b": {"2.2.2.2"},
+		"c": {"3.3.3.3"},
 	})
 
 	assert.Equal(t, []connection{{
@@ -72,6 +78,12 @@ func TestResolveConenctions(t *testing.T) {
 			"41f4e9bc7478b32191a1f8e2bb215a2bef8",
 		minPort: 7,
 		maxPort: 8,
+	}, {
+		from:     "2.2.2.2",
+		to:       "3.3.3.3",
+		minPort:  53,
+		maxPort:  53,
+		protocol: "udp",
 	}}, connections)
 
 	exp := map[string][]string{
@@ -107,10 +119,10 @@ func TestDuplicateAddressSet(t *testing.T) {
 			From: []string{"foo", "bar", "repeated"},
 			To:   []string{"foo", "bar", "repeated", "repeated"},
 		},
-	}, map[string]string{
-		"foo":      "foo",
-		"bar":      "bar",
-		"repeated": "repeated",
+	}, map[string][]string{
+		"foo":      {"foo"},
+		"bar":      {"bar"},
+		"repeated": {"repeated"},
 	})
 
 	exp := ovsdb.AddressSet{
@@ -164,6 +176,16 @@ func TestSyncACLs(t *testing.T) {
 			to:      "9.9.9.9",
 			minPort: 8080,
 			maxPort: 8080,
+		}, {
+			from:     "8.8.8.8",
+			to:       "7.7.7.7",
+			minPort:  53,
+			maxPort:  53,
+			protocol: "udp",
+		}, {
+			from:     "9.9.9.9",
+			to:       "8.8.8.8",
+			protocol: "icmp",
 		},
 	}
 	core := ovsdb.ACLCore{Match: "a"}
@@ -206,23 +228,54 @@ func TestSyncACLs(t *testing.T) {
 	}, {
 		Priority:  1,
 		Direction: "from-lport",
-		Match:     getMatchString(connection{"8.8.8.8", "9.9.9.9", 80, 80}),
+		Match:     getMatchString(conns[0]),
 		Action:    "allow",
 	}, {
 		Priority:  1,
 		Direction: "to-lport",
-		Match:     getMatchString(connection{"8.8.8.8", "9.9.9.9", 80, 80}),
+		Match:     getMatchString(conns[0]),
 		Action:    "allow",
 	}, {
 		Priority:  1,
 		Direction: "from-lport",
-		Match:     getMatchString(connection{"8.8.8.8", "9.9.9.9", 8080, 8080}),
+		Match:     getMatchString(conns[1]),
 		Action:    "allow",
 	}, {
 		Priority:  1,
 		Direction: "to-lport",
-		Match:     getMatchString(connection{"8.8.8.8", "9.9.9.9", 8080, 8080}),
+		Match:     getMatchString(conns[1]),
 		Action:    "allow",
+	}, {
+		Priority:  1,
+		Direction: "from-lport",
+		Match:     and(from("8.8.8.8"), to("7.7.7.7"), "icmp"),
+		Action:    "allow-related",
+	}, {
+		Priority:  1,
+		Direction: "to-lport",
+		Match:     and(from("8.8.8.8"), to("7.7.7.7"), "icmp"),
+		Action:    "allow-related",
+	}, {
+		Priority:  1,
+		Direction: "from-lport",
+		Match:     getMatchString(conns[2]),
+		Action:    "allow",
+	}, {
+		Priority:  1,
+		Direction: "to-lport",
+		Match:     getMatchString(conns[2]),
+		Action:    "allow",
+	}, {
+		// ICMP connections only need the ICMP rule.
+		Priority:  1,
+		Direction: "from-lport",
+		Match:     and(from("9.9.9.9"), to("8.8.8.8"), "icmp"),
+		Action:    "allow-related",
+	}, {
+		Priority:  1,
+		Direction: "to-lport",
+		Match:     and(from("9.9.9.9"), to("8.8.8.8"), "icmp"),
+		Action:    "allow-related",
 	}}
 
 	assert.Equal(t, len(actualACLs), len(expACLs))
@@ -237,3 +290,21 @@ func TestSyncACLs(t *testing.T) {
 	syncACLs(client, conns)
 	client.AssertCalled(t, "ListACLs")
 }
+
+func TestGetMatchString(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "((ip4.src == 1.1.1.1 && ip4.dst == 2.2.2.2 && "+
+		"(80 <= udp.dst <= 81 || 80 <= tcp.dst <= 81)) || "+
+		"(ip4.src == 2.2.2.2 && ip4.dst == 1.1.1.1 && "+
+		"(80 <= udp.src <= 81 || 80 <= tcp.src <= 81)))",
+		getMatchString(connection{from: "1.1.1.1", to: "2.2.2.2",
+			minPort: 80, maxPort: 81}))
+
+	assert.Equal(t, "((ip4.src == 1.1.1.1 && ip4.dst == 2.2.2.2 && "+
+		"(53 <= udp.dst <= 53)) || "+
+		"(ip4.src == 2.2.2.2 && ip4.dst == 1.1.1.1 && "+
+		"(53 <= udp.src <= 53)))",
+		getMatchString(connection{from: "1.1.1.1", to: "2.2.2.2",
+			minPort: 53, maxPort: 53, protocol: "udp"}))
+}
@@ -65,13 +65,25 @@ func joinHostnames(view db.Database) error {
 			})
 		}
 	}
+
+	// While a container is being replaced by a rolling update, its hostname
+	// refers to the retiring container until the replacement is ready.
+	hostnameToContainer := map[string]db.Container{}
 	for _, c := range view.SelectFromContainer(nil) {
-		if c.Hostname != "" && c.IP != "" {
-			target = append(target, db.Hostname{
-				Hostname: c.Hostname,
-				IP:       c.IP,
-			})
+		if c.Hostname == "" || c.IP == "" {
+			continue
 		}
+
+		if other, ok := hostnameToContainer[c.Hostname]; ok && other.Retiring {
+			continue
+		}
+		hostnameToContainer[c.Hostname] = c
+	}
+	for _, c := range hostnameToContainer {
+		target = append(target, db.Hostname{
+			Hostname: c.Hostname,
+			IP:       c.IP,
+		})
 	}
 
 	key := func(iface interface{}) interface{} {
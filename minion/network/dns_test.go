@@ -236,6 +236,24 @@ func TestSyncHostnames(t *testing.T) {
 				{Hostname: "container", IP: "containerIP"},
 			},
 		},
+		{
+			// Hostnames refer to retiring containers until they're
+			// removed.
+			containers: []db.Container{
+				{
+					Hostname: "container",
+					IP:       "newIP",
+				},
+				{
+					Hostname: "container",
+					IP:       "oldIP",
+					Retiring: true,
+				},
+			},
+			expHostnames: []db.Hostname{
+				{Hostname: "container", IP: "oldIP"},
+			},
+		},
 	}
 	for _, test := range tests {
 		conn := db.New()
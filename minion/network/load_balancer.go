@@ -46,13 +46,16 @@ unchanged.
 8. 10.2.0.1 receives the packet from the router.
 */
 func updateLoadBalancers(client ovsdb.Client, loadBalancers []db.LoadBalancer,
-	hostnameToIP map[string]string) {
-	updateLoadBalancerIPs(client, loadBalancers, hostnameToIP)
+	hostnameToIP map[string]string, unready map[string]struct{}) {
+	updateLoadBalancerIPs(client, loadBalancers, hostnameToIP, unready)
 	updateLoadBalancerARP(client, loadBalancers)
 }
 
+// updateLoadBalancerIPs sets the VIPs of each load balancer to the IPs of the
+// containers behind it, excluding the IPs in `unready` so that containers don't
+// receive traffic until they pass their readiness checks.
 func updateLoadBalancerIPs(client ovsdb.Client, loadBalancers []db.LoadBalancer,
-	hostnameToIP map[string]string) {
+	hostnameToIP map[string]string, unready map[string]struct{}) {
 	curr, err := client.ListLoadBalancers()
 	if err != nil {
 		log.WithError(err).Error("Failed to get load balancers")
@@ -64,6 +67,10 @@ func updateLoadBalancerIPs(client ovsdb.Client, loadBalancers []db.LoadBalancer,
 		var ips []string
 		for _, hostname := range lb.Hostnames {
 			ip := hostnameToIP[hostname]
+			if _, ok := unready[ip]; ok {
+				continue
+			}
+
 			if ip != "" {
 				ips = append(ips, ip)
 			}
@@ -17,7 +17,7 @@ func TestUpdateLoadBalancerIPs(t *testing.T) {
 
 	// Test error handling.
 	client.On("ListLoadBalancers").Return(nil, assert.AnError).Once()
-	updateLoadBalancerIPs(client, nil, nil)
+	updateLoadBalancerIPs(client, nil, nil, nil)
 	client.AssertNotCalled(t, "CreateLoadBalancer",
 		mock.Anything, mock.Anything, mock.Anything)
 	client.AssertNotCalled(t, "DeleteLoadBalancer", mock.Anything, mock.Anything)
@@ -51,7 +51,32 @@ func TestUpdateLoadBalancerIPs(t *testing.T) {
 		"red":    "10.0.0.4",
 		"blue":   "10.0.0.3",
 		"yellow": "10.0.0.11",
-	})
+	}, nil)
+	client.AssertExpectations(t)
+
+	// Containers that aren't ready are removed from the load balancer.
+	client.On("ListLoadBalancers").Return([]ovsdb.LoadBalancer{
+		{
+			Name: "red",
+			VIPs: map[string]string{"10.0.0.2": "10.0.0.3,10.0.0.4"},
+		},
+	}, nil).Once()
+	client.On("DeleteLoadBalancer", lSwitch, ovsdb.LoadBalancer{
+		Name: "red",
+		VIPs: map[string]string{"10.0.0.2": "10.0.0.3,10.0.0.4"},
+	}).Return(nil).Once()
+	client.On("CreateLoadBalancer", lSwitch, "red",
+		map[string]string{"10.0.0.2": "10.0.0.3"}).Return(nil).Once()
+	updateLoadBalancerIPs(client, []db.LoadBalancer{
+		{
+			Name:      "red",
+			IP:        "10.0.0.2",
+			Hostnames: []string{"red", "blue"},
+		},
+	}, map[string]string{
+		"red":  "10.0.0.4",
+		"blue": "10.0.0.3",
+	}, map[string]struct{}{"10.0.0.4": {}})
 	client.AssertExpectations(t)
 }
 
@@ -192,12 +192,40 @@ func getRules(ipt IPTables, table, chain string) (rules []string, err error) {
 	return rules, nil
 }
 
+// A natPort is a range of ports of a protocol that is forwarded to or from the
+// public internet. Inbound ports may be forwarded from a different public port.
+// ICMP ports have no numbers.
+type natPort struct {
+	protocol   string
+	min, max   int
+	publicPort int
+}
+
+// natPorts returns the ports allowed by `conn`.
+func natPorts(conn db.Connection) (ports []natPort) {
+	publicPort, _ := conn.PublicPorts()
+	for _, protocol := range blueprint.Protocols(conn.Protocol) {
+		ports = append(ports, natPort{protocol, conn.MinPort, conn.MaxPort,
+			publicPort})
+	}
+	return ports
+}
+
+// dport returns the iptables syntax for matching the destination ports in the
+// inclusive range, e.g. "8000:8010".
+func dport(min, max int) string {
+	if min == max {
+		return fmt.Sprintf("%d", min)
+	}
+	return fmt.Sprintf("%d:%d", min, max)
+}
+
 func preroutingRules(publicInterface string, containers []db.Container,
 	connections []db.Connection) (rules []string) {
 
 	// Map each hostname to all ports on which it can receive packets
 	// from the public internet.
-	portsFromWeb := make(map[string]map[int]struct{})
+	portsFromWeb := make(map[string]map[natPort]struct{})
 	for _, conn := range connections {
 		if !str.SliceContains(conn.From, blueprint.PublicInternetLabel) {
 			continue
@@ -205,23 +233,34 @@ func preroutingRules(publicInterface string, containers []db.Container,
 
 		for _, to := range conn.To {
 			if _, ok := portsFromWeb[to]; !ok {
-				portsFromWeb[to] = make(map[int]struct{})
+				portsFromWeb[to] = make(map[natPort]struct{})
 			}
 
-			portsFromWeb[to][conn.MinPort] = struct{}{}
+			for _, port := range natPorts(conn) {
+				portsFromWeb[to][port] = struct{}{}
+			}
 		}
 	}
 
-	// Map the container's port to the same port of the host.
+	// Map the host's public port to the container's port. Ranges are always
+	// forwarded to the same ports, so the destination port is left unchanged.
+	// ICMP packets are answered by the host, so they aren't forwarded.
 	for _, dbc := range containers {
 		for port := range portsFromWeb[dbc.Hostname] {
-			for _, protocol := range []string{"tcp", "udp"} {
-				rules = append(rules, fmt.Sprintf(
-					"-i %[1]s -p %[2]s -m %[2]s "+
-						"--dport %[3]d -j DNAT "+
-						"--to-destination %[4]s:%[3]d",
-					publicInterface, protocol, port, dbc.IP))
+			if port.protocol == blueprint.ICMPProtocol {
+				continue
+			}
+
+			dst := dbc.IP
+			if port.min == port.max {
+				dst += fmt.Sprintf(":%d", port.min)
 			}
+
+			publicMax := port.publicPort + port.max - port.min
+			rules = append(rules, fmt.Sprintf(
+				"-i %[1]s -p %[2]s -m %[2]s --dport %[3]s -j DNAT "+
+					"--to-destination %[4]s", publicInterface,
+				port.protocol, dport(port.publicPort, publicMax), dst))
 		}
 	}
 
@@ -233,7 +272,7 @@ func postroutingRules(publicInterface string, containers []db.Container,
 
 	// Map each hostname to all ports on which it can send packets
 	// to the public internet.
-	portsToWeb := make(map[string]map[int]struct{})
+	portsToWeb := make(map[string]map[natPort]struct{})
 	for _, conn := range connections {
 		for _, to := range conn.To {
 			if to != blueprint.PublicInternetLabel {
@@ -243,23 +282,33 @@ func postroutingRules(publicInterface string, containers []db.Container,
 
 		for _, from := range conn.From {
 			if _, ok := portsToWeb[from]; !ok {
-				portsToWeb[from] = make(map[int]struct{})
+				portsToWeb[from] = make(map[natPort]struct{})
 			}
 
-			portsToWeb[from][conn.MinPort] = struct{}{}
+			for _, port := range natPorts(conn) {
+				// Outbound traffic isn't affected by public ports.
+				port.publicPort = 0
+				portsToWeb[from][port] = struct{}{}
+			}
 		}
 	}
 
 	for _, dbc := range containers {
 		for port := range portsToWeb[dbc.Hostname] {
-			for _, protocol := range []string{"tcp", "udp"} {
+			if port.protocol == blueprint.ICMPProtocol {
 				rules = append(rules, fmt.Sprintf(
-					"-s %[1]s/32 -p %[2]s -m %[2]s "+
-						"--dport %[3]d -o %[4]s "+
-						"-j MASQUERADE",
-					dbc.IP, protocol, port, publicInterface,
-				))
+					"-s %s/32 -p icmp -o %s -j MASQUERADE",
+					dbc.IP, publicInterface))
+				continue
 			}
+
+			rules = append(rules, fmt.Sprintf(
+				"-s %[1]s/32 -p %[2]s -m %[2]s "+
+					"--dport %[3]s -o %[4]s "+
+					"-j MASQUERADE",
+				dbc.IP, port.protocol, dport(port.min, port.max),
+				publicInterface,
+			))
 		}
 	}
 
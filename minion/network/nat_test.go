@@ -69,24 +69,54 @@ func TestPreroutingRules(t *testing.T) {
 			From:    []string{blueprint.PublicInternetLabel},
 			To:      []string{"red"},
 			MinPort: 80,
+			MaxPort: 80,
 		},
 		{
 			From:    []string{blueprint.PublicInternetLabel},
 			To:      []string{"purple"},
 			MinPort: 81,
+			MaxPort: 81,
 		},
 		{
 			From:    []string{"yellow"},
 			To:      []string{blueprint.PublicInternetLabel},
 			MinPort: 80,
+			MaxPort: 80,
+		},
+		{
+			From:     []string{blueprint.PublicInternetLabel},
+			To:       []string{"red"},
+			MinPort:  53,
+			MaxPort:  53,
+			Protocol: "udp",
+		},
+		{
+			From:       []string{blueprint.PublicInternetLabel},
+			To:         []string{"purple"},
+			MinPort:    80,
+			MaxPort:    80,
+			PublicPort: 8080,
+			Protocol:   "tcp",
+		},
+		{
+			From:     []string{blueprint.PublicInternetLabel},
+			To:       []string{"red"},
+			MinPort:  9000,
+			MaxPort:  9010,
+			Protocol: "tcp",
 		},
 	}
 
 	actual := preroutingRules("eth0", containers, connections)
+	sort.Strings(actual)
 	exp := []string{
 		"-i eth0 -p tcp -m tcp --dport 80 -j DNAT --to-destination 8.8.8.8:80",
-		"-i eth0 -p udp -m udp --dport 80 -j DNAT --to-destination 8.8.8.8:80",
+		"-i eth0 -p tcp -m tcp --dport 8080 -j DNAT --to-destination 9.9.9.9:80",
 		"-i eth0 -p tcp -m tcp --dport 81 -j DNAT --to-destination 9.9.9.9:81",
+		"-i eth0 -p tcp -m tcp --dport 9000:9010 -j DNAT " +
+			"--to-destination 8.8.8.8",
+		"-i eth0 -p udp -m udp --dport 53 -j DNAT --to-destination 8.8.8.8:53",
+		"-i eth0 -p udp -m udp --dport 80 -j DNAT --to-destination 8.8.8.8:80",
 		"-i eth0 -p udp -m udp --dport 81 -j DNAT --to-destination 9.9.9.9:81",
 	}
 	assert.Equal(t, exp, actual)
@@ -111,17 +141,41 @@ func TestPostroutingRules(t *testing.T) {
 			From:    []string{"red"},
 			To:      []string{blueprint.PublicInternetLabel},
 			MinPort: 80,
+			MaxPort: 80,
 		},
 		{
 			From:    []string{"purple"},
 			To:      []string{blueprint.PublicInternetLabel},
 			MinPort: 81,
+			MaxPort: 81,
+		},
+		{
+			From:     []string{"red"},
+			To:       []string{blueprint.PublicInternetLabel},
+			MinPort:  443,
+			MaxPort:  443,
+			Protocol: "tcp",
+		},
+		{
+			From:     []string{"red"},
+			To:       []string{blueprint.PublicInternetLabel},
+			MinPort:  5000,
+			MaxPort:  5100,
+			Protocol: "udp",
+		},
+		{
+			From:     []string{"purple"},
+			To:       []string{blueprint.PublicInternetLabel},
+			Protocol: "icmp",
 		},
 	}
 
 	exp := []string{
+		"-s 8.8.8.8/32 -p tcp -m tcp --dport 443 -o eth0 -j MASQUERADE",
 		"-s 8.8.8.8/32 -p tcp -m tcp --dport 80 -o eth0 -j MASQUERADE",
+		"-s 8.8.8.8/32 -p udp -m udp --dport 5000:5100 -o eth0 -j MASQUERADE",
 		"-s 8.8.8.8/32 -p udp -m udp --dport 80 -o eth0 -j MASQUERADE",
+		"-s 9.9.9.9/32 -p icmp -o eth0 -j MASQUERADE",
 		"-s 9.9.9.9/32 -p tcp -m tcp --dport 81 -o eth0 -j MASQUERADE",
 		"-s 9.9.9.9/32 -p udp -m udp --dport 81 -o eth0 -j MASQUERADE",
 	}
@@ -76,8 +76,48 @@ func runMaster(conn db.Conn) {
 
 	updateLogicalSwitch(ovsdbClient, containers)
 	updateLoadBalancerRouter(ovsdbClient)
-	updateLoadBalancers(ovsdbClient, loadBalancers, hostnameToIP)
-	updateACLs(ovsdbClient, connections, hostnameToIP)
+	updateLoadBalancers(ovsdbClient, loadBalancers, hostnameToIP,
+		unreadyIPs(containers))
+	updateACLs(ovsdbClient, connections,
+		hostnameToIPs(hostnameToIP, containers))
+}
+
+// hostnameToIPs returns the IPs that each hostname refers to in ACLs. While a
+// container is being replaced by a rolling update, its hostname refers to the
+// retiring container, but the replacement must also be able to communicate so
+// that it can become ready.
+func hostnameToIPs(hostnameToIP map[string]string,
+	containers []db.Container) map[string][]string {
+
+	ips := map[string][]string{}
+	for hostname, ip := range hostnameToIP {
+		ips[hostname] = []string{ip}
+	}
+
+	for _, dbc := range containers {
+		ip, ok := hostnameToIP[dbc.Hostname]
+		if ok && ip != dbc.IP {
+			ips[dbc.Hostname] = append(ips[dbc.Hostname], dbc.IP)
+		}
+	}
+	return ips
+}
+
+// unreadyIPs returns the IPs of the containers that are failing their
+// readiness checks. Readiness is tracked per IP rather than per hostname
+// because during a rolling update the retiring container and its replacement
+// share a hostname, and the retiring container should keep serving until the
+// replacement is ready. Containers without readiness checks are always
+// considered ready, so that they aren't affected by delays in syncing pod
+// statuses.
+func unreadyIPs(containers []db.Container) map[string]struct{} {
+	unready := map[string]struct{}{}
+	for _, dbc := range containers {
+		if dbc.ReadinessCheck != nil && !dbc.Ready {
+			unready[dbc.IP] = struct{}{}
+		}
+	}
+	return unready
 }
 
 func updateLogicalSwitch(ovsdbClient ovsdb.Client, containers []db.Container) {
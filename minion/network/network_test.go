@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	"github.com/kelda/kelda/blueprint"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion/ipdef"
 	"github.com/kelda/kelda/minion/ovsdb"
@@ -125,3 +126,58 @@ func TestUpdateLoadBalancerRouter(t *testing.T) {
 	updateLoadBalancerRouter(client)
 	client.AssertExpectations(t)
 }
+
+func TestUnreadyIPs(t *testing.T) {
+	t.Parallel()
+
+	check := &blueprint.HealthCheck{Exec: []string{"true"}}
+	assert.Equal(t, map[string]struct{}{"10.0.0.2": {}}, unreadyIPs(
+		[]db.Container{
+			{IP: "10.0.0.2", Hostname: "unready", ReadinessCheck: check},
+			{IP: "10.0.0.3", Hostname: "ready", ReadinessCheck: check,
+				Ready: true},
+			{IP: "10.0.0.4", Hostname: "unchecked"},
+		}))
+}
+
+func TestRollingUpdateReadiness(t *testing.T) {
+	t.Parallel()
+
+	// The retiring container keeps receiving traffic while its replacement,
+	// which shares its hostname, is still failing its readiness check.
+	check := &blueprint.HealthCheck{Exec: []string{"true"}}
+	containers := []db.Container{
+		{IP: "10.0.0.3", Hostname: "web", ReadinessCheck: check, Ready: true,
+			Retiring: true},
+		{IP: "10.0.0.4", Hostname: "web", ReadinessCheck: check},
+	}
+	hostnameToIP := map[string]string{"web": "10.0.0.3"}
+
+	client := new(mocks.Client)
+	client.On("ListLoadBalancers").Return(nil, nil).Once()
+	client.On("CreateLoadBalancer", lSwitch, "web",
+		map[string]string{"10.0.0.2": "10.0.0.3"}).Return(nil).Once()
+	updateLoadBalancerIPs(client, []db.LoadBalancer{
+		{Name: "web", IP: "10.0.0.2", Hostnames: []string{"web"}},
+	}, hostnameToIP, unreadyIPs(containers))
+	client.AssertExpectations(t)
+}
+
+func TestHostnameToIPs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[string][]string{
+		"lb":  {"10.0.0.1"},
+		"web": {"10.0.0.2", "10.0.0.3"},
+		"db":  {"10.0.0.4"},
+	}, hostnameToIPs(map[string]string{
+		"lb":  "10.0.0.1",
+		"web": "10.0.0.2",
+		"db":  "10.0.0.4",
+	}, []db.Container{
+		{Hostname: "web", IP: "10.0.0.2", Retiring: true},
+		{Hostname: "web", IP: "10.0.0.3"},
+		{Hostname: "db", IP: "10.0.0.4"},
+		{Hostname: "new", IP: "10.0.0.5"},
+	}))
+}
@@ -3,8 +3,10 @@ package openflow
 import (
 	"fmt"
 	"os/exec"
+	"sort"
 	"strings"
 
+	"github.com/kelda/kelda/blueprint"
 	"github.com/kelda/kelda/counter"
 	"github.com/kelda/kelda/minion/ipdef"
 	"github.com/kelda/kelda/minion/ovsdb"
@@ -95,17 +97,23 @@ Table_2 {
 			output:veth
 		}
 
+		// Port ranges are matched with one flow per port/mask pair.
 		for each toPub {
 			// Response packets have toPub as the source port.
-			[tcp|udp],dl_dst=dbc.mac,ip_dst=dbc.ip,tp_src=toPub,
+			toPub.protocol,dl_dst=dbc.mac,ip_dst=dbc.ip,tp_src=toPub,
 				actions=output:veth
 		}
 
 		for each fromPub {
 			// Inbound packets have toPub as the destination port.
-			[tcp|udp],dl_dst=dbc.mac,ip_dst=dbc.ip,tp_dst=fromPub,
+			fromPub.protocol,dl_dst=dbc.mac,ip_dst=dbc.ip,tp_dst=fromPub,
 				actions=output:veth
 		}
+
+		if icmp in toPub or fromPub {
+			// ICMP has no ports.
+			icmp,dl_dst=dbc.mac,ip_dst=dbc.ip,actions=output:veth
+		}
         }
 }
 
@@ -126,15 +134,19 @@ Table_3 {
 	for each db.Container {
 		for each toPub {
 			// Outbound packets have fromPub as the destination port.
-			[tcp|udp],dl_src=dbc.mac,ip_src=dbc.ip,tp_dst=toPub,
+			toPub.protocol,dl_src=dbc.mac,ip_src=dbc.ip,tp_dst=toPub,
 				actions=output:LOCAL
 		}
 
 		for each fromPub {
 			// Response packets have fromPub as the source port.
-			[tcp|udp],dl_src=dbc.mac,ip_src=dbc.ip,tp_src=fromPub,
+			fromPub.protocol,dl_src=dbc.mac,ip_src=dbc.ip,tp_src=fromPub,
 				actions=output:LOCAL
 		}
+
+		if icmp in toPub or fromPub {
+			icmp,dl_src=dbc.mac,ip_src=dbc.ip,actions=output:LOCAL
+		}
 	}
 }
 
@@ -148,8 +160,15 @@ type Container struct {
 	IP    string
 
 	// Set of ports going to and from the public internet.
-	ToPub   map[int]struct{}
-	FromPub map[int]struct{}
+	ToPub   map[PortRange]struct{}
+	FromPub map[PortRange]struct{}
+}
+
+// A PortRange is an inclusive range of ports of a transport protocol. ICMP
+// ranges have no ports.
+type PortRange struct {
+	Protocol string
+	Min, Max int
 }
 
 type container struct {
@@ -242,35 +261,95 @@ func containerFlows(c container) []string {
 			"action=output:%d", c.Mac, ipdef.GatewayIP, c.vethPort),
 	}
 
-	table2 := "table=2,priority=500,%s,dl_dst=%s,ip_dst=%s,tp_src=%d," +
+	table2 := "table=2,priority=500,%s,dl_dst=%s,ip_dst=%s,tp_src=%s," +
 		"actions=output:%d"
-	table3 := "table=3,priority=500,%s,dl_src=%s,ip_src=%s,tp_dst=%d," +
+	table3 := "table=3,priority=500,%s,dl_src=%s,ip_src=%s,tp_dst=%s," +
 		"actions=output:LOCAL"
-	for to := range c.Container.ToPub {
-		flows = append(flows,
-			fmt.Sprintf(table2, "tcp", c.Mac, c.IP, to, c.vethPort),
-			fmt.Sprintf(table2, "udp", c.Mac, c.IP, to, c.vethPort),
-
-			fmt.Sprintf(table3, "tcp", c.Mac, c.IP, to),
-			fmt.Sprintf(table3, "udp", c.Mac, c.IP, to))
+	icmp := false
+	for _, to := range sortedPorts(c.Container.ToPub) {
+		if to.Protocol == blueprint.ICMPProtocol {
+			icmp = true
+			continue
+		}
+		for _, port := range portMatches(to.Min, to.Max) {
+			flows = append(flows,
+				fmt.Sprintf(table2, to.Protocol, c.Mac, c.IP, port,
+					c.vethPort),
+				fmt.Sprintf(table3, to.Protocol, c.Mac, c.IP, port))
+		}
 	}
 
-	table2 = "table=2,priority=500,%s,dl_dst=%s,ip_dst=%s,tp_dst=%d," +
+	table2 = "table=2,priority=500,%s,dl_dst=%s,ip_dst=%s,tp_dst=%s," +
 		"actions=output:%d"
-	table3 = "table=3,priority=500,%s,dl_src=%s,ip_src=%s,tp_src=%d," +
+	table3 = "table=3,priority=500,%s,dl_src=%s,ip_src=%s,tp_src=%s," +
 		"actions=output:LOCAL"
-	for from := range c.Container.FromPub {
-		flows = append(flows,
-			fmt.Sprintf(table2, "tcp", c.Mac, c.IP, from, c.vethPort),
-			fmt.Sprintf(table2, "udp", c.Mac, c.IP, from, c.vethPort),
+	for _, from := range sortedPorts(c.Container.FromPub) {
+		if from.Protocol == blueprint.ICMPProtocol {
+			icmp = true
+			continue
+		}
+		for _, port := range portMatches(from.Min, from.Max) {
+			flows = append(flows,
+				fmt.Sprintf(table2, from.Protocol, c.Mac, c.IP, port,
+					c.vethPort),
+				fmt.Sprintf(table3, from.Protocol, c.Mac, c.IP, port))
+		}
+	}
 
-			fmt.Sprintf(table3, "tcp", c.Mac, c.IP, from),
-			fmt.Sprintf(table3, "udp", c.Mac, c.IP, from))
+	// ICMP packets have no ports, so the same flows allow both directions.
+	if icmp {
+		flows = append(flows,
+			fmt.Sprintf("table=2,priority=500,icmp,dl_dst=%s,ip_dst=%s,"+
+				"actions=output:%d", c.Mac, c.IP, c.vethPort),
+			fmt.Sprintf("table=3,priority=500,icmp,dl_src=%s,ip_src=%s,"+
+				"actions=output:LOCAL", c.Mac, c.IP))
 	}
 
 	return flows
 }
 
+// sortedPorts returns the ports in a consistent order, so that the flows don't
+// change between runs.
+func sortedPorts(ports map[PortRange]struct{}) []PortRange {
+	var sorted []PortRange
+	for port := range ports {
+		sorted = append(sorted, port)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Min != sorted[j].Min {
+			return sorted[i].Min < sorted[j].Min
+		}
+		if sorted[i].Max != sorted[j].Max {
+			return sorted[i].Max < sorted[j].Max
+		}
+		return sorted[i].Protocol < sorted[j].Protocol
+	})
+	return sorted
+}
+
+// portMatches returns the transport port matches that together cover the
+// inclusive range. OpenFlow can't match ranges directly, so ranges are split
+// into the fewest port/mask pairs, e.g. 8000-8010 becomes 0x1f40/0xfff8 and
+// 0x1f48/0xfffe and 0x1f4a/0xffff.
+func portMatches(min, max int) []string {
+	if min == max {
+		return []string{fmt.Sprintf("%d", min)}
+	}
+
+	var matches []string
+	for min <= max {
+		// Grow the block while it stays aligned and within the range.
+		size := 1
+		for min%(size*2) == 0 && min+size*2-1 <= max {
+			size *= 2
+		}
+		matches = append(matches,
+			fmt.Sprintf("0x%x/0x%x", min, 0xffff&^(size-1)))
+		min += size
+	}
+	return matches
+}
+
 func allFlows(containers []container) []string {
 	var gatewayBroadcastActions []string
 	for _, c := range containers {
@@ -73,16 +73,19 @@ func TestAllFlows(t *testing.T) {
 		patchPort: 4,
 		vethPort:  5,
 		Container: Container{
-			IP:    "6.7.8.9",
-			Mac:   "66:66:66:66:66:66",
-			ToPub: map[int]struct{}{5: {}}},
+			IP:  "6.7.8.9",
+			Mac: "66:66:66:66:66:66",
+			ToPub: map[PortRange]struct{}{
+				{"tcp", 5, 5}: {}, {"udp", 5, 5}: {},
+				{"icmp", 0, 0}: {}}},
 	}, {
 		patchPort: 9,
 		vethPort:  8,
 		Container: Container{
-			IP:      "9.8.7.6",
-			Mac:     "99:99:99:99:99:99",
-			FromPub: map[int]struct{}{8: {}}}}})
+			IP:  "9.8.7.6",
+			Mac: "99:99:99:99:99:99",
+			FromPub: map[PortRange]struct{}{
+				{"udp", 8, 8}: {}, {"tcp", 10, 13}: {}}}}})
 	exp := append(staticFlows,
 		"table=0,in_port=5,dl_src=66:66:66:66:66:66,"+
 			"actions=load:0x4->NXM_NX_REG0[],resubmit(,1)",
@@ -92,31 +95,53 @@ func TestAllFlows(t *testing.T) {
 			"action=output:5",
 		"table=2,priority=500,tcp,dl_dst=66:66:66:66:66:66,ip_dst=6.7.8.9,"+
 			"tp_src=5,actions=output:5",
-		"table=2,priority=500,udp,dl_dst=66:66:66:66:66:66,ip_dst=6.7.8.9,"+
-			"tp_src=5,actions=output:5",
 		"table=3,priority=500,tcp,dl_src=66:66:66:66:66:66,ip_src=6.7.8.9,"+
 			"tp_dst=5,actions=output:LOCAL",
+		"table=2,priority=500,udp,dl_dst=66:66:66:66:66:66,ip_dst=6.7.8.9,"+
+			"tp_src=5,actions=output:5",
 		"table=3,priority=500,udp,dl_src=66:66:66:66:66:66,ip_src=6.7.8.9,"+
 			"tp_dst=5,actions=output:LOCAL",
+		"table=2,priority=500,icmp,dl_dst=66:66:66:66:66:66,ip_dst=6.7.8.9,"+
+			"actions=output:5",
+		"table=3,priority=500,icmp,dl_src=66:66:66:66:66:66,ip_src=6.7.8.9,"+
+			"actions=output:LOCAL",
 		"table=0,in_port=8,dl_src=99:99:99:99:99:99,"+
 			"actions=load:0x9->NXM_NX_REG0[],resubmit(,1)",
 		"table=0,in_port=9,actions=output:8",
 		"table=2,priority=900,arp,dl_dst=99:99:99:99:99:99,action=output:8",
 		"table=2,priority=800,ip,dl_dst=99:99:99:99:99:99,nw_src=10.0.0.1,"+
 			"action=output:8",
-		"table=2,priority=500,tcp,dl_dst=99:99:99:99:99:99,ip_dst=9.8.7.6,"+
-			"tp_dst=8,actions=output:8",
 		"table=2,priority=500,udp,dl_dst=99:99:99:99:99:99,ip_dst=9.8.7.6,"+
 			"tp_dst=8,actions=output:8",
-		"table=3,priority=500,tcp,dl_src=99:99:99:99:99:99,ip_src=9.8.7.6,"+
-			"tp_src=8,actions=output:LOCAL",
 		"table=3,priority=500,udp,dl_src=99:99:99:99:99:99,ip_src=9.8.7.6,"+
 			"tp_src=8,actions=output:LOCAL",
+		"table=2,priority=500,tcp,dl_dst=99:99:99:99:99:99,ip_dst=9.8.7.6,"+
+			"tp_dst=0xa/0xfffe,actions=output:8",
+		"table=3,priority=500,tcp,dl_src=99:99:99:99:99:99,ip_src=9.8.7.6,"+
+			"tp_src=0xa/0xfffe,actions=output:LOCAL",
+		"table=2,priority=500,tcp,dl_dst=99:99:99:99:99:99,ip_dst=9.8.7.6,"+
+			"tp_dst=0xc/0xfffe,actions=output:8",
+		"table=3,priority=500,tcp,dl_src=99:99:99:99:99:99,ip_src=9.8.7.6,"+
+			"tp_src=0xc/0xfffe,actions=output:LOCAL",
 		"table=2,priority=1000,dl_dst=ff:ff:ff:ff:ff:ff,"+
 			"actions=output:5,output:8")
 	assert.Equal(t, exp, flows)
 }
 
+func TestPortMatches(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"80"}, portMatches(80, 80))
+	assert.Equal(t, []string{"0x0/0xfffe"}, portMatches(0, 1))
+	assert.Equal(t, []string{"0x1f40/0xfff8", "0x1f48/0xfffe", "0x1f4a/0xffff"},
+		portMatches(8000, 8010))
+	assert.Equal(t, []string{"0x1/0xffff", "0x2/0xfffe", "0x4/0xfffc",
+		"0x8/0xfff8", "0x10/0xfff0", "0x20/0xffe0", "0x40/0xffc0",
+		"0x80/0xff80", "0x100/0xff00", "0x200/0xfe00", "0x400/0xfc00",
+		"0x800/0xf800", "0x1000/0xf000", "0x2000/0xe000", "0x4000/0xc000",
+		"0x8000/0x8000"}, portMatches(1, 65535))
+}
+
 func TestResolveContainers(t *testing.T) {
 	t.Parallel()
 
@@ -38,8 +38,8 @@ func updateOpenflow(conn db.Conn, myIP string) {
 }
 
 func openflowContainers(dbcs []db.Container, conns []db.Connection) []Container {
-	fromPubPorts := map[string][]int{}
-	toPubPorts := map[string][]int{}
+	fromPubPorts := map[string][]PortRange{}
+	toPubPorts := map[string][]PortRange{}
 	for _, conn := range conns {
 		for _, from := range conn.From {
 			for _, to := range conn.To {
@@ -48,21 +48,24 @@ func openflowContainers(dbcs []db.Container, conns []db.Connection) []Container
 					continue
 				}
 
-				if conn.MinPort != conn.MaxPort {
-					c.Inc("Unsupported Public Port Range")
-					log.WithField("connection", conn).Debug(
-						"Unsupported Public Port Range")
-					continue
+				// Inbound packets have already been forwarded from
+				// the public port, so only the container's ports
+				// matter.
+				var ports []PortRange
+				for _, protocol := range blueprint.Protocols(
+					conn.Protocol) {
+					ports = append(ports, PortRange{protocol,
+						conn.MinPort, conn.MaxPort})
 				}
 
 				if from == blueprint.PublicInternetLabel {
 					fromPubPorts[to] = append(fromPubPorts[to],
-						conn.MinPort)
+						ports...)
 				}
 
 				if to == blueprint.PublicInternetLabel {
 					toPubPorts[from] = append(toPubPorts[from],
-						conn.MinPort)
+						ports...)
 				}
 			}
 		}
@@ -78,8 +81,8 @@ func openflowContainers(dbcs []db.Container, conns []db.Connection) []Container
 			Mac:   ipdef.IPStrToMac(dbc.IP),
 			IP:    dbc.IP,
 
-			ToPub:   map[int]struct{}{},
-			FromPub: map[int]struct{}{},
+			ToPub:   map[PortRange]struct{}{},
+			FromPub: map[PortRange]struct{}{},
 		}
 
 		for _, p := range toPubPorts[dbc.Hostname] {
@@ -24,15 +24,37 @@ func TestOpenflowContainers(t *testing.T) {
 			From:    []string{"foo"},
 			To:      []string{"public"},
 			MinPort: 1,
-			MaxPort: 1000}})
+			MaxPort: 1000,
+		}, {
+			From:       []string{"public"},
+			To:         []string{"foo"},
+			MinPort:    8000,
+			MaxPort:    8000,
+			PublicPort: 9000,
+			Protocol:   "tcp",
+		}, {
+			From:     []string{"public"},
+			To:       []string{"foo"},
+			MinPort:  53,
+			MaxPort:  53,
+			Protocol: "udp",
+		}, {
+			From:     []string{"foo"},
+			To:       []string{"public"},
+			Protocol: "icmp"}})
 
 	assert.Equal(t, []Container{{
-		Veth:    "1.2.3.4",
-		Patch:   "q_1.2.3.4",
-		Mac:     "02:00:01:02:03:04",
-		IP:      "1.2.3.4",
-		ToPub:   map[int]struct{}{22: {}},
-		FromPub: map[int]struct{}{80: {}},
+		Veth:  "1.2.3.4",
+		Patch: "q_1.2.3.4",
+		Mac:   "02:00:01:02:03:04",
+		IP:    "1.2.3.4",
+		ToPub: map[PortRange]struct{}{
+			{"tcp", 22, 22}: {}, {"udp", 22, 22}: {},
+			{"tcp", 1, 1000}: {}, {"udp", 1, 1000}: {},
+			{"icmp", 0, 0}: {}},
+		FromPub: map[PortRange]struct{}{
+			{"tcp", 80, 80}: {}, {"udp", 80, 80}: {},
+			{"tcp", 8000, 8000}: {}, {"udp", 53, 53}: {}},
 	}}, containers)
 
 }
@@ -8,11 +8,14 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/kelda/kelda/counter"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion/docker"
 	"sync"
 )
 
+var c = counter.New("Registry")
+
 /*
 The registry submodule builds custom Dockerfiles. When a custom Dockerfile is
 deployed in a blueprint (e.g.`new Container({ name: 'name', image:
@@ -70,8 +73,10 @@ func syncImages(conn db.Conn, dk docker.Client) {
 		writeImage(conn, img)
 
 		log.WithField("image", img.Name).Info("Building image...")
+		start := time.Now()
 		repoDigest, err := updateRegistry(dk, myIP, img)
 		if err != nil {
+			c.Inc("Build Failed")
 			img.Status = "" // Unset the building status.
 
 			log.WithError(err).WithField("image", img.Name).
@@ -79,6 +84,8 @@ func syncImages(conn db.Conn, dk docker.Client) {
 			return
 		}
 
+		c.Inc("Build")
+		c.Observe("Build", time.Since(start).Seconds())
 		img.RepoDigest = repoDigest
 		img.Status = db.Built
 
@@ -28,8 +28,9 @@ import (
 
 var c = counter.New("Minion")
 
-// Run blocks executing the minion.
-func Run(role db.Role, inboundPubIntf, outboundPubIntf string) {
+// Run blocks executing the minion. Prometheus metrics are served on
+// `metricsAddr`, unless it's empty.
+func Run(role db.Role, inboundPubIntf, outboundPubIntf, metricsAddr string) {
 	// XXX Uncomment the following line to run the profiler
 	//runProfiler(5 * time.Minute)
 
@@ -83,6 +84,9 @@ func Run(role db.Role, inboundPubIntf, outboundPubIntf string) {
 		return
 	}
 
+	if metricsAddr != "" {
+		go serveMetrics(metricsAddr)
+	}
 	go minionServerRun(conn, creds)
 	go apiServer.Run(conn, fmt.Sprintf("tcp://0.0.0.0:%d", api.DefaultRemotePort),
 		false, creds)
@@ -94,6 +98,12 @@ func Run(role db.Role, inboundPubIntf, outboundPubIntf string) {
 
 }
 
+func serveMetrics(addr string) {
+	err := counter.ServeMetrics(addr)
+	log.WithError(err).WithField("address", addr).Error(
+		"Failed to serve metrics")
+}
+
 func runProfiler(duration time.Duration) {
 	go func() {
 		p := pprofile.New("minion")
@@ -5,12 +5,14 @@ import (
 	"sort"
 	"strings"
 
+	apiServer "github.com/kelda/kelda/api/server"
 	"github.com/kelda/kelda/blueprint"
 	"github.com/kelda/kelda/connection"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion/pb"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -20,7 +22,13 @@ type server struct {
 }
 
 func minionServerRun(conn db.Conn, creds connection.Credentials) {
-	sock, s := connection.Server("tcp", ":9999", creds.ServerOpts())
+	// User certificates are signed by the same CA as the daemon's, so they must
+	// be refused explicitly. Otherwise users could bypass their roles by
+	// configuring the minion directly.
+	unary, stream := apiServer.InternalInterceptors()
+	opts := append(creds.ServerOpts(), grpc.UnaryInterceptor(unary),
+		grpc.StreamInterceptor(stream))
+	sock, s := connection.Server("tcp", ":9999", opts)
 	server := server{conn}
 	pb.RegisterMinionServer(s, server)
 	s.Serve(sock)
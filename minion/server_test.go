@@ -1,12 +1,23 @@
 package minion
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-
+	"golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+
+	"github.com/kelda/kelda/api"
+	apiServer "github.com/kelda/kelda/api/server"
 	"github.com/kelda/kelda/blueprint"
 	"github.com/kelda/kelda/db"
 	"github.com/kelda/kelda/minion/pb"
@@ -202,3 +213,36 @@ func TestGetMinionConfig(t *testing.T) {
 		AuthorizedKeys: []string{"key1", "key2"},
 	}, *cfg)
 }
+
+func TestMinionServerRefusesUsers(t *testing.T) {
+	t.Parallel()
+
+	unary, _ := apiServer.InternalInterceptors()
+	info := &grpc.UnaryServerInfo{FullMethod: "/Minion/SetMinionConfig"}
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return &pb.Reply{}, nil
+	}
+
+	// Even admins must go through the daemon, which enforces roles and
+	// revocations.
+	user := certContext(api.UserSubject("alice", api.AdminRole))
+	_, err := unary(user, &pb.MinionConfig{}, info, handler)
+	assert.Equal(t, codes.PermissionDenied, grpc.Code(err))
+	assert.False(t, called)
+
+	daemon := certContext(pkix.Name{CommonName: "kelda:daemon"})
+	_, err = unary(daemon, &pb.MinionConfig{}, info, handler)
+	assert.NoError(t, err)
+	assert.True(t, called)
+}
+
+func certContext(subject pkix.Name) context.Context {
+	cert := &x509.Certificate{Subject: subject, SerialNumber: big.NewInt(1)}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+		}},
+	})
+}
@@ -101,7 +101,7 @@ resources:
 						Type:   "bind",
 					},
 				},
-				Args: kubeControllerManagerArgs(),
+				Args: kubeControllerManagerArgs(minion.Provider),
 			}, docker.RunOptions{
 				Name:  KubeSchedulerName,
 				Image: kubeImage,
@@ -146,6 +146,10 @@ func kubeAPIServerArgs(ip string, etcdIPs []string) []string {
 		"--kubelet-client-certificate=" +
 			tlsIO.SignedCertPath(cliPath.MinionTLSDir),
 		"--kubelet-client-key=" + tlsIO.SignedKeyPath(cliPath.MinionTLSDir),
+		// Connect to kubelets by their private IP, which is the only
+		// address in their certificates, even if their nodes are named
+		// after their instances.
+		"--kubelet-preferred-address-types=InternalIP",
 		"--tls-ca-file=" + tlsIO.CACertPath(cliPath.MinionTLSDir),
 		"--tls-cert-file=" + tlsIO.SignedCertPath(cliPath.MinionTLSDir),
 		"--tls-private-key-file=" + tlsIO.SignedKeyPath(cliPath.MinionTLSDir),
@@ -156,13 +160,14 @@ func kubeAPIServerArgs(ip string, etcdIPs []string) []string {
 	}
 }
 
-func kubeControllerManagerArgs() []string {
-	return []string{
+func kubeControllerManagerArgs(provider string) []string {
+	args := []string{
 		"kube-controller-manager", "--master=http://localhost:8080",
 		"--service-account-private-key-file=" +
 			tlsIO.SignedKeyPath(cliPath.MinionTLSDir),
 		"--pod-eviction-timeout=30s",
 	}
+	return append(args, cloudProviderArgs(provider)...)
 }
 
 func kubeSchedulerArgs() []string {
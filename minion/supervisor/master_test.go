@@ -66,7 +66,7 @@ func TestMaster(t *testing.T) {
 	util.WriteFile(cliPath.MinionKubeSecretPath, []byte("secret"), 0644)
 	runMasterOnce()
 	exp[KubeAPIServerName] = kubeAPIServerArgs(ip, etcdIPs)
-	exp[KubeControllerManagerName] = kubeControllerManagerArgs()
+	exp[KubeControllerManagerName] = kubeControllerManagerArgs("")
 	exp[KubeSchedulerName] = kubeSchedulerArgs()
 	assert.Equal(t, exp, ctx.fd.running())
 
@@ -105,7 +105,7 @@ func TestEtcdAdd(t *testing.T) {
 		OvsdbName:                 {"ovsdb-server"},
 		RegistryName:              nil,
 		KubeAPIServerName:         kubeAPIServerArgs(ip, etcdIPs),
-		KubeControllerManagerName: kubeControllerManagerArgs(),
+		KubeControllerManagerName: kubeControllerManagerArgs(""),
 		KubeSchedulerName:         kubeSchedulerArgs(),
 	}
 	assert.Equal(t, exp, ctx.fd.running())
@@ -149,7 +149,7 @@ func TestEtcdRemove(t *testing.T) {
 		OvsdbName:                 {"ovsdb-server"},
 		RegistryName:              nil,
 		KubeAPIServerName:         kubeAPIServerArgs(ip, etcdIPs),
-		KubeControllerManagerName: kubeControllerManagerArgs(),
+		KubeControllerManagerName: kubeControllerManagerArgs(""),
 		KubeSchedulerName:         kubeSchedulerArgs(),
 	}
 	assert.Equal(t, exp, ctx.fd.running())
@@ -218,6 +218,32 @@ func filesHash(filepathToContent map[string]string) string {
 	return fmt.Sprintf("%x", sha1.Sum([]byte(toHash)))
 }
 
+// cloudProviderArgs returns the flags that enable Kubernetes' integration with
+// the machine's cloud provider, which creates and attaches the disks of cloud
+// volumes. Only Google machines are allowed to manage disks, so Kubernetes
+// can't integrate with the other providers.
+func cloudProviderArgs(provider string) []string {
+	switch db.ProviderName(provider) {
+	case db.Google:
+		return []string{"--cloud-provider=gce"}
+	default:
+		return nil
+	}
+}
+
+// nodeNameArgs returns the flags that set the name of the machine's Kubernetes
+// node. Nodes are named by their private IP, except on Google, where
+// Kubernetes' cloud provider looks up instances by node name. Google nodes
+// keep the machine's hostname, which is the name of its instance. Either way,
+// the name matches the CommonName of the certificate the kubelet authenticates
+// with, which is set in cloud/credentials.go.
+func nodeNameArgs(myIP, provider string) []string {
+	if db.ProviderName(provider) == db.Google {
+		return nil
+	}
+	return []string{"--hostname-override", myIP}
+}
+
 // execRun() is a global variable so that it can be mocked out by the unit tests.
 var execRun = func(name string, arg ...string) ([]byte, error) {
 	c.Inc(name)
@@ -109,3 +109,19 @@ func TestJoinContainersWithStopped(t *testing.T) {
 	assert.True(t, foundStoppedContainer,
 		"the stopped container should still exist, but be renamed")
 }
+
+func TestCloudProviderArgs(t *testing.T) {
+	assert.Empty(t, cloudProviderArgs(string(db.Amazon)))
+	assert.Equal(t, []string{"--cloud-provider=gce"},
+		cloudProviderArgs(string(db.Google)))
+	assert.Empty(t, cloudProviderArgs(string(db.DigitalOcean)))
+	assert.Empty(t, cloudProviderArgs(""))
+}
+
+func TestNodeNameArgs(t *testing.T) {
+	assert.Equal(t, []string{"--hostname-override", "1.2.3.4"},
+		nodeNameArgs("1.2.3.4", string(db.Amazon)))
+	assert.Equal(t, []string{"--hostname-override", "1.2.3.4"},
+		nodeNameArgs("1.2.3.4", ""))
+	assert.Empty(t, nodeNameArgs("1.2.3.4", string(db.Google)))
+}
@@ -151,7 +151,7 @@ func runWorkerOnce() {
 						Type:   "bind",
 					},
 				},
-				Args: kubeletArgs(minion.PrivateIP),
+				Args: kubeletArgs(minion.PrivateIP, minion.Provider),
 				FilepathToContent: map[string]string{
 					"/var/lib/kubelet/kubeconfig": string(
 						kubeconfigBytes),
@@ -165,20 +165,21 @@ func runWorkerOnce() {
 	joinContainers(desiredContainers)
 }
 
-func kubeletArgs(myIP string) []string {
-	return []string{"kubelet",
+func kubeletArgs(myIP, provider string) []string {
+	args := []string{"kubelet",
 		"--pod-cidr=10.0.0.0/24",
 		"--network-plugin=cni",
 		"--resolv-conf=/kelda_resolv.conf",
 		"--make-iptables-util-chains=false",
 		"--kubeconfig=/var/lib/kubelet/kubeconfig",
-		"--hostname-override", myIP,
 		"--anonymous-auth=false",
 		"--client-ca-file", tlsIO.CACertPath(cliPath.MinionTLSDir),
 		"--tls-cert-file", tlsIO.SignedCertPath(cliPath.MinionTLSDir),
 		"--tls-private-key-file", tlsIO.SignedKeyPath(cliPath.MinionTLSDir),
 		"--allow-privileged",
 	}
+	args = append(args, nodeNameArgs(myIP, provider)...)
+	return append(args, cloudProviderArgs(provider)...)
 }
 
 func cfgOVNImpl(myIP, leaderIP string) error {
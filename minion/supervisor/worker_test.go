@@ -61,7 +61,7 @@ func TestWorker(t *testing.T) {
 		OvsdbName:         {"ovsdb-server"},
 		OvncontrollerName: {"ovn-controller"},
 		OvsvswitchdName:   {"ovs-vswitchd"},
-		KubeletName:       kubeletArgs(ip),
+		KubeletName:       kubeletArgs(ip, ""),
 	}
 	assert.Equal(t, exp, ctx.fd.running())
 